@@ -80,7 +80,7 @@ func main() {
 	case "http":
 		startHTTPServer(cfg, logger, sigChan)
 	case "mcp":
-		startMCPServer(logger, sigChan)
+		startMCPServer(cfg, logger, sigChan)
 	case "mcp-http":
 		startMCPHTTPServer(cfg, logger, sigChan)
 	case "mcp-streamable":
@@ -102,10 +102,13 @@ func startHTTPServer(cfg *config.Config, logger *logrus.Logger, sigChan chan os.
 
 	<-sigChan
 	logger.Info("Shutting down HTTP server...")
+	if err := httpServer.Close(); err != nil {
+		logger.Errorf("Failed to stop supervised services: %v", err)
+	}
 }
 
-func startMCPServer(logger *logrus.Logger, sigChan chan os.Signal) {
-	mcpServer := mcp.NewServer(logger)
+func startMCPServer(cfg *config.Config, logger *logrus.Logger, sigChan chan os.Signal) {
+	mcpServer := mcp.NewServer(cfg, logger)
 
 	go func() {
 		logger.Info("Starting MCP server...")
@@ -114,6 +117,9 @@ func startMCPServer(logger *logrus.Logger, sigChan chan os.Signal) {
 
 	<-sigChan
 	logger.Info("Shutting down MCP server...")
+	if err := mcpServer.Close(); err != nil {
+		logger.Errorf("Failed to stop supervised services: %v", err)
+	}
 }
 
 func startMCPHTTPServer(cfg *config.Config, logger *logrus.Logger, sigChan chan os.Signal) {
@@ -128,6 +134,9 @@ func startMCPHTTPServer(cfg *config.Config, logger *logrus.Logger, sigChan chan
 
 	<-sigChan
 	logger.Info("Shutting down MCP HTTP server...")
+	if err := mcpHTTPServer.Close(); err != nil {
+		logger.Errorf("Failed to stop supervised services: %v", err)
+	}
 }
 
 func startMCPStreamableServer(cfg *config.Config, logger *logrus.Logger, sigChan chan os.Signal) {
@@ -142,6 +151,9 @@ func startMCPStreamableServer(cfg *config.Config, logger *logrus.Logger, sigChan
 
 	<-sigChan
 	logger.Info("Shutting down MCP Streamable server...")
+	if err := mcpStreamableServer.Close(); err != nil {
+		logger.Errorf("Failed to stop supervised services: %v", err)
+	}
 }
 
 func showHelp() {
@@ -189,6 +201,8 @@ Supported service types:
   - systemd (modern Linux distributions)
   - System V init (traditional Linux distributions)
   - Docker containers
+  - Native processes supervised by this server (see "native" in config.yaml)
+  - External manager plugins (see "plugins" in config.yaml)
 
 The server will automatically detect available service managers on your system.
 `, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
@@ -3,6 +3,7 @@ module nucc.com/mcp_srv_mgr
 go 1.21
 
 require (
+	github.com/godbus/dbus/v5 v5.1.0
 	github.com/gorilla/mux v1.8.0
 	github.com/sirupsen/logrus v1.9.3
 	gopkg.in/yaml.v2 v2.4.0
@@ -1,16 +1,30 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
 )
 
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	Log    LogConfig    `yaml:"log"`
+	Server   ServerConfig     `yaml:"server"`
+	Log      LogConfig        `yaml:"log"`
+	Timeouts TimeoutConfig    `yaml:"timeouts"`
+	Systemd  SystemdConfig    `yaml:"systemd,omitempty"`
+	SysV     SysVConfig       `yaml:"sysv,omitempty"`
+	Scripts  []ScriptService  `yaml:"scripts,omitempty"`
+	Native   []NativeService  `yaml:"native,omitempty"`
+	Plugins  []PluginConfig   `yaml:"plugins,omitempty"`
+	Health   []HealthCheck    `yaml:"health,omitempty"`
+	Watchdog []WatchdogPolicy `yaml:"watchdog,omitempty"`
 }
 
 type ServerConfig struct {
@@ -24,6 +38,391 @@ type LogConfig struct {
 	Output string `yaml:"output"`
 }
 
+// TimeoutConfig holds the default deadline applied to each service manager
+// operation. Operations without a dedicated entry use Default; a zero value
+// disables the deadline.
+type TimeoutConfig struct {
+	Default time.Duration `yaml:"default"`
+	Start   time.Duration `yaml:"start"`
+	Stop    time.Duration `yaml:"stop"`
+	Restart time.Duration `yaml:"restart"`
+	Enable  time.Duration `yaml:"enable"`
+	Disable time.Duration `yaml:"disable"`
+	Status  time.Duration `yaml:"status"`
+	List    time.Duration `yaml:"list"`
+}
+
+// ForOperation returns the configured timeout for the named operation.
+func (t TimeoutConfig) ForOperation(operation string) time.Duration {
+	var timeout time.Duration
+	switch operation {
+	case "start":
+		timeout = t.Start
+	case "stop":
+		timeout = t.Stop
+	case "restart":
+		timeout = t.Restart
+	case "enable":
+		timeout = t.Enable
+	case "disable":
+		timeout = t.Disable
+	case "status":
+		timeout = t.Status
+	case "list":
+		timeout = t.List
+	}
+
+	if timeout == 0 {
+		return t.Default
+	}
+	return timeout
+}
+
+// WithTimeout derives a context bounded by the operation's configured timeout.
+func (t TimeoutConfig) WithTimeout(parent context.Context, operation string) (context.Context, context.CancelFunc) {
+	if timeout := t.ForOperation(operation); timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// SystemdConfig selects which systemd instances are managed.
+type SystemdConfig struct {
+	// Scope is used when a request names none: "system" (the default),
+	// "user" for the user running the server, or "user:<name>".
+	Scope string `yaml:"scope,omitempty"`
+	// UserUnits lists the units of user managers alongside system units:
+	// those of Users and, when running as root, of every lingering user.
+	UserUnits bool     `yaml:"user_units,omitempty"`
+	Users     []string `yaml:"users,omitempty"`
+	// UnitDir is where unit files written through the API are installed
+	// for the system manager; it defaults to /etc/systemd/system. The
+	// previous version of a replaced or removed file is kept in BackupDir,
+	// or next to the unit with a .bak suffix.
+	UnitDir   string `yaml:"unit_dir,omitempty"`
+	BackupDir string `yaml:"backup_dir,omitempty"`
+}
+
+// validate checks the scope syntax; see managers.ParseSystemdScope.
+func (c SystemdConfig) validate() error {
+	switch {
+	case c.Scope == "" || c.Scope == "system" || c.Scope == "user":
+	case strings.HasPrefix(c.Scope, "user:") && len(c.Scope) > len("user:"):
+	default:
+		return fmt.Errorf("systemd: invalid scope %q, expected system, user or user:<name>", c.Scope)
+	}
+	for i, user := range c.Users {
+		if user == "" {
+			return fmt.Errorf("systemd: users[%d] is empty", i)
+		}
+	}
+	return nil
+}
+
+// SysVConfig configures the System V init manager.
+type SysVConfig struct {
+	// Logs maps a service name to the files it logs to, read by the log
+	// API. Services without an entry use /var/log/<name>.log and
+	// /var/log/<name>/*.log.
+	Logs map[string][]string `yaml:"logs,omitempty"`
+}
+
+// ScriptService declares a service that no init system manages. Commands
+// are run with /bin/sh -c. Status is taken from the exit code of Status,
+// following the LSB convention (0 running, 1-2 dead, 3 stopped), or from
+// whether the process in PIDFile is alive when Status is empty.
+type ScriptService struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Start       string            `yaml:"start"`
+	Stop        string            `yaml:"stop"`
+	Status      string            `yaml:"status,omitempty"`
+	Reload      string            `yaml:"reload,omitempty"`
+	PIDFile     string            `yaml:"pidfile,omitempty"`
+	WorkDir     string            `yaml:"workdir,omitempty"`
+	Env         map[string]string `yaml:"env,omitempty"`
+	User        string            `yaml:"user,omitempty"`
+}
+
+// NativeService declares a process that mcp_srv_mgr spawns and supervises
+// itself. Command is run with /bin/sh -c; its stdout and stderr go to
+// rotating files under Logs.Dir.
+type NativeService struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Command     string            `yaml:"command"`
+	WorkDir     string            `yaml:"workdir,omitempty"`
+	Env         map[string]string `yaml:"env,omitempty"`
+	User        string            `yaml:"user,omitempty"`
+	// Autostart starts the process when the server starts.
+	Autostart bool `yaml:"autostart,omitempty"`
+	// Restart is "always", "on-failure" (the default) or "never".
+	Restart string        `yaml:"restart,omitempty"`
+	Backoff BackoffConfig `yaml:"backoff,omitempty"`
+	// MaxRestarts gives up after that many consecutive restarts; zero
+	// retries forever.
+	MaxRestarts int             `yaml:"max_restarts,omitempty"`
+	StopTimeout time.Duration   `yaml:"stop_timeout,omitempty"`
+	Logs        NativeLogConfig `yaml:"logs,omitempty"`
+}
+
+// BackoffConfig is an exponential backoff: the delay starts at Initial and
+// doubles up to Max.
+type BackoffConfig struct {
+	Initial time.Duration `yaml:"initial,omitempty"`
+	Max     time.Duration `yaml:"max,omitempty"`
+}
+
+// NativeLogConfig controls where a native service's output is written and
+// how it is rotated.
+type NativeLogConfig struct {
+	Dir      string `yaml:"dir,omitempty"`
+	MaxSize  int64  `yaml:"max_size,omitempty"`
+	MaxFiles int    `yaml:"max_files,omitempty"`
+}
+
+// PluginConfig declares an external manager. Command is started once and
+// kept running; it receives JSON-RPC requests on stdin and answers on stdout.
+// Name becomes the service type of every service the plugin reports.
+type PluginConfig struct {
+	Name    string            `yaml:"name"`
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args,omitempty"`
+	WorkDir string            `yaml:"workdir,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+}
+
+// HealthCheck declares a probe of a service's health. Exactly one of HTTP,
+// TCP and Exec is set. The probe runs every Interval (30s by default) and
+// fails after Timeout (5s); it turns healthy after HealthyThreshold (1)
+// consecutive successes and unhealthy after UnhealthyThreshold (3)
+// consecutive failures.
+type HealthCheck struct {
+	Service string `yaml:"service"`
+	// Type restricts the check to services of that type; empty matches the
+	// service name under any type.
+	Type string `yaml:"type,omitempty"`
+	// Name tells several probes of one service apart; it defaults to the
+	// probe kind.
+	Name               string        `yaml:"name,omitempty"`
+	HTTP               *HTTPProbe    `yaml:"http,omitempty"`
+	TCP                *TCPProbe     `yaml:"tcp,omitempty"`
+	Exec               *ExecProbe    `yaml:"exec,omitempty"`
+	Interval           time.Duration `yaml:"interval,omitempty"`
+	Timeout            time.Duration `yaml:"timeout,omitempty"`
+	HealthyThreshold   int           `yaml:"healthy_threshold,omitempty"`
+	UnhealthyThreshold int           `yaml:"unhealthy_threshold,omitempty"`
+}
+
+// HTTPProbe succeeds when URL answers with one of ExpectStatus, any 2xx or
+// 3xx status by default, and its body contains ExpectBody.
+type HTTPProbe struct {
+	URL          string `yaml:"url"`
+	Method       string `yaml:"method,omitempty"`
+	ExpectStatus []int  `yaml:"expect_status,omitempty"`
+	ExpectBody   string `yaml:"expect_body,omitempty"`
+}
+
+// TCPProbe succeeds when a connection to Address (host:port) is accepted.
+type TCPProbe struct {
+	Address string `yaml:"address"`
+}
+
+// ExecProbe succeeds when Command, run with /bin/sh -c, exits with 0.
+type ExecProbe struct {
+	Command string `yaml:"command"`
+}
+
+// Kind returns the probe kind: http, tcp or exec.
+func (h HealthCheck) Kind() string {
+	switch {
+	case h.HTTP != nil:
+		return "http"
+	case h.TCP != nil:
+		return "tcp"
+	default:
+		return "exec"
+	}
+}
+
+// WatchdogPolicy has the server restart a service that failed, or that is
+// active but unhealthy according to its health checks. Restarts are spaced by
+// Backoff (30s, doubling up to 5m) and limited to MaxAttempts (5) within
+// Window (1h); once the budget is spent Escalate runs and the service is left
+// alone until old attempts leave the window. The service is checked every
+// Interval (30s). After a restart, unhealthy counts again only once a probe
+// failed after it, so Backoff.Initial should cover the service's startup.
+type WatchdogPolicy struct {
+	Service string `yaml:"service"`
+	// Type and Scope select the service as in API requests; without a type
+	// every manager is asked for the service.
+	Type  string `yaml:"type,omitempty"`
+	Scope string `yaml:"scope,omitempty"`
+	// On lists the conditions that trigger a restart, "failed" and
+	// "unhealthy"; both by default.
+	On          []string      `yaml:"on,omitempty"`
+	Interval    time.Duration `yaml:"interval,omitempty"`
+	Backoff     BackoffConfig `yaml:"backoff,omitempty"`
+	MaxAttempts int           `yaml:"max_attempts,omitempty"`
+	Window      time.Duration `yaml:"window,omitempty"`
+	// Escalate is run with /bin/sh -c when the restart budget is exhausted,
+	// with WATCHDOG_SERVICE, WATCHDOG_TYPE, WATCHDOG_REASON and
+	// WATCHDOG_ATTEMPTS set in its environment.
+	Escalate string `yaml:"escalate,omitempty"`
+}
+
+// Watchdog conditions.
+const (
+	WatchOnFailed    = "failed"
+	WatchOnUnhealthy = "unhealthy"
+)
+
+// validateWatchdog checks that every policy names a service once and only
+// known conditions.
+func (c *Config) validateWatchdog() error {
+	seen := make(map[string]bool)
+	for i, policy := range c.Watchdog {
+		if policy.Service == "" {
+			return fmt.Errorf("watchdog[%d]: service is required", i)
+		}
+		key := policy.Type + "/" + policy.Scope + "/" + policy.Service
+		if seen[key] {
+			return fmt.Errorf("watchdog[%d]: duplicate policy for %s", i, policy.Service)
+		}
+		seen[key] = true
+
+		for _, on := range policy.On {
+			if on != WatchOnFailed && on != WatchOnUnhealthy {
+				return fmt.Errorf("watchdog policy of %s: unknown condition %q, expected failed or unhealthy", policy.Service, on)
+			}
+		}
+		if policy.Scope != "" && policy.Type != "" && policy.Type != string(types.ServiceTypeSystemd) {
+			return fmt.Errorf("watchdog policy of %s: scope is only supported for systemd services", policy.Service)
+		}
+		if policy.Interval < 0 || policy.Window < 0 || policy.MaxAttempts < 0 || policy.Backoff.Initial < 0 || policy.Backoff.Max < 0 {
+			return fmt.Errorf("watchdog policy of %s: interval, window, max_attempts and backoff must not be negative", policy.Service)
+		}
+	}
+	return nil
+}
+
+// validateHealth checks that every health check names a service and exactly
+// one usable probe.
+func (c *Config) validateHealth() error {
+	seen := make(map[string]bool)
+	for i, check := range c.Health {
+		if check.Service == "" {
+			return fmt.Errorf("health[%d]: service is required", i)
+		}
+		probes := 0
+		for _, set := range []bool{check.HTTP != nil, check.TCP != nil, check.Exec != nil} {
+			if set {
+				probes++
+			}
+		}
+		if probes != 1 {
+			return fmt.Errorf("health check of %s: exactly one of http, tcp and exec is required", check.Service)
+		}
+		switch {
+		case check.HTTP != nil && !strings.HasPrefix(check.HTTP.URL, "http://") && !strings.HasPrefix(check.HTTP.URL, "https://"):
+			return fmt.Errorf("health check of %s: http url must start with http:// or https://", check.Service)
+		case check.TCP != nil && check.TCP.Address == "":
+			return fmt.Errorf("health check of %s: tcp address is required", check.Service)
+		case check.Exec != nil && check.Exec.Command == "":
+			return fmt.Errorf("health check of %s: exec command is required", check.Service)
+		}
+		if check.Interval < 0 || check.Timeout < 0 || check.HealthyThreshold < 0 || check.UnhealthyThreshold < 0 {
+			return fmt.Errorf("health check of %s: interval, timeout and thresholds must not be negative", check.Service)
+		}
+
+		name := check.Name
+		if name == "" {
+			name = check.Kind()
+		}
+		key := check.Type + "/" + check.Service + "/" + name
+		if seen[key] {
+			return fmt.Errorf("health[%d]: duplicate %s probe of %s, set a distinct name", i, name, check.Service)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// pluginNamePattern keeps plugin names usable as URL path segments.
+var pluginNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// validateScripts checks that every script service can be driven.
+func (c *Config) validateScripts() error {
+	seen := make(map[string]bool)
+	for i, script := range c.Scripts {
+		if script.Name == "" {
+			return fmt.Errorf("scripts[%d]: name is required", i)
+		}
+		if seen[script.Name] {
+			return fmt.Errorf("scripts[%d]: duplicate name %q", i, script.Name)
+		}
+		seen[script.Name] = true
+
+		if script.Start == "" || script.Stop == "" {
+			return fmt.Errorf("script %s: start and stop commands are required", script.Name)
+		}
+		if script.Status == "" && script.PIDFile == "" {
+			return fmt.Errorf("script %s: a status command or a pidfile is required", script.Name)
+		}
+	}
+	return nil
+}
+
+// validateNative checks that every native service can be spawned.
+func (c *Config) validateNative() error {
+	seen := make(map[string]bool)
+	for i, native := range c.Native {
+		if native.Name == "" {
+			return fmt.Errorf("native[%d]: name is required", i)
+		}
+		if seen[native.Name] {
+			return fmt.Errorf("native[%d]: duplicate name %q", i, native.Name)
+		}
+		seen[native.Name] = true
+
+		if native.Command == "" {
+			return fmt.Errorf("native service %s: command is required", native.Name)
+		}
+		switch native.Restart {
+		case "", "always", "on-failure", "never":
+		default:
+			return fmt.Errorf("native service %s: unknown restart policy %q", native.Name, native.Restart)
+		}
+		if native.MaxRestarts < 0 {
+			return fmt.Errorf("native service %s: max_restarts must not be negative", native.Name)
+		}
+	}
+	return nil
+}
+
+// validatePlugins checks that plugin names are unique and do not shadow a
+// built-in service type.
+func (c *Config) validatePlugins() error {
+	seen := make(map[string]bool)
+	for _, builtin := range types.BuiltinServiceTypes {
+		seen[string(builtin)] = true
+	}
+	for i, plugin := range c.Plugins {
+		if !pluginNamePattern.MatchString(plugin.Name) {
+			return fmt.Errorf("plugins[%d]: name %q must be lowercase letters, digits, '-' or '_'", i, plugin.Name)
+		}
+		if seen[plugin.Name] {
+			return fmt.Errorf("plugins[%d]: service type %q is already in use", i, plugin.Name)
+		}
+		seen[plugin.Name] = true
+
+		if plugin.Command == "" {
+			return fmt.Errorf("plugin %s: command is required", plugin.Name)
+		}
+	}
+	return nil
+}
+
 func Load(configPath string) (*Config, error) {
 	// Default configuration
 	config := &Config{
@@ -36,6 +435,14 @@ func Load(configPath string) (*Config, error) {
 			Format: "json",
 			Output: "stdout",
 		},
+		Timeouts: TimeoutConfig{
+			Default: 60 * time.Second,
+			Start:   90 * time.Second,
+			Stop:    90 * time.Second,
+			Restart: 180 * time.Second,
+			Status:  15 * time.Second,
+			List:    30 * time.Second,
+		},
 	}
 
 	// Load from file if exists
@@ -49,6 +456,25 @@ func Load(configPath string) (*Config, error) {
 			if err := yaml.Unmarshal(data, config); err != nil {
 				return nil, fmt.Errorf("failed to parse config file: %v", err)
 			}
+
+			if err := config.validateScripts(); err != nil {
+				return nil, fmt.Errorf("invalid config file: %v", err)
+			}
+			if err := config.validateNative(); err != nil {
+				return nil, fmt.Errorf("invalid config file: %v", err)
+			}
+			if err := config.validatePlugins(); err != nil {
+				return nil, fmt.Errorf("invalid config file: %v", err)
+			}
+			if err := config.validateHealth(); err != nil {
+				return nil, fmt.Errorf("invalid config file: %v", err)
+			}
+			if err := config.validateWatchdog(); err != nil {
+				return nil, fmt.Errorf("invalid config file: %v", err)
+			}
+			if err := config.Systemd.validate(); err != nil {
+				return nil, fmt.Errorf("invalid config file: %v", err)
+			}
 		}
 	}
 
@@ -85,4 +511,4 @@ func (c *Config) SaveToFile(filename string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
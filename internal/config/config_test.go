@@ -1,9 +1,11 @@
 package config
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoad_Default(t *testing.T) {
@@ -270,4 +272,437 @@ func TestConfig_EmptyFile(t *testing.T) {
 	if config.Server.Port != 8080 {
 		t.Errorf("Expected default port, got %d", config.Server.Port)
 	}
-}
\ No newline at end of file
+}
+func TestLoad_Timeouts(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "timeouts.yaml")
+
+	configContent := `
+timeouts:
+  default: 20s
+  stop: 2m
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if got := config.Timeouts.ForOperation("stop"); got != 2*time.Minute {
+		t.Errorf("Expected stop timeout 2m, got %s", got)
+	}
+	// 未在文件中覆盖的值保留默认值
+	if got := config.Timeouts.ForOperation("start"); got != 90*time.Second {
+		t.Errorf("Expected default start timeout 90s, got %s", got)
+	}
+	// 没有专门配置的操作使用default
+	if got := config.Timeouts.ForOperation("enable"); got != 20*time.Second {
+		t.Errorf("Expected enable to fall back to default 20s, got %s", got)
+	}
+}
+
+func TestTimeoutConfig_WithTimeout(t *testing.T) {
+	timeouts := TimeoutConfig{Status: time.Second}
+
+	ctx, cancel := timeouts.WithTimeout(context.Background(), "status")
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("Expected status context to carry a deadline")
+	}
+
+	ctx, cancel = timeouts.WithTimeout(context.Background(), "start")
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected zero timeout to leave the context without a deadline")
+	}
+}
+
+func TestLoad_Scripts(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "scripts.yaml")
+
+	configContent := `
+scripts:
+  - name: minecraft
+    description: Minecraft server in tmux
+    start: tmux new-session -d -s mc 'java -jar server.jar'
+    stop: tmux send-keys -t mc stop Enter
+    status: tmux has-session -t mc
+    workdir: /srv/minecraft
+    user: games
+    env:
+      JAVA_OPTS: -Xmx2G
+  - name: vendor
+    start: /opt/vendor/bin/start.sh
+    stop: /opt/vendor/bin/stop.sh
+    pidfile: /opt/vendor/run/vendor.pid
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(config.Scripts) != 2 {
+		t.Fatalf("Expected 2 script services, got %d", len(config.Scripts))
+	}
+	mc := config.Scripts[0]
+	if mc.Name != "minecraft" || mc.User != "games" || mc.WorkDir != "/srv/minecraft" || mc.Env["JAVA_OPTS"] != "-Xmx2G" {
+		t.Errorf("Unexpected script service: %+v", mc)
+	}
+	if config.Scripts[1].PIDFile != "/opt/vendor/run/vendor.pid" {
+		t.Errorf("Unexpected pidfile: %s", config.Scripts[1].PIDFile)
+	}
+}
+
+func TestLoad_InvalidScripts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing name", "scripts:\n  - start: a\n    stop: b\n    status: c\n"},
+		{"missing stop", "scripts:\n  - name: x\n    start: a\n    status: c\n"},
+		{"no status source", "scripts:\n  - name: x\n    start: a\n    stop: b\n"},
+		{"duplicate", "scripts:\n  - {name: x, start: a, stop: b, status: c}\n  - {name: x, start: a, stop: b, status: c}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+			if _, err := Load(configFile); err == nil {
+				t.Error("Expected invalid script declaration to be rejected")
+			}
+		})
+	}
+}
+
+func TestLoad_Native(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "native.yaml")
+
+	configContent := `
+native:
+  - name: api
+    description: Internal API
+    command: ./api --port 9000
+    workdir: /srv/api
+    autostart: true
+    restart: always
+    backoff:
+      initial: 2s
+      max: 30s
+    max_restarts: 5
+    stop_timeout: 15s
+    logs:
+      dir: /var/log/api
+      max_size: 1048576
+      max_files: 3
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(config.Native) != 1 {
+		t.Fatalf("Expected 1 native service, got %d", len(config.Native))
+	}
+	api := config.Native[0]
+	if api.Command != "./api --port 9000" || !api.Autostart || api.Restart != "always" || api.MaxRestarts != 5 {
+		t.Errorf("Unexpected native service: %+v", api)
+	}
+	if api.Backoff.Initial != 2*time.Second || api.Backoff.Max != 30*time.Second || api.StopTimeout != 15*time.Second {
+		t.Errorf("Unexpected durations: %+v", api)
+	}
+	if api.Logs.Dir != "/var/log/api" || api.Logs.MaxSize != 1048576 || api.Logs.MaxFiles != 3 {
+		t.Errorf("Unexpected log config: %+v", api.Logs)
+	}
+}
+
+func TestLoad_InvalidNative(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing name", "native:\n  - command: a\n"},
+		{"missing command", "native:\n  - name: x\n"},
+		{"unknown restart", "native:\n  - {name: x, command: a, restart: sometimes}\n"},
+		{"negative max_restarts", "native:\n  - {name: x, command: a, max_restarts: -1}\n"},
+		{"duplicate", "native:\n  - {name: x, command: a}\n  - {name: x, command: b}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+			if _, err := Load(configFile); err == nil {
+				t.Error("Expected invalid native declaration to be rejected")
+			}
+		})
+	}
+}
+
+func TestLoad_Plugins(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "plugins.yaml")
+
+	configContent := `
+plugins:
+  - name: appliance
+    command: /opt/appliance/bin/mcp-plugin
+    args: ["--profile", "prod"]
+    workdir: /opt/appliance
+    env:
+      APPLIANCE_HOST: 10.0.0.5
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(config.Plugins) != 1 {
+		t.Fatalf("Expected 1 plugin, got %d", len(config.Plugins))
+	}
+	plugin := config.Plugins[0]
+	if plugin.Name != "appliance" || plugin.Command != "/opt/appliance/bin/mcp-plugin" || len(plugin.Args) != 2 || plugin.Env["APPLIANCE_HOST"] != "10.0.0.5" {
+		t.Errorf("Unexpected plugin: %+v", plugin)
+	}
+}
+
+func TestLoad_InvalidPlugins(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing name", "plugins:\n  - command: a\n"},
+		{"invalid name", "plugins:\n  - {name: My Plugin, command: a}\n"},
+		{"missing command", "plugins:\n  - name: x\n"},
+		{"builtin type", "plugins:\n  - {name: systemd, command: a}\n"},
+		{"duplicate", "plugins:\n  - {name: x, command: a}\n  - {name: x, command: b}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+			if _, err := Load(configFile); err == nil {
+				t.Error("Expected invalid plugin declaration to be rejected")
+			}
+		})
+	}
+}
+
+func TestLoad_Systemd(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "systemd.yaml")
+	configContent := `
+systemd:
+  scope: user:alice
+  user_units: true
+  users: [alice, bob]
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Systemd.Scope != "user:alice" || !config.Systemd.UserUnits || len(config.Systemd.Users) != 2 {
+		t.Errorf("Unexpected systemd config: %+v", config.Systemd)
+	}
+
+	for _, content := range []string{"systemd:\n  scope: session\n", "systemd:\n  scope: \"user:\"\n", "systemd:\n  users: [\"\"]\n"} {
+		if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+		if _, err := Load(configFile); err == nil {
+			t.Errorf("Expected %q to be rejected", content)
+		}
+	}
+}
+
+func TestLoad_SysVLogs(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "sysv.yaml")
+
+	configContent := `
+sysv:
+  logs:
+    nginx:
+      - /var/log/nginx/access.log
+      - /var/log/nginx/error.log
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	logs := config.SysV.Logs["nginx"]
+	if len(logs) != 2 || logs[1] != "/var/log/nginx/error.log" {
+		t.Errorf("Unexpected SysV log files: %v", config.SysV.Logs)
+	}
+}
+
+func TestLoad_Health(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "health.yaml")
+
+	configContent := `
+health:
+  - service: nginx
+    http:
+      url: http://127.0.0.1/healthz
+      expect_status: [200, 204]
+    interval: 10s
+    unhealthy_threshold: 2
+  - service: nginx
+    name: port
+    tcp:
+      address: 127.0.0.1:443
+  - service: worker
+    type: native
+    exec:
+      command: ./check-queue
+    timeout: 2s
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(config.Health) != 3 {
+		t.Fatalf("Expected 3 health checks, got %d", len(config.Health))
+	}
+	http := config.Health[0]
+	if http.Kind() != "http" || len(http.HTTP.ExpectStatus) != 2 || http.Interval != 10*time.Second || http.UnhealthyThreshold != 2 {
+		t.Errorf("Unexpected http check: %+v", http)
+	}
+	if config.Health[1].Kind() != "tcp" || config.Health[1].Name != "port" {
+		t.Errorf("Unexpected tcp check: %+v", config.Health[1])
+	}
+	if exec := config.Health[2]; exec.Kind() != "exec" || exec.Type != "native" || exec.Timeout != 2*time.Second {
+		t.Errorf("Unexpected exec check: %+v", exec)
+	}
+}
+
+func TestLoad_InvalidHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing service", "health:\n  - tcp: {address: ':80'}\n"},
+		{"missing probe", "health:\n  - service: x\n"},
+		{"two probes", "health:\n  - {service: x, tcp: {address: ':80'}, exec: {command: 'true'}}\n"},
+		{"bad url", "health:\n  - {service: x, http: {url: 'localhost/healthz'}}\n"},
+		{"missing address", "health:\n  - {service: x, tcp: {}}\n"},
+		{"negative threshold", "health:\n  - {service: x, tcp: {address: ':80'}, unhealthy_threshold: -1}\n"},
+		{"duplicate", "health:\n  - {service: x, tcp: {address: ':80'}}\n  - {service: x, tcp: {address: ':81'}}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+			if _, err := Load(configFile); err == nil {
+				t.Error("Expected invalid health check to be rejected")
+			}
+		})
+	}
+}
+
+func TestLoad_Watchdog(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "watchdog.yaml")
+
+	configContent := `
+watchdog:
+  - service: nginx
+    type: systemd
+    on: [failed]
+    interval: 15s
+    backoff:
+      initial: 5s
+      max: 2m
+    max_attempts: 3
+    window: 30m
+    escalate: /usr/local/bin/page-oncall
+  - service: worker
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(config.Watchdog) != 2 {
+		t.Fatalf("Expected 2 watchdog policies, got %d", len(config.Watchdog))
+	}
+	nginx := config.Watchdog[0]
+	if len(nginx.On) != 1 || nginx.On[0] != WatchOnFailed || nginx.MaxAttempts != 3 || nginx.Escalate != "/usr/local/bin/page-oncall" {
+		t.Errorf("Unexpected policy: %+v", nginx)
+	}
+	if nginx.Interval != 15*time.Second || nginx.Backoff.Initial != 5*time.Second || nginx.Backoff.Max != 2*time.Minute || nginx.Window != 30*time.Minute {
+		t.Errorf("Unexpected durations: %+v", nginx)
+	}
+}
+
+func TestLoad_InvalidWatchdog(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing service", "watchdog:\n  - type: systemd\n"},
+		{"unknown condition", "watchdog:\n  - {service: x, on: [inactive]}\n"},
+		{"scope of container", "watchdog:\n  - {service: x, type: docker, scope: user}\n"},
+		{"negative max_attempts", "watchdog:\n  - {service: x, max_attempts: -1}\n"},
+		{"duplicate", "watchdog:\n  - {service: x}\n  - {service: x}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+			if _, err := Load(configFile); err == nil {
+				t.Error("Expected invalid watchdog policy to be rejected")
+			}
+		})
+	}
+}
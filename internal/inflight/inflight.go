@@ -0,0 +1,92 @@
+// Package inflight tracks MCP requests that are still being processed so a
+// later notifications/cancelled message can abort them. It is shared by the
+// stdio and HTTP servers.
+package inflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrCancelled is the cancellation cause used when the client sends
+// notifications/cancelled for a request that is still running.
+var ErrCancelled = errors.New("request cancelled by client")
+
+// Requests maps the IDs of running requests to their cancel functions.
+type Requests struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}
+
+func New() *Requests {
+	return &Requests{cancels: make(map[string]context.CancelCauseFunc)}
+}
+
+// Begin derives the context a request runs under. The context is cancelled
+// when parent or session is done, or when Cancel is called with the same ID.
+// session may be nil. The returned function must be called once the request
+// has finished.
+func (r *Requests) Begin(parent, session context.Context, id interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	stop := func() bool { return false }
+	if session != nil {
+		stop = context.AfterFunc(session, func() { cancel(context.Cause(session)) })
+	}
+
+	key := Key(id)
+	if id != nil {
+		r.mu.Lock()
+		r.cancels[key] = cancel
+		r.mu.Unlock()
+	}
+
+	return ctx, func() {
+		if id != nil {
+			r.mu.Lock()
+			delete(r.cancels, key)
+			r.mu.Unlock()
+		}
+		stop()
+		cancel(nil)
+	}
+}
+
+// Cancel aborts the in-flight request with the given ID. It reports whether
+// such a request was found.
+func (r *Requests) Cancel(id interface{}) bool {
+	r.mu.Lock()
+	cancel, ok := r.cancels[Key(id)]
+	delete(r.cancels, Key(id))
+	r.mu.Unlock()
+
+	if ok {
+		cancel(ErrCancelled)
+	}
+	return ok
+}
+
+// CancelledByClient reports whether ctx was aborted through
+// notifications/cancelled, in which case no response must be sent for the
+// request.
+func CancelledByClient(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrCancelled)
+}
+
+// CancelledRequestID extracts params.requestId from a notifications/cancelled
+// message.
+func CancelledRequestID(params interface{}) (interface{}, bool) {
+	p, ok := params.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	id, ok := p["requestId"]
+	return id, ok && id != nil
+}
+
+// Key normalises a JSON-RPC ID. IDs decoded from JSON are strings or float64
+// values; both print the same way as the client sent them.
+func Key(id interface{}) string {
+	return fmt.Sprint(id)
+}
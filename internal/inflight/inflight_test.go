@@ -0,0 +1,79 @@
+package inflight
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequests_CancelByID(t *testing.T) {
+	inflight := New()
+
+	// JSON 解码后的数字 ID 为 float64
+	ctx, done := inflight.Begin(context.Background(), context.Background(), float64(3))
+	defer done()
+
+	if !inflight.Cancel(float64(3)) {
+		t.Fatal("Expected in-flight request to be found")
+	}
+	if ctx.Err() == nil {
+		t.Fatal("Expected request context to be cancelled")
+	}
+	if !CancelledByClient(ctx) {
+		t.Error("Expected cancellation to be attributed to the client")
+	}
+	if inflight.Cancel(float64(3)) {
+		t.Error("Expected request to be forgotten after cancellation")
+	}
+}
+
+func TestRequests_SessionClosed(t *testing.T) {
+	inflight := New()
+	session, closeSession := context.WithCancel(context.Background())
+
+	ctx, done := inflight.Begin(context.Background(), session, "abc")
+	defer done()
+
+	closeSession()
+	<-ctx.Done()
+
+	if CancelledByClient(ctx) {
+		t.Error("Session shutdown must not look like a client cancellation")
+	}
+}
+
+func TestRequests_NoSession(t *testing.T) {
+	inflight := New()
+
+	// 标准输入输出服务器没有会话上下文
+	ctx, done := inflight.Begin(context.Background(), nil, float64(1))
+	defer done()
+
+	if !inflight.Cancel("1") || !CancelledByClient(ctx) {
+		t.Error("Expected string and number IDs with the same text to match")
+	}
+}
+
+func TestRequests_Done(t *testing.T) {
+	inflight := New()
+
+	_, done := inflight.Begin(context.Background(), context.Background(), "abc")
+	done()
+
+	if inflight.Cancel("abc") {
+		t.Error("Expected finished request to be removed")
+	}
+}
+
+func TestCancelledRequestID(t *testing.T) {
+	id, ok := CancelledRequestID(map[string]interface{}{"requestId": float64(5), "reason": "user"})
+	if !ok || id != float64(5) {
+		t.Errorf("Expected requestId 5, got %v (%t)", id, ok)
+	}
+
+	if _, ok := CancelledRequestID(map[string]interface{}{}); ok {
+		t.Error("Expected missing requestId to be rejected")
+	}
+	if _, ok := CancelledRequestID(nil); ok {
+		t.Error("Expected nil params to be rejected")
+	}
+}
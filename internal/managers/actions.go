@@ -0,0 +1,229 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// Reloader is implemented by managers that can make a service re-read its
+// configuration without stopping it.
+type Reloader interface {
+	Reload(ctx context.Context, serviceName string) error
+}
+
+// UnitActionManager holds the systemd-only lifecycle actions.
+type UnitActionManager interface {
+	Reloader
+	// ReloadOrRestart reloads the service if it supports reloading and
+	// restarts it otherwise.
+	ReloadOrRestart(ctx context.Context, serviceName string) error
+	// TryRestart restarts the service only if it is running.
+	TryRestart(ctx context.Context, serviceName string) error
+	Mask(ctx context.Context, serviceName string) error
+	Unmask(ctx context.Context, serviceName string) error
+	ResetFailed(ctx context.Context, serviceName string) error
+}
+
+// Killer is implemented by managers that can signal a service's processes.
+type Killer interface {
+	Kill(ctx context.Context, serviceName string, signal Signal) error
+}
+
+// Pauser is implemented by managers that can freeze and thaw a service.
+type Pauser interface {
+	Pause(ctx context.Context, serviceName string) error
+	Unpause(ctx context.Context, serviceName string) error
+}
+
+// ServiceAction describes an action of PerformAction beyond start, stop,
+// restart, enable and disable.
+type ServiceAction struct {
+	Name        string
+	Description string
+}
+
+// ServiceActions are the extended lifecycle actions. Each is served as a
+// POST /services/{name}/{action} route and as an MCP tool, see
+// ServiceActionTool.
+var ServiceActions = []ServiceAction{
+	{"reload", "Reload a service's configuration without stopping it (systemd, sysv)"},
+	{"reload-or-restart", "Reload a service if it supports reloading, restart it otherwise (systemd)"},
+	{"try-restart", "Restart a service only if it is running (systemd)"},
+	{"kill", "Send a signal to a service's processes (systemd, docker)"},
+	{"mask", "Mask a service so that it cannot be started (systemd)"},
+	{"unmask", "Unmask a masked service (systemd)"},
+	{"reset-failed", "Reset the failed state of a service (systemd)"},
+	{"pause", "Pause all processes of a container (docker)"},
+	{"unpause", "Resume a paused container (docker)"},
+}
+
+// ServiceActionTool returns the MCP tool name of an action, e.g.
+// reload_or_restart_service.
+func ServiceActionTool(action string) string {
+	return strings.ReplaceAll(action, "-", "_") + "_service"
+}
+
+// ServiceActionForTool returns the action behind an MCP tool name of
+// ServiceActionTool.
+func ServiceActionForTool(tool string) (string, bool) {
+	for _, action := range ServiceActions {
+		if ServiceActionTool(action.Name) == tool {
+			return action.Name, true
+		}
+	}
+	return "", false
+}
+
+// UnsupportedActionError is returned by PerformAction when the manager does
+// not implement the action.
+type UnsupportedActionError struct {
+	Action string
+}
+
+func (e *UnsupportedActionError) Error() string {
+	return fmt.Sprintf("action %s is not supported by this service manager", e.Action)
+}
+
+// IsUnsupportedAction reports whether err is an UnsupportedActionError.
+func IsUnsupportedAction(err error) bool {
+	var unsupported *UnsupportedActionError
+	return errors.As(err, &unsupported)
+}
+
+// IsServiceAction reports whether PerformAction knows the action.
+func IsServiceAction(action string) bool {
+	switch action {
+	case "start", "stop", "restart", "enable", "disable":
+		return true
+	}
+	_, ok := ServiceActionForTool(ServiceActionTool(action))
+	return ok
+}
+
+// PerformAction runs a lifecycle action by name: start, stop, restart,
+// enable, disable or one of ServiceActions. signal is only used by kill.
+func PerformAction(ctx context.Context, manager types.ServiceManager, serviceName, action string, signal Signal) error {
+	switch action {
+	case "start":
+		return manager.Start(ctx, serviceName)
+	case "stop":
+		return manager.Stop(ctx, serviceName)
+	case "restart":
+		return manager.Restart(ctx, serviceName)
+	case "enable":
+		return manager.Enable(ctx, serviceName)
+	case "disable":
+		return manager.Disable(ctx, serviceName)
+	case "reload":
+		if reloader, ok := manager.(Reloader); ok {
+			return reloader.Reload(ctx, serviceName)
+		}
+	case "reload-or-restart", "try-restart", "mask", "unmask", "reset-failed":
+		units, ok := manager.(UnitActionManager)
+		if !ok {
+			break
+		}
+		switch action {
+		case "reload-or-restart":
+			return units.ReloadOrRestart(ctx, serviceName)
+		case "try-restart":
+			return units.TryRestart(ctx, serviceName)
+		case "mask":
+			return units.Mask(ctx, serviceName)
+		case "unmask":
+			return units.Unmask(ctx, serviceName)
+		default:
+			return units.ResetFailed(ctx, serviceName)
+		}
+	case "kill":
+		if killer, ok := manager.(Killer); ok {
+			return killer.Kill(ctx, serviceName, signal)
+		}
+	case "pause", "unpause":
+		pauser, ok := manager.(Pauser)
+		if !ok {
+			break
+		}
+		if action == "pause" {
+			return pauser.Pause(ctx, serviceName)
+		}
+		return pauser.Unpause(ctx, serviceName)
+	default:
+		return fmt.Errorf("unsupported action: %s", action)
+	}
+	return &UnsupportedActionError{Action: action}
+}
+
+// ActionMessage describes a completed action, e.g. "Service nginx reloaded
+// successfully".
+func ActionMessage(serviceName, action string) string {
+	done := map[string]string{
+		"start":             "started",
+		"stop":              "stopped",
+		"restart":           "restarted",
+		"enable":            "enabled",
+		"disable":           "disabled",
+		"reload":            "reloaded",
+		"reload-or-restart": "reloaded or restarted",
+		"try-restart":       "restarted if running",
+		"kill":              "signalled",
+		"mask":              "masked",
+		"unmask":            "unmasked",
+		"reset-failed":      "reset",
+		"pause":             "paused",
+		"unpause":           "unpaused",
+	}[action]
+	if done == "" {
+		return fmt.Sprintf("Service %s: %s completed successfully", serviceName, action)
+	}
+	return fmt.Sprintf("Service %s %s successfully", serviceName, done)
+}
+
+// Signal is a Linux signal number.
+type Signal int
+
+var signalNames = []string{
+	1: "HUP", 2: "INT", 3: "QUIT", 4: "ILL", 5: "TRAP", 6: "ABRT", 7: "BUS", 8: "FPE",
+	9: "KILL", 10: "USR1", 11: "SEGV", 12: "USR2", 13: "PIPE", 14: "ALRM", 15: "TERM",
+	16: "STKFLT", 17: "CHLD", 18: "CONT", 19: "STOP", 20: "TSTP", 21: "TTIN", 22: "TTOU",
+	23: "URG", 24: "XCPU", 25: "XFSZ", 26: "VTALRM", 27: "PROF", 28: "WINCH", 29: "IO",
+	30: "PWR", 31: "SYS",
+}
+
+// SIGTERM is the signal kill sends by default.
+const SIGTERM Signal = 15
+
+// String returns the signal's name, e.g. SIGHUP, or its number for
+// real-time signals.
+func (s Signal) String() string {
+	if int(s) < len(signalNames) && signalNames[s] != "" {
+		return "SIG" + signalNames[s]
+	}
+	return strconv.Itoa(int(s))
+}
+
+// ParseSignal accepts a signal name with or without the SIG prefix, in any
+// case, or a number from 1 to 64. An empty string means SIGTERM.
+func ParseSignal(value string) (Signal, error) {
+	if value == "" {
+		return SIGTERM, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 1 || n > 64 {
+			return 0, fmt.Errorf("invalid signal %q", value)
+		}
+		return Signal(n), nil
+	}
+	name := strings.TrimPrefix(strings.ToUpper(value), "SIG")
+	for n, signalName := range signalNames {
+		if signalName != "" && signalName == name {
+			return Signal(n), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid signal %q", value)
+}
@@ -0,0 +1,147 @@
+package managers
+
+import (
+	"context"
+	"testing"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+func TestParseSignal(t *testing.T) {
+	valid := map[string]Signal{
+		"":        SIGTERM,
+		"SIGHUP":  1,
+		"hup":     1,
+		"Kill":    9,
+		"sigusr1": 10,
+		"15":      15,
+		"34":      34,
+	}
+	for value, expected := range valid {
+		signal, err := ParseSignal(value)
+		if err != nil || signal != expected {
+			t.Errorf("ParseSignal(%q) = %v, %v; expected %v", value, signal, err, expected)
+		}
+	}
+
+	for _, value := range []string{"0", "65", "-1", "BOGUS", "SIG"} {
+		if _, err := ParseSignal(value); err == nil {
+			t.Errorf("Expected ParseSignal(%q) to fail", value)
+		}
+	}
+
+	if SIGTERM.String() != "SIGTERM" || Signal(34).String() != "34" {
+		t.Errorf("Unexpected signal names: %s, %s", SIGTERM, Signal(34))
+	}
+}
+
+func TestServiceActionTool(t *testing.T) {
+	for _, action := range ServiceActions {
+		tool := ServiceActionTool(action.Name)
+		if got, ok := ServiceActionForTool(tool); !ok || got != action.Name {
+			t.Errorf("ServiceActionForTool(%s) = %s, %t", tool, got, ok)
+		}
+		if !IsServiceAction(action.Name) {
+			t.Errorf("Expected %s to be a service action", action.Name)
+		}
+	}
+	if ServiceActionTool("reset-failed") != "reset_failed_service" {
+		t.Errorf("Unexpected tool name: %s", ServiceActionTool("reset-failed"))
+	}
+	// 基本操作已有各自的工具
+	if _, ok := ServiceActionForTool("start_service"); ok {
+		t.Error("Expected start_service not to be an extended action tool")
+	}
+	if IsServiceAction("explode") || !IsServiceAction("restart") {
+		t.Error("Unexpected IsServiceAction result")
+	}
+}
+
+func TestActionMessage(t *testing.T) {
+	if msg := ActionMessage("nginx", "stop"); msg != "Service nginx stopped successfully" {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+	if msg := ActionMessage("nginx", "reset-failed"); msg != "Service nginx reset successfully" {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+}
+
+func TestPerformAction_Unsupported(t *testing.T) {
+	manager := NewMockManager(types.ServiceTypeSystemd)
+	ctx := context.Background()
+
+	for _, action := range []string{"reload", "mask", "kill", "pause"} {
+		err := PerformAction(ctx, manager, "test-service-1", action, SIGTERM)
+		if !IsUnsupportedAction(err) {
+			t.Errorf("Expected %s to be unsupported, got %v", action, err)
+		}
+	}
+	if err := PerformAction(ctx, manager, "test-service-1", "explode", SIGTERM); err == nil || IsUnsupportedAction(err) {
+		t.Errorf("Expected unknown action error, got %v", err)
+	}
+	if err := PerformAction(ctx, manager, "test-service-1", "stop", SIGTERM); err != nil {
+		t.Errorf("PerformAction(stop) failed: %v", err)
+	}
+}
+
+func TestSystemdScopeManager_Actions(t *testing.T) {
+	manager, calls := newTestSystemdScopeManager(t, config.SystemdConfig{})
+	ctx := context.Background()
+
+	for _, action := range []string{"reload", "reload-or-restart", "try-restart", "mask", "unmask", "reset-failed"} {
+		if err := PerformAction(ctx, manager, "nginx", action, SIGTERM); err != nil {
+			t.Fatalf("PerformAction(%s) failed: %v", action, err)
+		}
+	}
+	hup, _ := ParseSignal("HUP")
+	if err := PerformAction(WithScope(ctx, "user"), manager, "app", "kill", hup); err != nil {
+		t.Fatalf("PerformAction(kill) failed: %v", err)
+	}
+	if err := PerformAction(ctx, manager, "nginx", "pause", SIGTERM); !IsUnsupportedAction(err) {
+		t.Errorf("Expected pause to be unsupported by systemd, got %v", err)
+	}
+
+	expected := []string{
+		"reload nginx", "reload-or-restart nginx", "try-restart nginx",
+		"mask nginx", "unmask nginx", "reset-failed nginx",
+		"--user kill --signal=SIGHUP app",
+	}
+	got := readCalls(t, calls)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected calls %q, got %q", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Call %d: expected %q, got %q", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestDockerAPIManager_Actions(t *testing.T) {
+	manager, engine := newTestDockerAPIManager(t)
+	ctx := context.Background()
+
+	if err := PerformAction(ctx, manager, "web", "pause", SIGTERM); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if info, _ := manager.GetStatus(ctx, "web"); info.SubState != "paused" {
+		t.Errorf("Expected paused container, got %+v", info)
+	}
+	if err := PerformAction(ctx, manager, "web", "unpause", SIGTERM); err != nil {
+		t.Fatalf("Unpause failed: %v", err)
+	}
+
+	if err := PerformAction(ctx, manager, "web", "kill", Signal(9)); err != nil {
+		t.Fatalf("Kill failed: %v", err)
+	}
+	if engine.signal != "SIGKILL" {
+		t.Errorf("Expected SIGKILL, got %q", engine.signal)
+	}
+	if err := PerformAction(ctx, manager, "missing", "kill", SIGTERM); err == nil {
+		t.Error("Expected killing a missing container to fail")
+	}
+	if err := PerformAction(ctx, manager, "web", "reload", SIGTERM); !IsUnsupportedAction(err) {
+		t.Errorf("Expected reload to be unsupported by docker, got %v", err)
+	}
+}
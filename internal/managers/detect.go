@@ -0,0 +1,145 @@
+package managers
+
+import (
+	"errors"
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// DetectManagers probes the host for every supported init system and
+// container runtime and returns a manager for each one that is available,
+// plus the script services, native processes and plugins declared in cfg.
+// Callers decide how to fall back when the result is empty.
+func DetectManagers(cfg *config.Config, logger *logrus.Logger) map[types.ServiceType]types.ServiceManager {
+	detected := make(map[types.ServiceType]types.ServiceManager)
+
+	if IsSystemdAvailable() {
+		var system types.ServiceManager
+		if dbusManager, err := NewSystemdDBusManager(); err == nil {
+			system = dbusManager
+			logger.Info("Systemd manager initialized (D-Bus)")
+		} else {
+			logger.Debugf("Systemd D-Bus API unavailable, falling back to systemctl: %v", err)
+			system = NewSystemdManager()
+			logger.Info("Systemd manager initialized")
+		}
+		// User managers are reached per request or listed alongside the
+		// system manager as configured.
+		if scoped, err := NewSystemdScopeManager(system, cfg.Systemd, logger); err == nil {
+			detected[types.ServiceTypeSystemd] = scoped
+		} else {
+			logger.Errorf("Ignoring systemd scope configuration: %v", err)
+			detected[types.ServiceTypeSystemd] = system
+		}
+	} else {
+		logger.Debug("Systemd not available on this system")
+	}
+
+	// OpenRC hosts also have /etc/init.d, so the SysV heuristics are only
+	// used when OpenRC is not the init system.
+	if IsOpenRCAvailable() {
+		detected[types.ServiceTypeOpenRC] = NewOpenRCManager()
+		logger.Info("OpenRC manager initialized")
+	} else if IsSysVAvailable() {
+		sysvManager := NewSysVManager()
+		sysvManager.logFiles = cfg.SysV.Logs
+		detected[types.ServiceTypeSysV] = sysvManager
+		logger.Info("SysV manager initialized")
+	} else {
+		logger.Debug("SysV not available on this system")
+	}
+
+	if IsRunitAvailable() {
+		detected[types.ServiceTypeRunit] = NewRunitManager()
+		logger.Info("runit manager initialized")
+	} else {
+		logger.Debug("runit not available on this system")
+	}
+
+	if IsS6Available() {
+		detected[types.ServiceTypeS6] = NewS6Manager()
+		logger.Info("s6 manager initialized")
+	} else {
+		logger.Debug("s6 not available on this system")
+	}
+
+	if dockerAPIManager, err := NewDockerAPIManager(); err == nil {
+		detected[types.ServiceTypeDocker] = dockerAPIManager
+		logger.Info("Docker manager initialized (Engine API)")
+	} else if IsDockerAvailable() {
+		logger.Debugf("Docker Engine API unavailable, falling back to docker CLI: %v", err)
+		detected[types.ServiceTypeDocker] = NewDockerManager()
+		logger.Info("Docker manager initialized")
+	} else {
+		logger.Debug("Docker not available on this system")
+	}
+
+	if IsPodmanAvailable() {
+		detected[types.ServiceTypePodman] = NewPodmanManager()
+		logger.Info("Podman manager initialized")
+	} else {
+		logger.Debug("Podman not available on this system")
+	}
+
+	if supervisorManager, err := NewSupervisorManager(); err == nil {
+		detected[types.ServiceTypeSupervisor] = supervisorManager
+		logger.Info("Supervisor manager initialized")
+	} else {
+		logger.Debugf("Supervisord not available on this system: %v", err)
+	}
+
+	if len(cfg.Scripts) > 0 {
+		detected[types.ServiceTypeScript] = NewScriptManager(cfg.Scripts)
+		logger.Infof("Script manager initialized with %d services", len(cfg.Scripts))
+	}
+
+	if len(cfg.Native) > 0 {
+		detected[types.ServiceTypeNative] = NewNativeManager(cfg.Native, logger)
+		logger.Infof("Native manager initialized with %d services", len(cfg.Native))
+	}
+
+	for _, plugin := range cfg.Plugins {
+		pluginManager, err := NewPluginManager(plugin, logger)
+		if err != nil {
+			logger.Errorf("Plugin %s not available: %v", plugin.Name, err)
+			continue
+		}
+		detected[pluginManager.ServiceType()] = pluginManager
+		logger.Infof("Plugin manager %s initialized", plugin.Name)
+	}
+
+	return detected
+}
+
+// ServiceTypeNames returns the built-in service types followed by the
+// plugins declared in cfg, in the form used by tool schema enums.
+func ServiceTypeNames(cfg *config.Config) []interface{} {
+	names := make([]interface{}, 0, len(types.BuiltinServiceTypes))
+	for _, serviceType := range types.BuiltinServiceTypes {
+		names = append(names, string(serviceType))
+	}
+	if cfg != nil {
+		for _, plugin := range cfg.Plugins {
+			names = append(names, plugin.Name)
+		}
+	}
+	return names
+}
+
+// CloseManagers closes every manager that holds resources, such as the
+// processes supervised by the native manager.
+func CloseManagers(detected map[types.ServiceType]types.ServiceManager) error {
+	var errs []error
+	for _, manager := range detected {
+		if closer, ok := manager.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
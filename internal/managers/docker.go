@@ -1,6 +1,7 @@
 package managers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
@@ -27,47 +28,66 @@ func NewDockerManager() *DockerManager {
 	return &DockerManager{}
 }
 
-func (dm *DockerManager) Start(containerName string) error {
-	cmd := exec.Command("docker", "start", containerName)
+func (dm *DockerManager) Start(ctx context.Context, containerName string) error {
+	cmd := commandContext(ctx, "docker", "start", containerName)
 	return cmd.Run()
 }
 
-func (dm *DockerManager) Stop(containerName string) error {
-	cmd := exec.Command("docker", "stop", containerName)
+func (dm *DockerManager) Stop(ctx context.Context, containerName string) error {
+	cmd := commandContext(ctx, "docker", "stop", containerName)
 	return cmd.Run()
 }
 
-func (dm *DockerManager) Restart(containerName string) error {
-	cmd := exec.Command("docker", "restart", containerName)
+func (dm *DockerManager) Restart(ctx context.Context, containerName string) error {
+	cmd := commandContext(ctx, "docker", "restart", containerName)
 	return cmd.Run()
 }
 
-func (dm *DockerManager) Enable(containerName string) error {
+func (dm *DockerManager) Enable(ctx context.Context, containerName string) error {
 	// For Docker, "enable" means setting restart policy to always
-	cmd := exec.Command("docker", "update", "--restart=always", containerName)
+	cmd := commandContext(ctx, "docker", "update", "--restart=always", containerName)
 	return cmd.Run()
 }
 
-func (dm *DockerManager) Disable(containerName string) error {
+func (dm *DockerManager) Disable(ctx context.Context, containerName string) error {
 	// For Docker, "disable" means setting restart policy to no
-	cmd := exec.Command("docker", "update", "--restart=no", containerName)
+	cmd := commandContext(ctx, "docker", "update", "--restart=no", containerName)
 	return cmd.Run()
 }
 
-func (dm *DockerManager) GetStatus(containerName string) (types.ServiceInfo, error) {
+func (dm *DockerManager) Kill(ctx context.Context, containerName string, signal Signal) error {
+	cmd := commandContext(ctx, "docker", "kill", "--signal="+signal.String(), containerName)
+	return cmd.Run()
+}
+
+func (dm *DockerManager) Pause(ctx context.Context, containerName string) error {
+	cmd := commandContext(ctx, "docker", "pause", containerName)
+	return cmd.Run()
+}
+
+func (dm *DockerManager) Unpause(ctx context.Context, containerName string) error {
+	cmd := commandContext(ctx, "docker", "unpause", containerName)
+	return cmd.Run()
+}
+
+func (dm *DockerManager) GetStatus(ctx context.Context, containerName string) (types.ServiceInfo, error) {
 	info := types.ServiceInfo{
 		Name: containerName,
 		Type: types.ServiceTypeDocker,
 	}
 
 	// Get container information
-	cmd := exec.Command("docker", "inspect", containerName)
+	cmd := commandContext(ctx, "docker", "inspect", containerName)
 	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return info, ctx.Err()
+	}
 	if err != nil {
 		return info, fmt.Errorf("container %s not found", containerName)
 	}
 
-	var containers []map[string]interface{}
+	// docker inspect prints the Engine API's container document.
+	var containers []DockerContainerJSON
 	if err := json.Unmarshal(output, &containers); err != nil {
 		return info, err
 	}
@@ -76,46 +96,12 @@ func (dm *DockerManager) GetStatus(containerName string) (types.ServiceInfo, err
 		return info, fmt.Errorf("container %s not found", containerName)
 	}
 
-	container := containers[0]
-
-	// Get state information
-	if state, ok := container["State"].(map[string]interface{}); ok {
-		if running, ok := state["Running"].(bool); ok {
-			if running {
-				info.Status = types.StatusActive
-			} else {
-				info.Status = types.StatusInactive
-			}
-		}
-
-		// Get PID
-		if pid, ok := state["Pid"].(float64); ok && pid > 0 {
-			info.PID = int(pid)
-		}
-
-		// Get start time
-		if startedAt, ok := state["StartedAt"].(string); ok && startedAt != "" {
-			if startTime, err := time.Parse(time.RFC3339Nano, startedAt); err == nil {
-				info.LastChanged = startTime
-				if info.Status == types.StatusActive {
-					info.Uptime = time.Since(startTime)
-				}
-			}
-		}
-	}
-
-	// Get config information for description
-	if config, ok := container["Config"].(map[string]interface{}); ok {
-		if image, ok := config["Image"].(string); ok {
-			info.Description = fmt.Sprintf("Docker container from image: %s", image)
-		}
-	}
-
+	dockerContainerInfo(&info, &containers[0])
 	return info, nil
 }
 
-func (dm *DockerManager) ListServices() ([]types.ServiceInfo, error) {
-	cmd := exec.Command("docker", "ps", "-a", "--format", "json")
+func (dm *DockerManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	cmd := commandContext(ctx, "docker", "ps", "-a", "--format", "json")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -141,17 +127,7 @@ func (dm *DockerManager) ListServices() ([]types.ServiceInfo, error) {
 			name = container.ID[:12]
 		}
 
-		var status types.ServiceStatus
-		switch container.State {
-		case "running":
-			status = types.StatusActive
-		case "exited", "created":
-			status = types.StatusInactive
-		case "dead", "restarting":
-			status = types.StatusFailed
-		default:
-			status = types.StatusUnknown
-		}
+		status := dockerStateStatus(container.State)
 
 		// Parse creation time
 		var lastChanged time.Time
@@ -178,20 +154,20 @@ func (dm *DockerManager) ListServices() ([]types.ServiceInfo, error) {
 
 // Additional Docker-specific methods
 
-func (dm *DockerManager) GetLogs(containerName string, lines int) (string, error) {
+func (dm *DockerManager) GetLogs(ctx context.Context, containerName string, lines int) (string, error) {
 	args := []string{"logs"}
 	if lines > 0 {
 		args = append(args, "--tail", strconv.Itoa(lines))
 	}
 	args = append(args, containerName)
 
-	cmd := exec.Command("docker", args...)
+	cmd := commandContext(ctx, "docker", args...)
 	output, err := cmd.Output()
 	return string(output), err
 }
 
-func (dm *DockerManager) GetStats(containerName string) (map[string]interface{}, error) {
-	cmd := exec.Command("docker", "stats", containerName, "--no-stream", "--format", "json")
+func (dm *DockerManager) GetStats(ctx context.Context, containerName string) (map[string]interface{}, error) {
+	cmd := commandContext(ctx, "docker", "stats", containerName, "--no-stream", "--format", "json")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -205,18 +181,47 @@ func (dm *DockerManager) GetStats(containerName string) (map[string]interface{},
 	return stats, nil
 }
 
-func (dm *DockerManager) RemoveContainer(containerName string, force bool) error {
+// ServiceStats maps `docker stats` of the container to the generic form.
+// The CLI rounds sizes and does not report CPU time.
+func (dm *DockerManager) ServiceStats(ctx context.Context, containerName string) (types.ServiceStats, error) {
+	entry, err := dm.GetStats(ctx, containerName)
+	if err != nil {
+		return types.ServiceStats{}, err
+	}
+	stats, err := containerCLIStats(entry)
+	stats.Name = containerName
+	return stats, err
+}
+
+// ServicePIDs returns the host PIDs of the container's processes.
+func (dm *DockerManager) ServicePIDs(ctx context.Context, containerName string) ([]int, error) {
+	cmd := commandContext(ctx, "docker", "top", containerName, "-o", "pid")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, line := range strings.Split(string(output), "\n") {
+		if pid, err := strconv.Atoi(strings.TrimSpace(line)); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+func (dm *DockerManager) RemoveContainer(ctx context.Context, containerName string, force bool) error {
 	args := []string{"rm"}
 	if force {
 		args = append(args, "-f")
 	}
 	args = append(args, containerName)
 
-	cmd := exec.Command("docker", args...)
+	cmd := commandContext(ctx, "docker", args...)
 	return cmd.Run()
 }
 
-func (dm *DockerManager) CreateContainer(imageName, containerName string, options []string) error {
+func (dm *DockerManager) CreateContainer(ctx context.Context, imageName, containerName string, options []string) error {
 	args := []string{"run", "-d"}
 	if containerName != "" {
 		args = append(args, "--name", containerName)
@@ -224,7 +229,7 @@ func (dm *DockerManager) CreateContainer(imageName, containerName string, option
 	args = append(args, options...)
 	args = append(args, imageName)
 
-	cmd := exec.Command("docker", args...)
+	cmd := commandContext(ctx, "docker", args...)
 	return cmd.Run()
 }
 
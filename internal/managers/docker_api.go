@@ -0,0 +1,1005 @@
+package managers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const (
+	defaultDockerHost = "unix:///var/run/docker.sock"
+	dockerPingTimeout = 3 * time.Second
+)
+
+// DockerContainerManager is implemented by both Docker backends and covers the
+// container specific operations exposed next to the generic service API.
+type DockerContainerManager interface {
+	types.ServiceManager
+	GetLogs(ctx context.Context, containerName string, lines int) (string, error)
+	GetStats(ctx context.Context, containerName string) (map[string]interface{}, error)
+	RemoveContainer(ctx context.Context, containerName string, force bool) error
+	CreateContainer(ctx context.Context, imageName, containerName string, options []string) error
+}
+
+// DockerAPIError is returned when the Engine API answers with a non-2xx
+// status. StatusCode carries the daemon's code, e.g. 404 for an unknown
+// container or 409 for a conflicting operation.
+type DockerAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *DockerAPIError) Error() string {
+	return fmt.Sprintf("docker API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// IsDockerNotFound reports whether err is a 404 from the Engine API.
+func IsDockerNotFound(err error) bool {
+	var apiErr *DockerAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsDockerConflict reports whether err is a 409 from the Engine API.
+func IsDockerConflict(err error) bool {
+	var apiErr *DockerAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// DockerContainerJSON is the subset of GET /containers/{id}/json we use.
+type DockerContainerJSON struct {
+	ID           string                `json:"Id"`
+	Name         string                `json:"Name"`
+	Created      string                `json:"Created"`
+	RestartCount int                   `json:"RestartCount"`
+	State        DockerContainerState  `json:"State"`
+	Config       DockerContainerConfig `json:"Config"`
+	HostConfig   DockerHostConfig      `json:"HostConfig"`
+}
+
+type DockerContainerState struct {
+	Status     string        `json:"Status"`
+	Running    bool          `json:"Running"`
+	Paused     bool          `json:"Paused"`
+	Restarting bool          `json:"Restarting"`
+	OOMKilled  bool          `json:"OOMKilled"`
+	Dead       bool          `json:"Dead"`
+	Pid        int           `json:"Pid"`
+	ExitCode   int           `json:"ExitCode"`
+	Error      string        `json:"Error"`
+	StartedAt  string        `json:"StartedAt"`
+	FinishedAt string        `json:"FinishedAt"`
+	Health     *DockerHealth `json:"Health,omitempty"`
+}
+
+type DockerHealth struct {
+	Status        string               `json:"Status"`
+	FailingStreak int                  `json:"FailingStreak"`
+	Log           []DockerHealthResult `json:"Log"`
+}
+
+type DockerHealthResult struct {
+	Start    string `json:"Start"`
+	End      string `json:"End"`
+	ExitCode int    `json:"ExitCode"`
+	Output   string `json:"Output"`
+}
+
+type DockerContainerConfig struct {
+	Image        string              `json:"Image"`
+	Hostname     string              `json:"Hostname,omitempty"`
+	User         string              `json:"User,omitempty"`
+	WorkingDir   string              `json:"WorkingDir,omitempty"`
+	Entrypoint   []string            `json:"Entrypoint,omitempty"`
+	Env          []string            `json:"Env,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	Tty          bool                `json:"Tty"`
+	OpenStdin    bool                `json:"OpenStdin,omitempty"`
+}
+
+type DockerHostConfig struct {
+	RestartPolicy  DockerRestartPolicy            `json:"RestartPolicy"`
+	PortBindings   map[string][]DockerPortBinding `json:"PortBindings,omitempty"`
+	Binds          []string                       `json:"Binds,omitempty"`
+	NetworkMode    string                         `json:"NetworkMode,omitempty"`
+	AutoRemove     bool                           `json:"AutoRemove,omitempty"`
+	Privileged     bool                           `json:"Privileged,omitempty"`
+	ReadonlyRootfs bool                           `json:"ReadonlyRootfs,omitempty"`
+	Init           *bool                          `json:"Init,omitempty"`
+	Memory         int64                          `json:"Memory,omitempty"`
+	NanoCpus       int64                          `json:"NanoCpus,omitempty"`
+}
+
+type DockerRestartPolicy struct {
+	Name              string `json:"Name"`
+	MaximumRetryCount int    `json:"MaximumRetryCount,omitempty"`
+}
+
+type DockerPortBinding struct {
+	HostIP   string `json:"HostIp,omitempty"`
+	HostPort string `json:"HostPort"`
+}
+
+// dockerCreateRequest is the body of POST /containers/create.
+type dockerCreateRequest struct {
+	DockerContainerConfig
+	HostConfig DockerHostConfig `json:"HostConfig"`
+}
+
+// DockerStats is the subset of GET /containers/{id}/stats?stream=false we use.
+type DockerStats struct {
+	Read        time.Time                     `json:"read"`
+	Name        string                        `json:"name"`
+	ID          string                        `json:"id"`
+	CPUStats    DockerCPUStats                `json:"cpu_stats"`
+	PreCPUStats DockerCPUStats                `json:"precpu_stats"`
+	MemoryStats DockerMemoryStats             `json:"memory_stats"`
+	Networks    map[string]DockerNetworkStats `json:"networks"`
+	BlkioStats  DockerBlkioStats              `json:"blkio_stats"`
+	PidsStats   DockerPidsStats               `json:"pids_stats"`
+}
+
+type DockerCPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemCPUUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs     uint32 `json:"online_cpus"`
+}
+
+type DockerMemoryStats struct {
+	Usage uint64            `json:"usage"`
+	Limit uint64            `json:"limit"`
+	Stats map[string]uint64 `json:"stats"`
+}
+
+type DockerNetworkStats struct {
+	RxBytes uint64 `json:"rx_bytes"`
+	TxBytes uint64 `json:"tx_bytes"`
+}
+
+type DockerBlkioStats struct {
+	IoServiceBytesRecursive []struct {
+		Op    string `json:"op"`
+		Value uint64 `json:"value"`
+	} `json:"io_service_bytes_recursive"`
+}
+
+type DockerPidsStats struct {
+	Current uint64 `json:"current"`
+}
+
+// DockerAPIManager manages containers through the Docker Engine REST API,
+// reached over the unix socket or the endpoint named by DOCKER_HOST, so no
+// docker CLI binary is needed on the host.
+type DockerAPIManager struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewDockerAPIManager connects to DOCKER_HOST (default
+// unix:///var/run/docker.sock) and pings the daemon.
+func NewDockerAPIManager() (*DockerAPIManager, error) {
+	if os.Getenv("DOCKER_TLS_VERIFY") != "" {
+		return nil, fmt.Errorf("TLS connections to the Docker daemon are not supported")
+	}
+
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = defaultDockerHost
+	}
+
+	dm, err := newDockerAPIManager(host)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerPingTimeout)
+	defer cancel()
+	if err := dm.Ping(ctx); err != nil {
+		return nil, err
+	}
+	return dm, nil
+}
+
+func newDockerAPIManager(host string) (*DockerAPIManager, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DOCKER_HOST %q: %v", host, err)
+	}
+
+	transport := &http.Transport{}
+	var baseURL string
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		baseURL = "http://docker"
+	case "tcp", "http":
+		baseURL = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("unsupported DOCKER_HOST scheme: %s", u.Scheme)
+	}
+
+	return &DockerAPIManager{
+		client:  &http.Client{Transport: transport},
+		baseURL: baseURL,
+	}, nil
+}
+
+// Ping checks that the daemon answers on /_ping.
+func (dm *DockerAPIManager) Ping(ctx context.Context) error {
+	resp, err := dm.request(ctx, http.MethodGet, "/_ping", nil, nil)
+	if err != nil {
+		return fmt.Errorf("docker daemon not reachable: %v", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (dm *DockerAPIManager) Start(ctx context.Context, containerName string) error {
+	return dm.post(ctx, containerPath(containerName, "start"), nil, nil)
+}
+
+func (dm *DockerAPIManager) Stop(ctx context.Context, containerName string) error {
+	return dm.post(ctx, containerPath(containerName, "stop"), nil, nil)
+}
+
+func (dm *DockerAPIManager) Restart(ctx context.Context, containerName string) error {
+	return dm.post(ctx, containerPath(containerName, "restart"), nil, nil)
+}
+
+func (dm *DockerAPIManager) Enable(ctx context.Context, containerName string) error {
+	// For Docker, "enable" means setting restart policy to always
+	return dm.updateRestartPolicy(ctx, containerName, "always")
+}
+
+func (dm *DockerAPIManager) Disable(ctx context.Context, containerName string) error {
+	// For Docker, "disable" means setting restart policy to no
+	return dm.updateRestartPolicy(ctx, containerName, "no")
+}
+
+func (dm *DockerAPIManager) Kill(ctx context.Context, containerName string, signal Signal) error {
+	return dm.post(ctx, containerPath(containerName, "kill"), url.Values{"signal": {signal.String()}}, nil)
+}
+
+func (dm *DockerAPIManager) Pause(ctx context.Context, containerName string) error {
+	return dm.post(ctx, containerPath(containerName, "pause"), nil, nil)
+}
+
+func (dm *DockerAPIManager) Unpause(ctx context.Context, containerName string) error {
+	return dm.post(ctx, containerPath(containerName, "unpause"), nil, nil)
+}
+
+func (dm *DockerAPIManager) updateRestartPolicy(ctx context.Context, containerName, policy string) error {
+	body := map[string]interface{}{
+		"RestartPolicy": DockerRestartPolicy{Name: policy},
+	}
+	return dm.post(ctx, containerPath(containerName, "update"), nil, body)
+}
+
+func (dm *DockerAPIManager) GetStatus(ctx context.Context, containerName string) (types.ServiceInfo, error) {
+	info := types.ServiceInfo{
+		Name: containerName,
+		Type: types.ServiceTypeDocker,
+	}
+
+	container, err := dm.Inspect(ctx, containerName)
+	if err != nil {
+		return info, err
+	}
+
+	dockerContainerInfo(&info, container)
+	return info, nil
+}
+
+// dockerContainerInfo fills info from an inspected container. Enable and
+// Disable set the restart policy, so it doubles as the enabled state.
+func dockerContainerInfo(info *types.ServiceInfo, container *DockerContainerJSON) {
+	info.Status = dockerStateStatus(container.State.Status)
+	info.SubState = container.State.Status
+	if container.State.Pid > 0 {
+		info.PID = container.State.Pid
+	}
+	if startTime, err := time.Parse(time.RFC3339Nano, container.State.StartedAt); err == nil && !startTime.IsZero() {
+		info.LastChanged = startTime
+		if info.Status == types.StatusActive {
+			info.ActiveSince = &startTime
+			info.Uptime = time.Since(startTime)
+		}
+	}
+	// ExitCode is 0 until the container first stops.
+	if finished, err := time.Parse(time.RFC3339Nano, container.State.FinishedAt); err == nil && !finished.IsZero() && !container.State.Running {
+		exitCode := container.State.ExitCode
+		info.ExitCode = &exitCode
+	}
+	info.Restarts = container.RestartCount
+	info.OOMKilled = container.State.OOMKilled
+	switch container.HostConfig.RestartPolicy.Name {
+	case "always", "unless-stopped":
+		info.EnabledState = "enabled"
+	default:
+		info.EnabledState = "disabled"
+	}
+	info.Description = fmt.Sprintf("Docker container from image: %s", container.Config.Image)
+	info.Health = engineHealth(info.Name, types.ServiceTypeDocker, container.State.Health)
+}
+
+// engineHealth maps the state of a container's HEALTHCHECK, as reported by
+// Docker and Podman inspect, to a probe. It is nil for containers without a
+// health check.
+func engineHealth(name string, serviceType types.ServiceType, health *DockerHealth) *types.ServiceHealth {
+	if health == nil || health.Status == "" || health.Status == "none" {
+		return nil
+	}
+	probe := types.ProbeResult{
+		Service:       name,
+		ServiceType:   serviceType,
+		Name:          string(serviceType),
+		Kind:          string(serviceType),
+		Status:        types.HealthStatus(health.Status),
+		FailingStreak: health.FailingStreak,
+	}
+	if n := len(health.Log); n > 0 {
+		last := health.Log[n-1]
+		if end, err := time.Parse(time.RFC3339Nano, last.End); err == nil {
+			probe.LastCheck = end
+		}
+		if last.ExitCode != 0 {
+			probe.Output = probeOutput(last.Output)
+		}
+		for i := n - 1; i >= 0 && health.Log[i].ExitCode == 0; i-- {
+			probe.SuccessStreak++
+		}
+	}
+	return types.NewServiceHealth([]types.ProbeResult{probe})
+}
+
+// Inspect returns the typed GET /containers/{id}/json document.
+func (dm *DockerAPIManager) Inspect(ctx context.Context, containerName string) (*DockerContainerJSON, error) {
+	var container DockerContainerJSON
+	if err := dm.get(ctx, containerPath(containerName, "json"), nil, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+func (dm *DockerAPIManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	var containers []DockerContainer
+	if err := dm.get(ctx, "/containers/json", url.Values{"all": {"1"}}, &containers); err != nil {
+		return nil, err
+	}
+
+	var services []types.ServiceInfo
+	for _, container := range containers {
+		var name string
+		if len(container.Names) > 0 {
+			name = strings.TrimPrefix(container.Names[0], "/")
+		} else if len(container.ID) > 12 {
+			name = container.ID[:12]
+		} else {
+			name = container.ID
+		}
+
+		status := dockerStateStatus(container.State)
+
+		var lastChanged time.Time
+		var uptime time.Duration
+		if container.Created > 0 {
+			lastChanged = time.Unix(container.Created, 0)
+			if status == types.StatusActive {
+				uptime = time.Since(lastChanged)
+			}
+		}
+
+		services = append(services, types.ServiceInfo{
+			Name:        name,
+			Type:        types.ServiceTypeDocker,
+			Status:      status,
+			Description: fmt.Sprintf("Docker container from image: %s", container.Image),
+			LastChanged: lastChanged,
+			Uptime:      uptime,
+		})
+	}
+
+	return services, nil
+}
+
+// Additional Docker-specific methods
+
+func (dm *DockerAPIManager) GetLogs(ctx context.Context, containerName string, lines int) (string, error) {
+	query := url.Values{"stdout": {"1"}, "stderr": {"1"}, "tail": {"all"}}
+	if lines > 0 {
+		query.Set("tail", strconv.Itoa(lines))
+	}
+
+	resp, err := dm.request(ctx, http.MethodGet, containerPath(containerName, "logs"), query, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return demuxDockerStream(data), nil
+}
+
+// Stats returns a single typed stats sample for the container.
+func (dm *DockerAPIManager) Stats(ctx context.Context, containerName string) (*DockerStats, error) {
+	var stats DockerStats
+	if err := dm.get(ctx, containerPath(containerName, "stats"), url.Values{"stream": {"false"}}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetStats returns the same keys as `docker stats --format json` so callers do
+// not depend on which Docker backend is in use.
+func (dm *DockerAPIManager) GetStats(ctx context.Context, containerName string) (map[string]interface{}, error) {
+	stats, err := dm.Stats(ctx, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	memUsage := stats.memoryUsage()
+	var memPerc float64
+	if stats.MemoryStats.Limit > 0 {
+		memPerc = float64(memUsage) / float64(stats.MemoryStats.Limit) * 100
+	}
+
+	var rx, tx uint64
+	for _, network := range stats.Networks {
+		rx += network.RxBytes
+		tx += network.TxBytes
+	}
+
+	var blkRead, blkWrite uint64
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			blkRead += entry.Value
+		case "write":
+			blkWrite += entry.Value
+		}
+	}
+
+	id := stats.ID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+
+	return map[string]interface{}{
+		"Container": containerName,
+		"ID":        id,
+		"Name":      strings.TrimPrefix(stats.Name, "/"),
+		"CPUPerc":   fmt.Sprintf("%.2f%%", stats.cpuPercent()),
+		"MemUsage":  fmt.Sprintf("%s / %s", formatBinarySize(memUsage), formatBinarySize(stats.MemoryStats.Limit)),
+		"MemPerc":   fmt.Sprintf("%.2f%%", memPerc),
+		"NetIO":     fmt.Sprintf("%s / %s", formatDecimalSize(rx), formatDecimalSize(tx)),
+		"BlockIO":   fmt.Sprintf("%s / %s", formatDecimalSize(blkRead), formatDecimalSize(blkWrite)),
+		"PIDs":      strconv.FormatUint(stats.PidsStats.Current, 10),
+	}, nil
+}
+
+// ServiceStats maps the engine's stats of the container to the generic
+// form. Memory excludes the inactive page cache, as docker stats does.
+func (dm *DockerAPIManager) ServiceStats(ctx context.Context, containerName string) (types.ServiceStats, error) {
+	stats, err := dm.Stats(ctx, containerName)
+	if err != nil {
+		return types.ServiceStats{}, err
+	}
+
+	result := types.ServiceStats{
+		Name:          containerName,
+		CPUUsageUsec:  stats.CPUStats.CPUUsage.TotalUsage / 1000,
+		MemoryCurrent: stats.memoryUsage(),
+		MemoryMax:     stats.MemoryStats.Limit,
+		PidsCurrent:   stats.PidsStats.Current,
+	}
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			result.IOReadBytes += entry.Value
+		case "write":
+			result.IOWriteBytes += entry.Value
+		}
+	}
+	return result, nil
+}
+
+// ServicePIDs returns the host PIDs of the container's processes as listed
+// by the engine's top endpoint.
+func (dm *DockerAPIManager) ServicePIDs(ctx context.Context, containerName string) ([]int, error) {
+	var top struct {
+		Titles    []string   `json:"Titles"`
+		Processes [][]string `json:"Processes"`
+	}
+	if err := dm.get(ctx, containerPath(containerName, "top"), nil, &top); err != nil {
+		return nil, err
+	}
+	column := -1
+	for i, title := range top.Titles {
+		if title == "PID" {
+			column = i
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("no PID column in processes of container %s", containerName)
+	}
+
+	var pids []int
+	for _, process := range top.Processes {
+		if column < len(process) {
+			if pid, err := strconv.Atoi(process[column]); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+	}
+	return pids, nil
+}
+
+func (dm *DockerAPIManager) RemoveContainer(ctx context.Context, containerName string, force bool) error {
+	query := url.Values{}
+	if force {
+		query.Set("force", "1")
+	}
+
+	resp, err := dm.request(ctx, http.MethodDelete, "/containers/"+url.PathEscape(containerName), query, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// CreateContainer mirrors `docker run -d`: it creates and starts the container,
+// pulling the image first if the daemon does not have it. options accepts the
+// common run flags, see dockerRunFlags; other options are handed to the
+// docker CLI when it is installed.
+func (dm *DockerAPIManager) CreateContainer(ctx context.Context, imageName, containerName string, options []string) error {
+	req, err := parseDockerRunOptions(imageName, options)
+	var unsupported *unsupportedRunOptionError
+	if errors.As(err, &unsupported) && IsDockerAvailable() {
+		return NewDockerManager().CreateContainer(ctx, imageName, containerName, options)
+	}
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if containerName != "" {
+		query.Set("name", containerName)
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err = dm.do(ctx, http.MethodPost, "/containers/create", query, req, &created)
+	if IsDockerNotFound(err) {
+		if pullErr := dm.pullImage(ctx, imageName); pullErr != nil {
+			return pullErr
+		}
+		err = dm.do(ctx, http.MethodPost, "/containers/create", query, req, &created)
+	}
+	if err != nil {
+		return err
+	}
+
+	return dm.Start(ctx, created.ID)
+}
+
+func (dm *DockerAPIManager) pullImage(ctx context.Context, imageName string) error {
+	image, tag := imageName, "latest"
+	if i := strings.LastIndex(imageName, ":"); i > strings.LastIndex(imageName, "/") {
+		image, tag = imageName[:i], imageName[i+1:]
+	}
+
+	resp, err := dm.request(ctx, http.MethodPost, "/images/create", url.Values{"fromImage": {image}, "tag": {tag}}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The pull progress is streamed as JSON messages; errors arrive in-band.
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := decoder.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read pull progress: %v", err)
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("failed to pull image %s: %s", imageName, msg.Error)
+		}
+	}
+}
+
+// HTTP helpers
+
+func (dm *DockerAPIManager) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return dm.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+func (dm *DockerAPIManager) post(ctx context.Context, path string, query url.Values, body interface{}) error {
+	return dm.do(ctx, http.MethodPost, path, query, body, nil)
+}
+
+func (dm *DockerAPIManager) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	resp, err := dm.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode docker API response: %v", err)
+	}
+	return nil
+}
+
+// request performs the call and turns any non-2xx status other than 304 Not
+// Modified (already started/stopped) into a *DockerAPIError.
+func (dm *DockerAPIManager) request(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	target := dm.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := dm.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 || resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	apiErr := &DockerAPIError{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(resp.Body)
+	var msg struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &msg) == nil && msg.Message != "" {
+		apiErr.Message = msg.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return nil, apiErr
+}
+
+func containerPath(containerName, action string) string {
+	return "/containers/" + url.PathEscape(containerName) + "/" + action
+}
+
+// dockerStateStatus maps a container state to a ServiceStatus.
+func dockerStateStatus(state string) types.ServiceStatus {
+	switch state {
+	case "running":
+		return types.StatusActive
+	case "exited", "created":
+		return types.StatusInactive
+	case "dead", "restarting":
+		return types.StatusFailed
+	default:
+		return types.StatusUnknown
+	}
+}
+
+// demuxDockerStream strips the 8-byte frame headers the daemon puts in front of
+// stdout/stderr chunks for non-TTY containers. TTY output is returned as is.
+func demuxDockerStream(data []byte) string {
+	var out bytes.Buffer
+	rest := data
+	for len(rest) > 0 {
+		if len(rest) < 8 || rest[0] > 2 || rest[1] != 0 || rest[2] != 0 || rest[3] != 0 {
+			return string(data)
+		}
+		size := int(binary.BigEndian.Uint32(rest[4:8]))
+		if len(rest) < 8+size {
+			return string(data)
+		}
+		out.Write(rest[8 : 8+size])
+		rest = rest[8+size:]
+	}
+	return out.String()
+}
+
+func (s *DockerStats) cpuPercent() float64 {
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemCPUUsage) - float64(s.PreCPUStats.SystemCPUUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	cpus := float64(s.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * cpus * 100
+}
+
+// memoryUsage excludes the page cache the same way the docker CLI does.
+func (s *DockerStats) memoryUsage() uint64 {
+	usage := s.MemoryStats.Usage
+	for _, key := range []string{"inactive_file", "total_inactive_file"} {
+		if v, ok := s.MemoryStats.Stats[key]; ok && v < usage {
+			return usage - v
+		}
+	}
+	return usage
+}
+
+// dockerRunFlags are the `docker run` flags the Engine API manager maps
+// itself, by long name. Boolean flags take no value unless written as
+// --flag=value.
+var dockerRunFlags = map[string]struct {
+	short   string
+	boolean bool
+}{
+	"--env":         {short: "-e"},
+	"--label":       {short: "-l"},
+	"--volume":      {short: "-v"},
+	"--publish":     {short: "-p"},
+	"--restart":     {},
+	"--network":     {},
+	"--net":         {},
+	"--memory":      {short: "-m"},
+	"--cpus":        {},
+	"--workdir":     {short: "-w"},
+	"--user":        {short: "-u"},
+	"--hostname":    {short: "-h"},
+	"--entrypoint":  {},
+	"--detach":      {short: "-d", boolean: true},
+	"--rm":          {boolean: true},
+	"--privileged":  {boolean: true},
+	"--read-only":   {boolean: true},
+	"--init":        {boolean: true},
+	"--tty":         {short: "-t", boolean: true},
+	"--interactive": {short: "-i", boolean: true},
+}
+
+// unsupportedRunOptionError is returned for a run option the Engine API
+// manager does not map.
+type unsupportedRunOptionError struct {
+	Option string
+}
+
+func (e *unsupportedRunOptionError) Error() string {
+	return fmt.Sprintf("unsupported option %s", e.Option)
+}
+
+// dockerRunFlag resolves a short flag to its long name.
+func dockerRunFlag(flag string) (string, bool) {
+	if _, ok := dockerRunFlags[flag]; ok && strings.HasPrefix(flag, "--") {
+		return flag, true
+	}
+	for name, spec := range dockerRunFlags {
+		if spec.short != "" && spec.short == flag {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func parseDockerRunOptions(imageName string, options []string) (*dockerCreateRequest, error) {
+	req := &dockerCreateRequest{}
+	req.Image = imageName
+
+	for i := 0; i < len(options); i++ {
+		flag, value, inline := options[i], "", false
+		if eq := strings.Index(flag, "="); eq > 0 && strings.HasPrefix(flag, "--") {
+			flag, value, inline = flag[:eq], flag[eq+1:], true
+		}
+		name, ok := dockerRunFlag(flag)
+		if !ok {
+			return nil, &unsupportedRunOptionError{Option: flag}
+		}
+
+		enabled := true
+		if dockerRunFlags[name].boolean {
+			if inline {
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %q for option %s", value, flag)
+				}
+				enabled = b
+			}
+		} else if !inline {
+			if i+1 >= len(options) {
+				return nil, fmt.Errorf("missing value for option %s", flag)
+			}
+			i++
+			value = options[i]
+		}
+
+		switch name {
+		case "--env":
+			req.Env = append(req.Env, value)
+		case "--label":
+			if req.Labels == nil {
+				req.Labels = make(map[string]string)
+			}
+			key, val, _ := strings.Cut(value, "=")
+			req.Labels[key] = val
+		case "--volume":
+			req.HostConfig.Binds = append(req.HostConfig.Binds, value)
+		case "--restart":
+			policy, retries, _ := strings.Cut(value, ":")
+			req.HostConfig.RestartPolicy.Name = policy
+			if retries != "" {
+				n, err := strconv.Atoi(retries)
+				if err != nil {
+					return nil, fmt.Errorf("invalid restart policy %q", value)
+				}
+				req.HostConfig.RestartPolicy.MaximumRetryCount = n
+			}
+		case "--publish":
+			if err := addPortBinding(req, value); err != nil {
+				return nil, err
+			}
+		case "--network", "--net":
+			req.HostConfig.NetworkMode = value
+		case "--memory":
+			memory, err := parseDockerMemory(value)
+			if err != nil {
+				return nil, err
+			}
+			req.HostConfig.Memory = memory
+		case "--cpus":
+			cpus, err := strconv.ParseFloat(value, 64)
+			if err != nil || cpus < 0 {
+				return nil, fmt.Errorf("invalid cpus %q", value)
+			}
+			req.HostConfig.NanoCpus = int64(math.Round(cpus * 1e9))
+		case "--workdir":
+			req.WorkingDir = value
+		case "--user":
+			req.User = value
+		case "--hostname":
+			req.Hostname = value
+		case "--entrypoint":
+			// docker run takes the entrypoint as a single executable.
+			req.Entrypoint = []string{value}
+		case "--detach":
+			// CreateContainer always detaches.
+		case "--rm":
+			req.HostConfig.AutoRemove = enabled
+		case "--privileged":
+			req.HostConfig.Privileged = enabled
+		case "--read-only":
+			req.HostConfig.ReadonlyRootfs = enabled
+		case "--init":
+			req.HostConfig.Init = &enabled
+		case "--tty":
+			req.Tty = enabled
+		case "--interactive":
+			req.OpenStdin = enabled
+		}
+	}
+
+	return req, nil
+}
+
+// parseDockerMemory parses a memory limit such as 512m or 1g; the units are
+// binary and a bare number is bytes, as for docker run --memory.
+func parseDockerMemory(value string) (int64, error) {
+	number := strings.TrimSuffix(strings.ToLower(value), "b")
+	multiplier := int64(1)
+	if number != "" {
+		switch number[len(number)-1] {
+		case 'k':
+			multiplier = 1 << 10
+		case 'm':
+			multiplier = 1 << 20
+		case 'g':
+			multiplier = 1 << 30
+		case 't':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			number = number[:len(number)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid memory limit %q", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// addPortBinding handles [hostIP:]hostPort:containerPort[/proto] and containerPort[/proto].
+func addPortBinding(req *dockerCreateRequest, spec string) error {
+	parts := strings.Split(spec, ":")
+	containerPort := parts[len(parts)-1]
+	if !strings.Contains(containerPort, "/") {
+		containerPort += "/tcp"
+	}
+
+	var binding DockerPortBinding
+	switch len(parts) {
+	case 1:
+	case 2:
+		binding.HostPort = parts[0]
+	case 3:
+		binding.HostIP, binding.HostPort = parts[0], parts[1]
+	default:
+		return fmt.Errorf("invalid port mapping %q", spec)
+	}
+
+	if req.ExposedPorts == nil {
+		req.ExposedPorts = make(map[string]struct{})
+	}
+	req.ExposedPorts[containerPort] = struct{}{}
+
+	if req.HostConfig.PortBindings == nil {
+		req.HostConfig.PortBindings = make(map[string][]DockerPortBinding)
+	}
+	req.HostConfig.PortBindings[containerPort] = append(req.HostConfig.PortBindings[containerPort], binding)
+	return nil
+}
+
+func formatBinarySize(n uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	size := float64(n)
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.4g%s", size, units[i])
+}
+
+func formatDecimalSize(n uint64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	size := float64(n)
+	i := 0
+	for size >= 1000 && i < len(units)-1 {
+		size /= 1000
+		i++
+	}
+	return fmt.Sprintf("%.4g%s", size, units[i])
+}
@@ -0,0 +1,606 @@
+package managers
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// fakeDockerEngine 是一个进程内的 Docker Engine API 模拟服务器
+type fakeDockerEngine struct {
+	mu         sync.Mutex
+	containers map[string]*DockerContainerJSON
+	images     map[string]bool
+	logs       map[string][]byte
+	created    *dockerCreateRequest
+	signal     string
+	logQuery   url.Values
+}
+
+func newFakeDockerEngine() *fakeDockerEngine {
+	return &fakeDockerEngine{
+		containers: map[string]*DockerContainerJSON{
+			"web": {
+				ID:      "0123456789abcdef0123",
+				Name:    "/web",
+				Created: "2024-01-01T00:00:00Z",
+				State: DockerContainerState{
+					Status:    "running",
+					Running:   true,
+					Pid:       4242,
+					StartedAt: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano),
+					Health:    &DockerHealth{Status: "healthy"},
+				},
+				Config: DockerContainerConfig{Image: "nginx:latest"},
+			},
+			"db": {
+				ID:           "fedcba98765432100000",
+				Name:         "/db",
+				Created:      "2024-01-01T00:00:00Z",
+				RestartCount: 3,
+				State: DockerContainerState{
+					Status:     "exited",
+					ExitCode:   137,
+					OOMKilled:  true,
+					StartedAt:  "2024-01-02T00:00:00Z",
+					FinishedAt: "2024-01-02T00:05:00Z",
+				},
+				Config:     DockerContainerConfig{Image: "postgres:16"},
+				HostConfig: DockerHostConfig{RestartPolicy: DockerRestartPolicy{Name: "always"}},
+			},
+		},
+		images: map[string]bool{"nginx:latest": true, "postgres:16": true},
+		logs: map[string][]byte{
+			"web": append(dockerFrame(1, "GET / 200\n"), dockerFrame(2, "warn: slow\n")...),
+		},
+	}
+}
+
+func dockerFrame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func (f *fakeDockerEngine) writeError(w http.ResponseWriter, code int, format string, args ...interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"message": fmt.Sprintf(format, args...)})
+}
+
+func (f *fakeDockerEngine) lookup(w http.ResponseWriter, r *http.Request) *DockerContainerJSON {
+	name := mux.Vars(r)["name"]
+	container, ok := f.containers[name]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, "No such container: %s", name)
+	}
+	return container
+}
+
+func (f *fakeDockerEngine) handler() http.Handler {
+	router := mux.NewRouter()
+
+	router.HandleFunc("/_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}).Methods("GET")
+
+	router.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.URL.Query().Get("all") != "1" {
+			f.writeError(w, http.StatusBadRequest, "expected all=1")
+			return
+		}
+		var list []DockerContainer
+		for _, c := range f.containers {
+			list = append(list, DockerContainer{ID: c.ID, Names: []string{c.Name}, Image: c.Config.Image, State: c.State.Status, Created: 1704067200})
+		}
+		json.NewEncoder(w).Encode(list)
+	}).Methods("GET")
+
+	router.HandleFunc("/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var req dockerCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.writeError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		if !f.images[req.Image] {
+			f.writeError(w, http.StatusNotFound, "No such image: %s", req.Image)
+			return
+		}
+		name := r.URL.Query().Get("name")
+		if _, exists := f.containers[name]; exists {
+			f.writeError(w, http.StatusConflict, "Conflict. The container name \"/%s\" is already in use", name)
+			return
+		}
+		f.created = &req
+		f.containers[name] = &DockerContainerJSON{ID: name + "-id", Name: "/" + name, State: DockerContainerState{Status: "created"}, Config: req.DockerContainerConfig}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"Id": name})
+	}).Methods("POST")
+
+	router.HandleFunc("/images/create", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		image := r.URL.Query().Get("fromImage") + ":" + r.URL.Query().Get("tag")
+		if strings.HasPrefix(image, "missing") {
+			json.NewEncoder(w).Encode(map[string]string{"error": "manifest unknown"})
+			return
+		}
+		f.images[image] = true
+		json.NewEncoder(w).Encode(map[string]string{"status": "Downloaded newer image"})
+	}).Methods("POST")
+
+	router.HandleFunc("/containers/{name}/json", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if c := f.lookup(w, r); c != nil {
+			json.NewEncoder(w).Encode(c)
+		}
+	}).Methods("GET")
+
+	router.HandleFunc("/containers/{name}/{action:start|stop|restart|kill|pause|unpause}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		c := f.lookup(w, r)
+		if c == nil {
+			return
+		}
+		switch mux.Vars(r)["action"] {
+		case "start":
+			if c.State.Running {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			c.State.Status, c.State.Running = "running", true
+		case "stop":
+			c.State.Status, c.State.Running = "exited", false
+		case "restart":
+			c.State.Status, c.State.Running = "running", true
+		case "kill":
+			f.signal = r.URL.Query().Get("signal")
+			c.State.Status, c.State.Running = "exited", false
+		case "pause":
+			c.State.Status, c.State.Paused = "paused", true
+		case "unpause":
+			c.State.Status, c.State.Paused = "running", false
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	router.HandleFunc("/containers/{name}/update", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		c := f.lookup(w, r)
+		if c == nil {
+			return
+		}
+		var body struct{ RestartPolicy DockerRestartPolicy }
+		json.NewDecoder(r.Body).Decode(&body)
+		c.HostConfig.RestartPolicy = body.RestartPolicy
+		json.NewEncoder(w).Encode(map[string]interface{}{"Warnings": []string{}})
+	}).Methods("POST")
+
+	router.HandleFunc("/containers/{name}/logs", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		c := f.lookup(w, r)
+		if c == nil {
+			return
+		}
+		f.logQuery = r.URL.Query()
+		logs := f.logs[strings.TrimPrefix(c.Name, "/")]
+		if r.URL.Query().Get("timestamps") != "1" {
+			w.Write(logs)
+			return
+		}
+		// 为每一帧加上递增的时间戳
+		for i := 0; len(logs) >= 8; i++ {
+			size := int(binary.BigEndian.Uint32(logs[4:8]))
+			stamp := time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC).Format(time.RFC3339Nano) + " "
+			w.Write(dockerFrame(logs[0], stamp+string(logs[8:8+size])))
+			logs = logs[8+size:]
+		}
+	}).Methods("GET")
+
+	router.HandleFunc("/containers/{name}/stats", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		c := f.lookup(w, r)
+		if c == nil {
+			return
+		}
+		var stats DockerStats
+		stats.ID, stats.Name = c.ID, c.Name
+		stats.CPUStats.CPUUsage.TotalUsage = 300
+		stats.CPUStats.SystemCPUUsage = 2000
+		stats.CPUStats.OnlineCPUs = 2
+		stats.PreCPUStats.CPUUsage.TotalUsage = 100
+		stats.PreCPUStats.SystemCPUUsage = 1000
+		stats.MemoryStats = DockerMemoryStats{Usage: 3 << 20, Limit: 8 << 20, Stats: map[string]uint64{"inactive_file": 1 << 20}}
+		stats.Networks = map[string]DockerNetworkStats{"eth0": {RxBytes: 1500, TxBytes: 500}}
+		stats.PidsStats.Current = 5
+		json.NewEncoder(w).Encode(stats)
+	}).Methods("GET")
+
+	router.HandleFunc("/containers/{name}/top", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.lookup(w, r) == nil {
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"Titles":    []string{"UID", "PID", "PPID", "C", "STIME", "TTY", "TIME", "CMD"},
+			"Processes": [][]string{{"root", "4242", "4200", "0", "10:00", "?", "00:00:01", "nginx: master process"}, {"101", "4243", "4242", "0", "10:00", "?", "00:00:00", "nginx: worker process"}},
+		})
+	}).Methods("GET")
+
+	router.HandleFunc("/containers/{name}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		c := f.lookup(w, r)
+		if c == nil {
+			return
+		}
+		if c.State.Running && r.URL.Query().Get("force") != "1" {
+			f.writeError(w, http.StatusConflict, "You cannot remove a running container %s", c.ID)
+			return
+		}
+		delete(f.containers, mux.Vars(r)["name"])
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("DELETE")
+
+	return router
+}
+
+// newTestDockerAPIManager 在临时 unix socket 上启动模拟服务器
+func newTestDockerAPIManager(t *testing.T) (*DockerAPIManager, *fakeDockerEngine) {
+	t.Helper()
+
+	engine := newFakeDockerEngine()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen on %s: %v", socket, err)
+	}
+
+	srv := &http.Server{Handler: engine.handler()}
+	go srv.Serve(listener)
+	t.Cleanup(func() { srv.Close() })
+
+	manager, err := newDockerAPIManager("unix://" + socket)
+	if err != nil {
+		t.Fatalf("Failed to create Docker API manager: %v", err)
+	}
+	if err := manager.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	return manager, engine
+}
+
+func TestDockerAPIManager_Interface(t *testing.T) {
+	var _ DockerContainerManager = (*DockerAPIManager)(nil)
+	var _ DockerContainerManager = (*DockerManager)(nil)
+}
+
+func TestNewDockerAPIManager_Hosts(t *testing.T) {
+	if _, err := newDockerAPIManager("tcp://127.0.0.1:2375"); err != nil {
+		t.Errorf("Expected tcp host to be accepted: %v", err)
+	}
+	if _, err := newDockerAPIManager("ssh://user@host"); err == nil {
+		t.Error("Expected unsupported scheme to be rejected")
+	}
+}
+
+func TestDockerAPIManager_GetStatus(t *testing.T) {
+	manager, _ := newTestDockerAPIManager(t)
+
+	info, err := manager.GetStatus(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.Status != types.StatusActive {
+		t.Errorf("Expected active, got %s", info.Status)
+	}
+	if info.PID != 4242 {
+		t.Errorf("Expected PID 4242, got %d", info.PID)
+	}
+	if info.Uptime < 59*time.Minute {
+		t.Errorf("Expected uptime of about an hour, got %s", info.Uptime)
+	}
+	if info.Description != "Docker container from image: nginx:latest" {
+		t.Errorf("Unexpected description: %s", info.Description)
+	}
+
+	container, err := manager.Inspect(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if container.State.Health == nil || container.State.Health.Status != "healthy" {
+		t.Errorf("Expected healthy health status, got %+v", container.State.Health)
+	}
+	if info.Health == nil || info.Health.Status != types.HealthHealthy || info.Health.Probes[0].Kind != "docker" {
+		t.Errorf("Expected the HEALTHCHECK status in the service info, got %+v", info.Health)
+	}
+}
+
+func TestDockerAPIManager_GetStatusDetails(t *testing.T) {
+	manager, _ := newTestDockerAPIManager(t)
+
+	info, err := manager.GetStatus(context.Background(), "db")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.Status != types.StatusInactive || info.SubState != "exited" {
+		t.Errorf("Unexpected status: %+v", info)
+	}
+	if info.ExitCode == nil || *info.ExitCode != 137 || !info.OOMKilled || info.Restarts != 3 {
+		t.Errorf("Expected OOM kill after 3 restarts, got %+v", info)
+	}
+	if info.EnabledState != "enabled" || info.ActiveSince != nil {
+		t.Errorf("Unexpected enabled state or active time: %+v", info)
+	}
+
+	// 运行中的容器没有退出码
+	web, err := manager.GetStatus(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if web.ExitCode != nil || web.EnabledState != "disabled" || web.ActiveSince == nil {
+		t.Errorf("Unexpected running container details: %+v", web)
+	}
+}
+
+func TestDockerAPIManager_NotFound(t *testing.T) {
+	manager, _ := newTestDockerAPIManager(t)
+
+	_, err := manager.GetStatus(context.Background(), "missing")
+	if !IsDockerNotFound(err) {
+		t.Fatalf("Expected 404 error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "No such container: missing") {
+		t.Errorf("Expected daemon message in error, got %v", err)
+	}
+
+	if err := manager.Start(context.Background(), "missing"); !IsDockerNotFound(err) {
+		t.Errorf("Expected 404 from Start, got %v", err)
+	}
+}
+
+func TestDockerAPIManager_ListServices(t *testing.T) {
+	manager, _ := newTestDockerAPIManager(t)
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	statuses := make(map[string]types.ServiceStatus)
+	for _, s := range services {
+		if s.Type != types.ServiceTypeDocker {
+			t.Errorf("Expected docker type, got %s", s.Type)
+		}
+		statuses[s.Name] = s.Status
+	}
+	if statuses["web"] != types.StatusActive || statuses["db"] != types.StatusInactive {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+}
+
+func TestDockerAPIManager_Lifecycle(t *testing.T) {
+	manager, engine := newTestDockerAPIManager(t)
+	ctx := context.Background()
+
+	if err := manager.Start(ctx, "db"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	// 304 Not Modified（已经在运行）不视为错误
+	if err := manager.Start(ctx, "db"); err != nil {
+		t.Fatalf("Start of running container failed: %v", err)
+	}
+	if err := manager.Stop(ctx, "web"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := manager.Restart(ctx, "web"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+
+	if err := manager.Enable(ctx, "db"); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	if policy := engine.containers["db"].HostConfig.RestartPolicy.Name; policy != "always" {
+		t.Errorf("Expected restart policy always, got %q", policy)
+	}
+	if err := manager.Disable(ctx, "db"); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+	if policy := engine.containers["db"].HostConfig.RestartPolicy.Name; policy != "no" {
+		t.Errorf("Expected restart policy no, got %q", policy)
+	}
+}
+
+func TestDockerAPIManager_RemoveConflict(t *testing.T) {
+	manager, engine := newTestDockerAPIManager(t)
+
+	err := manager.RemoveContainer(context.Background(), "web", false)
+	if !IsDockerConflict(err) {
+		t.Fatalf("Expected 409 conflict, got %v", err)
+	}
+
+	if err := manager.RemoveContainer(context.Background(), "web", true); err != nil {
+		t.Fatalf("Forced remove failed: %v", err)
+	}
+	if _, exists := engine.containers["web"]; exists {
+		t.Error("Expected container to be removed")
+	}
+}
+
+func TestDockerAPIManager_GetLogs(t *testing.T) {
+	manager, _ := newTestDockerAPIManager(t)
+
+	logs, err := manager.GetLogs(context.Background(), "web", 10)
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if logs != "GET / 200\nwarn: slow\n" {
+		t.Errorf("Unexpected demuxed logs: %q", logs)
+	}
+}
+
+func TestDockerAPIManager_GetStats(t *testing.T) {
+	manager, _ := newTestDockerAPIManager(t)
+
+	stats, err := manager.GetStats(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"CPUPerc":  "40.00%",
+		"MemUsage": "2MiB / 8MiB",
+		"MemPerc":  "25.00%",
+		"NetIO":    "1.5kB / 500B",
+		"PIDs":     "5",
+		"Name":     "web",
+	}
+	for key, want := range expected {
+		if stats[key] != want {
+			t.Errorf("%s: expected %v, got %v", key, want, stats[key])
+		}
+	}
+}
+
+func TestDockerAPIManager_CreateContainer(t *testing.T) {
+	manager, engine := newTestDockerAPIManager(t)
+	ctx := context.Background()
+
+	options := []string{"-e", "MODE=prod", "-p", "8080:80", "--restart=on-failure:3", "-v", "/data:/data"}
+	if err := manager.CreateContainer(ctx, "redis:7", "cache", options); err != nil {
+		t.Fatalf("CreateContainer failed: %v", err)
+	}
+
+	if !engine.images["redis:7"] {
+		t.Error("Expected missing image to be pulled")
+	}
+	req := engine.created
+	if req.Env[0] != "MODE=prod" || req.HostConfig.Binds[0] != "/data:/data" {
+		t.Errorf("Unexpected create request: %+v", req)
+	}
+	if req.HostConfig.PortBindings["80/tcp"][0].HostPort != "8080" {
+		t.Errorf("Unexpected port bindings: %+v", req.HostConfig.PortBindings)
+	}
+	if req.HostConfig.RestartPolicy != (DockerRestartPolicy{Name: "on-failure", MaximumRetryCount: 3}) {
+		t.Errorf("Unexpected restart policy: %+v", req.HostConfig.RestartPolicy)
+	}
+	if !engine.containers["cache"].State.Running {
+		t.Error("Expected created container to be started")
+	}
+
+	if err := manager.CreateContainer(ctx, "redis:7", "cache", nil); !IsDockerConflict(err) {
+		t.Errorf("Expected 409 for duplicate name, got %v", err)
+	}
+	if err := manager.CreateContainer(ctx, "missing/image:1", "x", nil); err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Errorf("Expected pull error, got %v", err)
+	}
+	// 没有 docker CLI 时无法映射的选项直接报错
+	t.Setenv("PATH", t.TempDir())
+	if err := manager.CreateContainer(ctx, "redis:7", "y", []string{"--cap-add", "NET_ADMIN"}); err == nil || !strings.Contains(err.Error(), "unsupported option --cap-add") {
+		t.Errorf("Expected unsupported option to be rejected, got %v", err)
+	}
+}
+
+func TestDockerAPIManager_CreateContainerFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker script requires a POSIX shell")
+	}
+	manager, engine := newTestDockerAPIManager(t)
+
+	// 无法映射的选项交给 docker CLI 原样执行
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$*\" >> " + filepath.Join(dir, "calls") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake docker: %v", err)
+	}
+	t.Setenv("PATH", dir)
+
+	if err := manager.CreateContainer(context.Background(), "redis:7", "cache", []string{"--cap-add", "NET_ADMIN", "--rm"}); err != nil {
+		t.Fatalf("CreateContainer failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "calls"))
+	if err != nil || !strings.HasSuffix(string(data), "run -d --name cache --cap-add NET_ADMIN --rm redis:7\n") {
+		t.Errorf("Expected the docker CLI to run the container, got %q, %v", data, err)
+	}
+	if _, ok := engine.containers["cache"]; ok {
+		t.Error("Expected no container to be created through the API")
+	}
+}
+
+func TestParseDockerRunOptions(t *testing.T) {
+	req, err := parseDockerRunOptions("nginx", []string{
+		"--rm", "--network=host", "-t", "-e", "MODE=prod", "--privileged", "-w", "/srv",
+		"--memory", "512m", "--cpus=1.5", "--entrypoint", "/bin/sh", "-u", "app", "--init=false", "-d",
+	})
+	if err != nil {
+		t.Fatalf("parseDockerRunOptions failed: %v", err)
+	}
+	host := req.HostConfig
+	if !host.AutoRemove || host.NetworkMode != "host" || !host.Privileged || host.Memory != 512<<20 || host.NanoCpus != 1500000000 {
+		t.Errorf("Unexpected host config: %+v", host)
+	}
+	if host.Init == nil || *host.Init {
+		t.Errorf("Expected --init=false to disable init, got %v", host.Init)
+	}
+	// 布尔选项不会吞掉后面的选项
+	if !req.Tty || len(req.Env) != 1 || req.Env[0] != "MODE=prod" || req.WorkingDir != "/srv" || req.User != "app" {
+		t.Errorf("Unexpected container config: %+v", req.DockerContainerConfig)
+	}
+	if len(req.Entrypoint) != 1 || req.Entrypoint[0] != "/bin/sh" {
+		t.Errorf("Unexpected entrypoint: %v", req.Entrypoint)
+	}
+
+	// 末尾的布尔选项不需要值
+	if req, err := parseDockerRunOptions("nginx", []string{"-p", "80", "--rm"}); err != nil || !req.HostConfig.AutoRemove {
+		t.Errorf("Expected trailing --rm to be accepted, got %v", err)
+	}
+
+	for _, options := range [][]string{
+		{"-e"},
+		{"--rm=maybe"},
+		{"--memory", "lots"},
+		{"--cpus", "-1"},
+	} {
+		if _, err := parseDockerRunOptions("nginx", options); err == nil {
+			t.Errorf("Expected %v to fail", options)
+		}
+	}
+	var unsupported *unsupportedRunOptionError
+	if _, err := parseDockerRunOptions("nginx", []string{"-it"}); !errors.As(err, &unsupported) {
+		t.Errorf("Expected combined short flags to be unsupported, got %v", err)
+	}
+}
+
+func TestDemuxDockerStream_TTY(t *testing.T) {
+	raw := "plain tty output\n"
+	if got := demuxDockerStream([]byte(raw)); got != raw {
+		t.Errorf("Expected TTY output unchanged, got %q", got)
+	}
+}
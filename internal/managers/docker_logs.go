@@ -0,0 +1,270 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// ServiceLogs reads the container's stdout and stderr with timestamps;
+// stderr lines are reported as errors. The engine applies the time bounds
+// and, when nothing else is filtered, the line limit. Filtered queries read
+// the whole log as a stream, keeping only the last matches.
+func (dm *DockerAPIManager) ServiceLogs(ctx context.Context, containerName string, query LogQuery) ([]types.LogEntry, error) {
+	params := url.Values{"stdout": {"1"}, "stderr": {"1"}, "timestamps": {"1"}, "tail": {"all"}}
+	if !query.filtered() {
+		params.Set("tail", strconv.Itoa(query.Lines))
+	}
+	if !query.Since.IsZero() {
+		params.Set("since", strconv.FormatInt(query.Since.Unix(), 10))
+	}
+	if !query.Until.IsZero() {
+		params.Set("until", strconv.FormatInt(query.Until.Unix()+1, 10))
+	}
+
+	resp, err := dm.request(ctx, http.MethodGet, containerPath(containerName, "logs"), params, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	tail := logTail{query: query}
+	if err := readDockerLogStream(resp.Body, tail.add); err != nil {
+		return nil, err
+	}
+	return filterLogEntries(tail.entries, query), nil
+}
+
+// ServiceLogs runs docker logs with timestamps. stdout and stderr are read
+// as streams, each keeping its last matches, and merged by time.
+func (dm *DockerManager) ServiceLogs(ctx context.Context, containerName string, query LogQuery) ([]types.LogEntry, error) {
+	args := []string{"logs", "--timestamps"}
+	if !query.filtered() {
+		args = append(args, "--tail", strconv.Itoa(query.Lines))
+	}
+	if !query.Since.IsZero() {
+		args = append(args, "--since", strconv.FormatInt(query.Since.Unix(), 10))
+	}
+	if !query.Until.IsZero() {
+		args = append(args, "--until", strconv.FormatInt(query.Until.Unix()+1, 10))
+	}
+	args = append(args, containerName)
+
+	cmd := commandContext(ctx, "docker", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run docker logs: %v", err)
+	}
+
+	// docker logs reports its own errors on stderr too; the last line is
+	// kept for the error message.
+	var lastError string
+	tails := map[string]*logTail{"stdout": {query: query}, "stderr": {query: query}}
+	var wg sync.WaitGroup
+	read := func(stream string, r io.Reader) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			for _, entry := range dockerLogLines(nil, stream, scanner.Text()) {
+				if stream == "stderr" {
+					lastError = entry.Message
+				}
+				tails[stream].add(entry)
+			}
+		}
+		// Drain the rest so that docker logs does not block on a full pipe.
+		io.Copy(io.Discard, r)
+	}
+	wg.Add(2)
+	go read("stdout", stdout)
+	go read("stderr", stderr)
+	wg.Wait()
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("docker logs failed: %v: %s", err, lastError)
+	}
+
+	entries := append(tails["stdout"].entries, tails["stderr"].entries...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return filterLogEntries(entries, query), nil
+}
+
+// FollowLogs streams the container's logs with follow=1 from the last
+// query.Lines entries on.
+func (dm *DockerAPIManager) FollowLogs(ctx context.Context, containerName string, query LogQuery, emit func(types.LogEntry)) error {
+	params := url.Values{
+		"stdout": {"1"}, "stderr": {"1"}, "timestamps": {"1"},
+		"follow": {"1"}, "tail": {strconv.Itoa(query.Lines)},
+	}
+	if !query.Since.IsZero() {
+		params.Set("since", strconv.FormatInt(query.Since.Unix(), 10))
+	}
+
+	resp, err := dm.request(ctx, http.MethodGet, containerPath(containerName, "logs"), params, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return readDockerLogStream(resp.Body, func(entry types.LogEntry) {
+		if query.Match(entry) {
+			emit(entry)
+		}
+	})
+}
+
+// FollowLogs runs docker logs --follow, reading stdout and stderr as they
+// are written.
+func (dm *DockerManager) FollowLogs(ctx context.Context, containerName string, query LogQuery, emit func(types.LogEntry)) error {
+	args := []string{"logs", "--follow", "--timestamps", "--tail", strconv.Itoa(query.Lines)}
+	if !query.Since.IsZero() {
+		args = append(args, "--since", strconv.FormatInt(query.Since.Unix(), 10))
+	}
+	args = append(args, containerName)
+
+	cmd := commandContext(ctx, "docker", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run docker logs: %v", err)
+	}
+
+	var mu sync.Mutex
+	var lastError string
+	var wg sync.WaitGroup
+	read := func(stream string, r io.Reader) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			mu.Lock()
+			for _, entry := range dockerLogLines(nil, stream, scanner.Text()) {
+				if stream == "stderr" {
+					lastError = entry.Message
+				}
+				if query.Match(entry) {
+					emit(entry)
+				}
+			}
+			mu.Unlock()
+		}
+	}
+	wg.Add(2)
+	go read("stdout", stdout)
+	go read("stderr", stderr)
+	wg.Wait()
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("docker logs failed: %v: %s", err, lastError)
+	}
+	return nil
+}
+
+// readDockerLogStream passes the entries of a log stream to emit as they
+// arrive. Lines may be split across frames; the rest of a line is kept per
+// stream until its newline is read.
+func readDockerLogStream(r io.Reader, emit func(types.LogEntry)) error {
+	reader := bufio.NewReader(r)
+	header, _ := reader.Peek(8)
+	if !isDockerFrameHeader(header) {
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			for _, entry := range dockerLogLines(nil, "stdout", scanner.Text()) {
+				emit(entry)
+			}
+		}
+		return scanner.Err()
+	}
+
+	pending := map[string]string{}
+	flush := func(stream, text string) {
+		for _, entry := range dockerLogLines(nil, stream, text) {
+			emit(entry)
+		}
+	}
+	for {
+		var frame [8]byte
+		if _, err := io.ReadFull(reader, frame[:]); err != nil {
+			for stream, rest := range pending {
+				flush(stream, rest)
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if !isDockerFrameHeader(frame[:]) {
+			return fmt.Errorf("invalid log frame header")
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(frame[4:8]))
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return err
+		}
+
+		stream := "stdout"
+		if frame[0] == 2 {
+			stream = "stderr"
+		}
+		text := pending[stream] + string(payload)
+		end := strings.LastIndexByte(text, '\n')
+		flush(stream, text[:end+1])
+		pending[stream] = text[end+1:]
+	}
+}
+
+func isDockerFrameHeader(header []byte) bool {
+	return len(header) == 8 && header[0] <= 2 && header[1] == 0 && header[2] == 0 && header[3] == 0
+}
+
+// dockerLogLines appends the lines of output, each starting with the
+// timestamp docker logs --timestamps adds.
+func dockerLogLines(entries []types.LogEntry, stream, output string) []types.LogEntry {
+	priority := LogPriorityInfo
+	if stream == "stderr" {
+		priority = LogPriorityErr
+	}
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := types.LogEntry{Priority: priority, Message: line, Source: stream}
+		if stamp, message, ok := strings.Cut(line, " "); ok {
+			if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+				entry.Time, entry.Message = t, message
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
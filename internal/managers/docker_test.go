@@ -1,6 +1,7 @@
 package managers
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -131,7 +132,7 @@ func TestDockerManager_ListServices(t *testing.T) {
 	}
 	
 	manager := NewDockerManager()
-	services, err := manager.ListServices()
+	services, err := manager.ListServices(context.Background())
 	
 	if err != nil {
 		t.Fatalf("Failed to list Docker containers: %v", err)
@@ -167,7 +168,7 @@ func TestDockerManager_GetStatus(t *testing.T) {
 	manager := NewDockerManager()
 	
 	// 首先获取容器列表
-	services, err := manager.ListServices()
+	services, err := manager.ListServices(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to list Docker containers: %v", err)
 	}
@@ -178,7 +179,7 @@ func TestDockerManager_GetStatus(t *testing.T) {
 	
 	// 测试第一个容器的状态
 	containerName := services[0].Name
-	info, err := manager.GetStatus(containerName)
+	info, err := manager.GetStatus(context.Background(), containerName)
 	
 	if err != nil {
 		t.Fatalf("Failed to get status for container %s: %v", containerName, err)
@@ -202,7 +203,7 @@ func TestDockerManager_NonexistentContainer(t *testing.T) {
 	nonexistentContainer := "definitely-does-not-exist-container-12345"
 	
 	// 获取不存在容器的状态应该返回错误
-	_, err := manager.GetStatus(nonexistentContainer)
+	_, err := manager.GetStatus(context.Background(), nonexistentContainer)
 	if err == nil {
 		t.Error("Expected error for nonexistent container, but got none")
 	}
@@ -217,27 +218,27 @@ func TestDockerManager_BasicOperations(t *testing.T) {
 	testContainer := "test-nonexistent-container"
 	
 	// 测试基本操作（这些操作应该会失败，因为容器不存在）
-	err := manager.Start(testContainer)
+	err := manager.Start(context.Background(), testContainer)
 	if err != nil {
 		t.Logf("Start failed (expected for nonexistent container): %v", err)
 	}
 	
-	err = manager.Stop(testContainer)
+	err = manager.Stop(context.Background(), testContainer)
 	if err != nil {
 		t.Logf("Stop failed (expected for nonexistent container): %v", err)
 	}
 	
-	err = manager.Restart(testContainer)
+	err = manager.Restart(context.Background(), testContainer)
 	if err != nil {
 		t.Logf("Restart failed (expected for nonexistent container): %v", err)
 	}
 	
-	err = manager.Enable(testContainer)
+	err = manager.Enable(context.Background(), testContainer)
 	if err != nil {
 		t.Logf("Enable failed (expected for nonexistent container): %v", err)
 	}
 	
-	err = manager.Disable(testContainer)
+	err = manager.Disable(context.Background(), testContainer)
 	if err != nil {
 		t.Logf("Disable failed (expected for nonexistent container): %v", err)
 	}
@@ -252,25 +253,25 @@ func TestDockerManager_AdditionalMethods(t *testing.T) {
 	testContainer := "test-nonexistent-container"
 	
 	// 测试获取日志（应该失败，因为容器不存在）
-	_, err := manager.GetLogs(testContainer, 10)
+	_, err := manager.GetLogs(context.Background(), testContainer, 10)
 	if err != nil {
 		t.Logf("GetLogs failed (expected for nonexistent container): %v", err)
 	}
 	
 	// 测试获取统计信息（应该失败，因为容器不存在）
-	_, err = manager.GetStats(testContainer)
+	_, err = manager.GetStats(context.Background(), testContainer)
 	if err != nil {
 		t.Logf("GetStats failed (expected for nonexistent container): %v", err)
 	}
 	
 	// 测试删除容器（应该失败，因为容器不存在）
-	err = manager.RemoveContainer(testContainer, false)
+	err = manager.RemoveContainer(context.Background(), testContainer, false)
 	if err != nil {
 		t.Logf("RemoveContainer failed (expected for nonexistent container): %v", err)
 	}
 	
 	// 测试创建容器（使用无效镜像，应该失败）
-	err = manager.CreateContainer("nonexistent-image:latest", "test-container", []string{})
+	err = manager.CreateContainer(context.Background(), "nonexistent-image:latest", "test-container", []string{})
 	if err != nil {
 		t.Logf("CreateContainer failed (expected for nonexistent image): %v", err)
 	}
@@ -286,7 +287,7 @@ func BenchmarkDockerManager_ListServices(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = manager.ListServices()
+		_, _ = manager.ListServices(context.Background())
 	}
 }
 
@@ -298,7 +299,7 @@ func BenchmarkDockerManager_GetStatus(b *testing.B) {
 	manager := NewDockerManager()
 	
 	// 获取一个容器名用于测试
-	services, err := manager.ListServices()
+	services, err := manager.ListServices(context.Background())
 	if err != nil || len(services) == 0 {
 		b.Skip("No containers available for benchmark")
 	}
@@ -307,6 +308,6 @@ func BenchmarkDockerManager_GetStatus(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = manager.GetStatus(containerName)
+		_, _ = manager.GetStatus(context.Background(), containerName)
 	}
 }
\ No newline at end of file
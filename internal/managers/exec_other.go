@@ -0,0 +1,47 @@
+//go:build windows
+
+package managers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+)
+
+const commandWaitDelay = 2 * time.Second
+
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = commandWaitDelay
+	return cmd
+}
+
+func shellCommand(ctx context.Context, script string) *exec.Cmd {
+	return commandContext(ctx, "cmd", "/C", script)
+}
+
+func setCommandUser(cmd *exec.Cmd, username string) error {
+	return errors.New("running commands as another user is not supported on windows")
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
+
+func terminateProcessGroup(pid int, force bool) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,83 @@
+//go:build !windows
+
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// commandWaitDelay bounds how long Wait blocks on output pipes still held by
+// grandchildren after the direct child has been killed.
+const commandWaitDelay = 2 * time.Second
+
+// commandContext is exec.CommandContext, except that cancellation kills the
+// whole process group so init scripts cannot leave orphaned children behind.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = commandWaitDelay
+	return cmd
+}
+
+// shellCommand runs script with /bin/sh -c under commandContext.
+func shellCommand(ctx context.Context, script string) *exec.Cmd {
+	return commandContext(ctx, "/bin/sh", "-c", script)
+}
+
+// setCommandUser makes cmd run as the named user with its primary and
+// supplementary groups.
+func setCommandUser(cmd *exec.Cmd, username string) error {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return err
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid uid %q for user %s", u.Uid, username)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid gid %q for user %s", u.Gid, username)
+	}
+
+	var groups []uint32
+	if ids, err := u.GroupIds(); err == nil {
+		for _, id := range ids {
+			if g, err := strconv.ParseUint(id, 10, 32); err == nil {
+				groups = append(groups, uint32(g))
+			}
+		}
+	}
+
+	cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid), Groups: groups}
+	return nil
+}
+
+// processAlive reports whether a process with the given pid exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminateProcessGroup sends SIGTERM, or SIGKILL when force is set, to the
+// process group led by pid.
+func terminateProcessGroup(pid int, force bool) error {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	return syscall.Kill(-pid, sig)
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package managers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCommandContext_KillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// 子shell再派生一个sleep，取消时整个进程组都应被杀死
+	cmd := commandContext(ctx, "sh", "-c", "sleep 30 & sleep 30; wait")
+
+	start := time.Now()
+	_, err := cmd.Output()
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("Expected error after context deadline")
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("Expected command to be killed promptly, took %s", elapsed)
+	}
+}
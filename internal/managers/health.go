@@ -0,0 +1,328 @@
+package managers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const (
+	defaultHealthInterval           = 30 * time.Second
+	defaultHealthTimeout            = 5 * time.Second
+	defaultHealthHealthyThreshold   = 1
+	defaultHealthUnhealthyThreshold = 3
+
+	// maxProbeOutput bounds the output kept from a probe, and
+	// maxProbeBody the part of an HTTP response searched for ExpectBody.
+	maxProbeOutput = 512
+	maxProbeBody   = 64 << 10
+)
+
+// HealthMonitor runs the health probes declared under health: in
+// config.yaml in the background and folds their results into the status of
+// the services they probe.
+type HealthMonitor struct {
+	logger *logrus.Logger
+	probes []*healthProbe
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type healthProbe struct {
+	check  config.HealthCheck
+	logger *logrus.Logger
+	client *http.Client
+
+	mu     sync.Mutex
+	result types.ProbeResult
+}
+
+// NewHealthMonitor starts a probe loop for each health check. Every probe
+// runs once right away and then at its interval until Close.
+func NewHealthMonitor(checks []config.HealthCheck, logger *logrus.Logger) *HealthMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &HealthMonitor{logger: logger, cancel: cancel}
+	for _, check := range checks {
+		check = withHealthDefaults(check)
+		probe := &healthProbe{
+			check:  check,
+			logger: logger,
+			result: types.ProbeResult{
+				Service:     check.Service,
+				ServiceType: types.ServiceType(check.Type),
+				Name:        check.Name,
+				Kind:        check.Kind(),
+				Target:      probeTarget(check),
+				Status:      types.HealthStarting,
+			},
+		}
+		if check.HTTP != nil {
+			probe.client = &http.Client{
+				// A redirect answers the probe; it is not followed.
+				CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+			}
+		}
+		m.probes = append(m.probes, probe)
+	}
+
+	for _, probe := range m.probes {
+		m.wg.Add(1)
+		go func(probe *healthProbe) {
+			defer m.wg.Done()
+			probe.loop(ctx)
+		}(probe)
+	}
+	if len(m.probes) > 0 {
+		logger.Infof("Health monitor started with %d probes", len(m.probes))
+	}
+	return m
+}
+
+func withHealthDefaults(check config.HealthCheck) config.HealthCheck {
+	if check.Interval <= 0 {
+		check.Interval = defaultHealthInterval
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = defaultHealthTimeout
+	}
+	if check.HealthyThreshold <= 0 {
+		check.HealthyThreshold = defaultHealthHealthyThreshold
+	}
+	if check.UnhealthyThreshold <= 0 {
+		check.UnhealthyThreshold = defaultHealthUnhealthyThreshold
+	}
+	if check.Name == "" {
+		check.Name = check.Kind()
+	}
+	if check.HTTP != nil && check.HTTP.Method == "" {
+		probe := *check.HTTP
+		probe.Method = http.MethodGet
+		check.HTTP = &probe
+	}
+	return check
+}
+
+func probeTarget(check config.HealthCheck) string {
+	switch {
+	case check.HTTP != nil:
+		return check.HTTP.URL
+	case check.TCP != nil:
+		return check.TCP.Address
+	default:
+		return check.Exec.Command
+	}
+}
+
+// Results returns the current state of every probe in configuration order.
+func (m *HealthMonitor) Results() []types.ProbeResult {
+	if m == nil {
+		return nil
+	}
+	results := make([]types.ProbeResult, 0, len(m.probes))
+	for _, probe := range m.probes {
+		results = append(results, probe.snapshot())
+	}
+	return results
+}
+
+// ServiceResults returns the state of the probes of one service. Probes
+// declared without a type match the service under any type.
+func (m *HealthMonitor) ServiceResults(serviceName string, serviceType types.ServiceType) []types.ProbeResult {
+	if m == nil {
+		return nil
+	}
+	var results []types.ProbeResult
+	for _, probe := range m.probes {
+		if probe.check.Service != serviceName && probe.check.Service != systemdListName(serviceName) {
+			continue
+		}
+		if probe.check.Type != "" && types.ServiceType(probe.check.Type) != serviceType {
+			continue
+		}
+		results = append(results, probe.snapshot())
+	}
+	return results
+}
+
+// Annotate adds the service's probe results to info.Health, next to the
+// container engine's own health check if the manager reported one.
+func (m *HealthMonitor) Annotate(info *types.ServiceInfo) {
+	results := m.ServiceResults(info.Name, info.Type)
+	if len(results) == 0 {
+		return
+	}
+	if info.Health != nil {
+		results = append(info.Health.Probes, results...)
+	}
+	info.Health = types.NewServiceHealth(results)
+}
+
+// AnnotateAll annotates every service of a listing.
+func (m *HealthMonitor) AnnotateAll(services []types.ServiceInfo) {
+	for i := range services {
+		m.Annotate(&services[i])
+	}
+}
+
+// Close stops the probe loops and waits for running probes to return.
+func (m *HealthMonitor) Close() error {
+	if m == nil {
+		return nil
+	}
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
+
+func (p *healthProbe) snapshot() types.ProbeResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.result
+}
+
+func (p *healthProbe) loop(ctx context.Context) {
+	ticker := time.NewTicker(p.check.Interval)
+	defer ticker.Stop()
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, p.check.Timeout)
+		output, err := p.probe(probeCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		p.record(output, err, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe runs the check once. The output is kept for exec probes.
+func (p *healthProbe) probe(ctx context.Context) (string, error) {
+	switch {
+	case p.check.HTTP != nil:
+		return "", p.probeHTTP(ctx)
+	case p.check.TCP != nil:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", p.check.TCP.Address)
+		if err != nil {
+			return "", err
+		}
+		return "", conn.Close()
+	default:
+		output, err := shellCommand(ctx, p.check.Exec.Command).CombinedOutput()
+		if ctx.Err() == context.DeadlineExceeded {
+			return string(output), fmt.Errorf("timed out after %s", p.check.Timeout)
+		}
+		return string(output), err
+	}
+}
+
+func (p *healthProbe) probeHTTP(ctx context.Context) error {
+	probe := p.check.HTTP
+	req, err := http.NewRequestWithContext(ctx, probe.Method, probe.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Read what is left of the body so that the connection can be reused by
+	// the next probe.
+	defer func() {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxProbeBody))
+		resp.Body.Close()
+	}()
+
+	if !expectedStatus(probe.ExpectStatus, resp.StatusCode) {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if probe.ExpectBody != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(body), probe.ExpectBody) {
+			return fmt.Errorf("response does not contain %q", probe.ExpectBody)
+		}
+	}
+	return nil
+}
+
+func expectedStatus(expected []int, status int) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 400
+	}
+	for _, code := range expected {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// record updates the streaks with one result and moves the probe to healthy
+// or unhealthy when a streak reaches its threshold.
+func (p *healthProbe) record(output string, err error, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := &p.result
+	previous := result.Status
+	result.LastCheck = at
+	if err == nil {
+		result.SuccessStreak++
+		result.FailingStreak = 0
+		result.Output = probeOutput(output)
+		if result.SuccessStreak >= p.check.HealthyThreshold {
+			result.Status = types.HealthHealthy
+		}
+	} else {
+		result.FailingStreak++
+		result.SuccessStreak = 0
+		result.Output = probeOutput(err.Error())
+		if message := probeOutput(output); message != "" {
+			result.Output = probeOutput(err.Error() + ": " + message)
+		}
+		if result.FailingStreak >= p.check.UnhealthyThreshold {
+			result.Status = types.HealthUnhealthy
+		}
+	}
+
+	if result.Status != previous {
+		if result.Status == types.HealthUnhealthy {
+			p.logger.Warnf("Health probe %s of %s is unhealthy: %s", result.Name, result.Service, result.Output)
+		} else {
+			p.logger.Infof("Health probe %s of %s is %s", result.Name, result.Service, result.Status)
+		}
+	}
+}
+
+// probeOutput trims probe output to a single bounded line.
+func probeOutput(output string) string {
+	output = strings.Join(strings.Fields(output), " ")
+	if len(output) > maxProbeOutput {
+		// Cut before the character that would be split.
+		end := maxProbeOutput
+		for end > 0 && !utf8.RuneStart(output[end]) {
+			end--
+		}
+		output = output[:end] + "..."
+	}
+	return output
+}
@@ -0,0 +1,281 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// newTestHealthMonitor 使用很短的探测间隔启动健康监控
+func newTestHealthMonitor(t *testing.T, checks ...config.HealthCheck) *HealthMonitor {
+	t.Helper()
+	for i := range checks {
+		if checks[i].Interval == 0 {
+			checks[i].Interval = 10 * time.Millisecond
+		}
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	monitor := NewHealthMonitor(checks, logger)
+	t.Cleanup(func() { monitor.Close() })
+	return monitor
+}
+
+// waitForHealth 轮询直到指定探测进入期望状态
+func waitForHealth(t *testing.T, monitor *HealthMonitor, name string, want types.HealthStatus) types.ProbeResult {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		for _, result := range monitor.Results() {
+			if result.Name == name && result.Status == want {
+				return result
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for probe %s to be %s, last %+v", name, want, monitor.Results())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHealthMonitor_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			w.Write([]byte(`{"status":"ok"}`))
+		case "/moved":
+			http.Redirect(w, r, "/missing", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	monitor := newTestHealthMonitor(t,
+		config.HealthCheck{Service: "api", Name: "ok", HTTP: &config.HTTPProbe{URL: srv.URL + "/healthz", ExpectBody: `"ok"`}},
+		config.HealthCheck{Service: "api", Name: "body", HTTP: &config.HTTPProbe{URL: srv.URL + "/healthz", ExpectBody: "ready"}, UnhealthyThreshold: 1},
+		config.HealthCheck{Service: "api", Name: "missing", HTTP: &config.HTTPProbe{URL: srv.URL + "/missing"}, UnhealthyThreshold: 1},
+		// 重定向本身即为应答，不会跟随到 404
+		config.HealthCheck{Service: "api", Name: "moved", HTTP: &config.HTTPProbe{URL: srv.URL + "/moved"}},
+		config.HealthCheck{Service: "api", Name: "status", HTTP: &config.HTTPProbe{URL: srv.URL + "/missing", ExpectStatus: []int{404}}},
+	)
+
+	waitForHealth(t, monitor, "ok", types.HealthHealthy)
+	waitForHealth(t, monitor, "moved", types.HealthHealthy)
+	waitForHealth(t, monitor, "status", types.HealthHealthy)
+	if result := waitForHealth(t, monitor, "body", types.HealthUnhealthy); !strings.Contains(result.Output, "does not contain") {
+		t.Errorf("Unexpected body failure: %q", result.Output)
+	}
+	if result := waitForHealth(t, monitor, "missing", types.HealthUnhealthy); !strings.Contains(result.Output, "404") {
+		t.Errorf("Unexpected status failure: %q", result.Output)
+	}
+}
+
+func TestHealthMonitor_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	// 关闭的端口用于模拟连接被拒绝
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	monitor := newTestHealthMonitor(t,
+		config.HealthCheck{Service: "db", Name: "open", TCP: &config.TCPProbe{Address: listener.Addr().String()}},
+		config.HealthCheck{Service: "db", Name: "closed", TCP: &config.TCPProbe{Address: closedAddress}},
+	)
+
+	waitForHealth(t, monitor, "open", types.HealthHealthy)
+	result := waitForHealth(t, monitor, "closed", types.HealthUnhealthy)
+	if result.FailingStreak < 3 || result.Target != closedAddress || result.Kind != "tcp" {
+		t.Errorf("Unexpected TCP failure: %+v", result)
+	}
+}
+
+func TestHealthMonitor_Exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec probes use /bin/sh")
+	}
+
+	monitor := newTestHealthMonitor(t,
+		config.HealthCheck{Service: "worker", Name: "pass", Exec: &config.ExecProbe{Command: "echo ready"}},
+		config.HealthCheck{Service: "worker", Name: "fail", Exec: &config.ExecProbe{Command: "echo queue stuck; exit 2"}, UnhealthyThreshold: 1},
+		config.HealthCheck{Service: "worker", Name: "slow", Exec: &config.ExecProbe{Command: "sleep 5"}, Timeout: 50 * time.Millisecond, UnhealthyThreshold: 1},
+	)
+
+	if result := waitForHealth(t, monitor, "pass", types.HealthHealthy); result.Output != "ready" {
+		t.Errorf("Expected exec output to be kept, got %q", result.Output)
+	}
+	if result := waitForHealth(t, monitor, "fail", types.HealthUnhealthy); !strings.Contains(result.Output, "queue stuck") {
+		t.Errorf("Expected exec failure output, got %q", result.Output)
+	}
+	if result := waitForHealth(t, monitor, "slow", types.HealthUnhealthy); !strings.Contains(result.Output, "timed out") {
+		t.Errorf("Expected a timeout, got %q", result.Output)
+	}
+}
+
+func TestHealthProbe_Thresholds(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	probe := &healthProbe{
+		check:  config.HealthCheck{HealthyThreshold: 2, UnhealthyThreshold: 2},
+		logger: logger,
+		result: types.ProbeResult{Status: types.HealthStarting},
+	}
+	failure := errors.New("connection refused")
+	now := time.Now()
+
+	steps := []struct {
+		err  error
+		want types.HealthStatus
+	}{
+		{nil, types.HealthStarting},
+		{nil, types.HealthHealthy},
+		// 单次失败不足以变为不健康
+		{failure, types.HealthHealthy},
+		{nil, types.HealthHealthy},
+		{failure, types.HealthHealthy},
+		{failure, types.HealthUnhealthy},
+		{nil, types.HealthUnhealthy},
+		{nil, types.HealthHealthy},
+	}
+	for i, step := range steps {
+		probe.record("", step.err, now)
+		if result := probe.snapshot(); result.Status != step.want {
+			t.Fatalf("Step %d: expected %s, got %+v", i, step.want, result)
+		}
+	}
+}
+
+func TestHealthMonitor_Annotate(t *testing.T) {
+	monitor := &HealthMonitor{probes: []*healthProbe{
+		{check: config.HealthCheck{Service: "nginx"}, result: types.ProbeResult{Service: "nginx", Name: "http", Status: types.HealthHealthy}},
+		{check: config.HealthCheck{Service: "web", Type: "docker"}, result: types.ProbeResult{Service: "web", Name: "tcp", Status: types.HealthUnhealthy}},
+	}}
+
+	// systemd 单元名带 .service 后缀时同样匹配
+	nginx := types.ServiceInfo{Name: "nginx.service", Type: types.ServiceTypeSystemd}
+	monitor.Annotate(&nginx)
+	if nginx.Health == nil || nginx.Health.Status != types.HealthHealthy || len(nginx.Health.Probes) != 1 {
+		t.Errorf("Unexpected nginx health: %+v", nginx.Health)
+	}
+
+	// 配置的探测与容器引擎的健康检查合并
+	web := types.ServiceInfo{Name: "web", Type: types.ServiceTypeDocker, Health: types.NewServiceHealth([]types.ProbeResult{{Name: "docker", Status: types.HealthHealthy}})}
+	monitor.Annotate(&web)
+	if web.Health.Status != types.HealthUnhealthy || len(web.Health.Probes) != 2 || web.Health.Probes[0].Name != "docker" {
+		t.Errorf("Unexpected web health: %+v", web.Health)
+	}
+
+	// 类型不匹配的探测不适用
+	other := types.ServiceInfo{Name: "web", Type: types.ServiceTypePodman}
+	monitor.Annotate(&other)
+	if other.Health != nil {
+		t.Errorf("Expected no health for podman web, got %+v", other.Health)
+	}
+
+	var none *HealthMonitor
+	none.Annotate(&other)
+	if none.Results() != nil || none.Close() != nil {
+		t.Error("Expected a nil monitor to be usable")
+	}
+}
+
+func TestEngineHealth(t *testing.T) {
+	if engineHealth("web", types.ServiceTypeDocker, nil) != nil || engineHealth("web", types.ServiceTypeDocker, &DockerHealth{Status: "none"}) != nil {
+		t.Error("Expected no health without a HEALTHCHECK")
+	}
+
+	health := engineHealth("web", types.ServiceTypeDocker, &DockerHealth{
+		Status:        "unhealthy",
+		FailingStreak: 3,
+		Log: []DockerHealthResult{
+			{End: "2024-01-01T00:00:00Z", ExitCode: 0},
+			{End: "2024-01-01T00:00:30Z", ExitCode: 1, Output: "curl: (7) Failed to connect\n"},
+		},
+	})
+	if health == nil || health.Status != types.HealthUnhealthy || len(health.Probes) != 1 {
+		t.Fatalf("Unexpected health: %+v", health)
+	}
+	probe := health.Probes[0]
+	if probe.Name != "docker" || probe.FailingStreak != 3 || probe.SuccessStreak != 0 || probe.Output != "curl: (7) Failed to connect" {
+		t.Errorf("Unexpected engine probe: %+v", probe)
+	}
+	if !probe.LastCheck.Equal(time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC)) {
+		t.Errorf("Unexpected last check: %s", probe.LastCheck)
+	}
+}
+
+func TestHealthProbe_HTTPReusesConnections(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("ok\n", 10000)))
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			connections++
+			mu.Unlock()
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	// 未设置 ExpectBody 时也要读完响应体，连接才能复用
+	probe := &healthProbe{
+		check:  config.HealthCheck{HTTP: &config.HTTPProbe{Method: http.MethodGet, URL: srv.URL}},
+		client: &http.Client{},
+	}
+	for i := 0; i < 5; i++ {
+		if err := probe.probeHTTP(context.Background()); err != nil {
+			t.Fatalf("Probe failed: %v", err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if connections != 1 {
+		t.Errorf("Expected one reused connection, got %d", connections)
+	}
+}
+
+func TestProbeOutput(t *testing.T) {
+	if got := probeOutput("  disk\n full  "); got != "disk full" {
+		t.Errorf("Expected whitespace to be collapsed, got %q", got)
+	}
+
+	// 截断不能拆开多字节字符
+	output := probeOutput("a" + strings.Repeat("磁", maxProbeOutput))
+	if !utf8.ValidString(output) || !strings.HasSuffix(output, "...") || len(output) > maxProbeOutput+3 {
+		t.Errorf("Unexpected truncated output: %q", output)
+	}
+}
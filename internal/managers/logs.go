@@ -0,0 +1,324 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const (
+	// DefaultLogLines is the number of entries returned when a query sets
+	// no limit.
+	DefaultLogLines = 100
+	// MaxLogLines bounds the entries a single query returns.
+	MaxLogLines = 10000
+
+	// LogPriorityErr and LogPriorityInfo are given to entries whose source
+	// has no levels of its own; LogPriorityDebug is the least important
+	// level.
+	LogPriorityErr   = 3
+	LogPriorityInfo  = 6
+	LogPriorityDebug = 7
+)
+
+// LogQuery selects the entries of a service's log. Build it with
+// ParseLogQuery; the zero value only keeps emergency messages.
+type LogQuery struct {
+	// Since and Until bound the entry times; zero means unbounded.
+	Since time.Time
+	Until time.Time
+	// Priority keeps entries of this syslog level or a more important one.
+	// LogPriorityDebug keeps all entries.
+	Priority int
+	// Grep keeps entries whose message matches; nil keeps all.
+	Grep *regexp.Regexp
+	// Lines is the number of entries to return, the most recent ones.
+	Lines int
+}
+
+// LogProvider is implemented by managers that can read a service's log.
+// Entries are returned oldest first.
+type LogProvider interface {
+	ServiceLogs(ctx context.Context, serviceName string, query LogQuery) ([]types.LogEntry, error)
+}
+
+// LogFollower is implemented by managers that can follow a service's log
+// like tail -f. FollowLogs passes the last query.Lines entries to emit,
+// then each new one as it is written, until ctx is done or the log source
+// ends. emit is never called concurrently.
+type LogFollower interface {
+	FollowLogs(ctx context.Context, serviceName string, query LogQuery, emit func(types.LogEntry)) error
+}
+
+// logTextProvider is the older capability of managers that only return the
+// last lines of a log as text.
+type logTextProvider interface {
+	GetLogs(ctx context.Context, serviceName string, lines int) (string, error)
+}
+
+var logPriorities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "error": 3,
+	"warning": 4, "warn": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// ParseLogQuery validates the filters of a log request. since and until
+// accept RFC 3339 times, "2006-01-02 15:04:05" or "2006-01-02" in local
+// time, or a duration such as "90m" meaning that long ago. priority is a
+// syslog level name (err, warning, ...) or number; lines defaults to
+// DefaultLogLines.
+func ParseLogQuery(since, until, priority, grep string, lines int) (LogQuery, error) {
+	query := LogQuery{Priority: LogPriorityDebug, Lines: lines}
+
+	var err error
+	if query.Since, err = parseLogTime(since); err != nil {
+		return query, fmt.Errorf("invalid since: %v", err)
+	}
+	if query.Until, err = parseLogTime(until); err != nil {
+		return query, fmt.Errorf("invalid until: %v", err)
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && query.Until.Before(query.Since) {
+		return query, fmt.Errorf("until must not be before since")
+	}
+
+	if priority != "" {
+		level, ok := logPriorities[strings.ToLower(priority)]
+		if n, err := strconv.Atoi(priority); err == nil {
+			level, ok = n, n >= 0 && n <= LogPriorityDebug
+		}
+		if !ok {
+			return query, fmt.Errorf("invalid priority %q, expected 0-7 or a level such as err or warning", priority)
+		}
+		query.Priority = level
+	}
+
+	if grep != "" {
+		if query.Grep, err = regexp.Compile(grep); err != nil {
+			return query, fmt.Errorf("invalid grep pattern: %v", err)
+		}
+	}
+
+	switch {
+	case query.Lines <= 0:
+		query.Lines = DefaultLogLines
+	case query.Lines > MaxLogLines:
+		query.Lines = MaxLogLines
+	}
+	return query, nil
+}
+
+func parseLogTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(strings.TrimPrefix(value, "-")); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a time or duration", value)
+}
+
+// Match reports whether entry passes the query's filters. Entries without
+// a time never pass a time bound.
+func (q LogQuery) Match(entry types.LogEntry) bool {
+	if entry.Priority > q.Priority {
+		return false
+	}
+	if !q.Since.IsZero() && (entry.Time.IsZero() || entry.Time.Before(q.Since)) {
+		return false
+	}
+	if !q.Until.IsZero() && (entry.Time.IsZero() || entry.Time.After(q.Until)) {
+		return false
+	}
+	return q.Grep == nil || q.Grep.MatchString(entry.Message)
+}
+
+// filtered reports whether the query filters beyond the time bounds, so
+// that a source cannot simply be asked for its last Lines entries.
+func (q LogQuery) filtered() bool {
+	return q.Grep != nil || q.Priority < LogPriorityDebug
+}
+
+// filterLogEntries keeps the last q.Lines entries that match q.
+func filterLogEntries(entries []types.LogEntry, q LogQuery) []types.LogEntry {
+	matched := make([]types.LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if q.Match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	if len(matched) > q.Lines {
+		matched = matched[len(matched)-q.Lines:]
+	}
+	return matched
+}
+
+// logTail collects the entries of a log read from start to end that match a
+// query. Only the last query.Lines entries can be returned, so at most twice
+// that many are held while reading.
+type logTail struct {
+	query   LogQuery
+	entries []types.LogEntry
+}
+
+func (t *logTail) add(entry types.LogEntry) {
+	if !t.query.Match(entry) {
+		return
+	}
+	t.entries = append(t.entries, entry)
+	if len(t.entries) >= 2*t.query.Lines {
+		t.entries = append(t.entries[:0], t.entries[len(t.entries)-t.query.Lines:]...)
+	}
+}
+
+// ReadServiceLogs reads a service's log through the manager's LogProvider.
+// Managers that only return the last lines of text are filtered here;
+// they cannot honour since and until.
+func ReadServiceLogs(ctx context.Context, manager types.ServiceManager, serviceName string, query LogQuery) ([]types.LogEntry, error) {
+	if provider, ok := manager.(LogProvider); ok {
+		return provider.ServiceLogs(ctx, serviceName, query)
+	}
+	text, ok := manager.(logTextProvider)
+	if !ok {
+		return nil, &UnsupportedActionError{Action: "logs"}
+	}
+	if !query.Since.IsZero() || !query.Until.IsZero() {
+		return nil, fmt.Errorf("since and until are not supported by this service manager")
+	}
+
+	output, err := text.GetLogs(ctx, serviceName, query.Lines)
+	if err != nil {
+		return nil, err
+	}
+	var entries []types.LogEntry
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if line != "" {
+			entries = append(entries, types.LogEntry{Priority: LogPriorityInfo, Message: line, Source: serviceName})
+		}
+	}
+	return filterLogEntries(entries, query), nil
+}
+
+// FollowServiceLogs follows a service's log through the manager's
+// LogFollower. It returns nil once ctx is done, so a subscription that is
+// cancelled or whose client went away ends without an error.
+func FollowServiceLogs(ctx context.Context, manager types.ServiceManager, serviceName string, query LogQuery, emit func(types.LogEntry)) error {
+	if err := CheckFollowLogs(manager, query); err != nil {
+		return err
+	}
+	err := manager.(LogFollower).FollowLogs(ctx, serviceName, query, emit)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// CheckFollowLogs reports why FollowServiceLogs would fail at once, so a
+// subscription can be refused before it is started.
+func CheckFollowLogs(manager types.ServiceManager, query LogQuery) error {
+	if _, ok := manager.(LogFollower); !ok {
+		return &UnsupportedActionError{Action: "follow"}
+	}
+	if !query.Until.IsZero() {
+		return fmt.Errorf("until cannot be used when following logs")
+	}
+	return nil
+}
+
+// logLineLayouts are the timestamp prefixes recognised in plain log files,
+// with the length of the prefix each one parses.
+var logLineLayouts = []struct {
+	layout string
+	length int
+}{
+	{"2006-01-02T15:04:05Z07:00", 0},
+	{"2006-01-02 15:04:05", 19},
+	{"Jan _2 15:04:05", 15},
+}
+
+// parseLogLineTime returns the time a log line starts with. Syslog times
+// carry no year; the most recent matching year is assumed.
+func parseLogLineTime(line string, now time.Time) (time.Time, bool) {
+	for _, format := range logLineLayouts {
+		prefix := line
+		if format.length == 0 {
+			prefix, _, _ = strings.Cut(line, " ")
+		} else if len(line) >= format.length {
+			prefix = line[:format.length]
+		} else {
+			continue
+		}
+		t, err := time.ParseInLocation(format.layout, prefix, time.Local)
+		if err != nil {
+			continue
+		}
+		if t.Year() == 0 {
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// readLogFiles reads plain-text log files. Lines without a timestamp, such
+// as the continuation of a stack trace, take the time of the line before.
+// Entries of several files are merged by time.
+func readLogFiles(ctx context.Context, paths []string, query LogQuery) ([]types.LogEntry, error) {
+	now := time.Now()
+	var entries []types.LogEntry
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %v", err)
+		}
+
+		var last time.Time
+		tail := logTail{query: query}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if err := ctx.Err(); err != nil {
+				file.Close()
+				return nil, err
+			}
+			line := scanner.Text()
+			if line == "" {
+				continue
+			}
+			entry := types.LogEntry{Priority: LogPriorityInfo, Message: line, Source: path}
+			if t, ok := parseLogLineTime(line, now); ok {
+				last = t
+			}
+			entry.Time = last
+			tail.add(entry)
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		entries = append(entries, tail.entries...)
+	}
+
+	if len(paths) > 1 {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	}
+	return filterLogEntries(entries, query), nil
+}
@@ -0,0 +1,361 @@
+package managers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// fakeJournalctlScript 按 journalctl --reverse 的顺序输出 JSON 日志
+const fakeJournalctlScript = `#!/bin/sh
+echo "$*" >> "$0.calls"
+echo '{"__REALTIME_TIMESTAMP":"1704067203000000","PRIORITY":"3","MESSAGE":"bind failed","SYSLOG_IDENTIFIER":"nginx","_PID":"42"}'
+echo '{"__REALTIME_TIMESTAMP":"1704067202000000","PRIORITY":"6","MESSAGE":[104,105],"_SYSTEMD_UNIT":"nginx.service"}'
+echo 'not json'
+echo '{"__REALTIME_TIMESTAMP":"1704067201000000","PRIORITY":"6","MESSAGE":"started","SYSLOG_IDENTIFIER":"nginx","_PID":"42"}'
+`
+
+func newTestJournalManager(t *testing.T) (*SystemdScopeManager, string) {
+	t.Helper()
+	manager, _ := newTestSystemdScopeManager(t, config.SystemdConfig{})
+	manager.journalctl = filepath.Join(t.TempDir(), "journalctl")
+	if err := os.WriteFile(manager.journalctl, []byte(fakeJournalctlScript), 0755); err != nil {
+		t.Fatalf("Failed to write fake journalctl: %v", err)
+	}
+	return manager, manager.journalctl + ".calls"
+}
+
+func TestParseLogQuery(t *testing.T) {
+	query, err := ParseLogQuery("", "", "", "", 0)
+	if err != nil {
+		t.Fatalf("ParseLogQuery failed: %v", err)
+	}
+	if query.Lines != DefaultLogLines || query.Priority != LogPriorityDebug || query.Grep != nil {
+		t.Errorf("Unexpected default query: %+v", query)
+	}
+
+	query, err = ParseLogQuery("1h", "2099-01-01T10:00:00Z", "warning", "fail(ed)?", MaxLogLines+1)
+	if err != nil {
+		t.Fatalf("ParseLogQuery failed: %v", err)
+	}
+	if query.Priority != 4 || query.Lines != MaxLogLines || time.Since(query.Since) < 59*time.Minute {
+		t.Errorf("Unexpected query: %+v", query)
+	}
+	if query, _ := ParseLogQuery("", "", "3", "", 0); query.Priority != 3 {
+		t.Errorf("Expected numeric priority 3, got %d", query.Priority)
+	}
+
+	invalid := [][4]string{
+		{"yesterday", "", "", ""},
+		{"", "", "loud", ""},
+		{"", "", "8", ""},
+		{"", "", "", "("},
+		{"2024-01-02", "2024-01-01", "", ""},
+	}
+	for _, args := range invalid {
+		if _, err := ParseLogQuery(args[0], args[1], args[2], args[3], 0); err == nil {
+			t.Errorf("Expected ParseLogQuery(%q) to fail", args)
+		}
+	}
+}
+
+func TestLogQuery_Match(t *testing.T) {
+	query, _ := ParseLogQuery("2024-01-01T00:00:00Z", "", "err", "fail", 10)
+
+	testCases := []struct {
+		entry    types.LogEntry
+		expected bool
+	}{
+		{types.LogEntry{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Priority: 3, Message: "bind failed"}, true},
+		{types.LogEntry{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Priority: 6, Message: "bind failed"}, false},
+		{types.LogEntry{Time: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), Priority: 3, Message: "bind failed"}, false},
+		{types.LogEntry{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Priority: 3, Message: "ok"}, false},
+		// 没有时间的条目不满足时间条件
+		{types.LogEntry{Priority: 3, Message: "bind failed"}, false},
+	}
+	for i, tc := range testCases {
+		if got := query.Match(tc.entry); got != tc.expected {
+			t.Errorf("Case %d: expected %t, got %t", i, tc.expected, got)
+		}
+	}
+}
+
+func TestLogEntry_String(t *testing.T) {
+	entry := types.LogEntry{
+		Time:     time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		Priority: 3,
+		Message:  "bind failed",
+		Source:   "nginx",
+		PID:      42,
+	}
+	if got := entry.String(); got != "2024-01-02T15:04:05Z [err] nginx[42]: bind failed" {
+		t.Errorf("Unexpected entry line: %s", got)
+	}
+}
+
+func TestParseLogLineTime(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	testCases := map[string]time.Time{
+		"2024-01-02T15:04:05Z GET /":           time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		"2024-01-02 15:04:05,123 INFO started": time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local),
+		"Feb  3 04:05:06 host app[1]: hello":   time.Date(2024, 2, 3, 4, 5, 6, 0, time.Local),
+		// 未来的日期属于上一年
+		"Dec 24 10:00:00 host app[1]: hello": time.Date(2023, 12, 24, 10, 0, 0, 0, time.Local),
+	}
+	for line, expected := range testCases {
+		got, ok := parseLogLineTime(line, now)
+		if !ok || !got.Equal(expected) {
+			t.Errorf("parseLogLineTime(%q) = %v, %t; expected %v", line, got, ok, expected)
+		}
+	}
+	if _, ok := parseLogLineTime("    at Main.run()", now); ok {
+		t.Error("Expected a continuation line to have no time")
+	}
+}
+
+func TestSysVManager_ServiceLogs(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewSysVManager()
+	manager.logDir = dir
+
+	errorLog := filepath.Join(dir, "app", "error.log")
+	os.MkdirAll(filepath.Dir(errorLog), 0755)
+	os.WriteFile(errorLog, []byte("2024-01-01 10:00:02 ERROR boom\n    at main()\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "app.log"), []byte("2024-01-01 10:00:01 started\n2024-01-01 10:00:03 stopped\n"), 0644)
+
+	query, _ := ParseLogQuery("", "", "", "", 0)
+	entries, err := manager.ServiceLogs(context.Background(), "app", query)
+	if err != nil {
+		t.Fatalf("ServiceLogs failed: %v", err)
+	}
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+	expected := "2024-01-01 10:00:01 started|2024-01-01 10:00:02 ERROR boom|    at main()|2024-01-01 10:00:03 stopped"
+	if strings.Join(messages, "|") != expected {
+		t.Errorf("Unexpected entries: %q", messages)
+	}
+
+	// 续行继承上一行的时间，因此也在时间范围内
+	query, _ = ParseLogQuery("2024-01-01 10:00:02", "2024-01-01 10:00:02", "", "", 1)
+	entries, err = manager.ServiceLogs(context.Background(), "app", query)
+	if err != nil || len(entries) != 1 || entries[0].Message != "    at main()" || entries[0].Source != errorLog {
+		t.Errorf("Unexpected filtered entries: %+v, %v", entries, err)
+	}
+
+	manager.logFiles = map[string][]string{"app": {filepath.Join(dir, "missing.log")}}
+	if _, err := manager.ServiceLogs(context.Background(), "app", query); err == nil {
+		t.Error("Expected a missing configured log file to fail")
+	}
+	if _, err := manager.ServiceLogs(context.Background(), "other", query); err == nil {
+		t.Error("Expected a service without log files to fail")
+	}
+}
+
+func TestSystemdScopeManager_ServiceLogs(t *testing.T) {
+	manager, calls := newTestJournalManager(t)
+	ctx := context.Background()
+
+	query, _ := ParseLogQuery("", "", "", "", 0)
+	entries, err := manager.ServiceLogs(ctx, "nginx", query)
+	if err != nil {
+		t.Fatalf("ServiceLogs failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", entries)
+	}
+	first, second := entries[0], entries[1]
+	if first.Message != "started" || first.PID != 42 || first.Source != "nginx" || !first.Time.Equal(time.Unix(1704067201, 0)) {
+		t.Errorf("Unexpected first entry: %+v", first)
+	}
+	// 非 UTF-8 消息以字节数组形式给出
+	if second.Message != "hi" || second.Source != "nginx.service" || entries[2].Priority != 3 {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+
+	query, _ = ParseLogQuery("", "", "", "fail|start", 1)
+	entries, err = manager.ServiceLogs(ctx, "nginx", query)
+	if err != nil || len(entries) != 1 || entries[0].Message != "bind failed" {
+		t.Errorf("Unexpected grep result: %+v, %v", entries, err)
+	}
+
+	query, _ = ParseLogQuery("2024-01-01T00:00:00Z", "", "err", "", 5)
+	if _, err := manager.ServiceLogs(WithScope(ctx, "user"), "app", query); err != nil {
+		t.Fatalf("ServiceLogs failed: %v", err)
+	}
+
+	expected := []string{
+		"--output=json --no-pager --unit=nginx.service --reverse --lines=100",
+		"--output=json --no-pager --unit=nginx.service --reverse",
+		fmt.Sprintf("--output=json --no-pager --user-unit=app.service _UID=%d --priority=3 --since=@1704067200 --reverse --lines=5", os.Getuid()),
+	}
+	got := readCalls(t, calls)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected calls %q, got %q", expected, got)
+	}
+}
+
+func TestDockerAPIManager_ServiceLogs(t *testing.T) {
+	manager, engine := newTestDockerAPIManager(t)
+
+	query, _ := ParseLogQuery("", "", "", "", 10)
+	entries, err := manager.ServiceLogs(context.Background(), "web", query)
+	if err != nil {
+		t.Fatalf("ServiceLogs failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "GET / 200" || entries[0].Priority != LogPriorityInfo || entries[0].Source != "stdout" {
+		t.Fatalf("Unexpected entries: %+v", entries)
+	}
+	if entries[1].Priority != LogPriorityErr || !entries[1].Time.Equal(time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)) {
+		t.Errorf("Unexpected stderr entry: %+v", entries[1])
+	}
+	if engine.logQuery.Get("tail") != "10" {
+		t.Errorf("Expected the engine to apply the line limit, got %v", engine.logQuery)
+	}
+
+	query, _ = ParseLogQuery("", "", "err", "", 10)
+	entries, err = manager.ServiceLogs(context.Background(), "web", query)
+	if err != nil || len(entries) != 1 || entries[0].Message != "warn: slow" {
+		t.Errorf("Unexpected priority filter result: %+v, %v", entries, err)
+	}
+	if engine.logQuery.Get("tail") != "all" {
+		t.Errorf("Expected all lines to be read when filtering, got %v", engine.logQuery)
+	}
+}
+
+// textLogManager 只提供旧的文本日志接口
+type textLogManager struct {
+	*MockManager
+}
+
+func (m textLogManager) GetLogs(ctx context.Context, serviceName string, lines int) (string, error) {
+	return "starting\nerror: disk full\nready\n", nil
+}
+
+func TestReadServiceLogs(t *testing.T) {
+	ctx := context.Background()
+	query, _ := ParseLogQuery("", "", "", "error", 0)
+
+	entries, err := ReadServiceLogs(ctx, textLogManager{NewMockManager(types.ServiceTypeNative)}, "app", query)
+	if err != nil || len(entries) != 1 || entries[0].Message != "error: disk full" {
+		t.Errorf("Unexpected entries: %+v, %v", entries, err)
+	}
+
+	query, _ = ParseLogQuery("1h", "", "", "", 0)
+	if _, err := ReadServiceLogs(ctx, textLogManager{NewMockManager(types.ServiceTypeNative)}, "app", query); err == nil {
+		t.Error("Expected since to be rejected for text logs")
+	}
+	if _, err := ReadServiceLogs(ctx, NewMockManager(types.ServiceTypeSystemd), "app", query); !IsUnsupportedAction(err) {
+		t.Errorf("Expected an unsupported action error, got %v", err)
+	}
+}
+
+func TestSystemdScopeManager_FollowLogs(t *testing.T) {
+	manager, calls := newTestJournalManager(t)
+
+	query, _ := ParseLogQuery("", "", "", "", 10)
+	var messages []string
+	err := FollowServiceLogs(context.Background(), manager, "nginx", query, func(entry types.LogEntry) {
+		messages = append(messages, entry.Message)
+	})
+	if err != nil {
+		t.Fatalf("FollowServiceLogs failed: %v", err)
+	}
+	if strings.Join(messages, "|") != "bind failed|hi|started" {
+		t.Errorf("Unexpected entries: %q", messages)
+	}
+	if got := readCalls(t, calls); len(got) != 1 || got[0] != "--output=json --no-pager --unit=nginx.service --follow --lines=10" {
+		t.Errorf("Unexpected calls: %q", got)
+	}
+
+	// journalctl 持续运行时，取消 ctx 即结束跟踪且不返回错误
+	script := "#!/bin/sh\necho '{\"MESSAGE\":\"ready\"}'\nexec sleep 30\n"
+	if err := os.WriteFile(manager.journalctl, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake journalctl: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	err = FollowServiceLogs(ctx, manager, "nginx", query, func(entry types.LogEntry) { cancel() })
+	if err != nil || time.Since(start) > 10*time.Second {
+		t.Errorf("Expected following to stop on cancel, got %v after %v", err, time.Since(start))
+	}
+
+	query, _ = ParseLogQuery("", "2099-01-01", "", "", 0)
+	if err := FollowServiceLogs(context.Background(), manager, "nginx", query, func(types.LogEntry) {}); err == nil {
+		t.Error("Expected until to be rejected when following")
+	}
+	if err := FollowServiceLogs(context.Background(), NewMockManager(types.ServiceTypeSystemd), "nginx", LogQuery{}, func(types.LogEntry) {}); !IsUnsupportedAction(err) {
+		t.Errorf("Expected an unsupported action error, got %v", err)
+	}
+}
+
+func TestDockerAPIManager_FollowLogs(t *testing.T) {
+	manager, engine := newTestDockerAPIManager(t)
+
+	query, _ := ParseLogQuery("", "", "err", "", 5)
+	var entries []types.LogEntry
+	if err := manager.FollowLogs(context.Background(), "web", query, func(entry types.LogEntry) {
+		entries = append(entries, entry)
+	}); err != nil {
+		t.Fatalf("FollowLogs failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Message != "warn: slow" || entries[0].Source != "stderr" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+	if engine.logQuery.Get("follow") != "1" || engine.logQuery.Get("tail") != "5" {
+		t.Errorf("Unexpected log query: %v", engine.logQuery)
+	}
+}
+
+func TestLogTail(t *testing.T) {
+	query, _ := ParseLogQuery("", "", "", "keep", 3)
+	tail := logTail{query: query}
+	for i := 0; i < 1000; i++ {
+		tail.add(types.LogEntry{Message: fmt.Sprintf("keep %d", i)})
+		tail.add(types.LogEntry{Message: "drop"})
+		// 读取过程中最多保留两倍行数
+		if len(tail.entries) >= 2*query.Lines {
+			t.Fatalf("Expected at most %d entries to be held, got %d", 2*query.Lines-1, len(tail.entries))
+		}
+	}
+
+	entries := filterLogEntries(tail.entries, query)
+	if len(entries) != 3 || entries[0].Message != "keep 997" || entries[2].Message != "keep 999" {
+		t.Errorf("Expected the last 3 matches, got %+v", entries)
+	}
+}
+
+func TestReadDockerLogStream(t *testing.T) {
+	collect := func(data []byte) []string {
+		var lines []string
+		err := readDockerLogStream(strings.NewReader(string(data)), func(entry types.LogEntry) {
+			lines = append(lines, entry.Source+":"+entry.Message)
+		})
+		if err != nil {
+			t.Fatalf("readDockerLogStream failed: %v", err)
+		}
+		return lines
+	}
+
+	// 一行可能被拆到多个帧中
+	var data []byte
+	data = append(data, dockerFrame(1, "par")...)
+	data = append(data, dockerFrame(2, "oops\n")...)
+	data = append(data, dockerFrame(1, "tial\nnext\nlast")...)
+	if got := strings.Join(collect(data), "|"); got != "stderr:oops|stdout:partial|stdout:next|stdout:last" {
+		t.Errorf("Unexpected multiplexed lines: %s", got)
+	}
+
+	// 带 TTY 的容器只有一个原始流
+	if got := strings.Join(collect([]byte("hello\nworld\n")), "|"); got != "stdout:hello|stdout:world" {
+		t.Errorf("Unexpected raw lines: %s", got)
+	}
+}
@@ -1,7 +1,9 @@
 package managers
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"nucc.com/mcp_srv_mgr/pkg/types"
@@ -10,6 +12,7 @@ import (
 // MockManager 用于测试的模拟管理器
 type MockManager struct {
 	serviceType types.ServiceType
+	mu          sync.RWMutex
 	services    map[string]types.ServiceInfo
 }
 
@@ -55,7 +58,13 @@ func NewMockManager(serviceType types.ServiceType) *MockManager {
 	return manager
 }
 
-func (m *MockManager) Start(serviceName string) error {
+func (m *MockManager) Start(ctx context.Context, serviceName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if service, exists := m.services[serviceName]; exists {
 		service.Status = types.StatusActive
 		service.LastChanged = time.Now()
@@ -66,7 +75,13 @@ func (m *MockManager) Start(serviceName string) error {
 	return fmt.Errorf("service %s not found", serviceName)
 }
 
-func (m *MockManager) Stop(serviceName string) error {
+func (m *MockManager) Stop(ctx context.Context, serviceName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if service, exists := m.services[serviceName]; exists {
 		service.Status = types.StatusInactive
 		service.LastChanged = time.Now()
@@ -78,15 +93,26 @@ func (m *MockManager) Stop(serviceName string) error {
 	return fmt.Errorf("service %s not found", serviceName)
 }
 
-func (m *MockManager) Restart(serviceName string) error {
-	if err := m.Stop(serviceName); err != nil {
+func (m *MockManager) Restart(ctx context.Context, serviceName string) error {
+	if err := m.Stop(ctx, serviceName); err != nil {
 		return err
 	}
-	time.Sleep(100 * time.Millisecond) // 模拟重启延迟
-	return m.Start(serviceName)
+	// 模拟重启延迟
+	select {
+	case <-time.After(100 * time.Millisecond):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return m.Start(ctx, serviceName)
 }
 
-func (m *MockManager) Enable(serviceName string) error {
+func (m *MockManager) Enable(ctx context.Context, serviceName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	if _, exists := m.services[serviceName]; exists {
 		// Mock 启用操作
 		return nil
@@ -94,7 +120,13 @@ func (m *MockManager) Enable(serviceName string) error {
 	return fmt.Errorf("service %s not found", serviceName)
 }
 
-func (m *MockManager) Disable(serviceName string) error {
+func (m *MockManager) Disable(ctx context.Context, serviceName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	if _, exists := m.services[serviceName]; exists {
 		// Mock 禁用操作
 		return nil
@@ -102,7 +134,13 @@ func (m *MockManager) Disable(serviceName string) error {
 	return fmt.Errorf("service %s not found", serviceName)
 }
 
-func (m *MockManager) GetStatus(serviceName string) (types.ServiceInfo, error) {
+func (m *MockManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return types.ServiceInfo{}, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	if service, exists := m.services[serviceName]; exists {
 		// 更新运行时间
 		if service.Status == types.StatusActive && service.PID > 0 {
@@ -113,7 +151,13 @@ func (m *MockManager) GetStatus(serviceName string) (types.ServiceInfo, error) {
 	return types.ServiceInfo{}, fmt.Errorf("service %s not found", serviceName)
 }
 
-func (m *MockManager) ListServices() ([]types.ServiceInfo, error) {
+func (m *MockManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	var services []types.ServiceInfo
 	for _, service := range m.services {
 		// 更新运行时间
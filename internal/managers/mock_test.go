@@ -0,0 +1,19 @@
+package managers
+
+import (
+	"context"
+	"testing"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+func TestMockManager_RestartCancelled(t *testing.T) {
+	manager := NewMockManager(types.ServiceTypeSystemd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := manager.Restart(ctx, "test-service-1"); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,533 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const (
+	defaultNativeRestart        = "on-failure"
+	defaultNativeBackoffInitial = time.Second
+	defaultNativeBackoffMax     = time.Minute
+	defaultNativeStopTimeout    = 10 * time.Second
+	defaultNativeLogDir         = "/var/log/mcp_srv_mgr"
+	defaultNativeLogMaxSize     = 10 << 20
+	defaultNativeLogMaxFiles    = 5
+)
+
+// States of a native process.
+const (
+	nativeStateStopped  = "stopped"
+	nativeStateRunning  = "running"
+	nativeStateBackoff  = "backoff"
+	nativeStateStopping = "stopping"
+	nativeStateExited   = "exited"
+	nativeStateFatal    = "fatal"
+)
+
+// NativeManager spawns and supervises the processes declared under native:
+// in config.yaml, restarting them with exponential backoff according to
+// their restart policy.
+type NativeManager struct {
+	logger    *logrus.Logger
+	processes map[string]*nativeProcess
+	order     []string
+}
+
+type nativeProcess struct {
+	spec   config.NativeService
+	logger *logrus.Logger
+
+	mu        sync.Mutex
+	state     string
+	pid       int
+	startedAt time.Time
+	changedAt time.Time
+	// exitCode is the status of the last exit, nil until the process has
+	// exited once.
+	exitCode  *int
+	lastError string
+	restarts  int
+	nextStart time.Time
+	// stop and done are set while a supervision loop runs: closing stop
+	// asks the loop to terminate the process, done is closed once it has.
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewNativeManager registers the declared processes and starts those with
+// autostart set. Failures to autostart are logged, not returned.
+func NewNativeManager(services []config.NativeService, logger *logrus.Logger) *NativeManager {
+	nm := &NativeManager{
+		logger:    logger,
+		processes: make(map[string]*nativeProcess),
+	}
+	for _, spec := range services {
+		if _, exists := nm.processes[spec.Name]; !exists {
+			nm.order = append(nm.order, spec.Name)
+		}
+		nm.processes[spec.Name] = &nativeProcess{
+			spec:   withNativeDefaults(spec),
+			logger: logger,
+			state:  nativeStateStopped,
+		}
+	}
+
+	for _, name := range nm.order {
+		if nm.processes[name].spec.Autostart {
+			if err := nm.processes[name].start(); err != nil {
+				logger.Errorf("Failed to autostart native service %s: %v", name, err)
+			}
+		}
+	}
+	return nm
+}
+
+func withNativeDefaults(spec config.NativeService) config.NativeService {
+	if spec.Restart == "" {
+		spec.Restart = defaultNativeRestart
+	}
+	if spec.Backoff.Initial <= 0 {
+		spec.Backoff.Initial = defaultNativeBackoffInitial
+	}
+	if spec.Backoff.Max < spec.Backoff.Initial {
+		spec.Backoff.Max = defaultNativeBackoffMax
+		if spec.Backoff.Max < spec.Backoff.Initial {
+			spec.Backoff.Max = spec.Backoff.Initial
+		}
+	}
+	if spec.StopTimeout <= 0 {
+		spec.StopTimeout = defaultNativeStopTimeout
+	}
+	if spec.Logs.Dir == "" {
+		spec.Logs.Dir = defaultNativeLogDir
+	}
+	if spec.Logs.MaxSize <= 0 {
+		spec.Logs.MaxSize = defaultNativeLogMaxSize
+	}
+	if spec.Logs.MaxFiles <= 0 {
+		spec.Logs.MaxFiles = defaultNativeLogMaxFiles
+	}
+	return spec
+}
+
+func (nm *NativeManager) Start(ctx context.Context, serviceName string) error {
+	process, err := nm.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+	return process.start()
+}
+
+func (nm *NativeManager) Stop(ctx context.Context, serviceName string) error {
+	process, err := nm.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+	return process.halt(ctx)
+}
+
+func (nm *NativeManager) Restart(ctx context.Context, serviceName string) error {
+	process, err := nm.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+	if err := process.halt(ctx); err != nil {
+		return err
+	}
+	return process.start()
+}
+
+// Enable is not supported: whether a native service starts with the server
+// is its autostart option in config.yaml.
+func (nm *NativeManager) Enable(ctx context.Context, serviceName string) error {
+	if _, err := nm.lookup(serviceName); err != nil {
+		return err
+	}
+	return fmt.Errorf("enable is not supported for native service %s: set autostart in config.yaml", serviceName)
+}
+
+// Disable is not supported, see Enable.
+func (nm *NativeManager) Disable(ctx context.Context, serviceName string) error {
+	if _, err := nm.lookup(serviceName); err != nil {
+		return err
+	}
+	return fmt.Errorf("disable is not supported for native service %s: set autostart in config.yaml", serviceName)
+}
+
+func (nm *NativeManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	process, err := nm.lookup(serviceName)
+	if err != nil {
+		return types.ServiceInfo{Name: serviceName, Type: types.ServiceTypeNative}, err
+	}
+	return process.info(), nil
+}
+
+func (nm *NativeManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	services := make([]types.ServiceInfo, 0, len(nm.order))
+	for _, name := range nm.order {
+		services = append(services, nm.processes[name].info())
+	}
+	return services, nil
+}
+
+// GetLogs returns the last lines of the service's stdout log.
+func (nm *NativeManager) GetLogs(ctx context.Context, serviceName string, lines int) (string, error) {
+	return nm.TailLog(ctx, serviceName, "stdout", lines)
+}
+
+// TailLog returns the last lines of the service's stdout or stderr log.
+func (nm *NativeManager) TailLog(ctx context.Context, serviceName, stream string, lines int) (string, error) {
+	process, err := nm.lookup(serviceName)
+	if err != nil {
+		return "", err
+	}
+	switch stream {
+	case "":
+		stream = "stdout"
+	case "stdout", "stderr":
+	default:
+		return "", fmt.Errorf("unknown log stream %q, expected stdout or stderr", stream)
+	}
+	if lines <= 0 {
+		lines = 100
+	}
+	return tailFile(process.logPath(stream), lines)
+}
+
+// Close stops every supervised process. It is called when the server shuts
+// down so that no child outlives it.
+func (nm *NativeManager) Close() error {
+	var errs []error
+	for _, name := range nm.order {
+		if err := nm.processes[name].halt(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (nm *NativeManager) lookup(serviceName string) (*nativeProcess, error) {
+	process, exists := nm.processes[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("service %s not found", serviceName)
+	}
+	return process, nil
+}
+
+func (p *nativeProcess) logPath(stream string) string {
+	return filepath.Join(p.spec.Logs.Dir, fmt.Sprintf("%s.%s.log", p.spec.Name, stream))
+}
+
+// start spawns the process and a loop supervising it. The first spawn is
+// synchronous so that configuration errors are returned to the caller.
+func (p *nativeProcess) start() error {
+	p.mu.Lock()
+	if p.stop != nil && p.state == nativeStateRunning {
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+
+	// A loop waiting out its backoff is replaced by a fresh start.
+	if err := p.halt(context.Background()); err != nil {
+		return err
+	}
+
+	stdout, err := newRotatingWriter(p.logPath("stdout"), p.spec.Logs.MaxSize, p.spec.Logs.MaxFiles)
+	if err != nil {
+		return err
+	}
+	stderr, err := newRotatingWriter(p.logPath("stderr"), p.spec.Logs.MaxSize, p.spec.Logs.MaxFiles)
+	if err != nil {
+		stdout.Close()
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stop != nil {
+		// A concurrent start won the race.
+		stdout.Close()
+		stderr.Close()
+		return nil
+	}
+
+	cmd, err := p.spawn(stdout, stderr)
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		p.setState(nativeStateFatal)
+		p.lastError = err.Error()
+		return fmt.Errorf("failed to start %s: %v", p.spec.Name, err)
+	}
+
+	p.restarts = 0
+	p.lastError = ""
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.supervise(cmd, stdout, stderr, p.stop, p.done)
+	return nil
+}
+
+// spawn starts the command once. The caller holds p.mu.
+func (p *nativeProcess) spawn(stdout, stderr *rotatingWriter) (*exec.Cmd, error) {
+	cmd := shellCommand(context.Background(), p.spec.Command)
+	cmd.Dir = p.spec.WorkDir
+	cmd.Env = commandEnv(p.spec.Env)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if p.spec.User != "" {
+		if err := setCommandUser(cmd, p.spec.User); err != nil {
+			return nil, fmt.Errorf("user %s: %v", p.spec.User, err)
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p.pid = cmd.Process.Pid
+	p.startedAt = time.Now()
+	p.setState(nativeStateRunning)
+	return cmd, nil
+}
+
+// supervise waits for the process to exit and restarts it according to the
+// restart policy until stop is closed or it gives up.
+func (p *nativeProcess) supervise(cmd *exec.Cmd, stdout, stderr *rotatingWriter, stop, done chan struct{}) {
+	defer close(done)
+	defer stdout.Close()
+	defer stderr.Close()
+
+	delay := p.spec.Backoff.Initial
+	for {
+		code := -1
+		if cmd != nil {
+			exited := make(chan error, 1)
+			go func() { exited <- cmd.Wait() }()
+
+			select {
+			case err := <-exited:
+				code = exitCode(err)
+			case <-stop:
+				p.terminate(cmd, exited)
+				p.mu.Lock()
+				p.pid = 0
+				p.setState(nativeStateStopped)
+				p.mu.Unlock()
+				return
+			}
+		}
+
+		p.mu.Lock()
+		ranFor := time.Since(p.startedAt)
+		p.pid = 0
+		p.exitCode = &code
+		if code != 0 {
+			p.lastError = fmt.Sprintf("exit status %d", code)
+		}
+
+		if !p.shouldRestart(code) {
+			p.setState(nativeStateExited)
+			p.release(stop)
+			p.mu.Unlock()
+			return
+		}
+		// A process that stayed up for a full backoff period is healthy
+		// again: the next failure starts from the initial delay.
+		if cmd != nil && ranFor >= p.spec.Backoff.Max {
+			p.restarts = 0
+			delay = p.spec.Backoff.Initial
+		}
+		if p.spec.MaxRestarts > 0 && p.restarts >= p.spec.MaxRestarts {
+			p.lastError = fmt.Sprintf("gave up after %d restarts, last %s", p.restarts, p.lastError)
+			p.setState(nativeStateFatal)
+			p.release(stop)
+			p.mu.Unlock()
+			p.logger.Errorf("Native service %s: %s", p.spec.Name, p.lastError)
+			return
+		}
+		p.restarts++
+		p.nextStart = time.Now().Add(delay)
+		p.setState(nativeStateBackoff)
+		p.mu.Unlock()
+
+		p.logger.Warnf("Native service %s exited with status %d, restarting in %s", p.spec.Name, code, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+			p.mu.Lock()
+			p.setState(nativeStateStopped)
+			p.mu.Unlock()
+			return
+		}
+
+		delay *= 2
+		if delay > p.spec.Backoff.Max {
+			delay = p.spec.Backoff.Max
+		}
+
+		p.mu.Lock()
+		var err error
+		cmd, err = p.spawn(stdout, stderr)
+		if err != nil {
+			cmd = nil
+			p.lastError = err.Error()
+		}
+		p.mu.Unlock()
+	}
+}
+
+// terminate sends SIGTERM to the process group and SIGKILL once the stop
+// timeout has passed.
+func (p *nativeProcess) terminate(cmd *exec.Cmd, exited <-chan error) {
+	p.mu.Lock()
+	p.setState(nativeStateStopping)
+	p.mu.Unlock()
+
+	terminateProcessGroup(cmd.Process.Pid, false)
+	timer := time.NewTimer(p.spec.StopTimeout)
+	defer timer.Stop()
+	select {
+	case <-exited:
+	case <-timer.C:
+		p.logger.Warnf("Native service %s did not stop within %s, killing it", p.spec.Name, p.spec.StopTimeout)
+		terminateProcessGroup(cmd.Process.Pid, true)
+		<-exited
+	}
+}
+
+// halt stops the supervision loop, if any, and waits for the process to
+// exit or ctx to end.
+func (p *nativeProcess) halt(ctx context.Context) error {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release forgets the loop that owns stop after it ended on its own. The
+// caller holds p.mu.
+func (p *nativeProcess) release(stop chan struct{}) {
+	if p.stop == stop {
+		p.stop, p.done = nil, nil
+	}
+}
+
+func (p *nativeProcess) shouldRestart(code int) bool {
+	switch p.spec.Restart {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return code != 0
+	}
+}
+
+// setState records a state change. The caller holds p.mu.
+func (p *nativeProcess) setState(state string) {
+	p.state = state
+	p.changedAt = time.Now()
+}
+
+func (p *nativeProcess) info() types.ServiceInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info := types.ServiceInfo{
+		Name:        p.spec.Name,
+		Type:        types.ServiceTypeNative,
+		LastChanged: p.changedAt,
+	}
+
+	var detail string
+	switch p.state {
+	case nativeStateRunning:
+		info.Status = types.StatusActive
+		info.PID = p.pid
+		info.Uptime = time.Since(p.startedAt)
+		info.LastChanged = p.startedAt
+	case nativeStateBackoff:
+		info.Status = types.StatusFailed
+		info.ExitCode = p.lastExitCode()
+		detail = fmt.Sprintf("%s, restart %d in %s", p.lastError, p.restarts, time.Until(p.nextStart).Round(time.Second))
+	case nativeStateFatal:
+		info.Status = types.StatusFailed
+		info.ExitCode = p.lastExitCode()
+		detail = p.lastError
+	case nativeStateExited:
+		info.ExitCode = p.lastExitCode()
+		if *p.exitCode == 0 {
+			info.Status = types.StatusInactive
+			detail = "exited"
+		} else {
+			info.Status = types.StatusFailed
+			detail = p.lastError
+		}
+	default:
+		info.Status = types.StatusInactive
+	}
+
+	info.Restarts = p.restarts
+	info.Description = p.spec.Description
+	if detail != "" {
+		if info.Description != "" {
+			info.Description = fmt.Sprintf("%s (%s)", info.Description, detail)
+		} else {
+			info.Description = detail
+		}
+	}
+	return info
+}
+
+// lastExitCode returns a copy of the last exit status. The caller holds p.mu.
+func (p *nativeProcess) lastExitCode() *int {
+	if p.exitCode == nil {
+		return nil
+	}
+	code := *p.exitCode
+	return &code
+}
+
+// exitCode returns the exit status carried by a Wait error, 128+signal when
+// the process was killed by a signal, or -1 when it could not be waited for.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,269 @@
+package managers
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// newTestNativeManager 使用临时日志目录和很短的退避时间
+func newTestNativeManager(t *testing.T, services ...config.NativeService) (*NativeManager, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("native service tests use /bin/sh")
+	}
+
+	dir := t.TempDir()
+	for i := range services {
+		services[i].WorkDir = dir
+		services[i].Logs.Dir = filepath.Join(dir, "logs")
+		if services[i].Backoff.Initial == 0 {
+			services[i].Backoff = config.BackoffConfig{Initial: 20 * time.Millisecond, Max: 40 * time.Millisecond}
+		}
+		if services[i].StopTimeout == 0 {
+			services[i].StopTimeout = 2 * time.Second
+		}
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	manager := NewNativeManager(services, logger)
+	t.Cleanup(func() { manager.Close() })
+	return manager, dir
+}
+
+// waitForStatus 轮询直到服务进入期望状态
+func waitForStatus(t *testing.T, manager *NativeManager, name string, want types.ServiceStatus) types.ServiceInfo {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := manager.GetStatus(context.Background(), name)
+		if err != nil {
+			t.Fatalf("GetStatus failed: %v", err)
+		}
+		if info.Status == want {
+			return info
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s to be %s, last %+v", name, want, info)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNativeManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*NativeManager)(nil)
+	var _ io.Closer = (*NativeManager)(nil)
+}
+
+func TestNativeManager_Lifecycle(t *testing.T) {
+	manager, _ := newTestNativeManager(t, config.NativeService{
+		Name:        "api",
+		Description: "Test API",
+		Command:     `echo "hello $GREETING"; echo oops >&2; exec sleep 30`,
+		Env:         map[string]string{"GREETING": "world"},
+	})
+	ctx := context.Background()
+
+	info := waitForStatus(t, manager, "api", types.StatusInactive)
+	if info.Description != "Test API" {
+		t.Errorf("Unexpected description: %s", info.Description)
+	}
+
+	if err := manager.Start(ctx, "api"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	// 已在运行时再次启动不报错
+	if err := manager.Start(ctx, "api"); err != nil {
+		t.Fatalf("Second start failed: %v", err)
+	}
+	info = waitForStatus(t, manager, "api", types.StatusActive)
+	if info.PID == 0 || info.LastChanged.IsZero() {
+		t.Errorf("Expected pid and start time, got %+v", info)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		logs, _ := manager.GetLogs(ctx, "api", 10)
+		if logs == "hello world\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected stdout to be captured, got %q", logs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stderr, _ := manager.TailLog(ctx, "api", "stderr", 10); stderr != "oops\n" {
+		t.Errorf("Expected stderr to be captured separately, got %q", stderr)
+	}
+
+	if err := manager.Stop(ctx, "api"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	info = waitForStatus(t, manager, "api", types.StatusInactive)
+	if info.PID != 0 {
+		t.Errorf("Expected no pid after stop, got %d", info.PID)
+	}
+}
+
+func TestNativeManager_RestartBackoff(t *testing.T) {
+	manager, dir := newTestNativeManager(t, config.NativeService{
+		Name:        "crasher",
+		Command:     "echo run >> runs; exit 4",
+		MaxRestarts: 2,
+		Autostart:   true,
+	})
+
+	info := waitForStatus(t, manager, "crasher", types.StatusFailed)
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(info.Description, "gave up after 2 restarts") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected manager to give up, last %+v", info)
+		}
+		time.Sleep(10 * time.Millisecond)
+		info, _ = manager.GetStatus(context.Background(), "crasher")
+	}
+	if !strings.Contains(info.Description, "exit status 4") {
+		t.Errorf("Expected exit status in description, got %s", info.Description)
+	}
+	if info.ExitCode == nil || *info.ExitCode != 4 || info.Restarts != 2 {
+		t.Errorf("Expected exit code 4 after 2 restarts, got %v, %d", info.ExitCode, info.Restarts)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "runs"))
+	if err != nil {
+		t.Fatalf("Failed to read run count: %v", err)
+	}
+	if runs := strings.Count(string(data), "run"); runs != 3 {
+		t.Errorf("Expected initial run plus 2 restarts, got %d", runs)
+	}
+}
+
+func TestNativeManager_RestartPolicies(t *testing.T) {
+	manager, _ := newTestNativeManager(t,
+		config.NativeService{Name: "oneshot", Command: "exit 0", Autostart: true},
+		config.NativeService{Name: "never", Command: "exit 3", Restart: "never", Autostart: true},
+	)
+
+	// on-failure 策略下正常退出不重启
+	oneshot := waitForStatus(t, manager, "oneshot", types.StatusInactive)
+	if !strings.Contains(oneshot.Description, "exited") {
+		t.Errorf("Unexpected oneshot description: %s", oneshot.Description)
+	}
+	if oneshot.ExitCode == nil || *oneshot.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got %v", oneshot.ExitCode)
+	}
+
+	never := waitForStatus(t, manager, "never", types.StatusFailed)
+	if never.Description != "exit status 3" {
+		t.Errorf("Unexpected description: %s", never.Description)
+	}
+}
+
+func TestNativeManager_StopDuringBackoff(t *testing.T) {
+	manager, _ := newTestNativeManager(t, config.NativeService{
+		Name:      "flappy",
+		Command:   "exit 1",
+		Autostart: true,
+		Backoff:   config.BackoffConfig{Initial: time.Minute, Max: time.Minute},
+	})
+
+	info := waitForStatus(t, manager, "flappy", types.StatusFailed)
+	if !strings.Contains(info.Description, "restart 1 in") {
+		t.Errorf("Expected pending restart in description, got %s", info.Description)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := manager.Stop(ctx, "flappy"); err != nil {
+		t.Fatalf("Stop during backoff failed: %v", err)
+	}
+	waitForStatus(t, manager, "flappy", types.StatusInactive)
+}
+
+func TestNativeManager_StopTimeoutKills(t *testing.T) {
+	manager, _ := newTestNativeManager(t, config.NativeService{
+		Name:        "stubborn",
+		Command:     "trap '' TERM; sleep 30",
+		StopTimeout: 100 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	if err := manager.Start(ctx, "stubborn"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	// 等待 trap 生效
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	if err := manager.Stop(ctx, "stubborn"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected SIGKILL after the stop timeout, took %s", elapsed)
+	}
+}
+
+func TestNativeManager_Errors(t *testing.T) {
+	manager, _ := newTestNativeManager(t,
+		config.NativeService{Name: "api", Command: "sleep 30"},
+		config.NativeService{Name: "nobody", Command: "true", User: "no-such-user-mcp"},
+	)
+	ctx := context.Background()
+
+	if err := manager.Start(ctx, "ghost"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := manager.Start(ctx, "nobody"); err == nil || !strings.Contains(err.Error(), "no-such-user-mcp") {
+		t.Errorf("Expected unknown user error, got %v", err)
+	}
+	if err := manager.Enable(ctx, "api"); err == nil {
+		t.Error("Expected enable to be unsupported")
+	}
+	if _, err := manager.TailLog(ctx, "api", "stdin", 10); err == nil {
+		t.Error("Expected unknown stream to be rejected")
+	}
+}
+
+func TestRotatingWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	w, err := newRotatingWriter(path, 10, 2)
+	if err != nil {
+		t.Fatalf("newRotatingWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for file, want := range expected {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: expected %q, got %q", filepath.Base(file), want, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected at most 2 rotated files")
+	}
+}
@@ -0,0 +1,293 @@
+package managers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const defaultOpenRCRunlevel = "default"
+
+// OpenRCManager drives OpenRC (Alpine, Gentoo) through rc-service, rc-update
+// and rc-status instead of guessing from init script output.
+type OpenRCManager struct {
+	rcService string
+	rcUpdate  string
+	rcStatus  string
+	initDPath string
+	runDir    string
+	// runlevel is the runlevel Enable/Disable add services to or remove them from.
+	runlevel string
+}
+
+func NewOpenRCManager() *OpenRCManager {
+	return &OpenRCManager{
+		rcService: "rc-service",
+		rcUpdate:  "rc-update",
+		rcStatus:  "rc-status",
+		initDPath: "/etc/init.d",
+		runDir:    "/run",
+		runlevel:  defaultOpenRCRunlevel,
+	}
+}
+
+func (om *OpenRCManager) Start(ctx context.Context, serviceName string) error {
+	return om.serviceCommand(ctx, serviceName, "start")
+}
+
+func (om *OpenRCManager) Stop(ctx context.Context, serviceName string) error {
+	return om.serviceCommand(ctx, serviceName, "stop")
+}
+
+func (om *OpenRCManager) Restart(ctx context.Context, serviceName string) error {
+	return om.serviceCommand(ctx, serviceName, "restart")
+}
+
+// Enable adds the service to the configured runlevel.
+func (om *OpenRCManager) Enable(ctx context.Context, serviceName string) error {
+	if !om.serviceExists(serviceName) {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	_, err := om.run(ctx, om.rcUpdate, "add", serviceName, om.runlevel)
+	return err
+}
+
+// Disable removes the service from the configured runlevel.
+func (om *OpenRCManager) Disable(ctx context.Context, serviceName string) error {
+	if !om.serviceExists(serviceName) {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	_, err := om.run(ctx, om.rcUpdate, "del", serviceName, om.runlevel)
+	return err
+}
+
+func (om *OpenRCManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	info := types.ServiceInfo{
+		Name: serviceName,
+		Type: types.ServiceTypeOpenRC,
+	}
+
+	if !om.serviceExists(serviceName) {
+		return info, fmt.Errorf("service %s not found", serviceName)
+	}
+
+	// rc-service exits non-zero for stopped and crashed services, so the
+	// state is taken from the output rather than the exit code.
+	cmd := commandContext(ctx, om.rcService, serviceName, "status")
+	output, _ := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return info, ctx.Err()
+	}
+	info.Status = openRCStateStatus(parseOpenRCStatus(string(output)))
+
+	runlevels, err := om.runlevels(ctx)
+	if err != nil {
+		return info, err
+	}
+	om.fillDetails(&info, runlevels[serviceName])
+
+	return info, nil
+}
+
+// ListServices reads every service and its state from `rc-status --servicelist`.
+func (om *OpenRCManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	output, err := om.run(ctx, om.rcStatus, "--servicelist")
+	if err != nil {
+		return nil, err
+	}
+
+	runlevels, err := om.runlevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []types.ServiceInfo
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		name, state, ok := parseOpenRCServiceLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		info := types.ServiceInfo{
+			Name:   name,
+			Type:   types.ServiceTypeOpenRC,
+			Status: openRCStateStatus(state),
+		}
+		om.fillDetails(&info, runlevels[name])
+		services = append(services, info)
+	}
+
+	return services, nil
+}
+
+// Runlevels returns the runlevels the service is a member of.
+func (om *OpenRCManager) Runlevels(ctx context.Context, serviceName string) ([]string, error) {
+	runlevels, err := om.runlevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runlevels[serviceName], nil
+}
+
+// runlevels parses `rc-update show --verbose`, whose lines look like
+// "      sshd | boot default".
+func (om *OpenRCManager) runlevels(ctx context.Context) (map[string][]string, error) {
+	output, err := om.run(ctx, om.rcUpdate, "show", "--verbose")
+	if err != nil {
+		return nil, err
+	}
+
+	membership := make(map[string][]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		name, levels, found := strings.Cut(scanner.Text(), "|")
+		if !found {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		membership[name] = strings.Fields(levels)
+	}
+
+	return membership, nil
+}
+
+// fillDetails adds the description from the init script, the PID and start
+// time from the service's pidfile, and whether the service is in a runlevel
+// as its enabled state.
+func (om *OpenRCManager) fillDetails(info *types.ServiceInfo, runlevels []string) {
+	info.Description = om.getServiceDescription(info.Name)
+
+	if info.Status == types.StatusActive {
+		pidFile := filepath.Join(om.runDir, info.Name+".pid")
+		if data, err := os.ReadFile(pidFile); err == nil {
+			if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && pid > 0 {
+				info.PID = pid
+			}
+			if stat, err := os.Stat(pidFile); err == nil {
+				info.LastChanged = stat.ModTime()
+				info.Uptime = time.Since(stat.ModTime())
+			}
+		}
+	}
+
+	if len(runlevels) > 0 {
+		info.EnabledState = "enabled"
+	} else {
+		info.EnabledState = "disabled"
+	}
+}
+
+func (om *OpenRCManager) serviceCommand(ctx context.Context, serviceName, action string) error {
+	if !om.serviceExists(serviceName) {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	_, err := om.run(ctx, om.rcService, serviceName, action)
+	return err
+}
+
+// run executes an OpenRC tool and folds its output into the returned error.
+func (om *OpenRCManager) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := commandContext(ctx, name, args...)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return nil, fmt.Errorf("%s %s failed: %s", filepath.Base(name), strings.Join(args, " "), msg)
+		}
+		return nil, fmt.Errorf("%s %s failed: %v", filepath.Base(name), strings.Join(args, " "), err)
+	}
+	return output, nil
+}
+
+func (om *OpenRCManager) serviceExists(serviceName string) bool {
+	if serviceName == "" || strings.ContainsRune(serviceName, '/') {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(om.initDPath, serviceName))
+	return err == nil && !info.IsDir()
+}
+
+// getServiceDescription reads the description="..." variable OpenRC scripts set.
+func (om *OpenRCManager) getServiceDescription(serviceName string) string {
+	file, err := os.Open(filepath.Join(om.initDPath, serviceName))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "description=") {
+			return strings.Trim(strings.TrimPrefix(line, "description="), `"'`)
+		}
+	}
+	return ""
+}
+
+// parseOpenRCStatus extracts the state from " * status: started".
+func parseOpenRCStatus(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if _, state, found := strings.Cut(line, "status:"); found {
+			return strings.TrimSpace(state)
+		}
+	}
+	return ""
+}
+
+// parseOpenRCServiceLine splits one `rc-status --servicelist` line such as
+// " sshd      [  started 00:10:02 (0) ]" into name and state.
+func parseOpenRCServiceLine(line string) (string, string, bool) {
+	open := strings.Index(line, "[")
+	end := strings.LastIndex(line, "]")
+	if open <= 0 || end < open {
+		return "", "", false
+	}
+
+	name := strings.TrimSpace(line[:open])
+	fields := strings.Fields(line[open+1 : end])
+	if name == "" || len(fields) == 0 {
+		return "", "", false
+	}
+	return name, fields[0], true
+}
+
+// openRCStateStatus maps an OpenRC service state to a ServiceStatus. A
+// crashed service is one whose daemon died while OpenRC still considers it
+// started.
+func openRCStateStatus(state string) types.ServiceStatus {
+	switch strings.ToLower(state) {
+	case "started":
+		return types.StatusActive
+	case "stopped", "inactive":
+		return types.StatusInactive
+	case "crashed", "failed":
+		return types.StatusFailed
+	default:
+		return types.StatusUnknown
+	}
+}
+
+// IsOpenRCAvailable reports whether OpenRC is the running init system.
+func IsOpenRCAvailable() bool {
+	if _, err := exec.LookPath("rc-service"); err != nil {
+		return false
+	}
+	info, err := os.Stat("/run/openrc")
+	return err == nil && info.IsDir()
+}
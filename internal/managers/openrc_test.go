@@ -0,0 +1,253 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const fakeRCServiceScript = `#!/bin/sh
+echo "rc-service $*" >> "$CALLS"
+case "$*" in
+"sshd status") echo " * status: started" ;;
+"crond status") echo " * status: stopped"; exit 3 ;;
+"nginx status") echo " * status: crashed"; exit 32 ;;
+"sshd start"|"sshd stop"|"sshd restart") echo " * Starting sshd ... [ ok ]" ;;
+"nginx start") echo " * nginx: configuration test failed" >&2; exit 1 ;;
+*) echo " * rc-service: service '$1' does not exist" >&2; exit 1 ;;
+esac
+`
+
+const fakeRCUpdateScript = `#!/bin/sh
+echo "rc-update $*" >> "$CALLS"
+case "$*" in
+"show --verbose")
+cat <<'EOF'
+             crond |      default
+             nginx |
+              sshd | boot default
+EOF
+;;
+"add crond default"|"del sshd default") echo " * service added" ;;
+*) exit 1 ;;
+esac
+`
+
+const fakeRCStatusScript = `#!/bin/sh
+echo "rc-status $*" >> "$CALLS"
+cat <<'EOF'
+ crond                                                    [  stopped  ]
+ nginx                                                    [  crashed  ]
+ sshd                                                     [  started 01:02:03 (0) ]
+EOF
+`
+
+// newTestOpenRCManager 使用假的 rc-service/rc-update/rc-status 脚本和临时 init.d 目录
+func newTestOpenRCManager(t *testing.T) (*OpenRCManager, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake OpenRC tools require a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	initD := filepath.Join(dir, "init.d")
+	runDir := filepath.Join(dir, "run")
+	for _, d := range []string{initD, runDir} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", d, err)
+		}
+	}
+
+	scripts := map[string]string{
+		"rc-service": fakeRCServiceScript,
+		"rc-update":  fakeRCUpdateScript,
+		"rc-status":  fakeRCStatusScript,
+	}
+	for name, content := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+			t.Fatalf("Failed to write fake %s: %v", name, err)
+		}
+	}
+
+	initScripts := map[string]string{
+		"sshd":  "#!/sbin/openrc-run\ndescription=\"OpenBSD Secure Shell server\"\ncommand=/usr/sbin/sshd\n",
+		"crond": "#!/sbin/openrc-run\ncommand=/usr/sbin/crond\n",
+		"nginx": "#!/sbin/openrc-run\ndescription='Nginx http server'\n",
+	}
+	for name, content := range initScripts {
+		if err := os.WriteFile(filepath.Join(initD, name), []byte(content), 0755); err != nil {
+			t.Fatalf("Failed to write init script %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "sshd.pid"), []byte("812\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pidfile: %v", err)
+	}
+
+	calls := filepath.Join(dir, "calls")
+	t.Setenv("CALLS", calls)
+
+	return &OpenRCManager{
+		rcService: filepath.Join(dir, "rc-service"),
+		rcUpdate:  filepath.Join(dir, "rc-update"),
+		rcStatus:  filepath.Join(dir, "rc-status"),
+		initDPath: initD,
+		runDir:    runDir,
+		runlevel:  defaultOpenRCRunlevel,
+	}, calls
+}
+
+func TestOpenRCManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*OpenRCManager)(nil)
+}
+
+func TestIsOpenRCAvailable(t *testing.T) {
+	t.Logf("OpenRC available: %t", IsOpenRCAvailable())
+}
+
+func TestOpenRCManager_GetStatus(t *testing.T) {
+	manager, _ := newTestOpenRCManager(t)
+	ctx := context.Background()
+
+	sshd, err := manager.GetStatus(ctx, "sshd")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if sshd.Status != types.StatusActive || sshd.PID != 812 {
+		t.Errorf("Unexpected sshd status: %+v", sshd)
+	}
+	// 属于任一运行级别即为 enabled
+	if sshd.Description != "OpenBSD Secure Shell server" || sshd.EnabledState != "enabled" {
+		t.Errorf("Unexpected description or enabled state: %s, %s", sshd.Description, sshd.EnabledState)
+	}
+	if sshd.LastChanged.IsZero() {
+		t.Error("Expected pidfile time as last change")
+	}
+
+	// crashed 状态应当映射为 failed
+	nginx, err := manager.GetStatus(ctx, "nginx")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if nginx.Status != types.StatusFailed {
+		t.Errorf("Expected crashed service to be failed, got %s", nginx.Status)
+	}
+	if nginx.Description != "Nginx http server" || nginx.EnabledState != "disabled" {
+		t.Errorf("Unexpected description or enabled state: %s, %s", nginx.Description, nginx.EnabledState)
+	}
+
+	crond, err := manager.GetStatus(ctx, "crond")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if crond.Status != types.StatusInactive || crond.PID != 0 {
+		t.Errorf("Unexpected crond status: %+v", crond)
+	}
+
+	if _, err := manager.GetStatus(ctx, "ghost"); err == nil {
+		t.Error("Expected error for unknown service")
+	}
+}
+
+func TestOpenRCManager_ListServices(t *testing.T) {
+	manager, calls := newTestOpenRCManager(t)
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	statuses := make(map[string]types.ServiceStatus)
+	for _, s := range services {
+		if s.Type != types.ServiceTypeOpenRC {
+			t.Errorf("Expected openrc type, got %s", s.Type)
+		}
+		statuses[s.Name] = s.Status
+	}
+
+	expected := map[string]types.ServiceStatus{
+		"sshd":  types.StatusActive,
+		"crond": types.StatusInactive,
+		"nginx": types.StatusFailed,
+	}
+	if len(statuses) != len(expected) {
+		t.Errorf("Expected %d services, got %v", len(expected), statuses)
+	}
+	for name, want := range expected {
+		if statuses[name] != want {
+			t.Errorf("%s: expected %s, got %s", name, want, statuses[name])
+		}
+	}
+
+	got := readCalls(t, calls)
+	if got[0] != "rc-status --servicelist" {
+		t.Errorf("Expected services to be listed via rc-status, got %v", got)
+	}
+}
+
+func TestOpenRCManager_Operations(t *testing.T) {
+	manager, calls := newTestOpenRCManager(t)
+	ctx := context.Background()
+
+	if err := manager.Restart(ctx, "sshd"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	if err := manager.Enable(ctx, "crond"); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	if err := manager.Disable(ctx, "sshd"); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+
+	err := manager.Start(ctx, "nginx")
+	if err == nil || !strings.Contains(err.Error(), "configuration test failed") {
+		t.Errorf("Expected rc-service output in error, got %v", err)
+	}
+	if err := manager.Start(ctx, "../sshd"); err == nil {
+		t.Error("Expected path-like service name to be rejected")
+	}
+
+	expected := []string{
+		"rc-service sshd restart",
+		"rc-update add crond default",
+		"rc-update del sshd default",
+		"rc-service nginx start",
+	}
+	got := readCalls(t, calls)
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected calls %v, got %v", expected, got)
+	}
+
+	runlevels, err := manager.Runlevels(ctx, "sshd")
+	if err != nil {
+		t.Fatalf("Runlevels failed: %v", err)
+	}
+	if strings.Join(runlevels, ",") != "boot,default" {
+		t.Errorf("Unexpected runlevels: %v", runlevels)
+	}
+}
+
+func TestParseOpenRCServiceLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		name  string
+		state string
+		ok    bool
+	}{
+		{" sshd     [  started  ]", "sshd", "started", true},
+		{" agetty.tty1  [  started 2 day(s) 03:04:05 (0) ]", "agetty.tty1", "started", true},
+		{"Runlevel: default", "", "", false},
+		{"[ stopped ]", "", "", false},
+	}
+
+	for _, tt := range tests {
+		name, state, ok := parseOpenRCServiceLine(tt.line)
+		if name != tt.name || state != tt.state || ok != tt.ok {
+			t.Errorf("parseOpenRCServiceLine(%q) = %q, %q, %t", tt.line, name, state, ok)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// OwnerQuery names the symptom to find the owning service of: a process, a
+// listening port or a file path. Exactly one of PID, Port and Path is set.
+type OwnerQuery struct {
+	PID  int
+	Port int
+	// Protocol restricts a port lookup to "tcp" or "udp"; empty matches
+	// both.
+	Protocol string
+	Path     string
+}
+
+// ParseOwnerQuery validates an owner lookup request.
+func ParseOwnerQuery(pid, port int, protocol, path string) (OwnerQuery, error) {
+	query := OwnerQuery{PID: pid, Port: port, Protocol: strings.ToLower(protocol), Path: path}
+
+	given := 0
+	for _, set := range []bool{pid != 0, port != 0, path != ""} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return query, fmt.Errorf("exactly one of pid, port and path is required")
+	}
+	if pid < 0 {
+		return query, fmt.Errorf("invalid pid %d", pid)
+	}
+	if port < 0 || port > 65535 {
+		return query, fmt.Errorf("invalid port %d", port)
+	}
+	if query.Protocol != "" && query.Protocol != "tcp" && query.Protocol != "udp" {
+		return query, fmt.Errorf("invalid protocol %q, expected tcp or udp", protocol)
+	}
+	if path != "" {
+		if !filepath.IsAbs(path) {
+			return query, fmt.Errorf("path must be absolute")
+		}
+		query.Path = filepath.Clean(path)
+	}
+	return query, nil
+}
+
+// OwnerNotFoundError is returned by LocateOwner when the process, port or
+// file does not exist or no service owns it.
+type OwnerNotFoundError struct {
+	Reason string
+}
+
+func (e *OwnerNotFoundError) Error() string {
+	return e.Reason
+}
+
+// IsOwnerNotFound reports whether err is an OwnerNotFoundError.
+func IsOwnerNotFound(err error) bool {
+	var notFound *OwnerNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// LocateOwner finds the service that owns the queried process, port or
+// file on this host.
+func LocateOwner(ctx context.Context, query OwnerQuery) (types.ServiceOwner, error) {
+	return newOwnerLocator().locate(ctx, query)
+}
+
+// ownerLocator maps processes to services through /proc. Processes outside
+// any systemd service or container are matched against SysV pidfiles.
+type ownerLocator struct {
+	procRoot string
+	sysv     *SysVManager
+}
+
+func newOwnerLocator() *ownerLocator {
+	return &ownerLocator{procRoot: defaultProcRoot, sysv: NewSysVManager()}
+}
+
+func (l *ownerLocator) locate(ctx context.Context, query OwnerQuery) (types.ServiceOwner, error) {
+	var pids []int
+	switch {
+	case query.PID != 0:
+		if _, err := os.Stat(filepath.Join(l.procRoot, strconv.Itoa(query.PID))); err != nil {
+			return types.ServiceOwner{}, &OwnerNotFoundError{fmt.Sprintf("process %d not found", query.PID)}
+		}
+		pids = []int{query.PID}
+	case query.Port != 0:
+		pids = l.portProcesses(query.Port, query.Protocol)
+		if len(pids) == 0 {
+			return types.ServiceOwner{}, &OwnerNotFoundError{fmt.Sprintf("no process is listening on port %d", query.Port)}
+		}
+	default:
+		pids = l.pathProcesses(query.Path)
+		if len(pids) == 0 {
+			return types.ServiceOwner{}, &OwnerNotFoundError{fmt.Sprintf("no process has %s open", query.Path)}
+		}
+	}
+
+	for _, pid := range pids {
+		if ctx.Err() != nil {
+			return types.ServiceOwner{}, ctx.Err()
+		}
+		if owner, ok := l.processOwner(pid); ok {
+			return owner, nil
+		}
+	}
+	if len(pids) == 1 {
+		return types.ServiceOwner{}, &OwnerNotFoundError{fmt.Sprintf("process %d does not belong to a service", pids[0])}
+	}
+	return types.ServiceOwner{}, &OwnerNotFoundError{fmt.Sprintf("processes %s do not belong to a service", joinPIDs(pids))}
+}
+
+// processOwner finds the service of a process from its cgroup, or else
+// from the pidfiles of the SysV scripts it or one of its ancestors is
+// recorded in.
+func (l *ownerLocator) processOwner(pid int) (types.ServiceOwner, bool) {
+	if cgroup := l.processCgroup(pid); cgroup != "" {
+		if owner, ok := cgroupOwner(cgroup); ok {
+			owner.PID = pid
+			return owner, true
+		}
+	}
+
+	scripts := l.sysvPIDs()
+	for current := pid; current > 1; {
+		if name, ok := scripts[current]; ok {
+			return types.ServiceOwner{Type: types.ServiceTypeSysV, Name: name, PID: pid}, true
+		}
+		parent, ok := procParent(l.procRoot, current)
+		if !ok || parent == current {
+			break
+		}
+		current = parent
+	}
+	return types.ServiceOwner{}, false
+}
+
+// processCgroup returns the unified hierarchy path from /proc/<pid>/cgroup,
+// or the systemd hierarchy path on cgroup v1.
+func (l *ownerLocator) processCgroup(pid int) string {
+	data, err := os.ReadFile(filepath.Join(l.procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	var unified, named string
+	for _, line := range strings.Split(string(data), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			unified = parts[2]
+		case parts[1] == "name=systemd":
+			named = parts[2]
+		}
+	}
+	if unified != "" && unified != "/" {
+		return unified
+	}
+	return named
+}
+
+var (
+	// dockerScope and containerID match the cgroup of a container run
+	// with the systemd cgroup driver (docker-<id>.scope) or the cgroupfs
+	// driver (/docker/<id>).
+	dockerScope = regexp.MustCompile(`^docker-([0-9a-f]{64})\.scope$`)
+	podmanScope = regexp.MustCompile(`^libpod-([0-9a-f]{64})\.scope$`)
+	containerID = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	userManager = regexp.MustCompile(`^user@(\d+)\.service$`)
+)
+
+// cgroupOwner maps a cgroup path to the innermost container or systemd
+// service it lies in, e.g. /system.slice/nginx.service or
+// /user.slice/user-1000.slice/user@1000.service/app.slice/app.service.
+func cgroupOwner(cgroup string) (types.ServiceOwner, bool) {
+	parts := strings.Split(strings.Trim(cgroup, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		part := parts[i]
+		if m := dockerScope.FindStringSubmatch(part); m != nil {
+			return types.ServiceOwner{Type: types.ServiceTypeDocker, Name: m[1], CGroup: cgroup}, true
+		}
+		if containerID.MatchString(part) && i > 0 && parts[i-1] == "docker" {
+			return types.ServiceOwner{Type: types.ServiceTypeDocker, Name: part, CGroup: cgroup}, true
+		}
+		if m := podmanScope.FindStringSubmatch(part); m != nil {
+			return types.ServiceOwner{Type: types.ServiceTypePodman, Name: m[1], CGroup: cgroup}, true
+		}
+		if !strings.HasSuffix(part, ".service") {
+			continue
+		}
+		if userManager.MatchString(part) {
+			// A process of the user manager itself, not of one of its units.
+			return types.ServiceOwner{}, false
+		}
+
+		owner := types.ServiceOwner{Type: types.ServiceTypeSystemd, Name: systemdListName(part), CGroup: cgroup, Scope: "system"}
+		for _, ancestor := range parts[:i] {
+			if m := userManager.FindStringSubmatch(ancestor); m != nil {
+				owner.Scope = "user:" + m[1]
+				if u, err := user.LookupId(m[1]); err == nil {
+					owner.Scope = "user:" + u.Username
+				}
+			}
+		}
+		return owner, true
+	}
+	return types.ServiceOwner{}, false
+}
+
+// sysvPIDs maps the running processes recorded in SysV pidfiles to their
+// scripts.
+func (l *ownerLocator) sysvPIDs() map[int]string {
+	scripts := make(map[int]string)
+	entries, _ := os.ReadDir(l.sysv.initDPath)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || l.sysv.isNonServiceFile(entry.Name()) {
+			continue
+		}
+		if pid := l.sysv.pidFileProcess(entry.Name()); pid > 0 {
+			scripts[pid] = entry.Name()
+		}
+	}
+	return scripts
+}
+
+// portProcesses returns the processes holding a socket that listens on port
+// in this host's network namespace.
+func (l *ownerLocator) portProcesses(port int, protocol string) []int {
+	inodes := make(map[uint64]bool)
+	for inode, socket := range readListenSockets(filepath.Join(l.procRoot, "net")) {
+		if socket.Port == port && strings.HasPrefix(socket.Protocol, protocol) {
+			inodes[inode] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return nil
+	}
+	return l.scanDescriptors(func(target string) bool {
+		inode, ok := strings.CutPrefix(target, "socket:[")
+		if !ok {
+			return false
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 64)
+		return err == nil && inodes[n]
+	}, nil)
+}
+
+// pathProcesses returns the processes that have path, or a file below it,
+// open or run it as their executable. Files deleted while open still match.
+func (l *ownerLocator) pathProcesses(path string) []int {
+	match := func(target string) bool {
+		target = strings.TrimSuffix(target, " (deleted)")
+		return target == path || strings.HasPrefix(target, strings.TrimSuffix(path, "/")+"/")
+	}
+	return l.scanDescriptors(match, match)
+}
+
+// scanDescriptors returns, in PID order, the processes with an open file
+// descriptor whose link target matches fd, or whose executable matches exe.
+func (l *ownerLocator) scanDescriptors(fd, exe func(target string) bool) []int {
+	var pids []int
+	for _, pid := range l.processes() {
+		dir := filepath.Join(l.procRoot, strconv.Itoa(pid))
+		if exe != nil {
+			if target, err := os.Readlink(filepath.Join(dir, "exe")); err == nil && exe(target) {
+				pids = append(pids, pid)
+				continue
+			}
+		}
+		fds, _ := os.ReadDir(filepath.Join(dir, "fd"))
+		for _, entry := range fds {
+			if target, err := os.Readlink(filepath.Join(dir, "fd", entry.Name())); err == nil && fd(target) {
+				pids = append(pids, pid)
+				break
+			}
+		}
+	}
+	return pids
+}
+
+// processes lists the PIDs under the proc root in ascending order.
+func (l *ownerLocator) processes() []int {
+	entries, _ := os.ReadDir(l.procRoot)
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+func joinPIDs(pids []int) string {
+	values := make([]string, len(pids))
+	for i, pid := range pids {
+		values[i] = strconv.Itoa(pid)
+	}
+	return strings.Join(values, ", ")
+}
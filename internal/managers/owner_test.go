@@ -0,0 +1,167 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const testContainerID = "4f1d2c3b4a5968778695a4b3c2d1e0f4f1d2c3b4a5968778695a4b3c2d1e0f00"
+
+// newTestOwnerLocator 创建基于模拟 /proc 和 init.d 目录的定位器
+func newTestOwnerLocator(t *testing.T) *ownerLocator {
+	t.Helper()
+	sysv := NewSysVManager()
+	sysv.initDPath = t.TempDir()
+	sysv.runDirs = []string{t.TempDir()}
+	sysv.procRoot = t.TempDir()
+	return &ownerLocator{procRoot: sysv.procRoot, sysv: sysv}
+}
+
+// writeProcessCgroup 设置模拟进程的 /proc/<pid>/cgroup
+func writeProcessCgroup(t *testing.T, root string, pid int, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(root, strconv.Itoa(pid), "cgroup"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write cgroup: %v", err)
+	}
+}
+
+func TestParseOwnerQuery(t *testing.T) {
+	if query, err := ParseOwnerQuery(0, 8080, "TCP", ""); err != nil || query.Port != 8080 || query.Protocol != "tcp" {
+		t.Errorf("Unexpected port query: %+v, %v", query, err)
+	}
+	if query, err := ParseOwnerQuery(0, 0, "", "/var/log/app/../app.log"); err != nil || query.Path != "/var/log/app.log" {
+		t.Errorf("Unexpected path query: %+v, %v", query, err)
+	}
+
+	invalid := []struct {
+		pid, port      int
+		protocol, path string
+	}{
+		{0, 0, "", ""},
+		{1, 80, "", ""},
+		{-1, 0, "", ""},
+		{0, 70000, "", ""},
+		{0, 80, "sctp", ""},
+		{0, 0, "", "app.log"},
+	}
+	for _, tt := range invalid {
+		if _, err := ParseOwnerQuery(tt.pid, tt.port, tt.protocol, tt.path); err == nil {
+			t.Errorf("Expected %+v to be rejected", tt)
+		}
+	}
+}
+
+func TestCgroupOwner(t *testing.T) {
+	tests := []struct {
+		cgroup string
+		owner  types.ServiceOwner
+		ok     bool
+	}{
+		{"/system.slice/nginx.service", types.ServiceOwner{Type: types.ServiceTypeSystemd, Name: "nginx", Scope: "system"}, true},
+		{"/system.slice/app.service/worker", types.ServiceOwner{Type: types.ServiceTypeSystemd, Name: "app", Scope: "system"}, true},
+		{"/system.slice/docker-" + testContainerID + ".scope", types.ServiceOwner{Type: types.ServiceTypeDocker, Name: testContainerID}, true},
+		{"/docker/" + testContainerID, types.ServiceOwner{Type: types.ServiceTypeDocker, Name: testContainerID}, true},
+		{"/machine.slice/libpod-" + testContainerID + ".scope/container", types.ServiceOwner{Type: types.ServiceTypePodman, Name: testContainerID}, true},
+		{"/user.slice/user-4294967.slice/user@4294967.service/app.slice/app.service", types.ServiceOwner{Type: types.ServiceTypeSystemd, Name: "app", Scope: "user:4294967"}, true},
+		{"/user.slice/user-4294967.slice/user@4294967.service/init.scope", types.ServiceOwner{}, false},
+		{"/user.slice/user-1000.slice/session-3.scope", types.ServiceOwner{}, false},
+		{"/init.scope", types.ServiceOwner{}, false},
+	}
+	for _, tt := range tests {
+		owner, ok := cgroupOwner(tt.cgroup)
+		if tt.ok {
+			tt.owner.CGroup = tt.cgroup
+		}
+		if ok != tt.ok || owner != tt.owner {
+			t.Errorf("cgroupOwner(%q) = %+v, %t", tt.cgroup, owner, ok)
+		}
+	}
+}
+
+func TestOwnerLocator_PID(t *testing.T) {
+	locator := newTestOwnerLocator(t)
+	root := locator.procRoot
+	writeFakeProcess(t, root, 100, 1, "nginx")
+	writeProcessCgroup(t, root, 100, "0::/system.slice/nginx.service\n")
+	writeFakeProcess(t, root, 200, 1, "legacyd")
+	writeProcessCgroup(t, root, 200, "0::/init.scope\n")
+	writeFakeProcess(t, root, 201, 200, "legacyd worker")
+	writeProcessCgroup(t, root, 201, "12:pids:/\n1:name=systemd:/\n0::/\n")
+	writeFakeProcess(t, root, 300, 1, "shell")
+	os.WriteFile(filepath.Join(locator.sysv.initDPath, "legacyd"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(locator.sysv.runDirs[0], "legacyd.pid"), []byte("200\n"), 0644)
+	ctx := context.Background()
+
+	owner, err := locator.locate(ctx, OwnerQuery{PID: 100})
+	if err != nil || owner.Type != types.ServiceTypeSystemd || owner.Name != "nginx" || owner.PID != 100 {
+		t.Errorf("Unexpected systemd owner: %+v, %v", owner, err)
+	}
+
+	// 子进程通过其父进程的 pidfile 归属到 SysV 脚本
+	owner, err = locator.locate(ctx, OwnerQuery{PID: 201})
+	if err != nil || owner.Type != types.ServiceTypeSysV || owner.Name != "legacyd" || owner.PID != 201 {
+		t.Errorf("Unexpected SysV owner: %+v, %v", owner, err)
+	}
+
+	if _, err := locator.locate(ctx, OwnerQuery{PID: 300}); err == nil || !strings.Contains(err.Error(), "does not belong") {
+		t.Errorf("Expected an unowned process to fail, got %v", err)
+	}
+	if _, err := locator.locate(ctx, OwnerQuery{PID: 999}); !IsOwnerNotFound(err) || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a missing process to fail, got %v", err)
+	}
+}
+
+func TestOwnerLocator_Port(t *testing.T) {
+	locator := newTestOwnerLocator(t)
+	root := locator.procRoot
+	writeFakeProcess(t, root, 100, 1, "nginx", 1001)
+	writeProcessCgroup(t, root, 100, "0::/system.slice/docker-"+testContainerID+".scope\n")
+	writeFakeProcess(t, root, 101, 1, "dns", 1003)
+	// /proc/net 指向当前进程的网络命名空间
+	os.Symlink("100/net", filepath.Join(root, "net"))
+	ctx := context.Background()
+
+	owner, err := locator.locate(ctx, OwnerQuery{Port: 8080, Protocol: "tcp"})
+	if err != nil || owner.Type != types.ServiceTypeDocker || owner.Name != testContainerID || owner.PID != 100 {
+		t.Errorf("Unexpected port owner: %+v, %v", owner, err)
+	}
+	if _, err := locator.locate(ctx, OwnerQuery{Port: 8080, Protocol: "udp"}); err == nil || !strings.Contains(err.Error(), "no process") {
+		t.Errorf("Expected no UDP listener on 8080, got %v", err)
+	}
+	if _, err := locator.locate(ctx, OwnerQuery{Port: 53}); err == nil || !strings.Contains(err.Error(), "process 101 does not belong") {
+		t.Errorf("Expected the DNS process to be unowned, got %v", err)
+	}
+}
+
+func TestOwnerLocator_Path(t *testing.T) {
+	locator := newTestOwnerLocator(t)
+	root := locator.procRoot
+	writeFakeProcess(t, root, 100, 1, "app")
+	writeProcessCgroup(t, root, 100, "0::/system.slice/app.service\n")
+	os.Symlink("/var/log/app/current.log (deleted)", filepath.Join(root, "100", "fd", "5"))
+	writeFakeProcess(t, root, 200, 1, "db")
+	writeProcessCgroup(t, root, 200, "0::/system.slice/db.service\n")
+	os.Symlink("/usr/sbin/db", filepath.Join(root, "200", "exe"))
+	ctx := context.Background()
+
+	tests := map[string]string{
+		"/var/log/app/current.log": "app",
+		"/var/log/app":             "app",
+		"/usr/sbin/db":             "db",
+	}
+	for path, name := range tests {
+		owner, err := locator.locate(ctx, OwnerQuery{Path: path})
+		if err != nil || owner.Name != name {
+			t.Errorf("Unexpected owner of %s: %+v, %v", path, owner, err)
+		}
+	}
+	if _, err := locator.locate(ctx, OwnerQuery{Path: "/var/log/app.log"}); err == nil {
+		t.Error("Expected a file no process has open to fail")
+	}
+}
@@ -0,0 +1,387 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// pluginMethodNotFound is the JSON-RPC code a plugin returns for a method it
+// does not implement, such as the optional logs method.
+const pluginMethodNotFound = -32601
+
+// pluginCloseTimeout is how long Close waits for a plugin to exit after its
+// stdin is closed before killing it.
+const pluginCloseTimeout = 5 * time.Second
+
+// PluginError is a JSON-RPC error returned by a plugin.
+type PluginError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *PluginError) Error() string {
+	return e.Message
+}
+
+// PluginManager drives an external executable that implements a service
+// manager. The plugin runs for the lifetime of the manager and exchanges
+// newline-delimited JSON-RPC 2.0 messages over stdin and stdout:
+//
+//	list                        -> [service, ...]
+//	status  {"name"}            -> service
+//	start, stop, restart,
+//	enable, disable {"name"}    -> any result
+//	logs    {"name", "lines", "stream"} -> "text" (optional)
+//
+// where a service is {"name", "status", "description", "pid", "since"} with
+// status one of active, inactive or failed and since an RFC 3339 time.
+// Requests may be answered out of order. Anything the plugin writes to
+// stderr is logged. A plugin that exits is restarted on the next call.
+type PluginManager struct {
+	spec   config.PluginConfig
+	logger *logrus.Entry
+
+	mu     sync.Mutex
+	conn   *pluginConn
+	nextID int64
+	closed bool
+}
+
+// pluginConn is one running plugin process.
+type pluginConn struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[int64]chan pluginResponse
+
+	// done is closed once the process has exited; err says why.
+	done chan struct{}
+	err  error
+}
+
+type pluginRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int64       `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type pluginResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *PluginError    `json:"error"`
+}
+
+type pluginNameParams struct {
+	Name string `json:"name"`
+}
+
+type pluginLogsParams struct {
+	Name   string `json:"name"`
+	Lines  int    `json:"lines"`
+	Stream string `json:"stream,omitempty"`
+}
+
+type pluginService struct {
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	Description string    `json:"description,omitempty"`
+	PID         int       `json:"pid,omitempty"`
+	Since       time.Time `json:"since,omitempty"`
+}
+
+// NewPluginManager starts the plugin declared by spec. Its services are
+// reported with spec.Name as their type.
+func NewPluginManager(spec config.PluginConfig, logger *logrus.Logger) (*PluginManager, error) {
+	pm := &PluginManager{
+		spec:   spec,
+		logger: logger.WithField("plugin", spec.Name),
+	}
+	if _, err := pm.connection(); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// ServiceType returns the type under which the plugin's services are listed.
+func (pm *PluginManager) ServiceType() types.ServiceType {
+	return types.ServiceType(pm.spec.Name)
+}
+
+func (pm *PluginManager) Start(ctx context.Context, serviceName string) error {
+	return pm.call(ctx, "start", pluginNameParams{Name: serviceName}, nil)
+}
+
+func (pm *PluginManager) Stop(ctx context.Context, serviceName string) error {
+	return pm.call(ctx, "stop", pluginNameParams{Name: serviceName}, nil)
+}
+
+func (pm *PluginManager) Restart(ctx context.Context, serviceName string) error {
+	return pm.call(ctx, "restart", pluginNameParams{Name: serviceName}, nil)
+}
+
+func (pm *PluginManager) Enable(ctx context.Context, serviceName string) error {
+	return pm.call(ctx, "enable", pluginNameParams{Name: serviceName}, nil)
+}
+
+func (pm *PluginManager) Disable(ctx context.Context, serviceName string) error {
+	return pm.call(ctx, "disable", pluginNameParams{Name: serviceName}, nil)
+}
+
+func (pm *PluginManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	var service pluginService
+	if err := pm.call(ctx, "status", pluginNameParams{Name: serviceName}, &service); err != nil {
+		return types.ServiceInfo{Name: serviceName, Type: pm.ServiceType()}, err
+	}
+	if service.Name == "" {
+		service.Name = serviceName
+	}
+	return pm.serviceInfo(service), nil
+}
+
+func (pm *PluginManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	var list []pluginService
+	if err := pm.call(ctx, "list", nil, &list); err != nil {
+		return nil, err
+	}
+	services := make([]types.ServiceInfo, 0, len(list))
+	for _, service := range list {
+		services = append(services, pm.serviceInfo(service))
+	}
+	return services, nil
+}
+
+// GetLogs returns the last lines of a service's log.
+func (pm *PluginManager) GetLogs(ctx context.Context, serviceName string, lines int) (string, error) {
+	return pm.TailLog(ctx, serviceName, "", lines)
+}
+
+// TailLog returns the last lines of a service's log. The stream is passed to
+// the plugin as is; an empty stream asks for its default log.
+func (pm *PluginManager) TailLog(ctx context.Context, serviceName, stream string, lines int) (string, error) {
+	if lines <= 0 {
+		lines = 100
+	}
+	var logs string
+	err := pm.call(ctx, "logs", pluginLogsParams{Name: serviceName, Lines: lines, Stream: stream}, &logs)
+	var pluginErr *PluginError
+	if errors.As(err, &pluginErr) && pluginErr.Code == pluginMethodNotFound {
+		return "", fmt.Errorf("plugin %s does not support logs", pm.spec.Name)
+	}
+	return logs, err
+}
+
+// Close asks the plugin to exit by closing its stdin and kills it if it has
+// not done so within pluginCloseTimeout.
+func (pm *PluginManager) Close() error {
+	pm.mu.Lock()
+	pm.closed = true
+	conn := pm.conn
+	pm.conn = nil
+	pm.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	conn.stdin.Close()
+	select {
+	case <-conn.done:
+	case <-time.After(pluginCloseTimeout):
+		pm.logger.Warn("Plugin did not exit after stdin was closed, killing it")
+		terminateProcessGroup(conn.cmd.Process.Pid, true)
+		<-conn.done
+	}
+	return nil
+}
+
+func (pm *PluginManager) serviceInfo(service pluginService) types.ServiceInfo {
+	info := types.ServiceInfo{
+		Name:        service.Name,
+		Type:        pm.ServiceType(),
+		Description: service.Description,
+		PID:         service.PID,
+		LastChanged: service.Since,
+	}
+	switch types.ServiceStatus(service.Status) {
+	case types.StatusActive, types.StatusInactive, types.StatusFailed:
+		info.Status = types.ServiceStatus(service.Status)
+	default:
+		info.Status = types.StatusUnknown
+	}
+	if info.Status == types.StatusActive && !service.Since.IsZero() {
+		info.Uptime = time.Since(service.Since)
+	}
+	return info
+}
+
+// call sends one request and decodes the result into out, which may be nil.
+// Giving up on ctx leaves the plugin running; its late answer is dropped.
+func (pm *PluginManager) call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	conn, err := pm.connection()
+	if err != nil {
+		return err
+	}
+
+	pm.mu.Lock()
+	pm.nextID++
+	id := pm.nextID
+	pm.mu.Unlock()
+
+	line, err := json.Marshal(pluginRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	replies := make(chan pluginResponse, 1)
+	conn.mu.Lock()
+	conn.pending[id] = replies
+	conn.mu.Unlock()
+	defer func() {
+		conn.mu.Lock()
+		delete(conn.pending, id)
+		conn.mu.Unlock()
+	}()
+
+	conn.writeMu.Lock()
+	_, err = conn.stdin.Write(append(line, '\n'))
+	conn.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("plugin %s: failed to send %s request: %v", pm.spec.Name, method, err)
+	}
+
+	select {
+	case reply := <-replies:
+		if reply.Error != nil {
+			return reply.Error
+		}
+		if out == nil || len(reply.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(reply.Result, out); err != nil {
+			return fmt.Errorf("plugin %s: unexpected %s result: %v", pm.spec.Name, method, err)
+		}
+		return nil
+	case <-conn.done:
+		return fmt.Errorf("plugin %s exited during %s: %v", pm.spec.Name, method, conn.err)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// connection returns the running plugin, starting it if it is not running.
+func (pm *PluginManager) connection() (*pluginConn, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if pm.closed {
+		return nil, fmt.Errorf("plugin %s is closed", pm.spec.Name)
+	}
+	if pm.conn != nil {
+		select {
+		case <-pm.conn.done:
+			pm.logger.Warnf("Plugin exited (%v), restarting it", pm.conn.err)
+		default:
+			return pm.conn, nil
+		}
+	}
+
+	conn, err := pm.spawn()
+	if err != nil {
+		return nil, err
+	}
+	pm.conn = conn
+	return conn, nil
+}
+
+func (pm *PluginManager) spawn() (*pluginConn, error) {
+	// The plugin outlives any single request, so it is not bound to a
+	// request context; Close stops it.
+	cmd := commandContext(context.Background(), pm.spec.Command, pm.spec.Args...)
+	cmd.Dir = pm.spec.WorkDir
+	cmd.Env = commandEnv(pm.spec.Env)
+
+	stderr := pm.logger.WriterLevel(logrus.WarnLevel)
+	cmd.Stderr = stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		stderr.Close()
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		stderr.Close()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		stderr.Close()
+		return nil, fmt.Errorf("failed to start plugin %s: %v", pm.spec.Name, err)
+	}
+
+	conn := &pluginConn{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[int64]chan pluginResponse),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		if err := conn.readResponses(stdout, pm.logger); err != nil {
+			// Without a reader the plugin would block on a full pipe.
+			pm.logger.Warnf("Failed to read plugin output, killing it: %v", err)
+			terminateProcessGroup(cmd.Process.Pid, true)
+		}
+		conn.err = cmd.Wait()
+		if conn.err == nil {
+			conn.err = errors.New("exit status 0")
+		}
+		stderr.Close()
+		close(conn.done)
+	}()
+	return conn, nil
+}
+
+// readResponses delivers each response on stdout to the call waiting for it
+// until the plugin closes stdout.
+func (c *pluginConn) readResponses(stdout io.Reader, logger *logrus.Entry) error {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var response pluginResponse
+		if err := json.Unmarshal(line, &response); err != nil || response.ID == nil {
+			logger.Warnf("Ignoring malformed plugin output: %s", line)
+			continue
+		}
+
+		c.mu.Lock()
+		replies, ok := c.pending[*response.ID]
+		c.mu.Unlock()
+		if ok {
+			select {
+			case replies <- response:
+			default:
+				logger.Warnf("Ignoring duplicate plugin response %d", *response.ID)
+			}
+		}
+	}
+	return scanner.Err()
+}
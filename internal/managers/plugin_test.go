@@ -0,0 +1,270 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// TestPluginHelperProcess 不是真正的测试：当设置了 MCP_PLUGIN_HELPER 时，
+// 测试二进制本身充当一个通过 stdin/stdout 通信的插件
+func TestPluginHelperProcess(t *testing.T) {
+	if os.Getenv("MCP_PLUGIN_HELPER") != "1" {
+		return
+	}
+	runFakePlugin(os.Stdin, os.Stdout)
+	os.Exit(0)
+}
+
+func runFakePlugin(in io.Reader, out io.Writer) {
+	var mu sync.Mutex
+	running := map[string]bool{"appliance": true, "backup": false}
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var writeMu sync.Mutex
+	reply := func(id json.RawMessage, result interface{}, code int, message string) {
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+		if message != "" {
+			response["error"] = map[string]interface{}{"code": code, "message": message}
+		} else {
+			response["result"] = result
+		}
+		data, _ := json.Marshal(response)
+		writeMu.Lock()
+		fmt.Fprintf(out, "%s\n", data)
+		writeMu.Unlock()
+	}
+	service := func(name string) map[string]interface{} {
+		if running[name] {
+			return map[string]interface{}{"name": name, "status": "active", "pid": 42, "since": since, "description": "Appliance " + name}
+		}
+		return map[string]interface{}{"name": name, "status": "inactive"}
+	}
+
+	fmt.Fprintln(os.Stderr, "fake plugin ready")
+	fmt.Fprintln(out, "not json")
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Name   string `json:"name"`
+				Lines  int    `json:"lines"`
+				Stream string `json:"stream"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			continue
+		}
+		name := request.Params.Name
+
+		// 请求并发处理，以便慢请求不会阻塞其他请求
+		go func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if request.Method != "list" {
+				if name == "hang" {
+					mu.Unlock()
+					time.Sleep(time.Hour)
+				}
+				if name == "crash" {
+					os.Exit(3)
+				}
+				if _, exists := running[name]; !exists {
+					reply(request.ID, nil, -32000, "service "+name+" not found")
+					return
+				}
+			}
+
+			switch request.Method {
+			case "list":
+				reply(request.ID, []interface{}{service("appliance"), service("backup")}, 0, "")
+			case "status":
+				reply(request.ID, service(name), 0, "")
+			case "start", "restart":
+				running[name] = true
+				reply(request.ID, nil, 0, "")
+			case "stop":
+				running[name] = false
+				reply(request.ID, true, 0, "")
+			case "logs":
+				if name == "backup" {
+					reply(request.ID, nil, pluginMethodNotFound, "method not found")
+					return
+				}
+				reply(request.ID, fmt.Sprintf("%s %s %d\n", name, request.Params.Stream, request.Params.Lines), 0, "")
+			default:
+				reply(request.ID, nil, -32000, request.Method+" is not supported by the appliance")
+			}
+		}()
+	}
+}
+
+func newTestPluginManager(t *testing.T) *PluginManager {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	manager, err := NewPluginManager(config.PluginConfig{
+		Name:    "appliance",
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestPluginHelperProcess"},
+		Env:     map[string]string{"MCP_PLUGIN_HELPER": "1"},
+	}, logger)
+	if err != nil {
+		t.Fatalf("NewPluginManager failed: %v", err)
+	}
+	t.Cleanup(func() { manager.Close() })
+	return manager
+}
+
+func TestPluginManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*PluginManager)(nil)
+	var _ io.Closer = (*PluginManager)(nil)
+}
+
+func TestPluginManager_ListAndStatus(t *testing.T) {
+	manager := newTestPluginManager(t)
+	ctx := context.Background()
+
+	services, err := manager.ListServices(ctx)
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 services, got %d", len(services))
+	}
+	appliance := services[0]
+	if appliance.Type != types.ServiceType("appliance") || appliance.Status != types.StatusActive || appliance.PID != 42 {
+		t.Errorf("Unexpected service: %+v", appliance)
+	}
+	if appliance.Uptime <= 0 || !appliance.LastChanged.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected uptime from since, got %+v", appliance)
+	}
+
+	info, err := manager.GetStatus(ctx, "backup")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.Status != types.StatusInactive || info.Uptime != 0 {
+		t.Errorf("Unexpected status: %+v", info)
+	}
+
+	if _, err := manager.GetStatus(ctx, "ghost"); err == nil || err.Error() != "service ghost not found" {
+		t.Errorf("Expected plugin error message, got %v", err)
+	}
+}
+
+func TestPluginManager_Actions(t *testing.T) {
+	manager := newTestPluginManager(t)
+	ctx := context.Background()
+
+	if err := manager.Start(ctx, "backup"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if info, _ := manager.GetStatus(ctx, "backup"); info.Status != types.StatusActive {
+		t.Errorf("Expected backup to be active, got %s", info.Status)
+	}
+	if err := manager.Stop(ctx, "backup"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := manager.Restart(ctx, "backup"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+
+	err := manager.Enable(ctx, "backup")
+	var pluginErr *PluginError
+	if !errors.As(err, &pluginErr) || pluginErr.Code != -32000 {
+		t.Errorf("Expected plugin error, got %v", err)
+	}
+}
+
+func TestPluginManager_Logs(t *testing.T) {
+	manager := newTestPluginManager(t)
+	ctx := context.Background()
+
+	logs, err := manager.TailLog(ctx, "appliance", "stderr", 20)
+	if err != nil {
+		t.Fatalf("TailLog failed: %v", err)
+	}
+	if logs != "appliance stderr 20\n" {
+		t.Errorf("Unexpected logs: %q", logs)
+	}
+	if logs, _ := manager.GetLogs(ctx, "appliance", 0); logs != "appliance  100\n" {
+		t.Errorf("Expected default line count, got %q", logs)
+	}
+
+	if _, err := manager.GetLogs(ctx, "backup", 10); err == nil || !strings.Contains(err.Error(), "does not support logs") {
+		t.Errorf("Expected unsupported logs error, got %v", err)
+	}
+}
+
+func TestPluginManager_Timeout(t *testing.T) {
+	manager := newTestPluginManager(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := manager.GetStatus(ctx, "hang"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+
+	// 超时的请求不影响后续请求
+	if _, err := manager.GetStatus(context.Background(), "appliance"); err != nil {
+		t.Errorf("Expected plugin to keep serving, got %v", err)
+	}
+}
+
+func TestPluginManager_RestartsAfterExit(t *testing.T) {
+	manager := newTestPluginManager(t)
+	ctx := context.Background()
+
+	if err := manager.Start(ctx, "crash"); err == nil || !strings.Contains(err.Error(), "exited") {
+		t.Fatalf("Expected plugin exit error, got %v", err)
+	}
+
+	services, err := manager.ListServices(ctx)
+	if err != nil {
+		t.Fatalf("Expected plugin to be restarted, got %v", err)
+	}
+	if len(services) != 2 {
+		t.Errorf("Expected 2 services, got %d", len(services))
+	}
+}
+
+func TestNewPluginManager_MissingCommand(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	if _, err := NewPluginManager(config.PluginConfig{Name: "missing", Command: "/nonexistent/plugin"}, logger); err == nil {
+		t.Error("Expected missing plugin command to fail")
+	}
+}
+
+func TestServiceTypeNames(t *testing.T) {
+	names := ServiceTypeNames(&config.Config{Plugins: []config.PluginConfig{{Name: "appliance"}}})
+	if len(names) != len(types.BuiltinServiceTypes)+1 {
+		t.Fatalf("Expected built-in types plus plugin, got %v", names)
+	}
+	if names[0] != "systemd" || names[len(names)-1] != "appliance" {
+		t.Errorf("Unexpected names: %v", names)
+	}
+	if len(ServiceTypeNames(nil)) != len(types.BuiltinServiceTypes) {
+		t.Error("Expected nil config to list built-in types only")
+	}
+}
@@ -0,0 +1,464 @@
+package managers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// PodmanPodPrefix marks a service name as a Podman pod rather than a single
+// container, e.g. "pod:webapp".
+const PodmanPodPrefix = "pod:"
+
+// PodmanManager manages Podman containers and pods through the podman CLI.
+// When the server runs as an unprivileged user it sees that user's rootless
+// containers.
+type PodmanManager struct {
+	binary   string
+	rootless bool
+}
+
+// PodmanContainer is one entry of `podman ps -a --format json`.
+type PodmanContainer struct {
+	ID        string            `json:"Id"`
+	Names     []string          `json:"Names"`
+	Image     string            `json:"Image"`
+	State     string            `json:"State"`
+	Status    string            `json:"Status"`
+	Created   int64             `json:"Created"`
+	StartedAt int64             `json:"StartedAt"`
+	Pid       int               `json:"Pid"`
+	Pod       string            `json:"Pod"`
+	PodName   string            `json:"PodName"`
+	IsInfra   bool              `json:"IsInfra"`
+	Labels    map[string]string `json:"Labels"`
+}
+
+// PodmanContainerInspect is the subset of `podman container inspect` we use.
+type PodmanContainerInspect struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	ImageName    string `json:"ImageName"`
+	Pod          string `json:"Pod"`
+	RestartCount int    `json:"RestartCount"`
+	HostConfig   struct {
+		RestartPolicy DockerRestartPolicy `json:"RestartPolicy"`
+	} `json:"HostConfig"`
+	State struct {
+		Status     string        `json:"Status"`
+		Running    bool          `json:"Running"`
+		Pid        int           `json:"Pid"`
+		ExitCode   int           `json:"ExitCode"`
+		OOMKilled  bool          `json:"OOMKilled"`
+		StartedAt  time.Time     `json:"StartedAt"`
+		FinishedAt time.Time     `json:"FinishedAt"`
+		Health     *DockerHealth `json:"Health,omitempty"`
+	} `json:"State"`
+}
+
+// PodmanPod is one entry of `podman pod ps --format json`.
+type PodmanPod struct {
+	ID         string    `json:"Id"`
+	Name       string    `json:"Name"`
+	Status     string    `json:"Status"`
+	Created    time.Time `json:"Created"`
+	Containers []struct {
+		ID     string `json:"Id"`
+		Names  string `json:"Names"`
+		Status string `json:"Status"`
+	} `json:"Containers"`
+}
+
+// PodmanPodInspect is the subset of `podman pod inspect` we use.
+type PodmanPodInspect struct {
+	ID         string    `json:"Id"`
+	Name       string    `json:"Name"`
+	State      string    `json:"State"`
+	Created    time.Time `json:"Created"`
+	Containers []struct {
+		ID    string `json:"Id"`
+		Name  string `json:"Name"`
+		State string `json:"State"`
+	} `json:"Containers"`
+}
+
+func NewPodmanManager() *PodmanManager {
+	return &PodmanManager{
+		binary:   "podman",
+		rootless: os.Geteuid() != 0,
+	}
+}
+
+func (pm *PodmanManager) Start(ctx context.Context, serviceName string) error {
+	_, err := pm.run(ctx, pm.podArgs(serviceName, "start")...)
+	return err
+}
+
+func (pm *PodmanManager) Stop(ctx context.Context, serviceName string) error {
+	_, err := pm.run(ctx, pm.podArgs(serviceName, "stop")...)
+	return err
+}
+
+func (pm *PodmanManager) Restart(ctx context.Context, serviceName string) error {
+	_, err := pm.run(ctx, pm.podArgs(serviceName, "restart")...)
+	return err
+}
+
+func (pm *PodmanManager) Enable(ctx context.Context, serviceName string) error {
+	// Like Docker, "enable" means setting restart policy to always. Podman
+	// honours it at boot through podman-restart.service.
+	return pm.updateRestartPolicy(ctx, serviceName, "always")
+}
+
+func (pm *PodmanManager) Disable(ctx context.Context, serviceName string) error {
+	return pm.updateRestartPolicy(ctx, serviceName, "no")
+}
+
+func (pm *PodmanManager) updateRestartPolicy(ctx context.Context, serviceName, policy string) error {
+	if _, isPod := podmanPodName(serviceName); isPod {
+		return fmt.Errorf("restart policies are set per container, not on pod %s", serviceName)
+	}
+	_, err := pm.run(ctx, "update", "--restart="+policy, serviceName)
+	return err
+}
+
+func (pm *PodmanManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	if pod, isPod := podmanPodName(serviceName); isPod {
+		return pm.getPodStatus(ctx, serviceName, pod)
+	}
+
+	info := types.ServiceInfo{
+		Name: serviceName,
+		Type: types.ServiceTypePodman,
+	}
+
+	output, err := pm.run(ctx, "container", "inspect", serviceName)
+	if err != nil {
+		return info, err
+	}
+
+	var containers []PodmanContainerInspect
+	if err := json.Unmarshal(output, &containers); err != nil {
+		return info, fmt.Errorf("failed to parse podman inspect output: %v", err)
+	}
+	if len(containers) == 0 {
+		return info, fmt.Errorf("container %s not found", serviceName)
+	}
+	container := containers[0]
+
+	info.Status = podmanStateStatus(container.State.Status)
+	info.SubState = container.State.Status
+	if container.State.Pid > 0 {
+		info.PID = container.State.Pid
+	}
+	if startTime := container.State.StartedAt; !startTime.IsZero() {
+		info.LastChanged = startTime
+		if info.Status == types.StatusActive {
+			info.ActiveSince = &startTime
+			info.Uptime = time.Since(startTime)
+		}
+	}
+	if !container.State.FinishedAt.IsZero() && !container.State.Running {
+		exitCode := container.State.ExitCode
+		info.ExitCode = &exitCode
+	}
+	info.Restarts = container.RestartCount
+	info.OOMKilled = container.State.OOMKilled
+	// Enable and Disable set the restart policy, see Enable.
+	switch container.HostConfig.RestartPolicy.Name {
+	case "always", "unless-stopped":
+		info.EnabledState = "enabled"
+	default:
+		info.EnabledState = "disabled"
+	}
+	info.Description = pm.describe(fmt.Sprintf("Podman container from image: %s", container.ImageName))
+	info.Health = engineHealth(info.Name, types.ServiceTypePodman, container.State.Health)
+
+	return info, nil
+}
+
+func (pm *PodmanManager) getPodStatus(ctx context.Context, serviceName, pod string) (types.ServiceInfo, error) {
+	info := types.ServiceInfo{
+		Name: serviceName,
+		Type: types.ServiceTypePodman,
+	}
+
+	output, err := pm.run(ctx, "pod", "inspect", pod)
+	if err != nil {
+		return info, err
+	}
+
+	// podman 4 prints a single object, podman 5 an array.
+	var pods []PodmanPodInspect
+	if trimmed := bytes.TrimSpace(output); len(trimmed) > 0 && trimmed[0] == '{' {
+		var single PodmanPodInspect
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return info, fmt.Errorf("failed to parse podman pod inspect output: %v", err)
+		}
+		pods = append(pods, single)
+	} else if err := json.Unmarshal(trimmed, &pods); err != nil {
+		return info, fmt.Errorf("failed to parse podman pod inspect output: %v", err)
+	}
+	if len(pods) == 0 {
+		return info, fmt.Errorf("pod %s not found", pod)
+	}
+
+	info.Status = podmanPodStatus(pods[0].State)
+	info.LastChanged = pods[0].Created
+	info.Description = pm.describe(fmt.Sprintf("Podman pod with %d containers", len(pods[0].Containers)))
+
+	return info, nil
+}
+
+// ListServices returns every container of the current user plus one entry
+// per pod, named with PodmanPodPrefix. Infra containers are left out.
+func (pm *PodmanManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	output, err := pm.run(ctx, "ps", "-a", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []PodmanContainer
+	if err := json.Unmarshal(output, &containers); err != nil {
+		return nil, fmt.Errorf("failed to parse podman ps output: %v", err)
+	}
+
+	var services []types.ServiceInfo
+	for _, container := range containers {
+		if container.IsInfra {
+			continue
+		}
+
+		var name string
+		if len(container.Names) > 0 {
+			name = container.Names[0]
+		} else if len(container.ID) > 12 {
+			name = container.ID[:12]
+		} else {
+			name = container.ID
+		}
+
+		status := podmanStateStatus(container.State)
+		description := fmt.Sprintf("Podman container from image: %s", container.Image)
+		if container.PodName != "" {
+			description += fmt.Sprintf(" (pod %s)", container.PodName)
+		}
+
+		service := types.ServiceInfo{
+			Name:        name,
+			Type:        types.ServiceTypePodman,
+			Status:      status,
+			Description: pm.describe(description),
+		}
+		if status == types.StatusActive && container.Pid > 0 {
+			service.PID = container.Pid
+		}
+		if container.StartedAt > 0 {
+			service.LastChanged = time.Unix(container.StartedAt, 0)
+		} else if container.Created > 0 {
+			service.LastChanged = time.Unix(container.Created, 0)
+		}
+		if status == types.StatusActive && !service.LastChanged.IsZero() {
+			service.Uptime = time.Since(service.LastChanged)
+		}
+
+		services = append(services, service)
+	}
+
+	pods, err := pm.listPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		services = append(services, types.ServiceInfo{
+			Name:        PodmanPodPrefix + pod.Name,
+			Type:        types.ServiceTypePodman,
+			Status:      podmanPodStatus(pod.Status),
+			Description: pm.describe(fmt.Sprintf("Podman pod with %d containers", len(pod.Containers))),
+			LastChanged: pod.Created,
+		})
+	}
+
+	return services, nil
+}
+
+func (pm *PodmanManager) listPods(ctx context.Context) ([]PodmanPod, error) {
+	output, err := pm.run(ctx, "pod", "ps", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []PodmanPod
+	if len(bytes.TrimSpace(output)) == 0 {
+		return pods, nil
+	}
+	if err := json.Unmarshal(output, &pods); err != nil {
+		return nil, fmt.Errorf("failed to parse podman pod ps output: %v", err)
+	}
+	return pods, nil
+}
+
+// Additional Podman-specific methods
+
+func (pm *PodmanManager) GetLogs(ctx context.Context, serviceName string, lines int) (string, error) {
+	args := []string{"logs"}
+	target := serviceName
+	if pod, isPod := podmanPodName(serviceName); isPod {
+		args = []string{"pod", "logs"}
+		target = pod
+	}
+	if lines > 0 {
+		args = append(args, "--tail", strconv.Itoa(lines))
+	}
+	args = append(args, target)
+
+	output, err := pm.run(ctx, args...)
+	return string(output), err
+}
+
+// GetStats returns `podman stats` for a container. For a pod the per-container
+// entries of `podman pod stats` are returned under "Containers".
+func (pm *PodmanManager) GetStats(ctx context.Context, serviceName string) (map[string]interface{}, error) {
+	if pod, isPod := podmanPodName(serviceName); isPod {
+		output, err := pm.run(ctx, "pod", "stats", "--no-stream", "--format", "json", pod)
+		if err != nil {
+			return nil, err
+		}
+
+		var entries []map[string]interface{}
+		if err := json.Unmarshal(output, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse podman pod stats output: %v", err)
+		}
+		return map[string]interface{}{
+			"Pod":        pod,
+			"Containers": entries,
+		}, nil
+	}
+
+	output, err := pm.run(ctx, "stats", "--no-stream", "--format", "json", serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse podman stats output: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no stats for container %s", serviceName)
+	}
+	return entries[0], nil
+}
+
+// ServiceStats maps `podman stats` to the generic form. The values of a
+// pod are the sums over its containers, with the largest container memory
+// limit as the limit.
+func (pm *PodmanManager) ServiceStats(ctx context.Context, serviceName string) (types.ServiceStats, error) {
+	result, err := pm.GetStats(ctx, serviceName)
+	if err != nil {
+		return types.ServiceStats{}, err
+	}
+	entries := []map[string]interface{}{result}
+	if containers, ok := result["Containers"].([]map[string]interface{}); ok {
+		entries = containers
+	}
+
+	stats := types.ServiceStats{Name: serviceName}
+	for _, entry := range entries {
+		container, err := containerCLIStats(entry)
+		if err != nil {
+			return stats, err
+		}
+		stats.CPUUsageUsec += container.CPUUsageUsec
+		stats.MemoryCurrent += container.MemoryCurrent
+		stats.IOReadBytes += container.IOReadBytes
+		stats.IOWriteBytes += container.IOWriteBytes
+		stats.PidsCurrent += container.PidsCurrent
+		if container.MemoryMax > stats.MemoryMax {
+			stats.MemoryMax = container.MemoryMax
+		}
+	}
+	return stats, nil
+}
+
+// run executes podman and folds its stderr into the returned error.
+func (pm *PodmanManager) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := commandContext(ctx, pm.binary, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("podman %s failed: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("podman %s failed: %v", args[0], err)
+	}
+	return output, nil
+}
+
+func (pm *PodmanManager) podArgs(serviceName, action string) []string {
+	if pod, isPod := podmanPodName(serviceName); isPod {
+		return []string{"pod", action, pod}
+	}
+	return []string{action, serviceName}
+}
+
+func (pm *PodmanManager) describe(description string) string {
+	if pm.rootless {
+		return description + " [rootless]"
+	}
+	return description
+}
+
+func podmanPodName(serviceName string) (string, bool) {
+	if strings.HasPrefix(serviceName, PodmanPodPrefix) {
+		return strings.TrimPrefix(serviceName, PodmanPodPrefix), true
+	}
+	return "", false
+}
+
+// podmanStateStatus maps a container state to a ServiceStatus.
+func podmanStateStatus(state string) types.ServiceStatus {
+	switch strings.ToLower(state) {
+	case "running":
+		return types.StatusActive
+	case "exited", "created", "configured", "initialized", "stopped":
+		return types.StatusInactive
+	case "dead", "restarting":
+		return types.StatusFailed
+	default:
+		return types.StatusUnknown
+	}
+}
+
+// podmanPodStatus maps a pod state to a ServiceStatus. A degraded pod has
+// some but not all of its containers running.
+func podmanPodStatus(state string) types.ServiceStatus {
+	switch strings.ToLower(state) {
+	case "running":
+		return types.StatusActive
+	case "created", "exited", "stopped":
+		return types.StatusInactive
+	case "degraded", "dead", "error":
+		return types.StatusFailed
+	default:
+		return types.StatusUnknown
+	}
+}
+
+func IsPodmanAvailable() bool {
+	cmd := exec.Command("podman", "--version")
+	return cmd.Run() == nil
+}
@@ -0,0 +1,244 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const fakePodmanScript = `#!/bin/sh
+echo "$*" >> "$0.calls"
+case "$*" in
+"ps -a --format json")
+cat <<'EOF'
+[
+  {"Id": "aaaaaaaaaaaaaaaa", "Names": ["web"], "Image": "docker.io/library/nginx:latest", "State": "running", "Created": 1700000000, "StartedAt": 1700000100, "Pid": 321, "PodName": "shop", "IsInfra": false},
+  {"Id": "bbbbbbbbbbbbbbbb", "Names": ["shop-infra"], "Image": "localhost/podman-pause:4.9", "State": "running", "Created": 1700000000, "IsInfra": true},
+  {"Id": "cccccccccccccccc", "Names": ["batch"], "Image": "docker.io/library/busybox", "State": "exited", "Created": 1700000000}
+]
+EOF
+;;
+"pod ps --format json")
+cat <<'EOF'
+[{"Id": "p1", "Name": "shop", "Status": "Degraded", "Created": "2023-11-14T22:13:20Z", "Containers": [{"Id": "aaaa", "Names": "web", "Status": "running"}, {"Id": "dddd", "Names": "worker", "Status": "exited"}]}]
+EOF
+;;
+"container inspect web")
+cat <<'EOF'
+[{"Id": "aaaaaaaaaaaaaaaa", "Name": "web", "ImageName": "docker.io/library/nginx:latest", "RestartCount": 2, "HostConfig": {"RestartPolicy": {"Name": "always"}}, "State": {"Status": "running", "Running": true, "Pid": 321, "StartedAt": "2023-11-14T22:15:00.123456789Z", "FinishedAt": "2023-11-14T22:14:00Z"}}]
+EOF
+;;
+"pod inspect shop")
+cat <<'EOF'
+{"Id": "p1", "Name": "shop", "State": "Running", "Created": "2023-11-14T22:13:20Z", "Containers": [{"Id": "aaaa", "Name": "web", "State": "running"}]}
+EOF
+;;
+"logs --tail 5 web")
+echo "hello from web"
+;;
+"stats --no-stream --format json web")
+echo '[{"id": "aaaaaaaaaaaa", "name": "web", "cpu_time": "1.5s", "cpu_percent": "1.50%", "mem_usage": "10MB / 2GB", "block_io": "4.1kB / 0B", "pids": "3"}]'
+;;
+"pod stats --no-stream --format json shop")
+echo '[{"Pod": "p1", "CID": "aaaa", "Name": "web", "MemUsage": "10MB / 2GB", "BlockIO": "4kB / 1kB", "PIDS": "3"}, {"Pod": "p1", "CID": "dddd", "Name": "worker", "MemUsage": "5MB / 1GB", "BlockIO": "0B / 0B", "PIDS": "2"}]'
+;;
+"start web"|"stop web"|"restart web"|"pod start shop"|"pod stop shop"|"update --restart=always web")
+echo ok
+;;
+*)
+echo "Error: no such object: $*" >&2
+exit 125
+;;
+esac
+`
+
+// newTestPodmanManager 使用一个返回固定输出的假 podman 脚本
+func newTestPodmanManager(t *testing.T) (*PodmanManager, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake podman script requires a POSIX shell")
+	}
+
+	binary := filepath.Join(t.TempDir(), "podman")
+	if err := os.WriteFile(binary, []byte(fakePodmanScript), 0755); err != nil {
+		t.Fatalf("Failed to write fake podman: %v", err)
+	}
+	return &PodmanManager{binary: binary, rootless: true}, binary + ".calls"
+}
+
+func readCalls(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read calls: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestPodmanManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*PodmanManager)(nil)
+}
+
+func TestIsPodmanAvailable(t *testing.T) {
+	t.Logf("Podman available: %t", IsPodmanAvailable())
+}
+
+func TestPodmanManager_ListServices(t *testing.T) {
+	manager, _ := newTestPodmanManager(t)
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	byName := make(map[string]types.ServiceInfo)
+	for _, s := range services {
+		if s.Type != types.ServiceTypePodman {
+			t.Errorf("Expected podman type, got %s", s.Type)
+		}
+		byName[s.Name] = s
+	}
+
+	if _, exists := byName["shop-infra"]; exists {
+		t.Error("Infra containers should not be listed")
+	}
+	if len(byName) != 3 {
+		t.Errorf("Expected web, batch and pod:shop, got %v", byName)
+	}
+
+	web := byName["web"]
+	if web.Status != types.StatusActive || web.PID != 321 {
+		t.Errorf("Unexpected web entry: %+v", web)
+	}
+	if web.Description != "Podman container from image: docker.io/library/nginx:latest (pod shop) [rootless]" {
+		t.Errorf("Unexpected description: %s", web.Description)
+	}
+	if !web.LastChanged.Equal(time.Unix(1700000100, 0)) {
+		t.Errorf("Expected start time as last change, got %s", web.LastChanged)
+	}
+	if byName["batch"].Status != types.StatusInactive {
+		t.Errorf("Expected exited container to be inactive, got %s", byName["batch"].Status)
+	}
+
+	pod := byName["pod:shop"]
+	if pod.Status != types.StatusFailed {
+		t.Errorf("Expected degraded pod to be failed, got %s", pod.Status)
+	}
+	if pod.Description != "Podman pod with 2 containers [rootless]" {
+		t.Errorf("Unexpected pod description: %s", pod.Description)
+	}
+}
+
+func TestPodmanManager_GetStatus(t *testing.T) {
+	manager, _ := newTestPodmanManager(t)
+
+	info, err := manager.GetStatus(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.Status != types.StatusActive || info.PID != 321 {
+		t.Errorf("Unexpected status: %+v", info)
+	}
+	if info.LastChanged.IsZero() || info.Uptime <= 0 {
+		t.Errorf("Expected start time and uptime, got %+v", info)
+	}
+	// 运行中的容器不报告上次退出码
+	if info.SubState != "running" || info.Restarts != 2 || info.ExitCode != nil || info.ActiveSince == nil || !info.ActiveSince.Equal(info.LastChanged) {
+		t.Errorf("Unexpected details: %+v", info)
+	}
+	// 重启策略 always 即为 enabled
+	if info.EnabledState != "enabled" {
+		t.Errorf("Expected the restart policy as enabled state, got %q", info.EnabledState)
+	}
+
+	pod, err := manager.GetStatus(context.Background(), "pod:shop")
+	if err != nil {
+		t.Fatalf("GetStatus for pod failed: %v", err)
+	}
+	if pod.Status != types.StatusActive || pod.Name != "pod:shop" {
+		t.Errorf("Unexpected pod status: %+v", pod)
+	}
+
+	_, err = manager.GetStatus(context.Background(), "ghost")
+	if err == nil || !strings.Contains(err.Error(), "no such object") {
+		t.Errorf("Expected podman error to be surfaced, got %v", err)
+	}
+}
+
+func TestPodmanManager_Operations(t *testing.T) {
+	manager, calls := newTestPodmanManager(t)
+	ctx := context.Background()
+
+	if err := manager.Start(ctx, "web"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := manager.Stop(ctx, "pod:shop"); err != nil {
+		t.Fatalf("Stop pod failed: %v", err)
+	}
+	if err := manager.Enable(ctx, "web"); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	if err := manager.Enable(ctx, "pod:shop"); err == nil {
+		t.Error("Expected enable on a pod to be rejected")
+	}
+
+	expected := []string{"start web", "pod stop shop", "update --restart=always web"}
+	got := readCalls(t, calls)
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected calls %v, got %v", expected, got)
+	}
+}
+
+func TestPodmanManager_LogsAndStats(t *testing.T) {
+	manager, _ := newTestPodmanManager(t)
+
+	logs, err := manager.GetLogs(context.Background(), "web", 5)
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if logs != "hello from web\n" {
+		t.Errorf("Unexpected logs: %q", logs)
+	}
+
+	stats, err := manager.GetStats(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats["cpu_percent"] != "1.50%" {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+}
+
+func TestPodmanManager_ServiceStats(t *testing.T) {
+	manager, _ := newTestPodmanManager(t)
+
+	stats, err := manager.ServiceStats(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("ServiceStats failed: %v", err)
+	}
+	expected := types.ServiceStats{Name: "web", CPUUsageUsec: 1500000, MemoryCurrent: 10e6, MemoryMax: 2e9, IOReadBytes: 4100, PidsCurrent: 3}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Unexpected container stats: %+v", stats)
+	}
+
+	// pod 的统计为各容器之和
+	stats, err = manager.ServiceStats(context.Background(), "pod:shop")
+	if err != nil {
+		t.Fatalf("ServiceStats failed: %v", err)
+	}
+	expected = types.ServiceStats{Name: "pod:shop", MemoryCurrent: 15e6, MemoryMax: 2e9, IOReadBytes: 4000, IOWriteBytes: 1000, PidsCurrent: 5}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Unexpected pod stats: %+v", stats)
+	}
+
+	if _, err := ReadServiceStats(context.Background(), manager, "web"); err != nil {
+		t.Errorf("Expected podman to provide stats, got %v", err)
+	}
+}
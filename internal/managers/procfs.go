@@ -0,0 +1,240 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// defaultProcRoot is where procfs is mounted.
+const defaultProcRoot = "/proc"
+
+// ProcessProvider is implemented by managers that can find the processes
+// of a service.
+type ProcessProvider interface {
+	ServicePIDs(ctx context.Context, serviceName string) ([]int, error)
+}
+
+// ReadServiceProcesses returns the processes of a service as found in
+// /proc, with the sockets each one listens on.
+func ReadServiceProcesses(ctx context.Context, manager types.ServiceManager, serviceName string) ([]types.ProcessInfo, error) {
+	provider, ok := manager.(ProcessProvider)
+	if !ok {
+		return nil, &UnsupportedActionError{Action: "processes"}
+	}
+	pids, err := provider.ServicePIDs(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return inspectProcesses(defaultProcRoot, pids), nil
+}
+
+// inspectProcesses reads the given processes under root, in PID order.
+// Processes that have exited meanwhile are left out.
+func inspectProcesses(root string, pids []int) []types.ProcessInfo {
+	sort.Ints(pids)
+	users := make(map[string]string)
+	// Sockets are looked up once per network namespace.
+	namespaces := make(map[string]map[uint64]types.ListenSocket)
+
+	processes := []types.ProcessInfo{}
+	for i, pid := range pids {
+		if i > 0 && pid == pids[i-1] {
+			continue
+		}
+		info, inodes, err := readProcess(root, pid, users)
+		if err != nil {
+			continue
+		}
+		if len(inodes) > 0 {
+			dir := filepath.Join(root, strconv.Itoa(pid))
+			ns, err := os.Readlink(filepath.Join(dir, "ns", "net"))
+			if err != nil {
+				ns = dir
+			}
+			sockets, ok := namespaces[ns]
+			if !ok {
+				sockets = readListenSockets(filepath.Join(dir, "net"))
+				namespaces[ns] = sockets
+			}
+			for _, inode := range inodes {
+				if socket, ok := sockets[inode]; ok {
+					info.Listening = append(info.Listening, socket)
+				}
+			}
+		}
+		processes = append(processes, info)
+	}
+	return processes
+}
+
+// readProcess reads a process's status, command line and descriptors. It
+// also returns the inodes of the sockets the process has open.
+func readProcess(root string, pid int, users map[string]string) (types.ProcessInfo, []uint64, error) {
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	info := types.ProcessInfo{PID: pid}
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return info, nil, err
+	}
+	var name, uid string
+	for _, line := range strings.Split(string(status), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Name":
+			name = value
+		case "PPid":
+			info.PPID, _ = strconv.Atoi(value)
+		case "Uid":
+			uid, _, _ = strings.Cut(value, "\t")
+		case "VmRSS":
+			kb, _ := strconv.ParseUint(strings.TrimSuffix(value, " kB"), 10, 64)
+			info.RSSBytes = kb * 1024
+		}
+	}
+
+	if uid != "" {
+		if _, ok := users[uid]; !ok {
+			users[uid] = uid
+			if u, err := user.LookupId(uid); err == nil {
+				users[uid] = u.Username
+			}
+		}
+		info.User = users[uid]
+	}
+
+	cmdline, _ := os.ReadFile(filepath.Join(dir, "cmdline"))
+	info.Command = strings.TrimSpace(strings.ReplaceAll(strings.TrimRight(string(cmdline), "\x00"), "\x00", " "))
+	if info.Command == "" {
+		info.Command = "[" + name + "]"
+	}
+
+	var inodes []uint64
+	fds, _ := os.ReadDir(filepath.Join(dir, "fd"))
+	info.OpenFiles = len(fds)
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+			if n, err := strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 64); err == nil {
+				inodes = append(inodes, n)
+			}
+		}
+	}
+	return info, inodes, nil
+}
+
+// readListenSockets reads the listening TCP and bound UDP sockets of a
+// network namespace from its /proc/<pid>/net directory, by inode.
+func readListenSockets(dir string) map[uint64]types.ListenSocket {
+	sockets := make(map[uint64]types.ListenSocket)
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		file, err := os.Open(filepath.Join(dir, protocol))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Scan() // header
+		for scanner.Scan() {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 {
+				continue
+			}
+			listening := fields[3] == "0A" // TCP_LISTEN
+			if strings.HasPrefix(protocol, "udp") {
+				// Unconnected UDP sockets have no remote port.
+				listening = fields[3] == "07" && strings.HasSuffix(fields[2], ":0000")
+			}
+			if !listening {
+				continue
+			}
+			address, port, err := parseProcNetAddress(fields[1])
+			if err != nil {
+				continue
+			}
+			inode, err := strconv.ParseUint(fields[9], 10, 64)
+			if err != nil || inode == 0 {
+				continue
+			}
+			sockets[inode] = types.ListenSocket{Protocol: protocol, Address: address, Port: port}
+		}
+		file.Close()
+	}
+	return sockets
+}
+
+// parseProcNetAddress decodes an address of /proc/net/tcp such as
+// "0100007F:1F90". The address is in host byte order, 32 bits at a time,
+// which is little-endian on the platforms this runs on.
+func parseProcNetAddress(value string) (string, int, error) {
+	hexIP, hexPort, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid address %q", value)
+	}
+	ip, err := hex.DecodeString(hexIP)
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid address %q", value)
+	}
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in %q", value)
+	}
+	return net.IP(ip).String(), int(port), nil
+}
+
+// procDescendants returns pid followed by all of its descendants, found
+// through the parent of every process under root.
+func procDescendants(root string, pid int) []int {
+	entries, _ := os.ReadDir(root)
+	children := make(map[int][]int)
+	for _, entry := range entries {
+		child, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if ppid, ok := procParent(root, child); ok {
+			children[ppid] = append(children[ppid], child)
+		}
+	}
+
+	pids := []int{pid}
+	for i := 0; i < len(pids); i++ {
+		pids = append(pids, children[pids[i]]...)
+	}
+	return pids
+}
+
+func procParent(root string, pid int) (int, bool) {
+	status, err := os.ReadFile(filepath.Join(root, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if value, ok := strings.CutPrefix(line, "PPid:"); ok {
+			ppid, err := strconv.Atoi(strings.TrimSpace(value))
+			return ppid, err == nil
+		}
+	}
+	return 0, false
+}
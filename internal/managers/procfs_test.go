@@ -0,0 +1,162 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// writeFakeProcess 在模拟的 /proc 目录中创建一个进程
+func writeFakeProcess(t *testing.T, root string, pid, ppid int, cmdline string, sockets ...int) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0755); err != nil {
+		t.Fatalf("Failed to create process: %v", err)
+	}
+	status := "Name:\tfake\nPPid:\t" + strconv.Itoa(ppid) + "\nUid:\t0\t0\t0\t0\nVmRSS:\t    2048 kB\n"
+	os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0644)
+	os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0644)
+	os.Symlink("/dev/null", filepath.Join(dir, "fd", "0"))
+	for i, inode := range sockets {
+		os.Symlink("socket:["+strconv.Itoa(inode)+"]", filepath.Join(dir, "fd", strconv.Itoa(i+3)))
+	}
+
+	// 所有进程共享同一网络命名空间
+	os.MkdirAll(filepath.Join(dir, "ns"), 0755)
+	os.Symlink("net:[4026531840]", filepath.Join(dir, "ns", "net"))
+	os.MkdirAll(filepath.Join(dir, "net"), 0755)
+	header := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	os.WriteFile(filepath.Join(dir, "net", "tcp"), []byte(header+
+		"   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0\n"+
+		"   1: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 20 4 30 10 -1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "net", "udp6"), []byte(header+
+		"   0: 00000000000000000000000000000000:0035 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 1003 2 0000000000000000 0\n"), 0644)
+}
+
+func TestInspectProcesses(t *testing.T) {
+	root := t.TempDir()
+	writeFakeProcess(t, root, 100, 1, "nginx: master process\x00", 1001, 1002)
+	writeFakeProcess(t, root, 101, 100, "nginx: worker process\x00", 1003)
+	writeFakeProcess(t, root, 102, 100, "")
+
+	// 已退出的进程被忽略，重复的 PID 只出现一次
+	processes := inspectProcesses(root, []int{101, 100, 999, 102, 100})
+	if len(processes) != 3 {
+		t.Fatalf("Expected 3 processes, got %+v", processes)
+	}
+
+	master := processes[0]
+	if master.PID != 100 || master.PPID != 1 || master.Command != "nginx: master process" || master.RSSBytes != 2<<20 || master.OpenFiles != 3 {
+		t.Errorf("Unexpected master process: %+v", master)
+	}
+	// 已建立的连接不算监听
+	if !reflect.DeepEqual(master.Listening, []types.ListenSocket{{Protocol: "tcp", Address: "0.0.0.0", Port: 8080}}) {
+		t.Errorf("Unexpected master sockets: %+v", master.Listening)
+	}
+	if !reflect.DeepEqual(processes[1].Listening, []types.ListenSocket{{Protocol: "udp6", Address: "::", Port: 53}}) {
+		t.Errorf("Unexpected worker sockets: %+v", processes[1].Listening)
+	}
+	if processes[2].Command != "[fake]" || processes[2].Listening != nil {
+		t.Errorf("Unexpected kernel thread: %+v", processes[2])
+	}
+}
+
+func TestParseProcNetAddress(t *testing.T) {
+	tests := []struct {
+		value   string
+		address string
+		port    int
+	}{
+		{"0100007F:1F90", "127.0.0.1", 8080},
+		{"00000000:0016", "0.0.0.0", 22},
+		{"00000000000000000000000001000000:01BB", "::1", 443},
+	}
+	for _, tt := range tests {
+		address, port, err := parseProcNetAddress(tt.value)
+		if err != nil || address != tt.address || port != tt.port {
+			t.Errorf("parseProcNetAddress(%q) = %s, %d, %v", tt.value, address, port, err)
+		}
+	}
+	if _, _, err := parseProcNetAddress("0100007F"); err == nil {
+		t.Error("Expected an address without a port to fail")
+	}
+}
+
+func TestProcDescendants(t *testing.T) {
+	root := t.TempDir()
+	writeFakeProcess(t, root, 10, 1, "init")
+	writeFakeProcess(t, root, 11, 10, "child")
+	writeFakeProcess(t, root, 12, 11, "grandchild")
+	writeFakeProcess(t, root, 20, 1, "other")
+
+	if pids := procDescendants(root, 10); !reflect.DeepEqual(pids, []int{10, 11, 12}) {
+		t.Errorf("Unexpected descendants: %v", pids)
+	}
+}
+
+func TestSystemdScopeManager_ServicePIDs(t *testing.T) {
+	manager, _ := newTestSystemdScopeManager(t, config.SystemdConfig{})
+	manager.cgroupRoot = t.TempDir()
+	writeFakeCgroup(t, manager.cgroupRoot, "system.slice/app.service", map[string]string{"cgroup.procs": "100\n101\n"})
+	writeFakeCgroup(t, manager.cgroupRoot, "system.slice/app.service/worker", map[string]string{"cgroup.procs": "102\n"})
+	ctx := context.Background()
+
+	pids, err := manager.ServicePIDs(ctx, "app")
+	if err != nil || !reflect.DeepEqual(pids, []int{100, 101, 102}) {
+		t.Errorf("Unexpected PIDs: %v, %v", pids, err)
+	}
+	if _, err := manager.ServicePIDs(ctx, "idle"); err == nil {
+		t.Error("Expected a unit without a cgroup to fail")
+	}
+	if _, err := ReadServiceProcesses(ctx, NewMockManager(types.ServiceTypeSysV), "app"); !IsUnsupportedAction(err) {
+		t.Errorf("Expected an unsupported action error, got %v", err)
+	}
+}
+
+func TestDockerAPIManager_ServicePIDs(t *testing.T) {
+	manager, _ := newTestDockerAPIManager(t)
+
+	pids, err := manager.ServicePIDs(context.Background(), "web")
+	if err != nil || !reflect.DeepEqual(pids, []int{4242, 4243}) {
+		t.Errorf("Unexpected PIDs: %v, %v", pids, err)
+	}
+	if _, err := manager.ServicePIDs(context.Background(), "missing"); err == nil {
+		t.Error("Expected a missing container to fail")
+	}
+}
+
+func TestSysVManager_ServicePIDs(t *testing.T) {
+	manager := NewSysVManager()
+	manager.initDPath = t.TempDir()
+	manager.runDirs = []string{t.TempDir()}
+	manager.procRoot = t.TempDir()
+	writeFakeProcess(t, manager.procRoot, 300, 1, "daemon")
+	writeFakeProcess(t, manager.procRoot, 301, 300, "helper")
+	os.WriteFile(filepath.Join(manager.initDPath, "daemon"), []byte("#!/bin/sh\necho \"daemon is running (pid 300)\"\n"), 0755)
+	os.WriteFile(filepath.Join(manager.initDPath, "other"), []byte("#!/bin/sh\nexit 3\n"), 0755)
+	ctx := context.Background()
+
+	// 没有 pidfile 时使用 status 输出中的 PID
+	pids, err := manager.ServicePIDs(ctx, "daemon")
+	if err != nil || !reflect.DeepEqual(pids, []int{300, 301}) {
+		t.Errorf("Unexpected PIDs from status: %v, %v", pids, err)
+	}
+
+	os.WriteFile(filepath.Join(manager.runDirs[0], "other.pid"), []byte("301\n"), 0644)
+	pids, err = manager.ServicePIDs(ctx, "other")
+	if err != nil || !reflect.DeepEqual(pids, []int{301}) {
+		t.Errorf("Unexpected PIDs from pidfile: %v, %v", pids, err)
+	}
+
+	// 过期的 pidfile 被忽略
+	os.WriteFile(filepath.Join(manager.runDirs[0], "other.pid"), []byte("999\n"), 0644)
+	if _, err := manager.ServicePIDs(ctx, "other"); err == nil {
+		t.Error("Expected a stale pidfile to fail")
+	}
+}
@@ -0,0 +1,77 @@
+package managers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// runitStatusSize is the size of the supervise/status file written by runsv.
+const runitStatusSize = 20
+
+// RunitManager manages services supervised by runsvdir. Status is read from
+// supervise/status directly, control goes through sv.
+type RunitManager struct {
+	supervisionTree
+}
+
+func NewRunitManager() *RunitManager {
+	return newRunitManager("sv", "/etc/sv", firstExistingDir("/var/service", "/etc/service", "/service"))
+}
+
+func newRunitManager(sv, serviceDir, scanDir string) *RunitManager {
+	return &RunitManager{supervisionTree{
+		serviceType: types.ServiceTypeRunit,
+		serviceDir:  serviceDir,
+		scanDir:     scanDir,
+		control: func(action, dir string) []string {
+			return []string{sv, action, dir}
+		},
+		parseStatus: parseRunitStatus,
+	}}
+}
+
+// parseRunitStatus decodes the 20 byte runsv status record: a TAI64N stamp,
+// the little-endian pid, the paused flag, the wanted state ('u' or 'd'), the
+// term flag and the run state (0 down, 1 run, 2 finish).
+func parseRunitStatus(data []byte) (SuperviseStatus, error) {
+	if len(data) < runitStatusSize {
+		return SuperviseStatus{}, fmt.Errorf("invalid runit status: expected %d bytes, got %d", runitStatusSize, len(data))
+	}
+
+	state := data[19]
+	status := SuperviseStatus{
+		Since:     parseTAI64N(data[0:12]),
+		Paused:    data[16] != 0,
+		WantUp:    data[17] == 'u',
+		Up:        state == 1,
+		Finishing: state == 2,
+	}
+	if state != 0 {
+		status.PID = int(binary.LittleEndian.Uint32(data[12:16]))
+	}
+	return status, nil
+}
+
+// IsRunitAvailable reports whether sv is installed and a runit scan
+// directory exists.
+func IsRunitAvailable() bool {
+	if _, err := exec.LookPath("sv"); err != nil {
+		return false
+	}
+	return firstExistingDir("/var/service", "/etc/service", "/service") != ""
+}
+
+// firstExistingDir returns the first of dirs that is a directory, or "" if
+// none is.
+func firstExistingDir(dirs ...string) string {
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
@@ -0,0 +1,242 @@
+package managers
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const fakeSupervisorScript = `#!/bin/sh
+echo "$(basename "$0") $*" >> "$CALLS"
+`
+
+// taiStamp 编码 TAI64N 时间戳
+func taiStamp(t time.Time) []byte {
+	label := make([]byte, 12)
+	binary.BigEndian.PutUint64(label[0:8], uint64(t.Unix())+taiOffset)
+	binary.BigEndian.PutUint32(label[8:12], uint32(t.Nanosecond()))
+	return label
+}
+
+func runitStatus(since time.Time, pid uint32, want byte, state byte) []byte {
+	data := make([]byte, runitStatusSize)
+	copy(data, taiStamp(since))
+	binary.LittleEndian.PutUint32(data[12:16], pid)
+	data[17] = want
+	data[19] = state
+	return data
+}
+
+// writeSupervisedService 在服务目录中创建服务及其 supervise/status 文件
+func writeSupervisedService(t *testing.T, serviceDir, name string, status []byte) {
+	t.Helper()
+	dir := filepath.Join(serviceDir, name)
+	if err := os.MkdirAll(filepath.Join(dir, "supervise"), 0755); err != nil {
+		t.Fatalf("Failed to create service %s: %v", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "run"), []byte("#!/bin/sh\nexec sleep 1000\n"), 0755); err != nil {
+		t.Fatalf("Failed to write run script: %v", err)
+	}
+	if status != nil {
+		if err := os.WriteFile(filepath.Join(dir, "supervise", "status"), status, 0644); err != nil {
+			t.Fatalf("Failed to write status: %v", err)
+		}
+	}
+}
+
+// newTestSupervisionDirs 创建服务目录、扫描目录和记录调用的假控制命令
+func newTestSupervisionDirs(t *testing.T, tools ...string) (string, string, string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake supervision tools require a POSIX shell")
+	}
+
+	root := t.TempDir()
+	serviceDir := filepath.Join(root, "sv")
+	scanDir := filepath.Join(root, "service")
+	binDir := filepath.Join(root, "bin")
+	for _, d := range []string{serviceDir, scanDir, binDir} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", d, err)
+		}
+	}
+	for _, tool := range tools {
+		if err := os.WriteFile(filepath.Join(binDir, tool), []byte(fakeSupervisorScript), 0755); err != nil {
+			t.Fatalf("Failed to write fake %s: %v", tool, err)
+		}
+	}
+
+	calls := filepath.Join(root, "calls")
+	t.Setenv("CALLS", calls)
+	return serviceDir, scanDir, binDir, calls
+}
+
+func newTestRunitManager(t *testing.T) (*RunitManager, string) {
+	t.Helper()
+	serviceDir, scanDir, binDir, calls := newTestSupervisionDirs(t, "sv")
+
+	since := time.Now().Add(-90 * time.Second)
+	writeSupervisedService(t, serviceDir, "sshd", runitStatus(since, 1234, 'u', 1))
+	writeSupervisedService(t, serviceDir, "crond", runitStatus(since, 0, 'd', 0))
+	writeSupervisedService(t, serviceDir, "agetty", runitStatus(since, 0, 'u', 0))
+	writeSupervisedService(t, serviceDir, "nginx", nil)
+
+	for _, name := range []string{"sshd", "crond", "agetty"} {
+		if err := os.Symlink(filepath.Join(serviceDir, name), filepath.Join(scanDir, name)); err != nil {
+			t.Fatalf("Failed to link %s: %v", name, err)
+		}
+	}
+
+	return newRunitManager(filepath.Join(binDir, "sv"), serviceDir, scanDir), calls
+}
+
+func TestRunitManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*RunitManager)(nil)
+}
+
+func TestIsRunitAvailable(t *testing.T) {
+	t.Logf("runit available: %t", IsRunitAvailable())
+}
+
+func TestParseRunitStatus(t *testing.T) {
+	since := time.Unix(1700000000, 500)
+	status, err := parseRunitStatus(runitStatus(since, 4321, 'd', 1))
+	if err != nil {
+		t.Fatalf("parseRunitStatus failed: %v", err)
+	}
+	if !status.Up || status.PID != 4321 || status.WantUp {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if !status.Since.Equal(since) {
+		t.Errorf("Expected since %s, got %s", since, status.Since)
+	}
+
+	finishing, _ := parseRunitStatus(runitStatus(since, 99, 'u', 2))
+	if finishing.Up || !finishing.Finishing {
+		t.Errorf("Expected finishing state, got %+v", finishing)
+	}
+
+	if _, err := parseRunitStatus([]byte("short")); err == nil {
+		t.Error("Expected error for truncated status")
+	}
+}
+
+func TestRunitManager_GetStatus(t *testing.T) {
+	manager, _ := newTestRunitManager(t)
+	ctx := context.Background()
+
+	sshd, err := manager.GetStatus(ctx, "sshd")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if sshd.Type != types.ServiceTypeRunit || sshd.Status != types.StatusActive || sshd.PID != 1234 {
+		t.Errorf("Unexpected sshd status: %+v", sshd)
+	}
+	if sshd.Uptime < 89*time.Second || sshd.LastChanged.IsZero() {
+		t.Errorf("Expected uptime from status stamp, got %+v", sshd)
+	}
+	if sshd.Description != "runit service" || sshd.EnabledState != "enabled" {
+		t.Errorf("Unexpected description or enabled state: %s, %s", sshd.Description, sshd.EnabledState)
+	}
+
+	// 已停止但 want up 表示服务不断退出
+	agetty, _ := manager.GetStatus(ctx, "agetty")
+	if agetty.Status != types.StatusFailed || agetty.Description != "runit service, want up" {
+		t.Errorf("Unexpected agetty status: %+v", agetty)
+	}
+
+	crond, _ := manager.GetStatus(ctx, "crond")
+	if crond.Status != types.StatusInactive || crond.PID != 0 {
+		t.Errorf("Unexpected crond status: %+v", crond)
+	}
+
+	nginx, err := manager.GetStatus(ctx, "nginx")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	// 未链接到扫描目录的服务不会随系统启动
+	if nginx.Status != types.StatusInactive || nginx.EnabledState != "disabled" || nginx.Description != "runit service, not supervised" {
+		t.Errorf("Unexpected nginx status: %+v", nginx)
+	}
+
+	if _, err := manager.GetStatus(ctx, "ghost"); err == nil {
+		t.Error("Expected error for unknown service")
+	}
+}
+
+func TestRunitManager_ListServices(t *testing.T) {
+	manager, _ := newTestRunitManager(t)
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	var names []string
+	for _, s := range services {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "agetty,crond,nginx,sshd" {
+		t.Errorf("Unexpected services: %v", names)
+	}
+}
+
+func TestRunitManager_EnableDisable(t *testing.T) {
+	manager, calls := newTestRunitManager(t)
+	ctx := context.Background()
+
+	if err := manager.Start(ctx, "nginx"); err == nil {
+		t.Error("Expected start of an unlinked service to fail")
+	}
+	if err := manager.Enable(ctx, "nginx"); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	target, err := os.Readlink(filepath.Join(manager.scanDir, "nginx"))
+	if err != nil || target != filepath.Join(manager.serviceDir, "nginx") {
+		t.Errorf("Expected scan dir link to service, got %q, %v", target, err)
+	}
+	// 重复启用不报错
+	if err := manager.Enable(ctx, "nginx"); err != nil {
+		t.Errorf("Second enable failed: %v", err)
+	}
+	if err := manager.Start(ctx, "nginx"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if err := manager.Disable(ctx, "sshd"); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(manager.scanDir, "sshd")); !os.IsNotExist(err) {
+		t.Error("Expected scan dir link to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(manager.serviceDir, "sshd")); err != nil {
+		t.Error("Expected service definition to be kept")
+	}
+
+	// runsvdir 自行轮询扫描目录，只有 Start 会调用 sv
+	got := readCalls(t, calls)
+	expected := "sv up " + filepath.Join(manager.scanDir, "nginx")
+	if len(got) != 1 || got[0] != expected {
+		t.Errorf("Expected %q, got %v", expected, got)
+	}
+
+	if err := manager.Enable(ctx, "../etc"); err == nil {
+		t.Error("Expected path-like service name to be rejected")
+	}
+}
+
+func TestRunitManager_DisableRefusesDirectory(t *testing.T) {
+	manager, _ := newTestRunitManager(t)
+
+	writeSupervisedService(t, manager.scanDir, "local", nil)
+	if err := manager.Disable(context.Background(), "local"); err == nil {
+		t.Error("Expected disable to refuse removing a real directory")
+	}
+}
@@ -0,0 +1,85 @@
+package managers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os/exec"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// s6 has written two status layouts: 35 bytes before s6 2.10 and 43 bytes
+// since, when the process group id was added.
+const (
+	s6StatusSizeLegacy = 35
+	s6StatusSize       = 43
+)
+
+const (
+	s6FlagPaused    = 1 << 0
+	s6FlagFinishing = 1 << 1
+	s6FlagWantUp    = 1 << 2
+)
+
+// S6Manager manages services supervised by s6-svscan. Status is read from
+// supervise/status directly, control goes through s6-svc.
+type S6Manager struct {
+	supervisionTree
+}
+
+func NewS6Manager() *S6Manager {
+	return newS6Manager("s6-svc", "s6-svscanctl", "/etc/s6/sv", firstExistingDir("/run/service", "/service"))
+}
+
+func newS6Manager(svc, svscanctl, serviceDir, scanDir string) *S6Manager {
+	return &S6Manager{supervisionTree{
+		serviceType: types.ServiceTypeS6,
+		serviceDir:  serviceDir,
+		scanDir:     scanDir,
+		control: func(action, dir string) []string {
+			flags := map[string]string{"up": "-u", "down": "-d", "restart": "-ru"}
+			return []string{svc, flags[action], dir}
+		},
+		// s6-svscan only rescans when told to; -n also stops the
+		// supervisors of services whose link was removed.
+		rescan: func(remove bool) []string {
+			if remove {
+				return []string{svscanctl, "-an", scanDir}
+			}
+			return []string{svscanctl, "-a", scanDir}
+		},
+		parseStatus: parseS6Status,
+	}}
+}
+
+// parseS6Status decodes the s6-supervise status record: the TAI64N stamp of
+// the last change, the readiness stamp, the big-endian pid, (since 2.10) the
+// pgid, the wait status and a flags byte.
+func parseS6Status(data []byte) (SuperviseStatus, error) {
+	if len(data) != s6StatusSizeLegacy && len(data) != s6StatusSize {
+		return SuperviseStatus{}, fmt.Errorf("invalid s6 status: unexpected size %d", len(data))
+	}
+
+	flags := data[len(data)-1]
+	status := SuperviseStatus{
+		Since:     parseTAI64N(data[0:12]),
+		PID:       int(binary.BigEndian.Uint64(data[24:32])),
+		Paused:    flags&s6FlagPaused != 0,
+		Finishing: flags&s6FlagFinishing != 0,
+		WantUp:    flags&s6FlagWantUp != 0,
+	}
+	status.Up = status.PID != 0 && !status.Finishing
+	if !status.Up {
+		status.PID = 0
+	}
+	return status, nil
+}
+
+// IsS6Available reports whether s6-svc is installed and an s6 scan
+// directory exists.
+func IsS6Available() bool {
+	if _, err := exec.LookPath("s6-svc"); err != nil {
+		return false
+	}
+	return firstExistingDir("/run/service", "/service") != ""
+}
@@ -0,0 +1,126 @@
+package managers
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+func s6Status(size int, since time.Time, pid uint64, flags byte) []byte {
+	data := make([]byte, size)
+	copy(data, taiStamp(since))
+	copy(data[12:24], taiStamp(since))
+	binary.BigEndian.PutUint64(data[24:32], pid)
+	data[size-1] = flags
+	return data
+}
+
+func newTestS6Manager(t *testing.T) (*S6Manager, string) {
+	t.Helper()
+	serviceDir, scanDir, binDir, calls := newTestSupervisionDirs(t, "s6-svc", "s6-svscanctl")
+
+	since := time.Now().Add(-time.Minute)
+	writeSupervisedService(t, serviceDir, "nginx", s6Status(s6StatusSize, since, 777, s6FlagWantUp))
+	writeSupervisedService(t, serviceDir, "redis", s6Status(s6StatusSizeLegacy, since, 0, s6FlagWantUp))
+	writeSupervisedService(t, serviceDir, "cron", nil)
+
+	for _, name := range []string{"nginx", "redis"} {
+		if err := os.Symlink(filepath.Join(serviceDir, name), filepath.Join(scanDir, name)); err != nil {
+			t.Fatalf("Failed to link %s: %v", name, err)
+		}
+	}
+
+	manager := newS6Manager(filepath.Join(binDir, "s6-svc"), filepath.Join(binDir, "s6-svscanctl"), serviceDir, scanDir)
+	return manager, calls
+}
+
+func TestS6Manager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*S6Manager)(nil)
+}
+
+func TestIsS6Available(t *testing.T) {
+	t.Logf("s6 available: %t", IsS6Available())
+}
+
+func TestParseS6Status(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+
+	for _, size := range []int{s6StatusSizeLegacy, s6StatusSize} {
+		status, err := parseS6Status(s6Status(size, since, 42, s6FlagWantUp|s6FlagPaused))
+		if err != nil {
+			t.Fatalf("parseS6Status(%d bytes) failed: %v", size, err)
+		}
+		if !status.Up || status.PID != 42 || !status.WantUp || !status.Paused {
+			t.Errorf("Unexpected status for %d bytes: %+v", size, status)
+		}
+		if !status.Since.Equal(since) {
+			t.Errorf("Expected since %s, got %s", since, status.Since)
+		}
+	}
+
+	// finish 脚本运行期间 pid 属于 finish 进程，不算运行中
+	finishing, _ := parseS6Status(s6Status(s6StatusSize, since, 42, s6FlagFinishing))
+	if finishing.Up || finishing.PID != 0 || !finishing.Finishing {
+		t.Errorf("Unexpected finishing status: %+v", finishing)
+	}
+
+	if _, err := parseS6Status(make([]byte, 20)); err == nil {
+		t.Error("Expected error for unknown status size")
+	}
+}
+
+func TestS6Manager_GetStatus(t *testing.T) {
+	manager, _ := newTestS6Manager(t)
+	ctx := context.Background()
+
+	nginx, err := manager.GetStatus(ctx, "nginx")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if nginx.Type != types.ServiceTypeS6 || nginx.Status != types.StatusActive || nginx.PID != 777 {
+		t.Errorf("Unexpected nginx status: %+v", nginx)
+	}
+	if nginx.Uptime < 59*time.Second {
+		t.Errorf("Expected uptime of about a minute, got %s", nginx.Uptime)
+	}
+
+	redis, _ := manager.GetStatus(ctx, "redis")
+	if redis.Status != types.StatusFailed || !strings.Contains(redis.Description, "want up") {
+		t.Errorf("Unexpected redis status: %+v", redis)
+	}
+}
+
+func TestS6Manager_Operations(t *testing.T) {
+	manager, calls := newTestS6Manager(t)
+	ctx := context.Background()
+
+	if err := manager.Restart(ctx, "nginx"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	if err := manager.Stop(ctx, "redis"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := manager.Enable(ctx, "cron"); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	if err := manager.Disable(ctx, "redis"); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+
+	expected := []string{
+		"s6-svc -ru " + filepath.Join(manager.scanDir, "nginx"),
+		"s6-svc -d " + filepath.Join(manager.scanDir, "redis"),
+		"s6-svscanctl -a " + manager.scanDir,
+		"s6-svscanctl -an " + manager.scanDir,
+	}
+	got := readCalls(t, calls)
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected calls %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,248 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// ScriptManager drives the services declared under scripts: in config.yaml
+// with their own start, stop, status and reload commands.
+type ScriptManager struct {
+	services map[string]config.ScriptService
+	order    []string
+}
+
+func NewScriptManager(scripts []config.ScriptService) *ScriptManager {
+	sm := &ScriptManager{services: make(map[string]config.ScriptService)}
+	for _, script := range scripts {
+		if _, exists := sm.services[script.Name]; !exists {
+			sm.order = append(sm.order, script.Name)
+		}
+		sm.services[script.Name] = script
+	}
+	return sm
+}
+
+func (sm *ScriptManager) Start(ctx context.Context, serviceName string) error {
+	script, err := sm.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+	_, err = sm.run(ctx, script, "start", script.Start)
+	return err
+}
+
+func (sm *ScriptManager) Stop(ctx context.Context, serviceName string) error {
+	script, err := sm.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+	_, err = sm.run(ctx, script, "stop", script.Stop)
+	return err
+}
+
+// Restart runs the stop command followed by the start command.
+func (sm *ScriptManager) Restart(ctx context.Context, serviceName string) error {
+	if err := sm.Stop(ctx, serviceName); err != nil {
+		return err
+	}
+	return sm.Start(ctx, serviceName)
+}
+
+// Reload runs the service's reload command, if it declares one.
+func (sm *ScriptManager) Reload(ctx context.Context, serviceName string) error {
+	script, err := sm.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+	if script.Reload == "" {
+		return fmt.Errorf("service %s has no reload command", serviceName)
+	}
+	_, err = sm.run(ctx, script, "reload", script.Reload)
+	return err
+}
+
+// Enable is not supported: script services are started by whoever calls
+// Start, not at boot.
+func (sm *ScriptManager) Enable(ctx context.Context, serviceName string) error {
+	if _, err := sm.lookup(serviceName); err != nil {
+		return err
+	}
+	return fmt.Errorf("enable is not supported for script service %s", serviceName)
+}
+
+// Disable is not supported, see Enable.
+func (sm *ScriptManager) Disable(ctx context.Context, serviceName string) error {
+	if _, err := sm.lookup(serviceName); err != nil {
+		return err
+	}
+	return fmt.Errorf("disable is not supported for script service %s", serviceName)
+}
+
+func (sm *ScriptManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	info := types.ServiceInfo{
+		Name: serviceName,
+		Type: types.ServiceTypeScript,
+	}
+
+	script, err := sm.lookup(serviceName)
+	if err != nil {
+		return info, err
+	}
+	info.Description = script.Description
+
+	pid, pidTime := readPIDFile(script.PIDFile)
+	alive := processAlive(pid)
+
+	if script.Status != "" {
+		code, err := sm.run(ctx, script, "status", script.Status)
+		if err != nil && code < 0 {
+			return info, err
+		}
+		info.Status = lsbStatus(code)
+	} else {
+		switch {
+		case alive:
+			info.Status = types.StatusActive
+		case pid > 0:
+			// The pidfile outlived its process.
+			info.Status = types.StatusFailed
+		default:
+			info.Status = types.StatusInactive
+		}
+	}
+
+	if info.Status == types.StatusActive && alive {
+		info.PID = pid
+		info.LastChanged = pidTime
+		info.Uptime = time.Since(pidTime)
+	}
+
+	return info, nil
+}
+
+func (sm *ScriptManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	services := make([]types.ServiceInfo, 0, len(sm.order))
+	for _, name := range sm.order {
+		info, err := sm.GetStatus(ctx, name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			info.Status = types.StatusUnknown
+		}
+		services = append(services, info)
+	}
+	return services, nil
+}
+
+func (sm *ScriptManager) lookup(serviceName string) (config.ScriptService, error) {
+	script, exists := sm.services[serviceName]
+	if !exists {
+		return script, fmt.Errorf("service %s not found", serviceName)
+	}
+	return script, nil
+}
+
+// run executes one of the service's commands in its working directory,
+// environment and user. It returns the exit code, or -1 if the command
+// could not be run at all. Output goes to a temporary file rather than a
+// pipe so that start commands which leave a daemon behind do not block.
+func (sm *ScriptManager) run(ctx context.Context, script config.ScriptService, action, command string) (int, error) {
+	cmd := shellCommand(ctx, command)
+	cmd.Dir = script.WorkDir
+	cmd.Env = commandEnv(script.Env)
+	if script.User != "" {
+		if err := setCommandUser(cmd, script.User); err != nil {
+			return -1, fmt.Errorf("failed to run %s as %s: %v", script.Name, script.User, err)
+		}
+	}
+
+	output, err := os.CreateTemp("", "mcp-script-*.log")
+	if err != nil {
+		return -1, err
+	}
+	defer os.Remove(output.Name())
+	defer output.Close()
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+	if runErr == nil {
+		return 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(runErr, &exitErr) {
+		return -1, fmt.Errorf("failed to %s %s: %v", action, script.Name, runErr)
+	}
+	code := exitErr.ExitCode()
+
+	data, _ := os.ReadFile(output.Name())
+	if msg := strings.TrimSpace(string(data)); msg != "" {
+		return code, fmt.Errorf("%s %s failed (exit status %d): %s", action, script.Name, code, msg)
+	}
+	return code, fmt.Errorf("%s %s failed: exit status %d", action, script.Name, code)
+}
+
+// commandEnv returns the current environment extended with env.
+func commandEnv(env map[string]string) []string {
+	result := os.Environ()
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result = append(result, key+"="+env[key])
+	}
+	return result
+}
+
+// readPIDFile returns the pid stored in path and the file's modification
+// time, or 0 if there is no usable pidfile.
+func readPIDFile(path string) (int, time.Time) {
+	if path == "" {
+		return 0, time.Time{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, time.Time{}
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, time.Time{}
+	}
+	var modTime time.Time
+	if stat, err := os.Stat(path); err == nil {
+		modTime = stat.ModTime()
+	}
+	return pid, modTime
+}
+
+// lsbStatus maps an LSB init script status exit code.
+func lsbStatus(code int) types.ServiceStatus {
+	switch code {
+	case 0:
+		return types.StatusActive
+	case 1, 2:
+		return types.StatusFailed
+	case 3:
+		return types.StatusInactive
+	default:
+		return types.StatusUnknown
+	}
+}
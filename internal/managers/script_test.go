@@ -0,0 +1,183 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// newTestScriptManager 声明两个脚本服务：一个基于 pidfile，一个基于 status 退出码
+func newTestScriptManager(t *testing.T) (*ScriptManager, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("script service tests use /bin/sh")
+	}
+
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "sleeper.pid")
+	manager := NewScriptManager([]config.ScriptService{
+		{
+			Name:        "sleeper",
+			Description: "Background sleep started by a script",
+			// 后台进程继承标准输出，Start 不应因此阻塞
+			Start:   `sleep 30 & echo $! > "$PIDFILE"; echo "started in $(pwd) as $MODE"`,
+			Stop:    `kill "$(cat "$PIDFILE")" && rm -f "$PIDFILE"`,
+			Reload:  `echo reloaded > reload.out`,
+			PIDFile: pidFile,
+			WorkDir: dir,
+			Env:     map[string]string{"PIDFILE": pidFile, "MODE": "test"},
+		},
+		{
+			Name:    "flag",
+			Start:   "touch flag",
+			Stop:    "rm -f flag",
+			Status:  `if [ -f flag ]; then exit 0; elif [ -f dead ]; then exit 1; else exit 3; fi`,
+			WorkDir: dir,
+		},
+	})
+	return manager, dir
+}
+
+func TestScriptManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*ScriptManager)(nil)
+}
+
+func TestScriptManager_PIDFileLifecycle(t *testing.T) {
+	manager, dir := newTestScriptManager(t)
+	ctx := context.Background()
+
+	info, err := manager.GetStatus(ctx, "sleeper")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.Status != types.StatusInactive {
+		t.Errorf("Expected inactive before start, got %s", info.Status)
+	}
+
+	start := time.Now()
+	if err := manager.Start(ctx, "sleeper"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Start blocked on the background process for %s", time.Since(start))
+	}
+	t.Cleanup(func() { manager.Stop(context.Background(), "sleeper") })
+
+	info, err = manager.GetStatus(ctx, "sleeper")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.Status != types.StatusActive || info.PID == 0 || info.LastChanged.IsZero() {
+		t.Errorf("Expected active service with pid, got %+v", info)
+	}
+	if info.Description != "Background sleep started by a script" {
+		t.Errorf("Unexpected description: %s", info.Description)
+	}
+
+	if err := manager.Reload(ctx, "sleeper"); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "reload.out")); err != nil {
+		t.Error("Expected reload to run in the working directory")
+	}
+
+	if err := manager.Stop(ctx, "sleeper"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	info, _ = manager.GetStatus(ctx, "sleeper")
+	if info.Status != types.StatusInactive || info.PID != 0 {
+		t.Errorf("Expected inactive after stop, got %+v", info)
+	}
+}
+
+func TestScriptManager_StalePIDFile(t *testing.T) {
+	manager, dir := newTestScriptManager(t)
+
+	// 一个几乎不可能存在的 pid
+	if err := os.WriteFile(filepath.Join(dir, "sleeper.pid"), []byte("999999999\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pidfile: %v", err)
+	}
+	info, err := manager.GetStatus(context.Background(), "sleeper")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.Status != types.StatusFailed {
+		t.Errorf("Expected stale pidfile to report failed, got %s", info.Status)
+	}
+}
+
+func TestScriptManager_StatusExitCode(t *testing.T) {
+	manager, dir := newTestScriptManager(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		setup func()
+		want  types.ServiceStatus
+	}{
+		{func() {}, types.StatusInactive},
+		{func() { manager.Start(ctx, "flag") }, types.StatusActive},
+		{func() { manager.Stop(ctx, "flag"); os.WriteFile(filepath.Join(dir, "dead"), nil, 0644) }, types.StatusFailed},
+	}
+
+	for i, tt := range tests {
+		tt.setup()
+		info, err := manager.GetStatus(ctx, "flag")
+		if err != nil {
+			t.Fatalf("Case %d: GetStatus failed: %v", i, err)
+		}
+		if info.Status != tt.want {
+			t.Errorf("Case %d: expected %s, got %s", i, tt.want, info.Status)
+		}
+	}
+}
+
+func TestScriptManager_ListServices(t *testing.T) {
+	manager, _ := newTestScriptManager(t)
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(services) != 2 || services[0].Name != "sleeper" || services[1].Name != "flag" {
+		t.Errorf("Expected services in declaration order, got %+v", services)
+	}
+	for _, s := range services {
+		if s.Type != types.ServiceTypeScript {
+			t.Errorf("Expected script type, got %s", s.Type)
+		}
+	}
+}
+
+func TestScriptManager_Errors(t *testing.T) {
+	manager, _ := newTestScriptManager(t)
+	ctx := context.Background()
+
+	if err := manager.Start(ctx, "ghost"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := manager.Reload(ctx, "flag"); err == nil || !strings.Contains(err.Error(), "no reload command") {
+		t.Errorf("Expected missing reload error, got %v", err)
+	}
+	if err := manager.Enable(ctx, "flag"); err == nil {
+		t.Error("Expected enable to be unsupported")
+	}
+
+	failing := NewScriptManager([]config.ScriptService{
+		{Name: "broken", Start: "echo 'port already in use' >&2; exit 2", Stop: "true", Status: "exit 3"},
+		{Name: "nobody", Start: "true", Stop: "true", Status: "exit 0", User: "no-such-user-mcp"},
+	})
+	err := failing.Start(ctx, "broken")
+	if err == nil || !strings.Contains(err.Error(), "port already in use") {
+		t.Errorf("Expected command output in error, got %v", err)
+	}
+	if err := failing.Start(ctx, "nobody"); err == nil || !strings.Contains(err.Error(), "no-such-user-mcp") {
+		t.Errorf("Expected unknown user error, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package managers
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// defaultCgroupRoot is where the cgroup v2 hierarchy is mounted.
+const defaultCgroupRoot = "/sys/fs/cgroup"
+
+// StatsProvider is implemented by managers that can report a service's
+// resource usage.
+type StatsProvider interface {
+	ServiceStats(ctx context.Context, serviceName string) (types.ServiceStats, error)
+}
+
+// ReadServiceStats returns a service's resource usage through the manager's
+// StatsProvider.
+func ReadServiceStats(ctx context.Context, manager types.ServiceManager, serviceName string) (types.ServiceStats, error) {
+	provider, ok := manager.(StatsProvider)
+	if !ok {
+		return types.ServiceStats{}, &UnsupportedActionError{Action: "stats"}
+	}
+	return provider.ServiceStats(ctx, serviceName)
+}
+
+// readCgroupStats reads the cgroup v2 interface files of the cgroup at path
+// below root. Files of controllers that are not enabled for the cgroup are
+// missing and leave their values at zero.
+func readCgroupStats(root, path string) (types.ServiceStats, error) {
+	stats := types.ServiceStats{CGroup: path}
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return stats, fmt.Errorf("no cgroup v2 hierarchy at %s", root)
+	}
+	dir := filepath.Join(root, filepath.FromSlash(path))
+	if _, err := os.Stat(dir); err != nil {
+		return stats, fmt.Errorf("cgroup %s not found", path)
+	}
+
+	r := cgroupReader{dir: dir}
+	cpu := r.keyed("cpu.stat")
+	stats.CPUUsageUsec = cpu["usage_usec"]
+	stats.CPUUserUsec = cpu["user_usec"]
+	stats.CPUSystemUsec = cpu["system_usec"]
+	stats.MemoryCurrent = r.value("memory.current")
+	stats.MemoryPeak = r.value("memory.peak")
+	stats.MemoryMax = r.value("memory.max")
+	stats.PidsCurrent = r.value("pids.current")
+	if events := r.keyed("memory.events"); len(events) > 0 {
+		stats.MemoryEvents = events
+	}
+
+	// io.stat has a line per device: "8:0 rbytes=1024 wbytes=0 rios=1 ..."
+	for _, line := range r.lines("io.stat") {
+		fields := strings.Fields(line)
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			n, _ := strconv.ParseUint(value, 10, 64)
+			switch key {
+			case "rbytes":
+				stats.IOReadBytes += n
+			case "wbytes":
+				stats.IOWriteBytes += n
+			}
+		}
+	}
+	return stats, r.err
+}
+
+// readCgroupPIDs returns the processes of the cgroup at path below root and
+// of its descendant cgroups.
+func readCgroupPIDs(root, path string) ([]int, error) {
+	dir := filepath.Join(root, filepath.FromSlash(path))
+	var pids []int
+	err := filepath.WalkDir(dir, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if current == dir {
+				return fmt.Errorf("cgroup %s not found", path)
+			}
+			// A child cgroup removed while walking.
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		r := cgroupReader{dir: current}
+		for _, line := range r.lines("cgroup.procs") {
+			if pid, err := strconv.Atoi(line); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+		return r.err
+	})
+	return pids, err
+}
+
+// cgroupReader reads the interface files of one cgroup. Missing files read
+// as empty; the first other error is kept in err.
+type cgroupReader struct {
+	dir string
+	err error
+}
+
+func (r *cgroupReader) lines(name string) []string {
+	data, err := os.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		if !os.IsNotExist(err) && r.err == nil {
+			r.err = fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// value reads a single-value file; "max" means no limit and reads as zero.
+func (r *cgroupReader) value(name string) uint64 {
+	lines := r.lines(name)
+	if len(lines) == 0 {
+		return 0
+	}
+	n, _ := strconv.ParseUint(lines[0], 10, 64)
+	return n
+}
+
+// keyed reads a flat keyed file of "key value" lines.
+func (r *cgroupReader) keyed(name string) map[string]uint64 {
+	values := make(map[string]uint64)
+	for _, line := range r.lines(name) {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			values[key] = n
+		}
+	}
+	return values
+}
+
+// sizeUnits are the unit suffixes of the sizes printed by docker and podman
+// stats: decimal for block IO and podman, binary for docker memory.
+var sizeUnits = map[string]float64{
+	"b": 1, "kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40,
+}
+
+// containerCLIStats maps one entry of `docker stats` or `podman stats` JSON
+// output, whose values are rounded human-readable strings. Only podman
+// reports CPU time.
+func containerCLIStats(entry map[string]interface{}) (types.ServiceStats, error) {
+	var stats types.ServiceStats
+	var err error
+	if value := cliStatsField(entry, "MemUsage", "mem_usage"); value != "" {
+		if stats.MemoryCurrent, stats.MemoryMax, err = parseSizePair(value); err != nil {
+			return stats, fmt.Errorf("invalid memory usage: %v", err)
+		}
+	}
+	if value := cliStatsField(entry, "BlockIO", "block_io"); value != "" {
+		if stats.IOReadBytes, stats.IOWriteBytes, err = parseSizePair(value); err != nil {
+			return stats, fmt.Errorf("invalid block IO: %v", err)
+		}
+	}
+	if value := cliStatsField(entry, "PIDs", "PIDS", "pids"); value != "" {
+		if stats.PidsCurrent, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return stats, fmt.Errorf("invalid pids %q", value)
+		}
+	}
+	if value := cliStatsField(entry, "cpu_time"); value != "" {
+		if cpu, err := time.ParseDuration(value); err == nil && cpu > 0 {
+			stats.CPUUsageUsec = uint64(cpu.Microseconds())
+		}
+	}
+	return stats, nil
+}
+
+// cliStatsField returns the first of keys present in entry. "--", printed
+// for a stopped container, reads as missing.
+func cliStatsField(entry map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := entry[key].(string); ok {
+			if value = strings.TrimSpace(value); value != "--" {
+				return value
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// parseSizePair parses a "used / limit" or "read / write" pair of sizes.
+func parseSizePair(value string) (uint64, uint64, error) {
+	first, second, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected two sizes in %q", value)
+	}
+	a, err := parseHumanSize(first)
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := parseHumanSize(second)
+	if err != nil {
+		return 0, 0, err
+	}
+	return a, b, nil
+}
+
+// parseHumanSize parses a size such as "1.5MiB", "12.3kB" or "0B".
+func parseHumanSize(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	split := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if split < 0 {
+		split = len(value)
+	}
+	number, err := strconv.ParseFloat(value[:split], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	unit := 1.0
+	if suffix := strings.ToLower(strings.TrimSpace(value[split:])); suffix != "" {
+		var ok bool
+		if unit, ok = sizeUnits[suffix]; !ok {
+			return 0, fmt.Errorf("invalid size %q", value)
+		}
+	}
+	return uint64(math.Round(number * unit)), nil
+}
@@ -0,0 +1,143 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// writeFakeCgroup 在临时目录中创建 cgroup v2 接口文件
+func writeFakeCgroup(t *testing.T, root, path string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create cgroup: %v", err)
+	}
+	os.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpu io memory pids\n"), 0644)
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+var fakeCgroupFiles = map[string]string{
+	"cpu.stat":       "usage_usec 1500000\nuser_usec 1000000\nsystem_usec 500000\nnr_periods 0\n",
+	"memory.current": "10485760\n",
+	"memory.peak":    "20971520\n",
+	"memory.max":     "max\n",
+	"memory.events":  "low 0\nhigh 2\nmax 1\noom 1\noom_kill 1\n",
+	"io.stat":        "8:0 rbytes=4096 wbytes=1024 rios=1 wios=1 dbytes=0 dios=0\n253:0 rbytes=4096 wbytes=0 rios=1 wios=0\n",
+	"pids.current":   "3\n",
+}
+
+func TestReadCgroupStats(t *testing.T) {
+	root := t.TempDir()
+	writeFakeCgroup(t, root, "system.slice/nginx.service", fakeCgroupFiles)
+
+	stats, err := readCgroupStats(root, "/system.slice/nginx.service")
+	if err != nil {
+		t.Fatalf("readCgroupStats failed: %v", err)
+	}
+	if stats.CPUUsageUsec != 1500000 || stats.CPUUserUsec != 1000000 || stats.CPUSystemUsec != 500000 {
+		t.Errorf("Unexpected CPU stats: %+v", stats)
+	}
+	if stats.MemoryCurrent != 10<<20 || stats.MemoryPeak != 20<<20 || stats.MemoryMax != 0 {
+		t.Errorf("Unexpected memory stats: %+v", stats)
+	}
+	if stats.IOReadBytes != 8192 || stats.IOWriteBytes != 1024 || stats.PidsCurrent != 3 {
+		t.Errorf("Unexpected IO or pids stats: %+v", stats)
+	}
+	if stats.MemoryEvents["oom_kill"] != 1 || stats.MemoryEvents["high"] != 2 || len(stats.MemoryEvents) != 5 {
+		t.Errorf("Unexpected memory events: %v", stats.MemoryEvents)
+	}
+
+	// 未启用的控制器没有对应文件，其值为零
+	writeFakeCgroup(t, root, "system.slice/cron.service", map[string]string{"pids.current": "1\n"})
+	stats, err = readCgroupStats(root, "/system.slice/cron.service")
+	if err != nil || stats.PidsCurrent != 1 || stats.MemoryCurrent != 0 || stats.MemoryEvents != nil {
+		t.Errorf("Unexpected partial stats: %+v, %v", stats, err)
+	}
+
+	if _, err := readCgroupStats(root, "/system.slice/missing.service"); err == nil {
+		t.Error("Expected a missing cgroup to fail")
+	}
+	if _, err := readCgroupStats(t.TempDir(), "/"); err == nil {
+		t.Error("Expected a directory without cgroup v2 to fail")
+	}
+}
+
+func TestSystemdScopeManager_ServiceStats(t *testing.T) {
+	manager, _ := newTestSystemdScopeManager(t, config.SystemdConfig{})
+	manager.cgroupRoot = t.TempDir()
+	writeFakeCgroup(t, manager.cgroupRoot, "system.slice/app.service", fakeCgroupFiles)
+	writeFakeCgroup(t, manager.cgroupRoot, "self.slice/app.service", map[string]string{"pids.current": "7\n"})
+	ctx := context.Background()
+
+	stats, err := ReadServiceStats(ctx, manager, "app")
+	if err != nil {
+		t.Fatalf("ReadServiceStats failed: %v", err)
+	}
+	if stats.Name != "app" || stats.CGroup != "/system.slice/app.service" || stats.MemoryCurrent != 10<<20 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// 用户单元的 cgroup 位于用户管理器之下
+	stats, err = ReadServiceStats(WithScope(ctx, "user"), manager, "app")
+	if err != nil || stats.PidsCurrent != 7 {
+		t.Errorf("Unexpected user unit stats: %+v, %v", stats, err)
+	}
+
+	if _, err := ReadServiceStats(ctx, manager, "idle"); err == nil {
+		t.Error("Expected a unit without a cgroup to fail")
+	}
+	if _, err := ReadServiceStats(ctx, NewMockManager(types.ServiceTypeSysV), "app"); !IsUnsupportedAction(err) {
+		t.Errorf("Expected an unsupported action error, got %v", err)
+	}
+}
+
+func TestDockerAPIManager_ServiceStats(t *testing.T) {
+	manager, _ := newTestDockerAPIManager(t)
+
+	stats, err := manager.ServiceStats(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("ServiceStats failed: %v", err)
+	}
+	if stats.Name != "web" || stats.MemoryCurrent != 2<<20 || stats.MemoryMax != 8<<20 || stats.PidsCurrent != 5 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestContainerCLIStats(t *testing.T) {
+	// docker stats --format json 的输出，内存为二进制单位
+	stats, err := containerCLIStats(map[string]interface{}{
+		"Name": "web", "CPUPerc": "0.50%", "MemUsage": "1.5MiB / 1GiB", "BlockIO": "12.3kB / 0B", "PIDs": "4",
+	})
+	if err != nil {
+		t.Fatalf("containerCLIStats failed: %v", err)
+	}
+	expected := types.ServiceStats{MemoryCurrent: 3 << 19, MemoryMax: 1 << 30, IOReadBytes: 12300, PidsCurrent: 4}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Unexpected docker stats: %+v", stats)
+	}
+
+	// 已停止的容器显示 --
+	if stats, err := containerCLIStats(map[string]interface{}{"MemUsage": "--", "PIDs": "--"}); err != nil || !reflect.DeepEqual(stats, types.ServiceStats{}) {
+		t.Errorf("Expected empty stats for a stopped container, got %+v, %v", stats, err)
+	}
+
+	for _, entry := range []map[string]interface{}{
+		{"MemUsage": "10MB"},
+		{"BlockIO": "1XB / 0B"},
+		{"PIDs": "many"},
+	} {
+		if _, err := containerCLIStats(entry); err == nil {
+			t.Errorf("Expected %v to fail", entry)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package managers
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// taiOffset is the TAI64 label of the Unix epoch (2^62 + 10 seconds), as
+// written by runit and skalibs without a leap second table.
+const taiOffset = 4611686018427387914
+
+// SuperviseStatus is the decoded content of a supervise/status file.
+type SuperviseStatus struct {
+	Up        bool
+	PID       int
+	WantUp    bool
+	Paused    bool
+	Finishing bool
+	// Since is the time of the last state change.
+	Since time.Time
+}
+
+// supervisionTree holds what runit and s6 have in common: a directory of
+// service definitions, a scan directory whose links select the supervised
+// services, and a supervise/status file per running supervisor.
+type supervisionTree struct {
+	serviceType types.ServiceType
+	serviceDir  string
+	scanDir     string
+	// control returns the command line sending action ("up", "down" or
+	// "restart") to the supervisor of the given service directory.
+	control func(action, dir string) []string
+	// rescan, when set, tells the scanner to pick up scan directory changes;
+	// runsvdir polls on its own. remove is true after a link was deleted.
+	rescan      func(remove bool) []string
+	parseStatus func(data []byte) (SuperviseStatus, error)
+}
+
+func (st *supervisionTree) Start(ctx context.Context, serviceName string) error {
+	return st.sendControl(ctx, serviceName, "up")
+}
+
+func (st *supervisionTree) Stop(ctx context.Context, serviceName string) error {
+	return st.sendControl(ctx, serviceName, "down")
+}
+
+func (st *supervisionTree) Restart(ctx context.Context, serviceName string) error {
+	return st.sendControl(ctx, serviceName, "restart")
+}
+
+// Enable links the service definition into the scan directory.
+func (st *supervisionTree) Enable(ctx context.Context, serviceName string) error {
+	if !st.validName(serviceName) {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	target := filepath.Join(st.serviceDir, serviceName)
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return fmt.Errorf("service %s not found in %s", serviceName, st.serviceDir)
+	}
+
+	link := filepath.Join(st.scanDir, serviceName)
+	if _, err := os.Lstat(link); err == nil {
+		return nil
+	}
+	if err := os.Symlink(target, link); err != nil {
+		return fmt.Errorf("failed to link %s into %s: %v", serviceName, st.scanDir, err)
+	}
+	return st.runRescan(ctx, false)
+}
+
+// Disable removes the service's link from the scan directory, which makes
+// the scanner stop its supervisor. Real directories in the scan directory
+// are left alone.
+func (st *supervisionTree) Disable(ctx context.Context, serviceName string) error {
+	if !st.validName(serviceName) {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+
+	link := filepath.Join(st.scanDir, serviceName)
+	info, err := os.Lstat(link)
+	if errors.Is(err, os.ErrNotExist) {
+		if st.serviceExists(serviceName) {
+			return nil
+		}
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("%s is not a link, refusing to remove it", link)
+	}
+	if err := os.Remove(link); err != nil {
+		return fmt.Errorf("failed to unlink %s: %v", link, err)
+	}
+	return st.runRescan(ctx, true)
+}
+
+func (st *supervisionTree) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	info := types.ServiceInfo{
+		Name: serviceName,
+		Type: st.serviceType,
+	}
+
+	if !st.serviceExists(serviceName) {
+		return info, fmt.Errorf("service %s not found", serviceName)
+	}
+
+	enabled := st.isLinked(serviceName)
+	data, err := os.ReadFile(filepath.Join(st.supervisedDir(serviceName), "supervise", "status"))
+	if errors.Is(err, os.ErrNotExist) {
+		// No supervisor has ever run for this service.
+		info.Status = types.StatusInactive
+		info.EnabledState = linkedState(enabled)
+		info.Description = st.describe(nil)
+		return info, nil
+	}
+	if err != nil {
+		return info, fmt.Errorf("failed to read supervise status: %v", err)
+	}
+
+	status, err := st.parseStatus(data)
+	if err != nil {
+		return info, err
+	}
+
+	switch {
+	case status.Up:
+		info.Status = types.StatusActive
+		info.PID = status.PID
+		info.Uptime = time.Since(status.Since)
+	case status.WantUp:
+		// Down although the supervisor wants it up: the service keeps exiting.
+		info.Status = types.StatusFailed
+	default:
+		info.Status = types.StatusInactive
+	}
+	info.LastChanged = status.Since
+	info.EnabledState = linkedState(enabled)
+	info.Description = st.describe(&status)
+
+	return info, nil
+}
+
+// ListServices returns every service defined in the service directory or
+// present in the scan directory.
+func (st *supervisionTree) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	names := make(map[string]bool)
+	for _, dir := range []string{st.serviceDir, st.scanDir} {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if st.validName(entry.Name()) {
+				names[entry.Name()] = true
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var services []types.ServiceInfo
+	for _, name := range sorted {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := st.GetStatus(ctx, name)
+		if err != nil {
+			continue
+		}
+		services = append(services, info)
+	}
+
+	return services, nil
+}
+
+func (st *supervisionTree) sendControl(ctx context.Context, serviceName, action string) error {
+	if !st.serviceExists(serviceName) {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	if !st.isLinked(serviceName) {
+		return fmt.Errorf("service %s is not enabled in %s", serviceName, st.scanDir)
+	}
+	return st.runCommand(ctx, st.control(action, filepath.Join(st.scanDir, serviceName)))
+}
+
+func (st *supervisionTree) runRescan(ctx context.Context, remove bool) error {
+	if st.rescan == nil {
+		return nil
+	}
+	return st.runCommand(ctx, st.rescan(remove))
+}
+
+func (st *supervisionTree) runCommand(ctx context.Context, argv []string) error {
+	cmd := commandContext(ctx, argv[0], argv[1:]...)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%s failed: %s", filepath.Base(argv[0]), msg)
+		}
+		return fmt.Errorf("%s failed: %v", filepath.Base(argv[0]), err)
+	}
+	return nil
+}
+
+// supervisedDir prefers the scan directory entry, since that is the
+// directory the running supervisor was started for.
+func (st *supervisionTree) supervisedDir(serviceName string) string {
+	if _, err := os.Stat(filepath.Join(st.scanDir, serviceName)); err == nil {
+		return filepath.Join(st.scanDir, serviceName)
+	}
+	return filepath.Join(st.serviceDir, serviceName)
+}
+
+func (st *supervisionTree) isLinked(serviceName string) bool {
+	_, err := os.Lstat(filepath.Join(st.scanDir, serviceName))
+	return err == nil
+}
+
+func (st *supervisionTree) serviceExists(serviceName string) bool {
+	if !st.validName(serviceName) {
+		return false
+	}
+	for _, dir := range []string{st.serviceDir, st.scanDir} {
+		if info, err := os.Stat(filepath.Join(dir, serviceName)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+func (st *supervisionTree) validName(serviceName string) bool {
+	return serviceName != "" && !strings.HasPrefix(serviceName, ".") && !strings.ContainsRune(serviceName, '/')
+}
+
+func (st *supervisionTree) describe(status *SuperviseStatus) string {
+	parts := []string{fmt.Sprintf("%s service", st.serviceType)}
+	switch {
+	case status == nil:
+		parts = append(parts, "not supervised")
+	case status.Finishing:
+		parts = append(parts, "finishing")
+	case !status.Up && status.WantUp:
+		parts = append(parts, "want up")
+	case status.Up && !status.WantUp:
+		parts = append(parts, "want down")
+	}
+	if status != nil && status.Paused {
+		parts = append(parts, "paused")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// linkedState is the enabled state of a service: enabled while it is linked
+// into the scan directory.
+func linkedState(linked bool) string {
+	if linked {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// parseTAI64N decodes a 12 byte TAI64N label.
+func parseTAI64N(label []byte) time.Time {
+	secs := binary.BigEndian.Uint64(label[0:8])
+	nanos := binary.BigEndian.Uint32(label[8:12])
+	if secs < taiOffset {
+		return time.Time{}
+	}
+	return time.Unix(int64(secs-taiOffset), int64(nanos))
+}
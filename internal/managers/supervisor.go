@@ -0,0 +1,433 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// supervisord fault codes (supervisor/xmlrpc.py).
+const (
+	supervisorFaultBadName        = 10
+	supervisorFaultAlreadyStarted = 60
+	supervisorFaultNotRunning     = 70
+	supervisorFaultSuccess        = 80
+)
+
+// logTailBytesPerLine is the read-ahead used to fetch the last N lines of a
+// log that can only be read by byte offset.
+const logTailBytesPerLine = 512
+
+// defaultSupervisorURLs are tried in order when SUPERVISOR_SERVER_URL is unset.
+var defaultSupervisorURLs = []string{
+	"unix:///var/run/supervisor.sock",
+	"unix:///run/supervisor.sock",
+	"unix:///tmp/supervisor.sock",
+	"http://127.0.0.1:9001",
+}
+
+// SupervisorProcessInfo is one entry of supervisor.getAllProcessInfo.
+type SupervisorProcessInfo struct {
+	Name          string `json:"name"`
+	Group         string `json:"group"`
+	Description   string `json:"description"`
+	Start         int64  `json:"start"`
+	Stop          int64  `json:"stop"`
+	Now           int64  `json:"now"`
+	State         int    `json:"state"`
+	StateName     string `json:"statename"`
+	SpawnErr      string `json:"spawnerr"`
+	ExitStatus    int    `json:"exitstatus"`
+	StdoutLogfile string `json:"stdout_logfile"`
+	StderrLogfile string `json:"stderr_logfile"`
+	PID           int    `json:"pid"`
+}
+
+// FullName is the group:name form supervisord accepts for every process.
+func (p SupervisorProcessInfo) FullName() string {
+	return p.Group + ":" + p.Name
+}
+
+type supervisorCallResult struct {
+	Name        string `json:"name"`
+	Group       string `json:"group"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+// SupervisorManager manages supervisord programs through its XML-RPC
+// interface. Each process group is a service; a single process can be
+// addressed as "group:process".
+type SupervisorManager struct {
+	rpc *xmlrpcClient
+}
+
+// NewSupervisorManager connects to SUPERVISOR_SERVER_URL, or the usual socket
+// locations and inet port, and verifies supervisord answers.
+// SUPERVISOR_USERNAME and SUPERVISOR_PASSWORD are used for HTTP basic auth.
+func NewSupervisorManager() (*SupervisorManager, error) {
+	candidates := defaultSupervisorURLs
+	if serverURL := os.Getenv("SUPERVISOR_SERVER_URL"); serverURL != "" {
+		candidates = []string{serverURL}
+	}
+
+	var lastErr error
+	for _, serverURL := range candidates {
+		manager, err := newSupervisorManager(serverURL)
+		if err != nil {
+			return nil, err
+		}
+		if manager.rpc.username == "" {
+			manager.rpc.username = os.Getenv("SUPERVISOR_USERNAME")
+			manager.rpc.password = os.Getenv("SUPERVISOR_PASSWORD")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		lastErr = manager.Ping(ctx)
+		cancel()
+		if lastErr == nil {
+			return manager, nil
+		}
+	}
+	return nil, fmt.Errorf("supervisord not reachable: %v", lastErr)
+}
+
+func newSupervisorManager(serverURL string) (*SupervisorManager, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid supervisord URL %q: %v", serverURL, err)
+	}
+
+	transport := &http.Transport{}
+	rpc := &xmlrpcClient{client: &http.Client{Transport: transport}}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		rpc.url = "http://localhost/RPC2"
+	case "http":
+		rpc.url = "http://" + u.Host + "/RPC2"
+	default:
+		return nil, fmt.Errorf("unsupported supervisord URL scheme: %s", u.Scheme)
+	}
+	if u.User != nil {
+		rpc.username = u.User.Username()
+		rpc.password, _ = u.User.Password()
+	}
+
+	return &SupervisorManager{rpc: rpc}, nil
+}
+
+// Ping checks that supervisord is up and running.
+func (sm *SupervisorManager) Ping(ctx context.Context) error {
+	var state struct {
+		StateName string `json:"statename"`
+	}
+	if err := sm.rpc.call(ctx, "supervisor.getState", &state); err != nil {
+		return err
+	}
+	if state.StateName != "RUNNING" {
+		return fmt.Errorf("supervisord is %s", state.StateName)
+	}
+	return nil
+}
+
+func (sm *SupervisorManager) Start(ctx context.Context, serviceName string) error {
+	if strings.Contains(serviceName, ":") {
+		err := sm.rpc.call(ctx, "supervisor.startProcess", nil, serviceName, true)
+		if isXMLRPCFault(err, supervisorFaultAlreadyStarted) {
+			return nil
+		}
+		return sm.wrap(serviceName, err)
+	}
+
+	var results []supervisorCallResult
+	if err := sm.rpc.call(ctx, "supervisor.startProcessGroup", &results, serviceName, true); err != nil {
+		return sm.wrap(serviceName, err)
+	}
+	return checkSupervisorResults(results, supervisorFaultAlreadyStarted)
+}
+
+func (sm *SupervisorManager) Stop(ctx context.Context, serviceName string) error {
+	if strings.Contains(serviceName, ":") {
+		err := sm.rpc.call(ctx, "supervisor.stopProcess", nil, serviceName, true)
+		if isXMLRPCFault(err, supervisorFaultNotRunning) {
+			return nil
+		}
+		return sm.wrap(serviceName, err)
+	}
+
+	var results []supervisorCallResult
+	if err := sm.rpc.call(ctx, "supervisor.stopProcessGroup", &results, serviceName, true); err != nil {
+		return sm.wrap(serviceName, err)
+	}
+	return checkSupervisorResults(results, supervisorFaultNotRunning)
+}
+
+func (sm *SupervisorManager) Restart(ctx context.Context, serviceName string) error {
+	if err := sm.Stop(ctx, serviceName); err != nil {
+		return err
+	}
+	return sm.Start(ctx, serviceName)
+}
+
+// Enable is not supported: whether a program starts with supervisord is the
+// autostart option in its configuration file.
+func (sm *SupervisorManager) Enable(ctx context.Context, serviceName string) error {
+	return fmt.Errorf("enable is not supported for supervisord programs: set autostart=true in the [program:%s] section", serviceName)
+}
+
+// Disable is not supported, see Enable.
+func (sm *SupervisorManager) Disable(ctx context.Context, serviceName string) error {
+	return fmt.Errorf("disable is not supported for supervisord programs: set autostart=false in the [program:%s] section", serviceName)
+}
+
+func (sm *SupervisorManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	processes, err := sm.processes(ctx, serviceName)
+	if err != nil {
+		return types.ServiceInfo{Name: serviceName, Type: types.ServiceTypeSupervisor}, err
+	}
+	return supervisorServiceInfo(serviceName, processes), nil
+}
+
+// ListServices returns one service per process group.
+func (sm *SupervisorManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	all, err := sm.allProcessInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	groups := make(map[string][]SupervisorProcessInfo)
+	for _, process := range all {
+		if _, seen := groups[process.Group]; !seen {
+			order = append(order, process.Group)
+		}
+		groups[process.Group] = append(groups[process.Group], process)
+	}
+
+	services := make([]types.ServiceInfo, 0, len(order))
+	for _, group := range order {
+		services = append(services, supervisorServiceInfo(group, groups[group]))
+	}
+	return services, nil
+}
+
+// GetLogs returns the last lines of a process's stdout log.
+func (sm *SupervisorManager) GetLogs(ctx context.Context, serviceName string, lines int) (string, error) {
+	return sm.TailLog(ctx, serviceName, "stdout", lines)
+}
+
+// TailLog returns the last lines of a process's stdout or stderr log. A
+// group name is accepted when the group has a single process.
+func (sm *SupervisorManager) TailLog(ctx context.Context, serviceName, stream string, lines int) (string, error) {
+	method := "supervisor.readProcessStdoutLog"
+	switch stream {
+	case "", "stdout":
+	case "stderr":
+		method = "supervisor.readProcessStderrLog"
+	default:
+		return "", fmt.Errorf("unknown log stream %q, expected stdout or stderr", stream)
+	}
+	if lines <= 0 {
+		lines = 100
+	}
+
+	processes, err := sm.processes(ctx, serviceName)
+	if err != nil {
+		return "", err
+	}
+	if len(processes) != 1 {
+		return "", fmt.Errorf("group %s has %d processes, use group:process to select one", serviceName, len(processes))
+	}
+
+	// A negative offset with zero length reads that many bytes from the end.
+	var data string
+	offset := -lines * logTailBytesPerLine
+	if err := sm.rpc.call(ctx, method, &data, processes[0].FullName(), offset, 0); err != nil {
+		return "", sm.wrap(serviceName, err)
+	}
+	return lastLines(data, lines, len(data) >= -offset), nil
+}
+
+func (sm *SupervisorManager) allProcessInfo(ctx context.Context) ([]SupervisorProcessInfo, error) {
+	var processes []SupervisorProcessInfo
+	if err := sm.rpc.call(ctx, "supervisor.getAllProcessInfo", &processes); err != nil {
+		return nil, fmt.Errorf("failed to list supervisord processes: %v", err)
+	}
+	return processes, nil
+}
+
+// processes returns the processes a service name refers to: one process for
+// "group:process", every process of the group otherwise.
+func (sm *SupervisorManager) processes(ctx context.Context, serviceName string) ([]SupervisorProcessInfo, error) {
+	if strings.Contains(serviceName, ":") {
+		var process SupervisorProcessInfo
+		if err := sm.rpc.call(ctx, "supervisor.getProcessInfo", &process, serviceName); err != nil {
+			return nil, sm.wrap(serviceName, err)
+		}
+		return []SupervisorProcessInfo{process}, nil
+	}
+
+	all, err := sm.allProcessInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var processes []SupervisorProcessInfo
+	for _, process := range all {
+		if process.Group == serviceName {
+			processes = append(processes, process)
+		}
+	}
+	if len(processes) == 0 {
+		return nil, fmt.Errorf("service %s not found", serviceName)
+	}
+	return processes, nil
+}
+
+func (sm *SupervisorManager) wrap(serviceName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if isXMLRPCFault(err, supervisorFaultBadName) {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	return fmt.Errorf("supervisord: %v", err)
+}
+
+func isXMLRPCFault(err error, code int) bool {
+	var fault *XMLRPCFault
+	return errors.As(err, &fault) && fault.Code == code
+}
+
+// checkSupervisorResults turns failed per-process results of a group call
+// into an error, ignoring the status that means "nothing to do".
+func checkSupervisorResults(results []supervisorCallResult, ignore int) error {
+	var failures []string
+	for _, result := range results {
+		if result.Status != supervisorFaultSuccess && result.Status != ignore {
+			failures = append(failures, fmt.Sprintf("%s:%s: %s", result.Group, result.Name, result.Description))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("supervisord: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func supervisorServiceInfo(name string, processes []SupervisorProcessInfo) types.ServiceInfo {
+	info := types.ServiceInfo{
+		Name: name,
+		Type: types.ServiceTypeSupervisor,
+	}
+
+	statuses := make([]types.ServiceStatus, 0, len(processes))
+	var summary []string
+	for _, process := range processes {
+		statuses = append(statuses, supervisorStateStatus(process))
+		summary = append(summary, fmt.Sprintf("%s %s", process.Name, process.StateName))
+
+		changed := process.Start
+		if process.Stop > changed {
+			changed = process.Stop
+		}
+		if changed > 0 && time.Unix(changed, 0).After(info.LastChanged) {
+			info.LastChanged = time.Unix(changed, 0)
+		}
+	}
+	info.Status = supervisorGroupStatus(statuses)
+
+	if len(processes) == 1 {
+		process := processes[0]
+		info.PID = process.PID
+		if process.StateName == "RUNNING" && process.Start > 0 {
+			info.Uptime = time.Since(time.Unix(process.Start, 0))
+		}
+		info.Description = process.Description
+		if process.SpawnErr != "" {
+			info.Description = process.SpawnErr
+		}
+		if process.StateName == "EXITED" || process.StateName == "FATAL" {
+			exitCode := process.ExitStatus
+			info.ExitCode = &exitCode
+		}
+	} else {
+		info.Description = fmt.Sprintf("%d processes: %s", len(processes), strings.Join(summary, ", "))
+	}
+
+	return info
+}
+
+// supervisorStateStatus maps a supervisord process state. EXITED with a
+// non-zero status and the BACKOFF/FATAL states are failures.
+func supervisorStateStatus(process SupervisorProcessInfo) types.ServiceStatus {
+	switch process.StateName {
+	case "RUNNING":
+		return types.StatusActive
+	case "STOPPED", "STOPPING":
+		return types.StatusInactive
+	case "EXITED":
+		if process.ExitStatus != 0 {
+			return types.StatusFailed
+		}
+		return types.StatusInactive
+	case "BACKOFF", "FATAL":
+		return types.StatusFailed
+	default:
+		return types.StatusUnknown
+	}
+}
+
+// supervisorGroupStatus is failed if any process failed, active if any
+// runs and inactive if all are stopped.
+func supervisorGroupStatus(statuses []types.ServiceStatus) types.ServiceStatus {
+	active, inactive := 0, 0
+	for _, status := range statuses {
+		switch status {
+		case types.StatusFailed:
+			return types.StatusFailed
+		case types.StatusActive:
+			active++
+		case types.StatusInactive:
+			inactive++
+		}
+	}
+	switch {
+	case active > 0:
+		return types.StatusActive
+	case inactive > 0 && inactive == len(statuses):
+		return types.StatusInactive
+	default:
+		return types.StatusUnknown
+	}
+}
+
+// lastLines returns the last n lines of data. When data was cut at the start
+// the first, partial line is dropped.
+func lastLines(data string, n int, truncated bool) string {
+	if truncated {
+		if i := strings.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	trimmed := strings.TrimSuffix(data, "\n")
+	if trimmed == "" {
+		return ""
+	}
+	lines := strings.Split(trimmed, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,358 @@
+package managers
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// fakeSupervisord 是一个进程内的 supervisord XML-RPC 模拟服务器
+type fakeSupervisord struct {
+	mu        sync.Mutex
+	processes []map[string]interface{}
+	logs      map[string]string
+	calls     []string
+	username  string
+	password  string
+}
+
+func newFakeSupervisord() *fakeSupervisord {
+	now := time.Now().Unix()
+	process := func(group, name, state string, pid int, exitStatus int) map[string]interface{} {
+		return map[string]interface{}{
+			"name": name, "group": group, "statename": state, "pid": pid,
+			"start": int(now - 120), "stop": 0, "now": int(now), "exitstatus": exitStatus,
+			"description": fmt.Sprintf("pid %d, uptime 0:02:00", pid), "spawnerr": "",
+		}
+	}
+	return &fakeSupervisord{
+		processes: []map[string]interface{}{
+			process("web", "web", "RUNNING", 100, 0),
+			process("workers", "worker_00", "RUNNING", 201, 0),
+			process("workers", "worker_01", "FATAL", 0, 127),
+			process("cron", "cron", "STOPPED", 0, 0),
+		},
+		logs: map[string]string{
+			"web:web":     "line 1\nline 2\nline 3\n",
+			"web:web:err": "Traceback (most recent call last)\n",
+		},
+	}
+}
+
+func (f *fakeSupervisord) find(name string) map[string]interface{} {
+	for _, p := range f.processes {
+		if p["group"].(string)+":"+p["name"].(string) == name || (p["group"] == name && p["name"] == name) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (f *fakeSupervisord) dispatch(method string, params []interface{}) (interface{}, *XMLRPCFault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, method)
+
+	badName := &XMLRPCFault{Code: supervisorFaultBadName, Message: "BAD_NAME"}
+	switch method {
+	case "supervisor.getState":
+		return map[string]interface{}{"statecode": 1, "statename": "RUNNING"}, nil
+	case "supervisor.getAllProcessInfo":
+		list := make([]interface{}, 0, len(f.processes))
+		for _, p := range f.processes {
+			list = append(list, p)
+		}
+		return list, nil
+	case "supervisor.getProcessInfo":
+		p := f.find(params[0].(string))
+		if p == nil {
+			return nil, badName
+		}
+		return p, nil
+	case "supervisor.startProcess", "supervisor.stopProcess":
+		p := f.find(params[0].(string))
+		if p == nil {
+			return nil, badName
+		}
+		if method == "supervisor.startProcess" {
+			if p["statename"] == "RUNNING" {
+				return nil, &XMLRPCFault{Code: supervisorFaultAlreadyStarted, Message: "ALREADY_STARTED"}
+			}
+			p["statename"] = "RUNNING"
+		} else {
+			if p["statename"] != "RUNNING" {
+				return nil, &XMLRPCFault{Code: supervisorFaultNotRunning, Message: "NOT_RUNNING"}
+			}
+			p["statename"] = "STOPPED"
+		}
+		return true, nil
+	case "supervisor.startProcessGroup", "supervisor.stopProcessGroup":
+		var results []interface{}
+		for _, p := range f.processes {
+			if p["group"] != params[0] {
+				continue
+			}
+			status := supervisorFaultSuccess
+			if method == "supervisor.startProcessGroup" {
+				if p["statename"] == "RUNNING" {
+					status = supervisorFaultAlreadyStarted
+				} else if p["statename"] == "FATAL" {
+					status = 50
+				}
+			}
+			results = append(results, map[string]interface{}{
+				"name": p["name"], "group": p["group"], "status": status, "description": "SPAWN_ERROR",
+			})
+		}
+		if results == nil {
+			return nil, badName
+		}
+		return results, nil
+	case "supervisor.readProcessStdoutLog", "supervisor.readProcessStderrLog":
+		name := params[0].(string)
+		if method == "supervisor.readProcessStderrLog" {
+			name += ":err"
+		}
+		data, ok := f.logs[name]
+		if !ok {
+			return nil, badName
+		}
+		if offset := -params[1].(int); offset < len(data) {
+			data = data[len(data)-offset:]
+		}
+		return data, nil
+	}
+	return nil, &XMLRPCFault{Code: 1, Message: "UNKNOWN_METHOD"}
+}
+
+func (f *fakeSupervisord) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.username != "" {
+		if user, pass, ok := r.BasicAuth(); !ok || user != f.username || pass != f.password {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var call xmlrpcMethodCall
+	if err := xml.NewDecoder(r.Body).Decode(&call); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params := make([]interface{}, 0, len(call.Params))
+	for _, p := range call.Params {
+		params = append(params, p.decode())
+	}
+
+	result, fault := f.dispatch(call.MethodName, params)
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0"?><methodResponse>`)
+	if fault != nil {
+		body.WriteString("<fault>")
+		writeXMLRPCValue(&body, map[string]interface{}{"faultCode": fault.Code, "faultString": fault.Message})
+		body.WriteString("</fault>")
+	} else {
+		body.WriteString("<params><param>")
+		writeXMLRPCValue(&body, result)
+		body.WriteString("</param></params>")
+	}
+	body.WriteString("</methodResponse>")
+	w.Header().Set("Content-Type", "text/xml")
+	w.Write(body.Bytes())
+}
+
+// newTestSupervisorManager 在临时 unix socket 上启动模拟的 supervisord
+func newTestSupervisorManager(t *testing.T, fake *fakeSupervisord) *SupervisorManager {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "supervisor.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen on %s: %v", socket, err)
+	}
+	srv := &http.Server{Handler: fake}
+	go srv.Serve(listener)
+	t.Cleanup(func() { srv.Close() })
+
+	manager, err := newSupervisorManager("unix://" + socket)
+	if err != nil {
+		t.Fatalf("Failed to create supervisor manager: %v", err)
+	}
+	if err := manager.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	return manager
+}
+
+func TestSupervisorManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*SupervisorManager)(nil)
+}
+
+func TestSupervisorManager_ListServices(t *testing.T) {
+	manager := newTestSupervisorManager(t, newFakeSupervisord())
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	var names []string
+	byName := make(map[string]types.ServiceInfo)
+	for _, s := range services {
+		if s.Type != types.ServiceTypeSupervisor {
+			t.Errorf("Expected supervisor type, got %s", s.Type)
+		}
+		names = append(names, s.Name)
+		byName[s.Name] = s
+	}
+	if strings.Join(names, ",") != "web,workers,cron" {
+		t.Errorf("Expected one service per group, got %v", names)
+	}
+
+	web := byName["web"]
+	if web.Status != types.StatusActive || web.PID != 100 || web.Uptime < 119*time.Second {
+		t.Errorf("Unexpected web entry: %+v", web)
+	}
+	// 组内任一进程 FATAL 即视为失败
+	workers := byName["workers"]
+	if workers.Status != types.StatusFailed {
+		t.Errorf("Expected group with a FATAL process to be failed, got %s", workers.Status)
+	}
+	if workers.Description != "2 processes: worker_00 RUNNING, worker_01 FATAL" {
+		t.Errorf("Unexpected group description: %s", workers.Description)
+	}
+	if byName["cron"].Status != types.StatusInactive {
+		t.Errorf("Expected stopped program to be inactive, got %s", byName["cron"].Status)
+	}
+}
+
+func TestSupervisorManager_GetStatus(t *testing.T) {
+	manager := newTestSupervisorManager(t, newFakeSupervisord())
+	ctx := context.Background()
+
+	worker, err := manager.GetStatus(ctx, "workers:worker_00")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if worker.Status != types.StatusActive || worker.PID != 201 || worker.ExitCode != nil {
+		t.Errorf("Unexpected process status: %+v", worker)
+	}
+
+	// 已退出或放弃启动的进程报告最后一次退出码
+	fatal, err := manager.GetStatus(ctx, "workers:worker_01")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if fatal.Status != types.StatusFailed || fatal.ExitCode == nil || *fatal.ExitCode != 127 {
+		t.Errorf("Expected exit code 127 for the FATAL process, got %+v", fatal)
+	}
+
+	if _, err := manager.GetStatus(ctx, "ghost"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if _, err := manager.GetStatus(ctx, "web:ghost"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected BAD_NAME fault to map to not found, got %v", err)
+	}
+}
+
+func TestSupervisorManager_Operations(t *testing.T) {
+	fake := newFakeSupervisord()
+	manager := newTestSupervisorManager(t, fake)
+	ctx := context.Background()
+
+	// ALREADY_STARTED 和 NOT_RUNNING 不视为错误
+	if err := manager.Start(ctx, "web:web"); err != nil {
+		t.Errorf("Start of running process failed: %v", err)
+	}
+	if err := manager.Stop(ctx, "cron:cron"); err != nil {
+		t.Errorf("Stop of stopped process failed: %v", err)
+	}
+	if err := manager.Restart(ctx, "web:web"); err != nil {
+		t.Errorf("Restart failed: %v", err)
+	}
+
+	err := manager.Start(ctx, "workers")
+	if err == nil || !strings.Contains(err.Error(), "workers:worker_01: SPAWN_ERROR") {
+		t.Errorf("Expected spawn error for FATAL process, got %v", err)
+	}
+	if err := manager.Stop(ctx, "workers"); err != nil {
+		t.Errorf("Stop group failed: %v", err)
+	}
+
+	if err := manager.Enable(ctx, "web"); err == nil || !strings.Contains(err.Error(), "autostart") {
+		t.Errorf("Expected enable to explain autostart, got %v", err)
+	}
+}
+
+func TestSupervisorManager_TailLog(t *testing.T) {
+	manager := newTestSupervisorManager(t, newFakeSupervisord())
+	ctx := context.Background()
+
+	logs, err := manager.GetLogs(ctx, "web", 2)
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if logs != "line 2\nline 3\n" {
+		t.Errorf("Unexpected log tail: %q", logs)
+	}
+
+	stderr, err := manager.TailLog(ctx, "web:web", "stderr", 10)
+	if err != nil {
+		t.Fatalf("TailLog stderr failed: %v", err)
+	}
+	if !strings.HasPrefix(stderr, "Traceback") {
+		t.Errorf("Unexpected stderr tail: %q", stderr)
+	}
+
+	if _, err := manager.GetLogs(ctx, "workers", 10); err == nil || !strings.Contains(err.Error(), "group:process") {
+		t.Errorf("Expected multi-process group to require a process name, got %v", err)
+	}
+	if _, err := manager.TailLog(ctx, "web", "stdin", 10); err == nil {
+		t.Error("Expected unknown stream to be rejected")
+	}
+}
+
+func TestSupervisorManager_BasicAuth(t *testing.T) {
+	fake := newFakeSupervisord()
+	fake.username, fake.password = "admin", "s3cret"
+
+	socket := filepath.Join(t.TempDir(), "supervisor.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := &http.Server{Handler: fake}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	anonymous, _ := newSupervisorManager("unix://" + socket)
+	if err := anonymous.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected 401 without credentials, got %v", err)
+	}
+
+	authed, _ := newSupervisorManager("unix://admin:s3cret@" + socket)
+	if err := authed.Ping(context.Background()); err != nil {
+		t.Errorf("Ping with credentials failed: %v", err)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	if got := lastLines("partial\nb\nc\n", 5, true); got != "b\nc\n" {
+		t.Errorf("Expected partial first line to be dropped, got %q", got)
+	}
+	if got := lastLines("a\nb\nc", 2, false); got != "b\nc\n" {
+		t.Errorf("Unexpected tail: %q", got)
+	}
+	if got := lastLines("", 2, false); got != "" {
+		t.Errorf("Expected empty tail, got %q", got)
+	}
+}
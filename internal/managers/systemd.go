@@ -2,6 +2,9 @@ package managers
 
 import (
 	"bufio"
+	"context"
+	"fmt"
+	"math"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -10,50 +13,76 @@ import (
 	"nucc.com/mcp_srv_mgr/pkg/types"
 )
 
-type SystemdManager struct{}
+// SystemdManager drives systemd through systemctl. It targets the system
+// manager unless it was created for a user scope.
+type SystemdManager struct {
+	systemctl string
+	scope     SystemdScope
+}
 
 func NewSystemdManager() *SystemdManager {
-	return &SystemdManager{}
+	return newSystemdManager("systemctl", SystemdScope{})
+}
+
+func newSystemdManager(systemctl string, scope SystemdScope) *SystemdManager {
+	return &SystemdManager{systemctl: systemctl, scope: scope}
+}
+
+// command runs systemctl against the manager's scope.
+func (sm *SystemdManager) command(ctx context.Context, args ...string) *exec.Cmd {
+	return commandContext(ctx, sm.systemctl, append(sm.scope.systemctlArgs(), args...)...)
+}
+
+func (sm *SystemdManager) Start(ctx context.Context, serviceName string) error {
+	cmd := sm.command(ctx, "start", serviceName)
+	return cmd.Run()
 }
 
-func (sm *SystemdManager) Start(serviceName string) error {
-	cmd := exec.Command("systemctl", "start", serviceName)
+func (sm *SystemdManager) Stop(ctx context.Context, serviceName string) error {
+	cmd := sm.command(ctx, "stop", serviceName)
 	return cmd.Run()
 }
 
-func (sm *SystemdManager) Stop(serviceName string) error {
-	cmd := exec.Command("systemctl", "stop", serviceName)
+func (sm *SystemdManager) Restart(ctx context.Context, serviceName string) error {
+	cmd := sm.command(ctx, "restart", serviceName)
 	return cmd.Run()
 }
 
-func (sm *SystemdManager) Restart(serviceName string) error {
-	cmd := exec.Command("systemctl", "restart", serviceName)
+func (sm *SystemdManager) Enable(ctx context.Context, serviceName string) error {
+	cmd := sm.command(ctx, "enable", serviceName)
 	return cmd.Run()
 }
 
-func (sm *SystemdManager) Enable(serviceName string) error {
-	cmd := exec.Command("systemctl", "enable", serviceName)
+func (sm *SystemdManager) Disable(ctx context.Context, serviceName string) error {
+	cmd := sm.command(ctx, "disable", serviceName)
 	return cmd.Run()
 }
 
-func (sm *SystemdManager) Disable(serviceName string) error {
-	cmd := exec.Command("systemctl", "disable", serviceName)
+// DaemonReload makes systemd re-read unit files.
+func (sm *SystemdManager) DaemonReload(ctx context.Context) error {
+	cmd := sm.command(ctx, "daemon-reload")
 	return cmd.Run()
 }
 
-func (sm *SystemdManager) GetStatus(serviceName string) (types.ServiceInfo, error) {
+func (sm *SystemdManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
 	info := types.ServiceInfo{
-		Name: serviceName,
-		Type: types.ServiceTypeSystemd,
+		Name:     serviceName,
+		Type:     types.ServiceTypeSystemd,
+		Scope:    sm.scope.String(),
+		UnitType: systemdUnitType(serviceName),
 	}
 
 	// Get basic status
-	cmd := exec.Command("systemctl", "is-active", serviceName)
+	cmd := sm.command(ctx, "is-active", serviceName)
 	output, err := cmd.Output()
-	if err != nil {
+	if ctx.Err() != nil {
+		return info, ctx.Err()
+	}
+	// is-active exits non-zero for every state but active and still prints
+	// the state.
+	if status := strings.TrimSpace(string(output)); err != nil && status == "" {
 		info.Status = types.StatusFailed
 	} else {
-		status := strings.TrimSpace(string(output))
 		switch status {
 		case "active":
 			info.Status = types.StatusActive
@@ -67,9 +96,17 @@ func (sm *SystemdManager) GetStatus(serviceName string) (types.ServiceInfo, erro
 	}
 
 	// Get detailed information
-	cmd = exec.Command("systemctl", "show", serviceName, "--property=MainPID,Description,ActiveEnterTimestamp")
+	properties := "--property=MainPID,Description,ActiveEnterTimestamp,LoadState,SubState,UnitFileState,FragmentPath"
+	if info.UnitType == "service" {
+		properties += ",NRestarts,ExecMainCode,ExecMainStatus,MemoryCurrent"
+	}
+	if info.UnitType == "socket" {
+		properties += ",Listen"
+	}
+	cmd = sm.command(ctx, "show", serviceName, properties)
 	output, err = cmd.Output()
 	if err == nil {
+		var execCode, execStatus int
 		scanner := bufio.NewScanner(strings.NewReader(string(output)))
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -87,59 +124,195 @@ func (sm *SystemdManager) GetStatus(serviceName string) (types.ServiceInfo, erro
 			case "Description":
 				info.Description = value
 			case "ActiveEnterTimestamp":
-				if value != "" && value != "n/a" {
-					if startTime, err := time.Parse("Mon 2006-01-02 15:04:05 MST", value); err == nil {
-						info.LastChanged = startTime
+				if startTime, ok := parseSystemdTimestamp(value); ok {
+					info.LastChanged = startTime
+					if info.Status == types.StatusActive {
+						info.ActiveSince = &startTime
 						info.Uptime = time.Since(startTime)
 					}
 				}
+			case "LoadState":
+				info.LoadState = value
+			case "SubState":
+				info.SubState = value
+			case "UnitFileState":
+				info.EnabledState = value
+			case "FragmentPath":
+				info.UnitPath = value
+			case "NRestarts":
+				info.Restarts, _ = strconv.Atoi(value)
+			case "ExecMainCode":
+				execCode, _ = strconv.Atoi(value)
+			case "ExecMainStatus":
+				execStatus, _ = strconv.Atoi(value)
+			case "MemoryCurrent":
+				// "[not set]" without memory accounting
+				if memory, err := strconv.ParseUint(value, 10, 64); err == nil {
+					info.MemoryBytes = systemdMemory(memory)
+				}
+			case "Listen":
+				info.Listen = append(info.Listen, value)
 			}
 		}
+		info.ExitCode = systemdExitCode(execCode, execStatus)
+	}
+	// show loads installed units on demand, so only units without a unit
+	// file are not found; this is what auto-detection relies on.
+	if info.LoadState == "not-found" {
+		return info, fmt.Errorf("service %s not found", serviceName)
 	}
 
 	return info, nil
 }
 
-func (sm *SystemdManager) ListServices() ([]types.ServiceInfo, error) {
-	cmd := exec.Command("systemctl", "list-units", "--type=service", "--no-pager", "--plain")
+// systemdExitCode converts a service's ExecMainCode and ExecMainStatus, a
+// siginfo code and status, into an exit code. Processes killed by a signal
+// report 128+signal like a shell does; nil means the main process has not
+// exited.
+func systemdExitCode(code, status int) *int {
+	switch code {
+	case 1: // CLD_EXITED
+		return &status
+	case 2, 3: // CLD_KILLED, CLD_DUMPED
+		exitCode := 128 + status
+		return &exitCode
+	default:
+		return nil
+	}
+}
+
+// systemdMemory drops the "infinity" MemoryCurrent reports without memory
+// accounting.
+func systemdMemory(memory uint64) uint64 {
+	if memory == math.MaxUint64 {
+		return 0
+	}
+	return memory
+}
+
+// ListServices lists loaded units of the types requested in ctx, services
+// by default, and with WithInstalledUnits the installed unit files too. See
+// WithUnitTypes.
+func (sm *SystemdManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	unitTypes := UnitTypesFromContext(ctx)
+	cmd := sm.command(ctx, "list-units", "--type="+strings.Join(unitTypes, ","), "--no-pager", "--plain")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
 
 	var services []types.ServiceInfo
+	var sockets []string
 	scanner := bufio.NewScanner(strings.NewReader(string(output)))
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, ".service") {
-			fields := strings.Fields(line)
-			if len(fields) >= 4 {
-				serviceName := strings.TrimSuffix(fields[0], ".service")
-				var status types.ServiceStatus
-				switch fields[3] {
-				case "running":
-					status = types.StatusActive
-				case "dead", "exited":
-					status = types.StatusInactive
-				case "failed":
-					status = types.StatusFailed
-				default:
-					status = types.StatusUnknown
-				}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.Contains(fields[0], ".") {
+			continue
+		}
+		unitType := systemdUnitType(fields[0])
+		if !strings.HasSuffix(fields[0], "."+unitType) || !containsString(unitTypes, unitType) {
+			continue
+		}
 
-				services = append(services, types.ServiceInfo{
-					Name:   serviceName,
-					Type:   types.ServiceTypeSystemd,
-					Status: status,
-				})
+		var status types.ServiceStatus
+		if unitType == "service" {
+			switch fields[3] {
+			case "running":
+				status = types.StatusActive
+			case "dead", "exited":
+				status = types.StatusInactive
+			case "failed":
+				status = types.StatusFailed
+			default:
+				status = types.StatusUnknown
 			}
+		} else {
+			// Sub-states differ per unit type (listening, mounted,
+			// waiting...), so other units go by their active state.
+			status = systemdActiveStatus(fields[2])
+		}
+
+		if unitType == "socket" {
+			sockets = append(sockets, fields[0])
+		}
+		services = append(services, types.ServiceInfo{
+			Name:      systemdListName(fields[0]),
+			Type:      types.ServiceTypeSystemd,
+			Status:    status,
+			Scope:     sm.scope.String(),
+			UnitType:  unitType,
+			LoadState: fields[1],
+			SubState:  fields[3],
+		})
+	}
+
+	if len(sockets) > 0 {
+		listen, err := sm.socketListen(ctx, sockets)
+		if err != nil {
+			return nil, err
+		}
+		for i := range services {
+			services[i].Listen = listen[services[i].Name]
 		}
 	}
 
+	if InstalledUnitsFromContext(ctx) {
+		unitFiles, err := sm.unitFiles(ctx, unitTypes)
+		if err != nil {
+			return nil, err
+		}
+		services = mergeUnitFiles(services, unitFiles, unitTypes, sm.scope.String())
+	}
+
 	return services, nil
 }
 
+// unitFiles maps the installed unit files of the given types to their state
+// (enabled, disabled, static, masked...).
+func (sm *SystemdManager) unitFiles(ctx context.Context, unitTypes []string) (map[string]string, error) {
+	cmd := sm.command(ctx, "list-unit-files", "--type="+strings.Join(unitTypes, ","), "--no-pager", "--plain", "--no-legend")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	unitFiles := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && strings.Contains(fields[0], ".") {
+			unitFiles[fields[0]] = fields[1]
+		}
+	}
+	return unitFiles, nil
+}
+
+// socketListen reads the listen addresses of the given socket units.
+func (sm *SystemdManager) socketListen(ctx context.Context, sockets []string) (map[string][]string, error) {
+	cmd := sm.command(ctx, append([]string{"show", "--property=Id,Listen"}, sockets...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	listen := make(map[string][]string)
+	var id string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		switch {
+		case !ok:
+			id = ""
+		case key == "Id":
+			id = value
+		case key == "Listen" && id != "":
+			listen[id] = append(listen[id], value)
+		}
+	}
+	return listen, nil
+}
+
 func IsSystemdAvailable() bool {
 	cmd := exec.Command("systemctl", "--version")
 	return cmd.Run() == nil
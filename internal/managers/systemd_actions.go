@@ -0,0 +1,174 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func (sm *SystemdManager) Reload(ctx context.Context, serviceName string) error {
+	return sm.run(ctx, "reload", serviceName)
+}
+
+func (sm *SystemdManager) ReloadOrRestart(ctx context.Context, serviceName string) error {
+	return sm.run(ctx, "reload-or-restart", serviceName)
+}
+
+func (sm *SystemdManager) TryRestart(ctx context.Context, serviceName string) error {
+	return sm.run(ctx, "try-restart", serviceName)
+}
+
+func (sm *SystemdManager) Mask(ctx context.Context, serviceName string) error {
+	return sm.run(ctx, "mask", serviceName)
+}
+
+func (sm *SystemdManager) Unmask(ctx context.Context, serviceName string) error {
+	return sm.run(ctx, "unmask", serviceName)
+}
+
+func (sm *SystemdManager) ResetFailed(ctx context.Context, serviceName string) error {
+	return sm.run(ctx, "reset-failed", serviceName)
+}
+
+// Kill signals every process of the unit, not just the main process.
+func (sm *SystemdManager) Kill(ctx context.Context, serviceName string, signal Signal) error {
+	return sm.run(ctx, "kill", "--signal="+signal.String(), serviceName)
+}
+
+// run runs systemctl and reports its error message on failure, e.g. that a
+// unit does not support reloading.
+func (sm *SystemdManager) run(ctx context.Context, args ...string) error {
+	if _, err := sm.command(ctx, args...).Output(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return err
+	}
+	return nil
+}
+
+func (sm *SystemdDBusManager) Reload(ctx context.Context, serviceName string) error {
+	return sm.runJob(ctx, "ReloadUnit", serviceName)
+}
+
+func (sm *SystemdDBusManager) ReloadOrRestart(ctx context.Context, serviceName string) error {
+	return sm.runJob(ctx, "ReloadOrRestartUnit", serviceName)
+}
+
+func (sm *SystemdDBusManager) TryRestart(ctx context.Context, serviceName string) error {
+	return sm.runJob(ctx, "TryRestartUnit", serviceName)
+}
+
+func (sm *SystemdDBusManager) Mask(ctx context.Context, serviceName string) error {
+	var changes []systemdUnitFileChange
+	// Like systemctl mask: persistent, and without replacing an existing
+	// unit file in /etc.
+	err := sm.manager().CallWithContext(ctx, systemdManagerIface+".MaskUnitFiles", 0,
+		[]string{systemdUnitName(serviceName)}, false, false).Store(&changes)
+	if err != nil {
+		return fmt.Errorf("failed to mask %s: %v", serviceName, err)
+	}
+	return sm.reload(ctx)
+}
+
+func (sm *SystemdDBusManager) Unmask(ctx context.Context, serviceName string) error {
+	var changes []systemdUnitFileChange
+	err := sm.manager().CallWithContext(ctx, systemdManagerIface+".UnmaskUnitFiles", 0,
+		[]string{systemdUnitName(serviceName)}, false).Store(&changes)
+	if err != nil {
+		return fmt.Errorf("failed to unmask %s: %v", serviceName, err)
+	}
+	return sm.reload(ctx)
+}
+
+func (sm *SystemdDBusManager) ResetFailed(ctx context.Context, serviceName string) error {
+	if err := sm.manager().CallWithContext(ctx, systemdManagerIface+".ResetFailedUnit", 0, systemdUnitName(serviceName)).Err; err != nil {
+		return fmt.Errorf("failed to reset %s: %v", serviceName, err)
+	}
+	return nil
+}
+
+// Kill signals every process of the unit, not just the main process.
+func (sm *SystemdDBusManager) Kill(ctx context.Context, serviceName string, signal Signal) error {
+	if err := sm.manager().CallWithContext(ctx, systemdManagerIface+".KillUnit", 0, systemdUnitName(serviceName), "all", int32(signal)).Err; err != nil {
+		return fmt.Errorf("failed to kill %s: %v", serviceName, err)
+	}
+	return nil
+}
+
+func (sm *SystemdScopeManager) Reload(ctx context.Context, serviceName string) error {
+	units, err := sm.unitActions(ctx, "reload")
+	if err != nil {
+		return err
+	}
+	return units.Reload(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) ReloadOrRestart(ctx context.Context, serviceName string) error {
+	units, err := sm.unitActions(ctx, "reload-or-restart")
+	if err != nil {
+		return err
+	}
+	return units.ReloadOrRestart(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) TryRestart(ctx context.Context, serviceName string) error {
+	units, err := sm.unitActions(ctx, "try-restart")
+	if err != nil {
+		return err
+	}
+	return units.TryRestart(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) Mask(ctx context.Context, serviceName string) error {
+	units, err := sm.unitActions(ctx, "mask")
+	if err != nil {
+		return err
+	}
+	return units.Mask(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) Unmask(ctx context.Context, serviceName string) error {
+	units, err := sm.unitActions(ctx, "unmask")
+	if err != nil {
+		return err
+	}
+	return units.Unmask(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) ResetFailed(ctx context.Context, serviceName string) error {
+	units, err := sm.unitActions(ctx, "reset-failed")
+	if err != nil {
+		return err
+	}
+	return units.ResetFailed(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) Kill(ctx context.Context, serviceName string, signal Signal) error {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return err
+	}
+	killer, ok := manager.(Killer)
+	if !ok {
+		return &UnsupportedActionError{Action: "kill"}
+	}
+	return killer.Kill(ctx, serviceName, signal)
+}
+
+// unitActions returns the manager of the scope in ctx as a
+// UnitActionManager.
+func (sm *SystemdScopeManager) unitActions(ctx context.Context, action string) (UnitActionManager, error) {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, ok := manager.(UnitActionManager)
+	if !ok {
+		return nil, &UnsupportedActionError{Action: action}
+	}
+	return units, nil
+}
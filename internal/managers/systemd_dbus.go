@@ -0,0 +1,411 @@
+package managers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const (
+	systemdBusName       = "org.freedesktop.systemd1"
+	systemdObjectPath    = dbus.ObjectPath("/org/freedesktop/systemd1")
+	systemdManagerIface  = "org.freedesktop.systemd1.Manager"
+	systemdUnitIface     = "org.freedesktop.systemd1.Unit"
+	systemdServiceIface  = "org.freedesktop.systemd1.Service"
+	systemdJobIface      = "org.freedesktop.systemd1.Job"
+	dbusPropertiesIface  = "org.freedesktop.DBus.Properties"
+	defaultSystemdJobTTL = 90 * time.Second
+)
+
+// systemdUnitStatus mirrors one entry of the Manager.ListUnits reply.
+type systemdUnitStatus struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Followed    string
+	Path        dbus.ObjectPath
+	JobID       uint32
+	JobType     string
+	JobPath     dbus.ObjectPath
+}
+
+// systemdUnitFile mirrors one entry of the ListUnitFiles reply.
+type systemdUnitFile struct {
+	Path  string
+	State string
+}
+
+// systemdUnitFileChange mirrors one entry of the Enable/DisableUnitFiles reply.
+type systemdUnitFileChange struct {
+	Type        string
+	Filename    string
+	Destination string
+}
+
+// SystemdDBusManager talks to org.freedesktop.systemd1 directly instead of
+// shelling out to systemctl. Start/Stop/Restart wait for the queued job to be
+// removed and surface any result other than "done" as an error.
+type SystemdDBusManager struct {
+	conn       *dbus.Conn
+	jobTimeout time.Duration
+	signals    chan *dbus.Signal
+
+	mu   sync.Mutex
+	jobs map[dbus.ObjectPath]chan string
+}
+
+func NewSystemdDBusManager() (*SystemdDBusManager, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %v", err)
+	}
+
+	manager, err := newSystemdDBusManager(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return manager, nil
+}
+
+func newSystemdDBusManager(conn *dbus.Conn) (*SystemdDBusManager, error) {
+	sm := &SystemdDBusManager{
+		conn:       conn,
+		jobTimeout: defaultSystemdJobTTL,
+		signals:    make(chan *dbus.Signal, 64),
+		jobs:       make(map[dbus.ObjectPath]chan string),
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(systemdObjectPath),
+		dbus.WithMatchInterface(systemdManagerIface),
+		dbus.WithMatchMember("JobRemoved"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to systemd job signals: %v", err)
+	}
+
+	// systemd only emits JobRemoved to clients that called Subscribe
+	if err := sm.manager().Call(systemdManagerIface+".Subscribe", 0).Err; err != nil {
+		return nil, fmt.Errorf("failed to subscribe to systemd: %v", err)
+	}
+
+	conn.Signal(sm.signals)
+	go sm.dispatchSignals()
+
+	return sm, nil
+}
+
+// Close releases the underlying bus connection, which also ends dispatchSignals.
+func (sm *SystemdDBusManager) Close() error {
+	return sm.conn.Close()
+}
+
+func (sm *SystemdDBusManager) Start(ctx context.Context, serviceName string) error {
+	return sm.runJob(ctx, "StartUnit", serviceName)
+}
+
+func (sm *SystemdDBusManager) Stop(ctx context.Context, serviceName string) error {
+	return sm.runJob(ctx, "StopUnit", serviceName)
+}
+
+func (sm *SystemdDBusManager) Restart(ctx context.Context, serviceName string) error {
+	return sm.runJob(ctx, "RestartUnit", serviceName)
+}
+
+func (sm *SystemdDBusManager) Enable(ctx context.Context, serviceName string) error {
+	var carriesInstallInfo bool
+	var changes []systemdUnitFileChange
+	// Like systemctl enable: persistent, and without replacing existing
+	// symlinks that point elsewhere.
+	err := sm.manager().CallWithContext(ctx, systemdManagerIface+".EnableUnitFiles", 0,
+		[]string{systemdUnitName(serviceName)}, false, false).Store(&carriesInstallInfo, &changes)
+	if err != nil {
+		return fmt.Errorf("failed to enable %s: %v", serviceName, err)
+	}
+	return sm.reload(ctx)
+}
+
+func (sm *SystemdDBusManager) Disable(ctx context.Context, serviceName string) error {
+	var changes []systemdUnitFileChange
+	err := sm.manager().CallWithContext(ctx, systemdManagerIface+".DisableUnitFiles", 0,
+		[]string{systemdUnitName(serviceName)}, false).Store(&changes)
+	if err != nil {
+		return fmt.Errorf("failed to disable %s: %v", serviceName, err)
+	}
+	return sm.reload(ctx)
+}
+
+// DaemonReload makes systemd re-read unit files.
+func (sm *SystemdDBusManager) DaemonReload(ctx context.Context) error {
+	return sm.reload(ctx)
+}
+
+func (sm *SystemdDBusManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	info := types.ServiceInfo{
+		Name:     serviceName,
+		Type:     types.ServiceTypeSystemd,
+		Scope:    "system",
+		UnitType: systemdUnitType(serviceName),
+	}
+
+	unitName := systemdUnitName(serviceName)
+
+	var unitPath dbus.ObjectPath
+	if err := sm.manager().CallWithContext(ctx, systemdManagerIface+".LoadUnit", 0, unitName).Store(&unitPath); err != nil {
+		return info, fmt.Errorf("service %s not found: %v", serviceName, err)
+	}
+
+	props, err := sm.getAllProperties(ctx, unitPath, systemdUnitIface)
+	if err != nil {
+		return info, err
+	}
+
+	if loadState, _ := props["LoadState"].Value().(string); loadState == "not-found" {
+		return info, fmt.Errorf("service %s not found", serviceName)
+	}
+
+	activeState, _ := props["ActiveState"].Value().(string)
+	info.Status = systemdActiveStatus(activeState)
+	info.Description, _ = props["Description"].Value().(string)
+	info.LoadState, _ = props["LoadState"].Value().(string)
+	info.SubState, _ = props["SubState"].Value().(string)
+	info.EnabledState, _ = props["UnitFileState"].Value().(string)
+	info.UnitPath, _ = props["FragmentPath"].Value().(string)
+
+	if usec, ok := props["ActiveEnterTimestamp"].Value().(uint64); ok && usec > 0 {
+		startTime := time.UnixMicro(int64(usec))
+		info.LastChanged = startTime
+		if info.Status == types.StatusActive {
+			info.ActiveSince = &startTime
+			info.Uptime = time.Since(startTime)
+		}
+	}
+
+	if strings.HasSuffix(unitName, ".service") {
+		serviceProps, err := sm.getAllProperties(ctx, unitPath, systemdServiceIface)
+		if err == nil {
+			if pid, ok := serviceProps["MainPID"].Value().(uint32); ok && pid > 0 {
+				info.PID = int(pid)
+			}
+			if restarts, ok := serviceProps["NRestarts"].Value().(uint32); ok {
+				info.Restarts = int(restarts)
+			}
+			code, _ := serviceProps["ExecMainCode"].Value().(int32)
+			status, _ := serviceProps["ExecMainStatus"].Value().(int32)
+			info.ExitCode = systemdExitCode(int(code), int(status))
+			if memory, ok := serviceProps["MemoryCurrent"].Value().(uint64); ok {
+				info.MemoryBytes = systemdMemory(memory)
+			}
+		}
+	}
+
+	if info.UnitType == "socket" {
+		listen, err := sm.socketListen(ctx, unitPath)
+		if err != nil {
+			return info, err
+		}
+		info.Listen = listen
+	}
+
+	return info, nil
+}
+
+// ListServices lists loaded units of the types requested in ctx, services
+// by default, and with WithInstalledUnits the installed unit files too. See
+// WithUnitTypes.
+func (sm *SystemdDBusManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	var units []systemdUnitStatus
+	if err := sm.manager().CallWithContext(ctx, systemdManagerIface+".ListUnits", 0).Store(&units); err != nil {
+		return nil, fmt.Errorf("failed to list units: %v", err)
+	}
+
+	unitTypes := UnitTypesFromContext(ctx)
+	var services []types.ServiceInfo
+	for _, unit := range units {
+		unitType := systemdUnitType(unit.Name)
+		if !strings.HasSuffix(unit.Name, "."+unitType) || !containsString(unitTypes, unitType) {
+			continue
+		}
+
+		service := types.ServiceInfo{
+			Name:        systemdListName(unit.Name),
+			Type:        types.ServiceTypeSystemd,
+			Status:      systemdActiveStatus(unit.ActiveState),
+			Description: unit.Description,
+			Scope:       "system",
+			UnitType:    unitType,
+			LoadState:   unit.LoadState,
+			SubState:    unit.SubState,
+		}
+		if unitType == "socket" {
+			listen, err := sm.socketListen(ctx, unit.Path)
+			if err != nil {
+				return nil, err
+			}
+			service.Listen = listen
+		}
+		services = append(services, service)
+	}
+
+	if InstalledUnitsFromContext(ctx) {
+		unitFiles, err := sm.unitFiles(ctx)
+		if err != nil {
+			return nil, err
+		}
+		services = mergeUnitFiles(services, unitFiles, unitTypes, "system")
+	}
+
+	return services, nil
+}
+
+// unitFiles maps every installed unit file to its state (enabled, disabled,
+// static, masked...).
+func (sm *SystemdDBusManager) unitFiles(ctx context.Context) (map[string]string, error) {
+	var files []systemdUnitFile
+	if err := sm.manager().CallWithContext(ctx, systemdManagerIface+".ListUnitFiles", 0).Store(&files); err != nil {
+		return nil, fmt.Errorf("failed to list unit files: %v", err)
+	}
+
+	unitFiles := make(map[string]string, len(files))
+	for _, file := range files {
+		unitFiles[path.Base(file.Path)] = file.State
+	}
+	return unitFiles, nil
+}
+
+// socketListen reads the Listen property of a socket unit.
+func (sm *SystemdDBusManager) socketListen(ctx context.Context, path dbus.ObjectPath) ([]string, error) {
+	props, err := sm.getAllProperties(ctx, path, systemdSocketIface)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []struct {
+		Type    string
+		Address string
+	}
+	if variant, ok := props["Listen"]; ok {
+		if err := variant.Store(&entries); err != nil {
+			return nil, fmt.Errorf("failed to read socket addresses: %v", err)
+		}
+	}
+
+	listen := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		listen = append(listen, formatSocketListen(entry.Type, entry.Address))
+	}
+	return listen, nil
+}
+
+func (sm *SystemdDBusManager) manager() dbus.BusObject {
+	return sm.conn.Object(systemdBusName, systemdObjectPath)
+}
+
+func (sm *SystemdDBusManager) reload(ctx context.Context) error {
+	if err := sm.manager().CallWithContext(ctx, systemdManagerIface+".Reload", 0).Err; err != nil {
+		return fmt.Errorf("failed to reload systemd: %v", err)
+	}
+	return nil
+}
+
+func (sm *SystemdDBusManager) getAllProperties(ctx context.Context, path dbus.ObjectPath, iface string) (map[string]dbus.Variant, error) {
+	var props map[string]dbus.Variant
+	obj := sm.conn.Object(systemdBusName, path)
+	if err := obj.CallWithContext(ctx, dbusPropertiesIface+".GetAll", 0, iface).Store(&props); err != nil {
+		return nil, fmt.Errorf("failed to read %s properties: %v", iface, err)
+	}
+	return props, nil
+}
+
+// runJob queues a unit job and blocks until systemd reports it as removed.
+// If ctx ends first the job is cancelled on a best-effort basis.
+func (sm *SystemdDBusManager) runJob(ctx context.Context, method, serviceName string) error {
+	unitName := systemdUnitName(serviceName)
+	done := make(chan string, 1)
+
+	// Hold the lock until the job is registered so a JobRemoved signal that
+	// races ahead of the method reply is not dropped by dispatchSignals.
+	sm.mu.Lock()
+	var jobPath dbus.ObjectPath
+	err := sm.manager().CallWithContext(ctx, systemdManagerIface+"."+method, 0, unitName, "replace").Store(&jobPath)
+	if err != nil {
+		sm.mu.Unlock()
+		return fmt.Errorf("failed to queue %s job for %s: %v", method, unitName, err)
+	}
+	sm.jobs[jobPath] = done
+	sm.mu.Unlock()
+
+	timer := time.NewTimer(sm.jobTimeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-done:
+		if result != "done" {
+			return fmt.Errorf("job for %s finished with result %q", unitName, result)
+		}
+		return nil
+	case <-ctx.Done():
+		sm.forgetJob(jobPath)
+		sm.conn.Object(systemdBusName, jobPath).Call(systemdJobIface+".Cancel", 0)
+		return fmt.Errorf("job %s on %s abandoned: %v", jobPath, unitName, ctx.Err())
+	case <-timer.C:
+		sm.forgetJob(jobPath)
+		return fmt.Errorf("timed out waiting for job %s on %s", jobPath, unitName)
+	}
+}
+
+func (sm *SystemdDBusManager) forgetJob(jobPath dbus.ObjectPath) {
+	sm.mu.Lock()
+	delete(sm.jobs, jobPath)
+	sm.mu.Unlock()
+}
+
+func (sm *SystemdDBusManager) dispatchSignals() {
+	for signal := range sm.signals {
+		if signal.Name != systemdManagerIface+".JobRemoved" || len(signal.Body) < 4 {
+			continue
+		}
+
+		jobPath, _ := signal.Body[1].(dbus.ObjectPath)
+		result, _ := signal.Body[3].(string)
+
+		sm.mu.Lock()
+		if done, ok := sm.jobs[jobPath]; ok {
+			done <- result
+			delete(sm.jobs, jobPath)
+		}
+		sm.mu.Unlock()
+	}
+}
+
+// systemdUnitName appends the .service suffix when the caller passed a bare name.
+func systemdUnitName(name string) string {
+	for _, suffix := range []string{".service", ".socket", ".target", ".timer", ".mount", ".automount", ".path", ".slice", ".scope", ".swap", ".device"} {
+		if strings.HasSuffix(name, suffix) {
+			return name
+		}
+	}
+	return name + ".service"
+}
+
+func systemdActiveStatus(activeState string) types.ServiceStatus {
+	switch activeState {
+	case "active", "reloading":
+		return types.StatusActive
+	case "inactive":
+		return types.StatusInactive
+	case "failed":
+		return types.StatusFailed
+	default:
+		return types.StatusUnknown
+	}
+}
@@ -0,0 +1,534 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const fakeBusConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// fakeSystemd 在私有总线上模拟 org.freedesktop.systemd1 的一个子集
+type fakeSystemd struct {
+	conn    *dbus.Conn
+	mu      sync.Mutex
+	units   map[string]*prop.Properties
+	results map[string]string
+	nextJob uint32
+	reloads int
+	enabled map[string]bool
+	masked  map[string]bool
+	killed  map[string]int32
+}
+
+// startFakeBus 启动一个私有的dbus-daemon，返回其地址
+func startFakeBus(t *testing.T) string {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not available, skipping D-Bus manager tests")
+	}
+
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "bus")
+	configPath := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(configPath, []byte(fmt.Sprintf(fakeBusConfig, socket)), 0644); err != nil {
+		t.Fatalf("Failed to write bus config: %v", err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+configPath, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to create stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+func newFakeSystemd(t *testing.T, address string) *fakeSystemd {
+	t.Helper()
+
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Failed to connect fake systemd to bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	fake := &fakeSystemd{
+		conn:    conn,
+		units:   make(map[string]*prop.Properties),
+		results: make(map[string]string),
+		enabled: make(map[string]bool),
+		masked:  make(map[string]bool),
+		killed:  make(map[string]int32),
+	}
+
+	if err := conn.Export(fake, systemdObjectPath, systemdManagerIface); err != nil {
+		t.Fatalf("Failed to export fake manager: %v", err)
+	}
+
+	reply, err := conn.RequestName(systemdBusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to own %s: %v", systemdBusName, err)
+	}
+
+	return fake
+}
+
+func unitObjectPath(name string) dbus.ObjectPath {
+	return dbus.ObjectPath("/org/freedesktop/systemd1/unit/" + strings.NewReplacer(".", "_2e", "-", "_2d").Replace(name))
+}
+
+func (f *fakeSystemd) addUnit(t *testing.T, name, activeState string, mainPID uint32) {
+	t.Helper()
+
+	props, err := prop.Export(f.conn, unitObjectPath(name), prop.Map{
+		systemdUnitIface: {
+			"Description":          {Value: "Fake " + name, Emit: prop.EmitFalse},
+			"LoadState":            {Value: "loaded", Emit: prop.EmitFalse},
+			"ActiveState":          {Value: activeState, Emit: prop.EmitFalse},
+			"SubState":             {Value: "running", Emit: prop.EmitFalse},
+			"ActiveEnterTimestamp": {Value: uint64(time.Now().Add(-time.Hour).UnixMicro()), Emit: prop.EmitFalse},
+			"UnitFileState":        {Value: "enabled", Emit: prop.EmitFalse},
+			"FragmentPath":         {Value: "/lib/systemd/system/" + name, Emit: prop.EmitFalse},
+		},
+		systemdServiceIface: {
+			"MainPID":        {Value: mainPID, Emit: prop.EmitFalse},
+			"NRestarts":      {Value: uint32(1), Emit: prop.EmitFalse},
+			"ExecMainCode":   {Value: int32(0), Emit: prop.EmitFalse},
+			"ExecMainStatus": {Value: int32(0), Emit: prop.EmitFalse},
+			"MemoryCurrent":  {Value: uint64(math.MaxUint64), Emit: prop.EmitFalse},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to export unit %s: %v", name, err)
+	}
+
+	f.mu.Lock()
+	f.units[name] = props
+	f.mu.Unlock()
+}
+
+func (f *fakeSystemd) Subscribe() *dbus.Error {
+	return nil
+}
+
+func (f *fakeSystemd) Reload() *dbus.Error {
+	f.mu.Lock()
+	f.reloads++
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeSystemd) LoadUnit(name string) (dbus.ObjectPath, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.units[name]; !ok {
+		return "", dbus.NewError("org.freedesktop.systemd1.NoSuchUnit", []interface{}{"Unit " + name + " not found."})
+	}
+	return unitObjectPath(name), nil
+}
+
+func (f *fakeSystemd) ListUnits() ([]systemdUnitStatus, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var units []systemdUnitStatus
+	for name, props := range f.units {
+		units = append(units, systemdUnitStatus{
+			Name:        name,
+			Description: props.GetMust(systemdUnitIface, "Description").(string),
+			LoadState:   "loaded",
+			ActiveState: props.GetMust(systemdUnitIface, "ActiveState").(string),
+			SubState:    props.GetMust(systemdUnitIface, "SubState").(string),
+			Path:        unitObjectPath(name),
+			JobPath:     "/",
+		})
+	}
+	units = append(units, systemdUnitStatus{Name: "multi-user.target", ActiveState: "active", Path: "/", JobPath: "/"})
+	return units, nil
+}
+
+// ListUnitFiles 在已导出的单元之外还返回一个未加载的服务和一个模板单元
+func (f *fakeSystemd) ListUnitFiles() ([]systemdUnitFile, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	files := []systemdUnitFile{
+		{Path: "/lib/systemd/system/backup.service", State: "disabled"},
+		{Path: "/lib/systemd/system/getty@.service", State: "enabled"},
+	}
+	for name := range f.units {
+		state := "disabled"
+		if f.enabled[name] {
+			state = "enabled"
+		}
+		files = append(files, systemdUnitFile{Path: "/lib/systemd/system/" + name, State: state})
+	}
+	return files, nil
+}
+
+func (f *fakeSystemd) StartUnit(name, mode string) (dbus.ObjectPath, *dbus.Error) {
+	return f.queueJob(name, "active")
+}
+
+func (f *fakeSystemd) StopUnit(name, mode string) (dbus.ObjectPath, *dbus.Error) {
+	return f.queueJob(name, "inactive")
+}
+
+func (f *fakeSystemd) RestartUnit(name, mode string) (dbus.ObjectPath, *dbus.Error) {
+	return f.queueJob(name, "active")
+}
+
+func (f *fakeSystemd) EnableUnitFiles(files []string, runtime, force bool) (bool, []systemdUnitFileChange, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// systemctl enable 不使用 runtime 和 force
+	if runtime || force {
+		return false, nil, dbus.NewError("org.freedesktop.DBus.Error.InvalidArgs", []interface{}{"unexpected runtime or force flag"})
+	}
+	var changes []systemdUnitFileChange
+	for _, file := range files {
+		f.enabled[file] = true
+		changes = append(changes, systemdUnitFileChange{Type: "symlink", Filename: file, Destination: "/etc/systemd/system/" + file})
+	}
+	return true, changes, nil
+}
+
+func (f *fakeSystemd) DisableUnitFiles(files []string, runtime bool) ([]systemdUnitFileChange, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var changes []systemdUnitFileChange
+	for _, file := range files {
+		delete(f.enabled, file)
+		changes = append(changes, systemdUnitFileChange{Type: "unlink", Filename: file})
+	}
+	return changes, nil
+}
+
+// queueJob 返回job路径，并在稍后异步发出JobRemoved信号
+func (f *fakeSystemd) ReloadUnit(name, mode string) (dbus.ObjectPath, *dbus.Error) {
+	return f.queueJob(name, "active")
+}
+
+func (f *fakeSystemd) MaskUnitFiles(files []string, runtime, force bool) ([]systemdUnitFileChange, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// systemctl mask 不使用 runtime 和 force
+	if runtime || force {
+		return nil, dbus.NewError("org.freedesktop.DBus.Error.InvalidArgs", []interface{}{"unexpected runtime or force flag"})
+	}
+	var changes []systemdUnitFileChange
+	for _, file := range files {
+		f.masked[file] = true
+		changes = append(changes, systemdUnitFileChange{Type: "symlink", Filename: "/etc/systemd/system/" + file, Destination: "/dev/null"})
+	}
+	return changes, nil
+}
+
+func (f *fakeSystemd) KillUnit(name, who string, signal int32) *dbus.Error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.units[name]; !ok {
+		return dbus.NewError("org.freedesktop.systemd1.NoSuchUnit", []interface{}{"Unit " + name + " not found."})
+	}
+	f.killed[name] = signal
+	return nil
+}
+
+func (f *fakeSystemd) queueJob(name, targetState string) (dbus.ObjectPath, *dbus.Error) {
+	f.mu.Lock()
+	props, ok := f.units[name]
+	if !ok {
+		f.mu.Unlock()
+		return "", dbus.NewError("org.freedesktop.systemd1.NoSuchUnit", []interface{}{"Unit " + name + " not found."})
+	}
+	f.nextJob++
+	id := f.nextJob
+	result, hasResult := f.results[name]
+	if !hasResult {
+		result = "done"
+	}
+	f.mu.Unlock()
+
+	jobPath := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/systemd1/job/%d", id))
+	if result == "hang" {
+		// 模拟永不完成的作业
+		return jobPath, nil
+	}
+	go func() {
+		if result == "done" {
+			props.SetMust(systemdUnitIface, "ActiveState", targetState)
+		}
+		f.conn.Emit(systemdObjectPath, systemdManagerIface+".JobRemoved", id, jobPath, name, result)
+	}()
+
+	return jobPath, nil
+}
+
+func newTestSystemdDBusManager(t *testing.T) (*SystemdDBusManager, *fakeSystemd) {
+	t.Helper()
+
+	address := startFakeBus(t)
+	fake := newFakeSystemd(t, address)
+	fake.addUnit(t, "nginx.service", "active", 4321)
+	fake.addUnit(t, "cron.service", "inactive", 0)
+
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Failed to connect client to bus: %v", err)
+	}
+
+	manager, err := newSystemdDBusManager(conn)
+	if err != nil {
+		conn.Close()
+		t.Fatalf("Failed to create D-Bus manager: %v", err)
+	}
+	manager.jobTimeout = 5 * time.Second
+	t.Cleanup(func() { manager.Close() })
+
+	return manager, fake
+}
+
+func TestSystemdDBusManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*SystemdDBusManager)(nil)
+}
+
+func TestSystemdUnitName(t *testing.T) {
+	testCases := map[string]string{
+		"nginx":             "nginx.service",
+		"nginx.service":     "nginx.service",
+		"docker.socket":     "docker.socket",
+		"getty@tty1":        "getty@tty1.service",
+		"multi-user.target": "multi-user.target",
+	}
+
+	for input, expected := range testCases {
+		if got := systemdUnitName(input); got != expected {
+			t.Errorf("systemdUnitName(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestSystemdDBusManager_GetStatus(t *testing.T) {
+	manager, _ := newTestSystemdDBusManager(t)
+
+	info, err := manager.GetStatus(context.Background(), "nginx")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+
+	if info.Status != types.StatusActive {
+		t.Errorf("Expected status %s, got %s", types.StatusActive, info.Status)
+	}
+	if info.PID != 4321 {
+		t.Errorf("Expected PID 4321, got %d", info.PID)
+	}
+	if info.Description != "Fake nginx.service" {
+		t.Errorf("Unexpected description: %s", info.Description)
+	}
+	if info.LastChanged.IsZero() || info.Uptime < 59*time.Minute {
+		t.Errorf("Expected uptime of about an hour, got %s", info.Uptime)
+	}
+	if info.EnabledState != "enabled" || info.SubState != "running" || info.UnitPath != "/lib/systemd/system/nginx.service" || info.Restarts != 1 {
+		t.Errorf("Unexpected unit details: %+v", info)
+	}
+	// 未开启内存统计时 MemoryCurrent 为 UINT64_MAX，不应报告
+	if info.ExitCode != nil || info.MemoryBytes != 0 || info.ActiveSince == nil || !info.ActiveSince.Equal(info.LastChanged) {
+		t.Errorf("Unexpected process details: %+v", info)
+	}
+
+	if _, err := manager.GetStatus(context.Background(), "definitely-does-not-exist"); err == nil {
+		t.Error("Expected error for nonexistent unit")
+	}
+}
+
+func TestSystemdDBusManager_ListServices(t *testing.T) {
+	manager, _ := newTestSystemdDBusManager(t)
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 services (targets filtered out), got %d", len(services))
+	}
+
+	for _, service := range services {
+		if service.Type != types.ServiceTypeSystemd {
+			t.Errorf("Service %s has wrong type %s", service.Name, service.Type)
+		}
+		if service.Name == "cron" && service.Status != types.StatusInactive {
+			t.Errorf("Expected cron to be inactive, got %s", service.Status)
+		}
+	}
+}
+
+func TestSystemdDBusManager_JobLifecycle(t *testing.T) {
+	manager, _ := newTestSystemdDBusManager(t)
+
+	if err := manager.Start(context.Background(), "cron"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	info, _ := manager.GetStatus(context.Background(), "cron")
+	if info.Status != types.StatusActive {
+		t.Errorf("Expected cron to be active after start, got %s", info.Status)
+	}
+
+	if err := manager.Stop(context.Background(), "cron"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	info, _ = manager.GetStatus(context.Background(), "cron")
+	if info.Status != types.StatusInactive {
+		t.Errorf("Expected cron to be inactive after stop, got %s", info.Status)
+	}
+
+	if err := manager.Restart(context.Background(), "nginx"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+}
+
+func TestSystemdDBusManager_JobFailure(t *testing.T) {
+	manager, fake := newTestSystemdDBusManager(t)
+
+	for _, result := range []string{"failed", "timeout", "dependency"} {
+		fake.mu.Lock()
+		fake.results["cron.service"] = result
+		fake.mu.Unlock()
+
+		err := manager.Start(context.Background(), "cron")
+		if err == nil {
+			t.Fatalf("Expected error for job result %q", result)
+		}
+		if !strings.Contains(err.Error(), result) {
+			t.Errorf("Expected error to mention %q, got: %v", result, err)
+		}
+	}
+
+	if err := manager.Start(context.Background(), "definitely-does-not-exist"); err == nil {
+		t.Error("Expected error when starting nonexistent unit")
+	}
+}
+
+func TestSystemdDBusManager_EnableDisable(t *testing.T) {
+	manager, fake := newTestSystemdDBusManager(t)
+
+	if err := manager.Enable(context.Background(), "nginx"); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	fake.mu.Lock()
+	enabled := fake.enabled["nginx.service"]
+	fake.mu.Unlock()
+	if !enabled {
+		t.Error("Expected nginx.service to be enabled")
+	}
+
+	if err := manager.Disable(context.Background(), "nginx"); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.enabled["nginx.service"] {
+		t.Error("Expected nginx.service to be disabled")
+	}
+	if fake.reloads != 2 {
+		t.Errorf("Expected 2 daemon reloads, got %d", fake.reloads)
+	}
+}
+
+func TestSystemdDBusManager_Actions(t *testing.T) {
+	manager, fake := newTestSystemdDBusManager(t)
+	ctx := context.Background()
+
+	if err := manager.Reload(ctx, "nginx"); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if err := manager.Mask(ctx, "cron"); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+	if err := manager.Kill(ctx, "nginx", Signal(1)); err != nil {
+		t.Fatalf("Kill failed: %v", err)
+	}
+	if err := manager.Kill(ctx, "missing", SIGTERM); err == nil {
+		t.Error("Expected killing a missing unit to fail")
+	}
+	// 假 systemd 未实现 ResetFailedUnit
+	if err := manager.ResetFailed(ctx, "nginx"); err == nil {
+		t.Error("Expected ResetFailed to fail on the fake bus")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if !fake.masked["cron.service"] || fake.killed["nginx.service"] != 1 {
+		t.Errorf("Unexpected fake state: masked %v, killed %v", fake.masked, fake.killed)
+	}
+	if fake.reloads != 1 {
+		t.Errorf("Expected a daemon reload after masking, got %d", fake.reloads)
+	}
+}
+
+func TestSystemdDBusManager_ContextCancellation(t *testing.T) {
+	manager, fake := newTestSystemdDBusManager(t)
+
+	fake.mu.Lock()
+	fake.results["nginx.service"] = "hang"
+	fake.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := manager.Stop(ctx, "nginx")
+	if err == nil {
+		t.Fatal("Expected error when context expires before job completes")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("Expected Stop to return at the deadline, took %s", time.Since(start))
+	}
+
+	manager.mu.Lock()
+	pending := len(manager.jobs)
+	manager.mu.Unlock()
+	if pending != 0 {
+		t.Errorf("Expected abandoned job to be forgotten, %d still pending", pending)
+	}
+}
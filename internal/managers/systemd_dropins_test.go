@@ -0,0 +1,94 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSystemdScopeManager_DropIns(t *testing.T) {
+	manager, calls := newTestUnitFileManager(t)
+	ctx := context.Background()
+
+	if dropIns, err := manager.ListDropIns(ctx, "nginx"); err != nil || len(dropIns) != 0 {
+		t.Fatalf("Expected no drop-ins, got %+v, %v", dropIns, err)
+	}
+
+	info, err := manager.WriteDropIn(ctx, "nginx", "limits", "[Service]\nLimitNOFILE=65536")
+	if err != nil {
+		t.Fatalf("WriteDropIn failed: %v", err)
+	}
+	path := filepath.Join(manager.unitDir, "nginx.service.d", "limits.conf")
+	if info.Path != path || info.Unit != "nginx.service" || info.Name != "limits.conf" {
+		t.Errorf("Unexpected drop-in: %+v", info)
+	}
+	if _, err := manager.WriteDropIn(ctx, "nginx.service", "env.conf", "[Service]\nEnvironment=DEBUG=1\n"); err != nil {
+		t.Fatalf("WriteDropIn failed: %v", err)
+	}
+
+	dropIns, err := manager.ListDropIns(ctx, "nginx")
+	if err != nil {
+		t.Fatalf("ListDropIns failed: %v", err)
+	}
+	if len(dropIns) != 2 || dropIns[0].Name != "env.conf" || dropIns[1].Content != "[Service]\nLimitNOFILE=65536\n" {
+		t.Errorf("Unexpected drop-ins: %+v", dropIns)
+	}
+
+	dropIn, err := manager.GetDropIn(ctx, "nginx", "env")
+	if err != nil || dropIn.Content != "[Service]\nEnvironment=DEBUG=1\n" {
+		t.Errorf("Unexpected drop-in %+v, %v", dropIn, err)
+	}
+
+	for _, name := range []string{"env", "limits.conf"} {
+		if _, err := manager.RemoveDropIn(ctx, "nginx", name); err != nil {
+			t.Fatalf("RemoveDropIn(%s) failed: %v", name, err)
+		}
+	}
+	// 删除最后一个覆盖文件后目录也被删除
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("Expected drop-in directory to be removed, got %v", err)
+	}
+	if _, err := manager.RemoveDropIn(ctx, "nginx", "env"); err == nil {
+		t.Error("Expected removing a missing drop-in to fail")
+	}
+
+	got := readCalls(t, calls)
+	if len(got) != 4 || strings.Count(strings.Join(got, "|"), "daemon-reload") != 4 {
+		t.Errorf("Expected a daemon-reload per change, got %q", got)
+	}
+}
+
+func TestSystemdScopeManager_DropInValidation(t *testing.T) {
+	manager, _ := newTestUnitFileManager(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"../escape", "a/b.conf", "bad name"} {
+		if _, err := manager.WriteDropIn(ctx, "nginx", name, "[Service]\n"); err == nil {
+			t.Errorf("Expected invalid drop-in name %q to be rejected", name)
+		}
+	}
+	if _, err := manager.WriteDropIn(ctx, "nginx", "empty", " \n"); err == nil {
+		t.Error("Expected empty drop-in to be rejected")
+	}
+	if _, err := manager.ListDropIns(WithScope(ctx, "user:alice"), "nginx"); err == nil {
+		t.Error("Expected another user's drop-ins to be rejected")
+	}
+}
+
+func TestSystemdScopeManager_CatUnit(t *testing.T) {
+	manager, calls := newTestUnitFileManager(t)
+
+	if _, err := manager.CatUnit(WithScope(context.Background(), "user"), "app"); err != nil {
+		t.Fatalf("CatUnit failed: %v", err)
+	}
+	if _, err := manager.CatUnit(context.Background(), "docker.socket"); err != nil {
+		t.Fatalf("CatUnit failed: %v", err)
+	}
+
+	got := readCalls(t, calls)
+	if len(got) != 2 || got[0] != "--user cat --no-pager app.service" || got[1] != "cat --no-pager docker.socket" {
+		t.Errorf("Unexpected calls: %q", got)
+	}
+}
@@ -0,0 +1,200 @@
+package managers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// ServiceLogs reads the unit's journal with journalctl, newest first, and
+// stops as soon as query.Lines matching entries have been read. Priority
+// and time filters are applied by journalctl, grep patterns here.
+func (sm *SystemdScopeManager) ServiceLogs(ctx context.Context, serviceName string, query LogQuery) ([]types.LogEntry, error) {
+	scope, err := sm.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args, err := journalArgs(scope, serviceName, query)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, "--reverse")
+	if query.Grep == nil {
+		args = append(args, "--lines="+strconv.Itoa(query.Lines))
+	}
+
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := commandContext(readCtx, sm.journalctl, args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run journalctl: %v", err)
+	}
+
+	entries := []types.LogEntry{}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() && len(entries) < query.Lines {
+		entry, err := parseJournalEntry(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		if query.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	complete := len(entries) < query.Lines
+	if !complete {
+		// Enough entries have been read; journalctl need not go on.
+		cancel()
+	}
+	if err := cmd.Wait(); err != nil && complete {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("journalctl failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("journalctl failed: %v", err)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// FollowLogs runs journalctl --follow for the unit. The backlog is the last
+// query.Lines journal entries, of which only those matching a grep pattern
+// are passed on.
+func (sm *SystemdScopeManager) FollowLogs(ctx context.Context, serviceName string, query LogQuery, emit func(types.LogEntry)) error {
+	scope, err := sm.scope(ctx)
+	if err != nil {
+		return err
+	}
+	args, err := journalArgs(scope, serviceName, query)
+	if err != nil {
+		return err
+	}
+	args = append(args, "--follow", "--lines="+strconv.Itoa(query.Lines))
+
+	var stderr bytes.Buffer
+	cmd := commandContext(ctx, sm.journalctl, args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run journalctl: %v", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		entry, err := parseJournalEntry(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		if query.Match(entry) {
+			emit(entry)
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if stderr.Len() > 0 {
+			return fmt.Errorf("journalctl failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("journalctl failed: %v", err)
+	}
+	return nil
+}
+
+// journalArgs selects the unit's entries for scope. User units are matched
+// by the user's UID as well, since their names are only unique per user.
+func journalArgs(scope SystemdScope, serviceName string, query LogQuery) ([]string, error) {
+	unitName := systemdUnitName(serviceName)
+	args := []string{"--output=json", "--no-pager"}
+	if scope.UserManager {
+		uid := strconv.Itoa(os.Getuid())
+		if scope.User != "" {
+			u, err := user.Lookup(scope.User)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up user %s: %v", scope.User, err)
+			}
+			uid = u.Uid
+		}
+		args = append(args, "--user-unit="+unitName, "_UID="+uid)
+	} else {
+		args = append(args, "--unit="+unitName)
+	}
+	if query.Priority < LogPriorityDebug {
+		args = append(args, "--priority="+strconv.Itoa(query.Priority))
+	}
+	if !query.Since.IsZero() {
+		args = append(args, "--since=@"+strconv.FormatInt(query.Since.Unix(), 10))
+	}
+	if !query.Until.IsZero() {
+		args = append(args, "--until=@"+strconv.FormatInt(query.Until.Unix(), 10))
+	}
+	return args, nil
+}
+
+// parseJournalEntry decodes a line of journalctl --output=json. Field
+// values are strings, or arrays of bytes when they are not valid UTF-8.
+func parseJournalEntry(line []byte) (types.LogEntry, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return types.LogEntry{}, err
+	}
+	field := func(name string) string {
+		var s string
+		if json.Unmarshal(fields[name], &s) == nil {
+			return s
+		}
+		var b []byte
+		var raw []int
+		if json.Unmarshal(fields[name], &raw) == nil {
+			for _, c := range raw {
+				b = append(b, byte(c))
+			}
+		}
+		return string(b)
+	}
+
+	entry := types.LogEntry{
+		Priority: LogPriorityInfo,
+		Message:  field("MESSAGE"),
+		Source:   field("SYSLOG_IDENTIFIER"),
+	}
+	if usec, err := strconv.ParseInt(field("__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+		entry.Time = time.UnixMicro(usec)
+	}
+	if priority, err := strconv.Atoi(field("PRIORITY")); err == nil {
+		entry.Priority = priority
+	}
+	if pid, err := strconv.Atoi(field("_PID")); err == nil {
+		entry.PID = pid
+	}
+	if entry.Source == "" {
+		entry.Source = field("_SYSTEMD_UNIT")
+	}
+	return entry, nil
+}
@@ -0,0 +1,330 @@
+package managers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const defaultLingerDir = "/var/lib/systemd/linger"
+
+type scopeKey struct{}
+
+// WithScope returns a context that asks the systemd manager to act in the
+// given scope instead of its configured default. See ParseSystemdScope.
+func WithScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFromContext returns the scope set by WithScope, or "".
+func ScopeFromContext(ctx context.Context) string {
+	scope, _ := ctx.Value(scopeKey{}).(string)
+	return scope
+}
+
+// ApplyScope attaches a per-request scope to ctx. Only systemd services
+// have scopes, so a scope without a service type selects systemd and a scope
+// with any other type is rejected.
+func ApplyScope(ctx context.Context, serviceType, scope string) (context.Context, string, error) {
+	if scope == "" {
+		return ctx, serviceType, nil
+	}
+	if serviceType == "" {
+		serviceType = string(types.ServiceTypeSystemd)
+	}
+	if serviceType != string(types.ServiceTypeSystemd) {
+		return ctx, serviceType, fmt.Errorf("scope is only supported for systemd services, not %s", serviceType)
+	}
+	if _, err := ParseSystemdScope(scope); err != nil {
+		return ctx, serviceType, err
+	}
+	return WithScope(ctx, scope), serviceType, nil
+}
+
+// SystemdScope selects a systemd instance: the system manager, the user
+// manager of the user running this server, or the user manager of a named
+// user.
+type SystemdScope struct {
+	UserManager bool
+	User        string
+}
+
+// ParseSystemdScope accepts "system", "user" or "user:<name>". An empty
+// string is the system scope.
+func ParseSystemdScope(scope string) (SystemdScope, error) {
+	switch {
+	case scope == "" || scope == "system":
+		return SystemdScope{}, nil
+	case scope == "user":
+		return SystemdScope{UserManager: true}, nil
+	case strings.HasPrefix(scope, "user:") && len(scope) > len("user:"):
+		return SystemdScope{UserManager: true, User: strings.TrimPrefix(scope, "user:")}, nil
+	default:
+		return SystemdScope{}, fmt.Errorf("invalid scope %q, expected system, user or user:<name>", scope)
+	}
+}
+
+func (s SystemdScope) String() string {
+	switch {
+	case !s.UserManager:
+		return "system"
+	case s.User == "":
+		return "user"
+	default:
+		return "user:" + s.User
+	}
+}
+
+// systemctlArgs are the systemctl options that connect to the scope's
+// manager; --machine=<user>@.host reaches another user's manager when
+// running as root.
+func (s SystemdScope) systemctlArgs() []string {
+	switch {
+	case !s.UserManager:
+		return nil
+	case s.User == "":
+		return []string{"--user"}
+	default:
+		return []string{"--user", "--machine=" + s.User + "@.host"}
+	}
+}
+
+// SystemdScopeManager is the systemd manager handed to the servers. It sends
+// each request to the system manager or to a user manager, chosen by the
+// scope in the request context or else the configured default, and can
+// list the units of user managers alongside system units.
+type SystemdScopeManager struct {
+	system       types.ServiceManager
+	systemctl    string
+	defaultScope SystemdScope
+	userUnits    bool
+	users        []string
+	lingerDir    string
+	// lingering adds lingering users to the listed scopes; only root can
+	// reach other users' managers.
+	lingering bool
+	logger    *logrus.Logger
+
+	// Unit file authoring, see UnitFileManager.
+	unitDir     string
+	userUnitDir string
+	backupDir   string
+	analyze     string
+
+	// journalctl reads unit logs, see LogProvider.
+	journalctl string
+	// cgroupRoot is where unit cgroups are read, see StatsProvider.
+	cgroupRoot string
+
+	mu                sync.Mutex
+	systemctlManagers map[SystemdScope]*SystemdManager
+}
+
+// NewSystemdScopeManager wraps system, which serves the system scope, with
+// the user scopes configured in cfg. User managers are driven through
+// systemctl.
+func NewSystemdScopeManager(system types.ServiceManager, cfg config.SystemdConfig, logger *logrus.Logger) (*SystemdScopeManager, error) {
+	defaultScope, err := ParseSystemdScope(cfg.Scope)
+	if err != nil {
+		return nil, err
+	}
+	unitDir := cfg.UnitDir
+	if unitDir == "" {
+		unitDir = defaultSystemdUnitDir
+	}
+	var userUnitDir string
+	if configDir, err := os.UserConfigDir(); err == nil {
+		userUnitDir = filepath.Join(configDir, "systemd", "user")
+	}
+	return &SystemdScopeManager{
+		system:            system,
+		systemctl:         "systemctl",
+		defaultScope:      defaultScope,
+		userUnits:         cfg.UserUnits,
+		users:             cfg.Users,
+		lingerDir:         defaultLingerDir,
+		lingering:         os.Geteuid() == 0,
+		logger:            logger,
+		unitDir:           unitDir,
+		userUnitDir:       userUnitDir,
+		backupDir:         cfg.BackupDir,
+		analyze:           "systemd-analyze",
+		journalctl:        "journalctl",
+		cgroupRoot:        defaultCgroupRoot,
+		systemctlManagers: make(map[SystemdScope]*SystemdManager),
+	}, nil
+}
+
+func (sm *SystemdScopeManager) Start(ctx context.Context, serviceName string) error {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return err
+	}
+	return manager.Start(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) Stop(ctx context.Context, serviceName string) error {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return err
+	}
+	return manager.Stop(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) Restart(ctx context.Context, serviceName string) error {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return err
+	}
+	return manager.Restart(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) Enable(ctx context.Context, serviceName string) error {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return err
+	}
+	return manager.Enable(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) Disable(ctx context.Context, serviceName string) error {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return err
+	}
+	return manager.Disable(ctx, serviceName)
+}
+
+func (sm *SystemdScopeManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return types.ServiceInfo{Name: serviceName, Type: types.ServiceTypeSystemd}, err
+	}
+	return manager.GetStatus(ctx, serviceName)
+}
+
+// ListServices lists the units of the requested scope. Without one it lists
+// the default scope and, when user units are enabled, the system manager and
+// every listed user's manager too. A user manager that cannot be reached,
+// typically because the user is not logged in and not lingering, is skipped.
+func (sm *SystemdScopeManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	if ScopeFromContext(ctx) != "" || !sm.userUnits {
+		manager, err := sm.manager(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return manager.ListServices(ctx)
+	}
+
+	services, err := sm.system.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range sm.listedUserScopes() {
+		userServices, err := sm.systemctlManager(scope).ListServices(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			sm.logger.Debugf("Skipping systemd %s units: %v", scope, err)
+			continue
+		}
+		services = append(services, userServices...)
+	}
+	return services, nil
+}
+
+// Close releases the system manager's resources.
+func (sm *SystemdScopeManager) Close() error {
+	if closer, ok := sm.system.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// scope returns the scope requested in ctx, or the default scope.
+func (sm *SystemdScopeManager) scope(ctx context.Context) (SystemdScope, error) {
+	if requested := ScopeFromContext(ctx); requested != "" {
+		return ParseSystemdScope(requested)
+	}
+	return sm.defaultScope, nil
+}
+
+// manager returns the manager for the scope requested in ctx.
+func (sm *SystemdScopeManager) manager(ctx context.Context) (types.ServiceManager, error) {
+	scope, err := sm.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.UserManager {
+		return sm.system, nil
+	}
+	return sm.systemctlManager(scope), nil
+}
+
+// systemctlManager returns a systemctl-driven manager for scope. User scopes
+// always use one; the system scope only for what D-Bus does not offer.
+func (sm *SystemdScopeManager) systemctlManager(scope SystemdScope) *SystemdManager {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	manager, exists := sm.systemctlManagers[scope]
+	if !exists {
+		manager = newSystemdManager(sm.systemctl, scope)
+		sm.systemctlManagers[scope] = manager
+	}
+	return manager
+}
+
+// listedUserScopes returns the user scopes whose units are listed next to
+// system units: the default scope if it is a user scope, the configured
+// users and, when running as root, every lingering user.
+func (sm *SystemdScopeManager) listedUserScopes() []SystemdScope {
+	var scopes []SystemdScope
+	seen := make(map[SystemdScope]bool)
+	add := func(scope SystemdScope) {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if sm.defaultScope.UserManager {
+		add(sm.defaultScope)
+	}
+	for _, user := range sm.users {
+		add(SystemdScope{UserManager: true, User: user})
+	}
+	if sm.lingering {
+		for _, user := range lingeringUsers(sm.lingerDir) {
+			add(SystemdScope{UserManager: true, User: user})
+		}
+	}
+	return scopes
+}
+
+// lingeringUsers returns the users for which logind keeps a user manager
+// running without a session. Each is a file named after the user in dir.
+func lingeringUsers(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var users []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			users = append(users, entry.Name())
+		}
+	}
+	sort.Strings(users)
+	return users
+}
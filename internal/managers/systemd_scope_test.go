@@ -0,0 +1,267 @@
+package managers
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// fakeSystemctlScript 根据 --user/--machine 参数返回不同实例的单元
+const fakeSystemctlScript = `#!/bin/sh
+echo "$*" >> "$0.calls"
+owner=system
+case "$*" in
+*--machine=carol@.host*)
+echo "Failed to connect to bus: No medium found" >&2
+exit 1
+;;
+*--machine=*)
+owner=$(echo "$*" | sed 's/.*--machine=\([^@]*\)@.*/\1/')
+;;
+--user*)
+owner=self
+;;
+esac
+case "$*" in
+*list-units*)
+echo "$owner-app.service loaded active running App of $owner"
+echo "$owner-job.service loaded failed failed Job of $owner"
+;;
+*is-active*)
+echo active
+;;
+*show\ idle*)
+echo "LoadState=loaded"
+echo "ControlGroup="
+;;
+*show*)
+echo "MainPID=77"
+echo "Description=App of $owner"
+echo "ControlGroup=/$owner.slice/app.service"
+;;
+esac
+`
+
+// newTestSystemctl 将 script 写为临时目录中的假 systemctl，返回使用它的管理器和调用记录文件
+func newTestSystemctl(t *testing.T, script string, scope SystemdScope) (*SystemdManager, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake systemctl script requires a POSIX shell")
+	}
+
+	systemctl := filepath.Join(t.TempDir(), "systemctl")
+	if err := os.WriteFile(systemctl, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake systemctl: %v", err)
+	}
+	return newSystemdManager(systemctl, scope), systemctl + ".calls"
+}
+
+// newTestSystemdScopeManager 使用假的 systemctl 同时充当系统实例和用户实例
+func newTestSystemdScopeManager(t *testing.T, cfg config.SystemdConfig) (*SystemdScopeManager, string) {
+	t.Helper()
+	system, calls := newTestSystemctl(t, fakeSystemctlScript, SystemdScope{})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	manager, err := NewSystemdScopeManager(system, cfg, logger)
+	if err != nil {
+		t.Fatalf("NewSystemdScopeManager failed: %v", err)
+	}
+	manager.systemctl = system.systemctl
+	manager.lingerDir = filepath.Join(filepath.Dir(system.systemctl), "linger")
+	manager.lingering = false
+	return manager, calls
+}
+
+func TestSystemdScopeManager_Interface(t *testing.T) {
+	var _ types.ServiceManager = (*SystemdScopeManager)(nil)
+	var _ io.Closer = (*SystemdScopeManager)(nil)
+}
+
+func TestParseSystemdScope(t *testing.T) {
+	valid := map[string]SystemdScope{
+		"":           {},
+		"system":     {},
+		"user":       {UserManager: true},
+		"user:alice": {UserManager: true, User: "alice"},
+	}
+	for input, expected := range valid {
+		scope, err := ParseSystemdScope(input)
+		if err != nil {
+			t.Errorf("ParseSystemdScope(%q) failed: %v", input, err)
+			continue
+		}
+		if scope != expected {
+			t.Errorf("ParseSystemdScope(%q) = %+v, expected %+v", input, scope, expected)
+		}
+	}
+
+	for _, input := range []string{"user:", "session", "users:alice"} {
+		if _, err := ParseSystemdScope(input); err == nil {
+			t.Errorf("Expected ParseSystemdScope(%q) to fail", input)
+		}
+	}
+
+	if got := (SystemdScope{UserManager: true, User: "alice"}).String(); got != "user:alice" {
+		t.Errorf("Unexpected scope string %q", got)
+	}
+}
+
+func TestApplyScope(t *testing.T) {
+	ctx, serviceType, err := ApplyScope(context.Background(), "", "user:alice")
+	if err != nil {
+		t.Fatalf("ApplyScope failed: %v", err)
+	}
+	if serviceType != "systemd" || ScopeFromContext(ctx) != "user:alice" {
+		t.Errorf("Expected systemd with scope, got %q and %q", serviceType, ScopeFromContext(ctx))
+	}
+
+	if _, _, err := ApplyScope(context.Background(), "docker", "user"); err == nil {
+		t.Error("Expected scope with a non-systemd type to be rejected")
+	}
+	if _, _, err := ApplyScope(context.Background(), "systemd", "bogus"); err == nil {
+		t.Error("Expected invalid scope to be rejected")
+	}
+
+	// 未指定作用域时不修改上下文
+	ctx, serviceType, err = ApplyScope(context.Background(), "docker", "")
+	if err != nil || serviceType != "docker" || ScopeFromContext(ctx) != "" {
+		t.Errorf("Expected request without scope to pass through, got %q, %v", serviceType, err)
+	}
+}
+
+func TestSystemdScopeManager_RequestScope(t *testing.T) {
+	manager, calls := newTestSystemdScopeManager(t, config.SystemdConfig{})
+
+	if err := manager.Start(context.Background(), "nginx"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := manager.Start(WithScope(context.Background(), "user:alice"), "app"); err != nil {
+		t.Fatalf("Start in user scope failed: %v", err)
+	}
+	if err := manager.Enable(WithScope(context.Background(), "user"), "app"); err != nil {
+		t.Fatalf("Enable in own user scope failed: %v", err)
+	}
+
+	info, err := manager.GetStatus(WithScope(context.Background(), "user:alice"), "app")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.Scope != "user:alice" || info.Description != "App of alice" || info.PID != 77 {
+		t.Errorf("Unexpected status: %+v", info)
+	}
+
+	got := readCalls(t, calls)
+	expected := []string{
+		"start nginx",
+		"--user --machine=alice@.host start app",
+		"--user enable app",
+		"--user --machine=alice@.host is-active app",
+	}
+	for i, call := range expected {
+		if i >= len(got) || got[i] != call {
+			t.Fatalf("Expected calls to start with %q, got %q", expected, got)
+		}
+	}
+
+	if _, err := manager.GetStatus(WithScope(context.Background(), "bogus"), "app"); err == nil {
+		t.Error("Expected invalid scope in context to be rejected")
+	}
+}
+
+func TestSystemdScopeManager_DefaultScope(t *testing.T) {
+	manager, calls := newTestSystemdScopeManager(t, config.SystemdConfig{Scope: "user:alice"})
+
+	if err := manager.Stop(context.Background(), "app"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	// 显式指定 system 时仍然使用系统实例
+	if err := manager.Stop(WithScope(context.Background(), "system"), "nginx"); err != nil {
+		t.Fatalf("Stop in system scope failed: %v", err)
+	}
+
+	got := readCalls(t, calls)
+	if len(got) != 2 || got[0] != "--user --machine=alice@.host stop app" || got[1] != "stop nginx" {
+		t.Errorf("Unexpected calls: %q", got)
+	}
+}
+
+func TestSystemdScopeManager_ListUserUnits(t *testing.T) {
+	manager, _ := newTestSystemdScopeManager(t, config.SystemdConfig{
+		UserUnits: true,
+		Users:     []string{"alice", "carol"},
+	})
+	if err := os.MkdirAll(manager.lingerDir, 0755); err != nil {
+		t.Fatalf("Failed to create linger dir: %v", err)
+	}
+	for _, user := range []string{"alice", "bob"} {
+		if err := os.WriteFile(filepath.Join(manager.lingerDir, user), nil, 0644); err != nil {
+			t.Fatalf("Failed to write linger file: %v", err)
+		}
+	}
+	manager.lingering = true
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	scopes := make(map[string]string)
+	for _, service := range services {
+		scopes[service.Name] = service.Scope
+	}
+	expected := map[string]string{
+		"system-app": "system",
+		"system-job": "system",
+		"alice-app":  "user:alice",
+		"alice-job":  "user:alice",
+		"bob-app":    "user:bob",
+		"bob-job":    "user:bob",
+	}
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %d services (carol unreachable), got %+v", len(expected), services)
+	}
+	for name, scope := range expected {
+		if scopes[name] != scope {
+			t.Errorf("Expected %s in scope %s, got %q", name, scope, scopes[name])
+		}
+	}
+
+	// 请求指定作用域时只列出该实例的单元
+	services, err = manager.ListServices(WithScope(context.Background(), "user:bob"))
+	if err != nil {
+		t.Fatalf("ListServices in user scope failed: %v", err)
+	}
+	if len(services) != 2 || services[0].Name != "bob-app" || services[1].Status != types.StatusFailed {
+		t.Errorf("Unexpected user services: %+v", services)
+	}
+}
+
+func TestSystemdScopeManager_ListWithoutUserUnits(t *testing.T) {
+	manager, calls := newTestSystemdScopeManager(t, config.SystemdConfig{Users: []string{"alice"}})
+
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(services) != 2 || services[0].Scope != "system" {
+		t.Errorf("Expected only system units, got %+v", services)
+	}
+	if got := readCalls(t, calls); len(got) != 1 {
+		t.Errorf("Expected a single systemctl call, got %q", got)
+	}
+}
+
+func TestNewSystemdScopeManager_InvalidScope(t *testing.T) {
+	if _, err := NewSystemdScopeManager(NewSystemdManager(), config.SystemdConfig{Scope: "session"}, logrus.New()); err == nil {
+		t.Error("Expected invalid default scope to be rejected")
+	}
+}
@@ -0,0 +1,71 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// ServiceStats reads the unit's resource usage from its cgroup. systemd
+// only keeps a cgroup while the unit has processes.
+func (sm *SystemdScopeManager) ServiceStats(ctx context.Context, serviceName string) (types.ServiceStats, error) {
+	scope, err := sm.scope(ctx)
+	if err != nil {
+		return types.ServiceStats{}, err
+	}
+	cgroup, err := sm.systemctlManager(scope).controlGroup(ctx, serviceName)
+	if err != nil {
+		return types.ServiceStats{}, err
+	}
+	if cgroup == "" {
+		return types.ServiceStats{}, fmt.Errorf("service %s has no cgroup, it is not running", serviceName)
+	}
+
+	stats, err := readCgroupStats(sm.cgroupRoot, cgroup)
+	stats.Name = serviceName
+	return stats, err
+}
+
+// ServicePIDs returns the processes in the unit's cgroup and in the
+// cgroups below it.
+func (sm *SystemdScopeManager) ServicePIDs(ctx context.Context, serviceName string) ([]int, error) {
+	scope, err := sm.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cgroup, err := sm.systemctlManager(scope).controlGroup(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if cgroup == "" {
+		return nil, fmt.Errorf("service %s has no cgroup, it is not running", serviceName)
+	}
+	return readCgroupPIDs(sm.cgroupRoot, cgroup)
+}
+
+// controlGroup returns the unit's ControlGroup property, its cgroup path
+// below the cgroup root.
+func (sm *SystemdManager) controlGroup(ctx context.Context, serviceName string) (string, error) {
+	output, err := sm.command(ctx, "show", serviceName, "--property=LoadState,ControlGroup").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cgroup of %s: %v", serviceName, err)
+	}
+
+	var cgroup string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		switch key {
+		case "LoadState":
+			if value == "not-found" {
+				return "", fmt.Errorf("service %s not found", serviceName)
+			}
+		case "ControlGroup":
+			cgroup = value
+		}
+	}
+	return cgroup, nil
+}
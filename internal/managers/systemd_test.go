@@ -1,6 +1,7 @@
 package managers
 
 import (
+	"context"
 	"testing"
 
 	"nucc.com/mcp_srv_mgr/pkg/types"
@@ -41,7 +42,7 @@ func TestSystemdManager_ParseServiceStatus(t *testing.T) {
 		t.Skip("systemd not available, skipping systemd manager tests")
 	}
 	
-	info, err := manager.GetStatus(serviceName)
+	info, err := manager.GetStatus(context.Background(), serviceName)
 	
 	// 验证返回的信息结构
 	if info.Name != serviceName {
@@ -83,7 +84,7 @@ func TestSystemdManager_ListServices(t *testing.T) {
 	}
 	
 	manager := NewSystemdManager()
-	services, err := manager.ListServices()
+	services, err := manager.ListServices(context.Background())
 	
 	if err != nil {
 		t.Fatalf("Failed to list systemd services: %v", err)
@@ -121,7 +122,7 @@ func TestSystemdManager_BasicOperations(t *testing.T) {
 	testService := "systemd-timesyncd"
 	
 	// 测试获取状态
-	_, err := manager.GetStatus(testService)
+	_, err := manager.GetStatus(context.Background(), testService)
 	if err != nil {
 		t.Logf("GetStatus failed (expected for non-root): %v", err)
 	}
@@ -130,31 +131,31 @@ func TestSystemdManager_BasicOperations(t *testing.T) {
 	// 在单元测试中，我们只测试这些方法不会panic
 	
 	// 测试启动（预期会失败，因为没有root权限）
-	err = manager.Start(testService)
+	err = manager.Start(context.Background(), testService)
 	if err != nil {
 		t.Logf("Start failed (expected for non-root): %v", err)
 	}
 	
 	// 测试停止（预期会失败，因为没有root权限）
-	err = manager.Stop(testService)
+	err = manager.Stop(context.Background(), testService)
 	if err != nil {
 		t.Logf("Stop failed (expected for non-root): %v", err)
 	}
 	
 	// 测试重启（预期会失败，因为没有root权限）
-	err = manager.Restart(testService)
+	err = manager.Restart(context.Background(), testService)
 	if err != nil {
 		t.Logf("Restart failed (expected for non-root): %v", err)
 	}
 	
 	// 测试启用（预期会失败，因为没有root权限）
-	err = manager.Enable(testService)
+	err = manager.Enable(context.Background(), testService)
 	if err != nil {
 		t.Logf("Enable failed (expected for non-root): %v", err)
 	}
 	
 	// 测试禁用（预期会失败，因为没有root权限）
-	err = manager.Disable(testService)
+	err = manager.Disable(context.Background(), testService)
 	if err != nil {
 		t.Logf("Disable failed (expected for non-root): %v", err)
 	}
@@ -170,7 +171,7 @@ func TestSystemdManager_NonexistentService(t *testing.T) {
 	nonexistentService := "definitely-does-not-exist-service-12345"
 	
 	// 获取不存在服务的状态应该返回错误或失败状态
-	info, err := manager.GetStatus(nonexistentService)
+	info, err := manager.GetStatus(context.Background(), nonexistentService)
 	
 	if err == nil && info.Status != types.StatusFailed && info.Status != types.StatusUnknown {
 		t.Errorf("Expected error or failed/unknown status for nonexistent service, got status: %s", info.Status)
@@ -188,7 +189,7 @@ func BenchmarkSystemdManager_GetStatus(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = manager.GetStatus(serviceName)
+		_, _ = manager.GetStatus(context.Background(), serviceName)
 	}
 }
 
@@ -201,6 +202,6 @@ func BenchmarkSystemdManager_ListServices(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = manager.ListServices()
+		_, _ = manager.ListServices(context.Background())
 	}
 }
\ No newline at end of file
@@ -0,0 +1,398 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const systemdTimerIface = "org.freedesktop.systemd1.Timer"
+
+// systemdTimerProperties are read with systemctl show for each timer.
+const systemdTimerProperties = "--property=Id,Description,Unit,ActiveState,NextElapseUSecRealtime,LastTriggerUSec"
+
+// TimerManager is implemented by the systemd managers. Timer names may be
+// given with or without the .timer suffix.
+type TimerManager interface {
+	ListTimers(ctx context.Context) ([]types.TimerInfo, error)
+	GetTimer(ctx context.Context, timerName string) (types.TimerInfo, error)
+	StartTimer(ctx context.Context, timerName string) error
+	StopTimer(ctx context.Context, timerName string) error
+	EnableTimer(ctx context.Context, timerName string) error
+	DisableTimer(ctx context.Context, timerName string) error
+	// TriggerTimer starts the unit the timer activates now, without
+	// waiting for the timer to elapse.
+	TriggerTimer(ctx context.Context, timerName string) error
+}
+
+// ManageTimer performs a timer action by name: start, stop, enable, disable
+// or trigger.
+func ManageTimer(ctx context.Context, timers TimerManager, timerName, action string) error {
+	switch action {
+	case "start":
+		return timers.StartTimer(ctx, timerName)
+	case "stop":
+		return timers.StopTimer(ctx, timerName)
+	case "enable":
+		return timers.EnableTimer(ctx, timerName)
+	case "disable":
+		return timers.DisableTimer(ctx, timerName)
+	case "trigger":
+		return timers.TriggerTimer(ctx, timerName)
+	default:
+		return fmt.Errorf("unsupported timer action: %s", action)
+	}
+}
+
+// timerUnitName appends the .timer suffix when the caller passed a bare name.
+func timerUnitName(name string) string {
+	if strings.HasSuffix(name, ".timer") {
+		return name
+	}
+	return name + ".timer"
+}
+
+func (sm *SystemdManager) ListTimers(ctx context.Context) ([]types.TimerInfo, error) {
+	cmd := sm.command(ctx, "list-units", "--type=timer", "--all", "--no-pager", "--plain", "--no-legend")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "●"))
+		if len(fields) > 0 && strings.HasSuffix(fields[0], ".timer") {
+			names = append(names, fields[0])
+		}
+	}
+	if len(names) == 0 {
+		return []types.TimerInfo{}, nil
+	}
+
+	cmd = sm.command(ctx, append([]string{"show", systemdTimerProperties}, names...)...)
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	timers := make([]types.TimerInfo, 0, len(names))
+	for _, props := range parseSystemdShow(string(output)) {
+		timers = append(timers, sm.timerInfo(props))
+	}
+	return timers, nil
+}
+
+func (sm *SystemdManager) GetTimer(ctx context.Context, timerName string) (types.TimerInfo, error) {
+	unitName := timerUnitName(timerName)
+	cmd := sm.command(ctx, "show", systemdTimerProperties+",LoadState", unitName)
+	output, err := cmd.Output()
+	if err != nil {
+		return types.TimerInfo{Name: strings.TrimSuffix(unitName, ".timer"), Scope: sm.scope.String()}, err
+	}
+
+	blocks := parseSystemdShow(string(output))
+	if len(blocks) == 0 || blocks[0]["LoadState"] == "not-found" {
+		return types.TimerInfo{Name: strings.TrimSuffix(unitName, ".timer"), Scope: sm.scope.String()}, fmt.Errorf("timer %s not found", unitName)
+	}
+	return sm.timerInfo(blocks[0]), nil
+}
+
+func (sm *SystemdManager) StartTimer(ctx context.Context, timerName string) error {
+	return sm.Start(ctx, timerUnitName(timerName))
+}
+
+func (sm *SystemdManager) StopTimer(ctx context.Context, timerName string) error {
+	return sm.Stop(ctx, timerUnitName(timerName))
+}
+
+func (sm *SystemdManager) EnableTimer(ctx context.Context, timerName string) error {
+	return sm.Enable(ctx, timerUnitName(timerName))
+}
+
+func (sm *SystemdManager) DisableTimer(ctx context.Context, timerName string) error {
+	return sm.Disable(ctx, timerUnitName(timerName))
+}
+
+func (sm *SystemdManager) TriggerTimer(ctx context.Context, timerName string) error {
+	timer, err := sm.GetTimer(ctx, timerName)
+	if err != nil {
+		return err
+	}
+	if timer.Unit == "" {
+		return fmt.Errorf("timer %s does not activate any unit", timerName)
+	}
+	return sm.Start(ctx, timer.Unit)
+}
+
+func (sm *SystemdManager) timerInfo(props map[string]string) types.TimerInfo {
+	timer := types.TimerInfo{
+		Name:        strings.TrimSuffix(props["Id"], ".timer"),
+		Unit:        props["Unit"],
+		Status:      systemdActiveStatus(props["ActiveState"]),
+		Description: props["Description"],
+		Scope:       sm.scope.String(),
+	}
+	if next, ok := parseSystemdTimestamp(props["NextElapseUSecRealtime"]); ok {
+		timer.NextElapse = next
+	}
+	if last, ok := parseSystemdTimestamp(props["LastTriggerUSec"]); ok {
+		timer.LastTrigger = last
+	}
+	return timer
+}
+
+// parseSystemdShow splits systemctl show output into one property map per
+// unit; units are separated by blank lines.
+func parseSystemdShow(output string) []map[string]string {
+	var blocks []map[string]string
+	current := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = make(map[string]string)
+			}
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			current[key] = value
+		}
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+// parseSystemdTimestamp parses a timestamp as printed by systemctl show,
+// either "Mon 2024-01-01 10:00:00 CET" or, with --timestamp=unix,
+// "@1704099600". systemctl prints local time with the zone's abbreviation.
+// Abbreviations the local zone does not define (time.Parse would give them
+// a zero offset) are read as local wall-clock time.
+func parseSystemdTimestamp(value string) (time.Time, bool) {
+	if value == "" || value == "n/a" {
+		return time.Time{}, false
+	}
+	if seconds, ok := strings.CutPrefix(value, "@"); ok {
+		sec, err := strconv.ParseInt(seconds, 10, 64)
+		if err != nil || sec <= 0 {
+			return time.Time{}, false
+		}
+		return time.Unix(sec, 0), true
+	}
+
+	t, err := time.ParseInLocation("Mon 2006-01-02 15:04:05 MST", value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if zone, _ := t.Zone(); t.Location() != time.Local && zone != "UTC" && zone != "GMT" {
+		wall := strings.TrimSuffix(value, " "+zone)
+		if t, err = time.ParseInLocation("Mon 2006-01-02 15:04:05", wall, time.Local); err != nil {
+			return time.Time{}, false
+		}
+	}
+	return t, true
+}
+
+func (sm *SystemdDBusManager) ListTimers(ctx context.Context) ([]types.TimerInfo, error) {
+	var units []systemdUnitStatus
+	if err := sm.manager().CallWithContext(ctx, systemdManagerIface+".ListUnits", 0).Store(&units); err != nil {
+		return nil, fmt.Errorf("failed to list units: %v", err)
+	}
+
+	timers := []types.TimerInfo{}
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, ".timer") {
+			continue
+		}
+		timer, err := sm.timerInfo(ctx, unit.Path, unit.Name, unit.Description, unit.ActiveState)
+		if err != nil {
+			return nil, err
+		}
+		timers = append(timers, timer)
+	}
+	return timers, nil
+}
+
+func (sm *SystemdDBusManager) GetTimer(ctx context.Context, timerName string) (types.TimerInfo, error) {
+	unitName := timerUnitName(timerName)
+	info := types.TimerInfo{Name: strings.TrimSuffix(unitName, ".timer"), Scope: "system"}
+
+	var unitPath dbus.ObjectPath
+	if err := sm.manager().CallWithContext(ctx, systemdManagerIface+".LoadUnit", 0, unitName).Store(&unitPath); err != nil {
+		return info, fmt.Errorf("timer %s not found: %v", unitName, err)
+	}
+
+	props, err := sm.getAllProperties(ctx, unitPath, systemdUnitIface)
+	if err != nil {
+		return info, err
+	}
+	if loadState, _ := props["LoadState"].Value().(string); loadState == "not-found" {
+		return info, fmt.Errorf("timer %s not found", unitName)
+	}
+
+	description, _ := props["Description"].Value().(string)
+	activeState, _ := props["ActiveState"].Value().(string)
+	return sm.timerInfo(ctx, unitPath, unitName, description, activeState)
+}
+
+func (sm *SystemdDBusManager) StartTimer(ctx context.Context, timerName string) error {
+	return sm.Start(ctx, timerUnitName(timerName))
+}
+
+func (sm *SystemdDBusManager) StopTimer(ctx context.Context, timerName string) error {
+	return sm.Stop(ctx, timerUnitName(timerName))
+}
+
+func (sm *SystemdDBusManager) EnableTimer(ctx context.Context, timerName string) error {
+	return sm.Enable(ctx, timerUnitName(timerName))
+}
+
+func (sm *SystemdDBusManager) DisableTimer(ctx context.Context, timerName string) error {
+	return sm.Disable(ctx, timerUnitName(timerName))
+}
+
+func (sm *SystemdDBusManager) TriggerTimer(ctx context.Context, timerName string) error {
+	timer, err := sm.GetTimer(ctx, timerName)
+	if err != nil {
+		return err
+	}
+	if timer.Unit == "" {
+		return fmt.Errorf("timer %s does not activate any unit", timerName)
+	}
+	return sm.Start(ctx, timer.Unit)
+}
+
+func (sm *SystemdDBusManager) timerInfo(ctx context.Context, path dbus.ObjectPath, unitName, description, activeState string) (types.TimerInfo, error) {
+	timer := types.TimerInfo{
+		Name:        strings.TrimSuffix(unitName, ".timer"),
+		Status:      systemdActiveStatus(activeState),
+		Description: description,
+		Scope:       "system",
+	}
+
+	props, err := sm.getAllProperties(ctx, path, systemdTimerIface)
+	if err != nil {
+		return timer, err
+	}
+	timer.Unit, _ = props["Unit"].Value().(string)
+	if usec, ok := props["NextElapseUSecRealtime"].Value().(uint64); ok {
+		timer.NextElapse = usecTime(usec)
+	}
+	if usec, ok := props["LastTriggerUSec"].Value().(uint64); ok {
+		timer.LastTrigger = usecTime(usec)
+	}
+	return timer, nil
+}
+
+// usecTime converts a systemd realtime timestamp in microseconds; zero and
+// infinity mean no time.
+func usecTime(usec uint64) time.Time {
+	if usec == 0 || usec == math.MaxUint64 {
+		return time.Time{}
+	}
+	return time.UnixMicro(int64(usec))
+}
+
+func (sm *SystemdScopeManager) ListTimers(ctx context.Context) ([]types.TimerInfo, error) {
+	if ScopeFromContext(ctx) != "" || !sm.userUnits {
+		timers, err := sm.timerManager(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return timers.ListTimers(ctx)
+	}
+
+	system, ok := sm.system.(TimerManager)
+	if !ok {
+		return nil, fmt.Errorf("timers are not supported by the systemd manager")
+	}
+	timers, err := system.ListTimers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range sm.listedUserScopes() {
+		userTimers, err := sm.systemctlManager(scope).ListTimers(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			sm.logger.Debugf("Skipping systemd %s timers: %v", scope, err)
+			continue
+		}
+		timers = append(timers, userTimers...)
+	}
+	return timers, nil
+}
+
+func (sm *SystemdScopeManager) GetTimer(ctx context.Context, timerName string) (types.TimerInfo, error) {
+	timers, err := sm.timerManager(ctx)
+	if err != nil {
+		return types.TimerInfo{Name: timerName}, err
+	}
+	return timers.GetTimer(ctx, timerName)
+}
+
+func (sm *SystemdScopeManager) StartTimer(ctx context.Context, timerName string) error {
+	timers, err := sm.timerManager(ctx)
+	if err != nil {
+		return err
+	}
+	return timers.StartTimer(ctx, timerName)
+}
+
+func (sm *SystemdScopeManager) StopTimer(ctx context.Context, timerName string) error {
+	timers, err := sm.timerManager(ctx)
+	if err != nil {
+		return err
+	}
+	return timers.StopTimer(ctx, timerName)
+}
+
+func (sm *SystemdScopeManager) EnableTimer(ctx context.Context, timerName string) error {
+	timers, err := sm.timerManager(ctx)
+	if err != nil {
+		return err
+	}
+	return timers.EnableTimer(ctx, timerName)
+}
+
+func (sm *SystemdScopeManager) DisableTimer(ctx context.Context, timerName string) error {
+	timers, err := sm.timerManager(ctx)
+	if err != nil {
+		return err
+	}
+	return timers.DisableTimer(ctx, timerName)
+}
+
+func (sm *SystemdScopeManager) TriggerTimer(ctx context.Context, timerName string) error {
+	timers, err := sm.timerManager(ctx)
+	if err != nil {
+		return err
+	}
+	return timers.TriggerTimer(ctx, timerName)
+}
+
+func (sm *SystemdScopeManager) timerManager(ctx context.Context) (TimerManager, error) {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timers, ok := manager.(TimerManager)
+	if !ok {
+		return nil, fmt.Errorf("timers are not supported by the systemd manager")
+	}
+	return timers, nil
+}
@@ -0,0 +1,223 @@
+package managers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5/prop"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// fakeTimerSystemctlScript 模拟 systemctl 对定时器的 list-units 和 show 输出
+const fakeTimerSystemctlScript = `#!/bin/sh
+echo "$*" >> "$0.calls"
+case "$*" in
+*list-units*)
+echo "backup.timer    loaded active  waiting Nightly backup"
+echo "cleanup.timer   loaded inactive dead   Cleanup"
+;;
+*show*missing.timer*)
+echo "Id=missing.timer"
+echo "LoadState=not-found"
+;;
+*show*)
+for unit in "$@"; do
+case "$unit" in
+backup.timer)
+echo "Id=backup.timer"
+echo "Description=Nightly backup"
+echo "Unit=backup.service"
+echo "ActiveState=active"
+echo "NextElapseUSecRealtime=Tue 2024-05-07 02:00:00 UTC"
+echo "LastTriggerUSec=Mon 2024-05-06 02:00:03 UTC"
+echo "LoadState=loaded"
+echo
+;;
+cleanup.timer)
+echo "Id=cleanup.timer"
+echo "Description=Cleanup"
+echo "Unit=cleanup.service"
+echo "ActiveState=inactive"
+echo "NextElapseUSecRealtime="
+echo "LastTriggerUSec=n/a"
+echo "LoadState=loaded"
+echo
+;;
+esac
+done
+;;
+esac
+`
+
+func TestSystemdManagers_TimerInterface(t *testing.T) {
+	var _ TimerManager = (*SystemdManager)(nil)
+	var _ TimerManager = (*SystemdDBusManager)(nil)
+	var _ TimerManager = (*SystemdScopeManager)(nil)
+}
+
+func TestSystemdManager_ListTimers(t *testing.T) {
+	manager, _ := newTestSystemctl(t, fakeTimerSystemctlScript, SystemdScope{})
+
+	timers, err := manager.ListTimers(context.Background())
+	if err != nil {
+		t.Fatalf("ListTimers failed: %v", err)
+	}
+	if len(timers) != 2 {
+		t.Fatalf("Expected 2 timers, got %+v", timers)
+	}
+
+	backup := timers[0]
+	if backup.Name != "backup" || backup.Unit != "backup.service" || backup.Status != types.StatusActive || backup.Scope != "system" {
+		t.Errorf("Unexpected timer: %+v", backup)
+	}
+	if !backup.NextElapse.Equal(time.Date(2024, 5, 7, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected next elapse: %s", backup.NextElapse)
+	}
+	if !backup.LastTrigger.Equal(time.Date(2024, 5, 6, 2, 0, 3, 0, time.UTC)) {
+		t.Errorf("Unexpected last trigger: %s", backup.LastTrigger)
+	}
+
+	// 未调度的定时器没有时间
+	cleanup := timers[1]
+	if cleanup.Status != types.StatusInactive || !cleanup.NextElapse.IsZero() || !cleanup.LastTrigger.IsZero() {
+		t.Errorf("Unexpected timer: %+v", cleanup)
+	}
+}
+
+func TestSystemdManager_TimerActions(t *testing.T) {
+	manager, calls := newTestSystemctl(t, fakeTimerSystemctlScript, SystemdScope{UserManager: true})
+	ctx := context.Background()
+
+	for _, action := range []string{"start", "stop", "enable", "disable", "trigger"} {
+		if err := ManageTimer(ctx, manager, "backup", action); err != nil {
+			t.Fatalf("%s failed: %v", action, err)
+		}
+	}
+	if err := ManageTimer(ctx, manager, "backup", "explode"); err == nil {
+		t.Error("Expected unsupported action to fail")
+	}
+	if _, err := manager.GetTimer(ctx, "missing"); err == nil {
+		t.Error("Expected missing timer to fail")
+	}
+
+	got := readCalls(t, calls)
+	expected := []string{
+		"--user start backup.timer",
+		"--user stop backup.timer",
+		"--user enable backup.timer",
+		"--user disable backup.timer",
+		"--user show " + systemdTimerProperties + ",LoadState backup.timer",
+		"--user start backup.service",
+	}
+	for i, call := range expected {
+		if i >= len(got) || got[i] != call {
+			t.Fatalf("Expected calls to start with %q, got %q", expected, got)
+		}
+	}
+}
+
+func TestParseSystemdShow(t *testing.T) {
+	blocks := parseSystemdShow("Id=a.timer\nUnit=a.service\n\n\nId=b.timer\nDescription=x=y\n")
+	if len(blocks) != 2 {
+		t.Fatalf("Expected 2 blocks, got %+v", blocks)
+	}
+	if blocks[0]["Unit"] != "a.service" || blocks[1]["Description"] != "x=y" {
+		t.Errorf("Unexpected blocks: %+v", blocks)
+	}
+}
+
+func TestParseSystemdTimestamp(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("CET", 3600)
+	defer func() { time.Local = local }()
+
+	expected := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for _, value := range []string{
+		"Mon 2024-01-01 10:00:00 CET",
+		"Mon 2024-01-01 09:00:00 UTC",
+		"@1704099600",
+		// 本地时区未定义的缩写按本地时间解析，而不是当作 UTC
+		"Mon 2024-01-01 10:00:00 XYZ",
+		"Mon 2024-01-01 10:00:00.250000 CET",
+	} {
+		got, ok := parseSystemdTimestamp(value)
+		if !ok || !got.Truncate(time.Second).Equal(expected) {
+			t.Errorf("parseSystemdTimestamp(%q) = %s, %v, expected %s", value, got, ok, expected)
+		}
+	}
+
+	for _, value := range []string{"", "n/a", "@0", "yesterday"} {
+		if _, ok := parseSystemdTimestamp(value); ok {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+// addTimer 导出一个定时器单元及其 Timer 接口属性
+func (f *fakeSystemd) addTimer(t *testing.T, name, unit string, next, last uint64) {
+	t.Helper()
+
+	props, err := prop.Export(f.conn, unitObjectPath(name), prop.Map{
+		systemdUnitIface: {
+			"Description": {Value: "Fake " + name, Emit: prop.EmitFalse},
+			"LoadState":   {Value: "loaded", Emit: prop.EmitFalse},
+			"ActiveState": {Value: "active", Emit: prop.EmitFalse},
+			"SubState":    {Value: "waiting", Emit: prop.EmitFalse},
+		},
+		systemdTimerIface: {
+			"Unit":                   {Value: unit, Emit: prop.EmitFalse},
+			"NextElapseUSecRealtime": {Value: next, Emit: prop.EmitFalse},
+			"LastTriggerUSec":        {Value: last, Emit: prop.EmitFalse},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to export timer %s: %v", name, err)
+	}
+
+	f.mu.Lock()
+	f.units[name] = props
+	f.mu.Unlock()
+}
+
+func TestSystemdDBusManager_Timers(t *testing.T) {
+	manager, fake := newTestSystemdDBusManager(t)
+	next := time.Date(2024, 5, 7, 2, 0, 0, 0, time.UTC)
+	fake.addTimer(t, "backup.timer", "nginx.service", uint64(next.UnixMicro()), 0)
+	ctx := context.Background()
+
+	timers, err := manager.ListTimers(ctx)
+	if err != nil {
+		t.Fatalf("ListTimers failed: %v", err)
+	}
+	if len(timers) != 1 {
+		t.Fatalf("Expected only the timer unit, got %+v", timers)
+	}
+	if timers[0].Name != "backup" || timers[0].Unit != "nginx.service" || !timers[0].NextElapse.Equal(next) || !timers[0].LastTrigger.IsZero() {
+		t.Errorf("Unexpected timer: %+v", timers[0])
+	}
+
+	// 定时器不应出现在服务列表中
+	services, err := manager.ListServices(ctx)
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(services) != 2 {
+		t.Errorf("Expected timers to be excluded from services, got %d services", len(services))
+	}
+
+	if err := manager.StopTimer(ctx, "backup"); err != nil {
+		t.Fatalf("StopTimer failed: %v", err)
+	}
+	if timer, _ := manager.GetTimer(ctx, "backup.timer"); timer.Status != types.StatusInactive {
+		t.Errorf("Expected stopped timer, got %+v", timer)
+	}
+
+	if err := manager.TriggerTimer(ctx, "backup"); err != nil {
+		t.Fatalf("TriggerTimer failed: %v", err)
+	}
+	if _, err := manager.GetTimer(ctx, "missing"); err == nil {
+		t.Error("Expected missing timer to fail")
+	}
+}
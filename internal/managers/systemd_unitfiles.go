@@ -0,0 +1,306 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const defaultSystemdUnitDir = "/etc/systemd/system"
+
+var unitFileNamePattern = regexp.MustCompile(`^[A-Za-z0-9:_.@\\-]+\.(service|socket|timer|target|mount|automount|path|slice)$`)
+
+// UnitFileManager installs and removes unit files. Every change is checked
+// with systemd-analyze verify first and followed by a daemon-reload; the
+// previous version of a replaced or removed file is kept as a backup.
+type UnitFileManager interface {
+	GetUnitFile(ctx context.Context, unitName string) (types.UnitFileInfo, error)
+	WriteUnitFile(ctx context.Context, unitName, content string) (types.UnitFileInfo, error)
+	// RemoveUnitFile stops and disables the unit before removing its file.
+	RemoveUnitFile(ctx context.Context, unitName string) (types.UnitFileInfo, error)
+}
+
+// daemonReloader is implemented by the systemd managers.
+type daemonReloader interface {
+	DaemonReload(ctx context.Context) error
+}
+
+// UnitFileContent returns the unit file text of a request: its raw content,
+// or its spec rendered with RenderUnitFile.
+func UnitFileContent(request types.UnitFileRequest) (string, error) {
+	switch {
+	case request.Content != "" && request.Spec != nil:
+		return "", fmt.Errorf("content and spec are mutually exclusive")
+	case request.Spec != nil:
+		return RenderUnitFile(*request.Spec)
+	case strings.TrimSpace(request.Content) != "":
+		return request.Content, nil
+	default:
+		return "", fmt.Errorf("content or spec is required")
+	}
+}
+
+// RenderUnitFile renders a service unit from spec.
+func RenderUnitFile(spec types.UnitFileSpec) (string, error) {
+	if strings.TrimSpace(spec.ExecStart) == "" {
+		return "", fmt.Errorf("exec_start is required")
+	}
+	switch spec.Restart {
+	case "", "no", "always", "on-success", "on-failure", "on-abnormal", "on-abort", "on-watchdog":
+	default:
+		return "", fmt.Errorf("invalid restart policy %q", spec.Restart)
+	}
+	// A newline in any value would end the directive and let the rest of
+	// the value inject directives of its own.
+	fields := map[string]string{
+		"description":       spec.Description,
+		"type":              spec.Type,
+		"exec_start":        spec.ExecStart,
+		"user":              spec.User,
+		"group":             spec.Group,
+		"working_directory": spec.WorkingDirectory,
+		"wanted_by":         spec.WantedBy,
+	}
+	for key, value := range spec.Environment {
+		if key == "" || strings.ContainsAny(key, `="`) || hasControlCharacter(key) {
+			return "", fmt.Errorf("invalid environment variable name %q", key)
+		}
+		fields["environment "+key] = value
+	}
+	for field, value := range fields {
+		if hasControlCharacter(value) {
+			return "", fmt.Errorf("%s must not contain control characters", field)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	if spec.Description != "" {
+		fmt.Fprintf(&b, "Description=%s\n", spec.Description)
+	}
+
+	b.WriteString("\n[Service]\n")
+	if spec.Type != "" {
+		fmt.Fprintf(&b, "Type=%s\n", spec.Type)
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", spec.ExecStart)
+	if spec.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", spec.User)
+	}
+	if spec.Group != "" {
+		fmt.Fprintf(&b, "Group=%s\n", spec.Group)
+	}
+	if spec.WorkingDirectory != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", spec.WorkingDirectory)
+	}
+	keys := make([]string, 0, len(spec.Environment))
+	for key := range spec.Environment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(spec.Environment[key])
+		fmt.Fprintf(&b, "Environment=\"%s=%s\"\n", key, value)
+	}
+	if spec.Restart != "" {
+		fmt.Fprintf(&b, "Restart=%s\n", spec.Restart)
+	}
+
+	if spec.WantedBy != "" {
+		b.WriteString("\n[Install]\n")
+		fmt.Fprintf(&b, "WantedBy=%s\n", spec.WantedBy)
+	}
+	return b.String(), nil
+}
+
+// hasControlCharacter reports whether s contains an ASCII control character.
+func hasControlCharacter(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0
+}
+
+func (sm *SystemdScopeManager) GetUnitFile(ctx context.Context, unitName string) (types.UnitFileInfo, error) {
+	path, err := sm.unitFilePath(ctx, unitName)
+	if err != nil {
+		return types.UnitFileInfo{Name: unitName}, err
+	}
+	info := types.UnitFileInfo{Name: filepath.Base(path), Path: path}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return info, fmt.Errorf("unit file %s not found", path)
+		}
+		return info, err
+	}
+	info.Content = string(content)
+	return info, nil
+}
+
+func (sm *SystemdScopeManager) WriteUnitFile(ctx context.Context, unitName, content string) (types.UnitFileInfo, error) {
+	path, err := sm.unitFilePath(ctx, unitName)
+	if err != nil {
+		return types.UnitFileInfo{Name: unitName}, err
+	}
+	info := types.UnitFileInfo{Name: filepath.Base(path), Path: path}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	if err := sm.verifyUnitFile(ctx, info.Name, content); err != nil {
+		return info, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return info, fmt.Errorf("failed to create unit directory: %v", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if info.Backup, err = sm.backupUnitFile(path, false); err != nil {
+			return info, err
+		}
+	}
+
+	// Write next to the target and rename so systemd never reads a
+	// partial file.
+	tmp := filepath.Join(filepath.Dir(path), "."+info.Name+".tmp")
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+		return info, fmt.Errorf("failed to write unit file: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return info, fmt.Errorf("failed to install unit file: %v", err)
+	}
+	info.Content = content
+
+	return info, sm.daemonReload(ctx)
+}
+
+func (sm *SystemdScopeManager) RemoveUnitFile(ctx context.Context, unitName string) (types.UnitFileInfo, error) {
+	path, err := sm.unitFilePath(ctx, unitName)
+	if err != nil {
+		return types.UnitFileInfo{Name: unitName}, err
+	}
+	info := types.UnitFileInfo{Name: filepath.Base(path), Path: path}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return info, fmt.Errorf("unit file %s not found", path)
+		}
+		return info, err
+	}
+
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return info, err
+	}
+	// The unit may be inactive or not loaded; removing the file is what
+	// matters.
+	if err := manager.Stop(ctx, info.Name); err != nil {
+		sm.logger.Debugf("Stopping %s before removal: %v", info.Name, err)
+	}
+	if err := manager.Disable(ctx, info.Name); err != nil {
+		sm.logger.Debugf("Disabling %s before removal: %v", info.Name, err)
+	}
+
+	if info.Backup, err = sm.backupUnitFile(path, true); err != nil {
+		return info, err
+	}
+	return info, sm.daemonReload(ctx)
+}
+
+// unitFilePath returns where the named unit's file lives for the scope in
+// ctx. Unit files of other users' managers are not managed, since the files
+// would have to be created in their home directories.
+func (sm *SystemdScopeManager) unitFilePath(ctx context.Context, unitName string) (string, error) {
+	unitName = systemdUnitName(unitName)
+	if !unitFileNamePattern.MatchString(unitName) {
+		return "", fmt.Errorf("invalid unit name %q", unitName)
+	}
+
+	scope, err := sm.scope(ctx)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case !scope.UserManager:
+		return filepath.Join(sm.unitDir, unitName), nil
+	case scope.User == "":
+		if sm.userUnitDir == "" {
+			return "", fmt.Errorf("cannot determine the user unit directory")
+		}
+		return filepath.Join(sm.userUnitDir, unitName), nil
+	default:
+		return "", fmt.Errorf("unit files can only be managed for the system or own user manager, not %s", scope)
+	}
+}
+
+// verifyUnitFile runs systemd-analyze verify on content saved under the
+// unit's name in a scratch directory.
+func (sm *SystemdScopeManager) verifyUnitFile(ctx context.Context, unitName, content string) error {
+	dir, err := os.MkdirTemp("", "unit-verify")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, unitName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	args := []string{"verify", path}
+	if scope, err := sm.scope(ctx); err == nil && scope.UserManager {
+		args = append([]string{"--user"}, args...)
+	}
+	output, err := commandContext(ctx, sm.analyze, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unit file verification failed: %v: %s", err, strings.TrimSpace(strings.ReplaceAll(string(output), dir+"/", "")))
+	}
+	return nil
+}
+
+// backupUnitFile copies path to its backup location, or moves it there when
+// remove is set, and returns the backup path.
+func (sm *SystemdScopeManager) backupUnitFile(path string, remove bool) (string, error) {
+	backup := path + ".bak"
+	if sm.backupDir != "" {
+		if err := os.MkdirAll(sm.backupDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create backup directory: %v", err)
+		}
+		backup = filepath.Join(sm.backupDir, filepath.Base(path)+".bak")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read unit file for backup: %v", err)
+	}
+	if err := os.WriteFile(backup, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up unit file: %v", err)
+	}
+	if remove {
+		if err := os.Remove(path); err != nil {
+			return backup, fmt.Errorf("failed to remove unit file: %v", err)
+		}
+	}
+	return backup, nil
+}
+
+func (sm *SystemdScopeManager) daemonReload(ctx context.Context) error {
+	manager, err := sm.manager(ctx)
+	if err != nil {
+		return err
+	}
+	reloader, ok := manager.(daemonReloader)
+	if !ok {
+		return fmt.Errorf("daemon-reload is not supported by the systemd manager")
+	}
+	if err := reloader.DaemonReload(ctx); err != nil {
+		return fmt.Errorf("daemon-reload failed: %v", err)
+	}
+	return nil
+}
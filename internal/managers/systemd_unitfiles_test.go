@@ -0,0 +1,213 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// fakeAnalyzeScript 在单元文件包含 Bogus 时模拟 systemd-analyze verify 失败
+const fakeAnalyzeScript = `#!/bin/sh
+echo "$*" >> "$0.calls"
+for unit; do :; done
+if grep -q Bogus "$unit"; then
+echo "$unit:3: Unknown key name 'Bogus' in section 'Service'" >&2
+exit 1
+fi
+`
+
+func newTestUnitFileManager(t *testing.T) (*SystemdScopeManager, string) {
+	t.Helper()
+	manager, calls := newTestSystemdScopeManager(t, config.SystemdConfig{})
+
+	dir := t.TempDir()
+	manager.analyze = filepath.Join(dir, "systemd-analyze")
+	if err := os.WriteFile(manager.analyze, []byte(fakeAnalyzeScript), 0755); err != nil {
+		t.Fatalf("Failed to write fake systemd-analyze: %v", err)
+	}
+	manager.unitDir = filepath.Join(dir, "system")
+	manager.userUnitDir = filepath.Join(dir, "user")
+	return manager, calls
+}
+
+func TestRenderUnitFile(t *testing.T) {
+	content, err := RenderUnitFile(types.UnitFileSpec{
+		Description: "Demo app",
+		ExecStart:   "/usr/local/bin/demo --port 8080",
+		User:        "demo",
+		Environment: map[string]string{"MODE": "prod", "GREETING": `say "hi"`},
+		Restart:     "on-failure",
+		WantedBy:    "multi-user.target",
+	})
+	if err != nil {
+		t.Fatalf("RenderUnitFile failed: %v", err)
+	}
+
+	expected := `[Unit]
+Description=Demo app
+
+[Service]
+ExecStart=/usr/local/bin/demo --port 8080
+User=demo
+Environment="GREETING=say \"hi\""
+Environment="MODE=prod"
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`
+	if content != expected {
+		t.Errorf("Unexpected unit file:\n%s", content)
+	}
+
+	if _, err := RenderUnitFile(types.UnitFileSpec{}); err == nil {
+		t.Error("Expected missing exec_start to fail")
+	}
+	if _, err := RenderUnitFile(types.UnitFileSpec{ExecStart: "/bin/true", Restart: "sometimes"}); err == nil {
+		t.Error("Expected invalid restart policy to fail")
+	}
+
+	// 换行会注入额外指令，必须拒绝
+	injections := []types.UnitFileSpec{
+		{ExecStart: "/bin/true", Description: "web\nExecStartPre=/bin/rm -rf /"},
+		{ExecStart: "/bin/true\nUser=root"},
+		{ExecStart: "/bin/true", User: "app\r"},
+		{ExecStart: "/bin/true", WorkingDirectory: "/srv\n[Install]"},
+		{ExecStart: "/bin/true", Environment: map[string]string{"MODE": "prod\nExecStartPost=/bin/sh"}},
+		{ExecStart: "/bin/true", Environment: map[string]string{"A\nB": "1"}},
+		{ExecStart: "/bin/true", Environment: map[string]string{"A=B": "1"}},
+	}
+	for _, spec := range injections {
+		if content, err := RenderUnitFile(spec); err == nil {
+			t.Errorf("Expected %+v to be rejected, got:\n%s", spec, content)
+		}
+	}
+}
+
+func TestUnitFileContent(t *testing.T) {
+	if content, err := UnitFileContent(types.UnitFileRequest{Content: "[Service]\nExecStart=/bin/true\n"}); err != nil || !strings.HasPrefix(content, "[Service]") {
+		t.Errorf("Expected raw content, got %q, %v", content, err)
+	}
+	if content, err := UnitFileContent(types.UnitFileRequest{Spec: &types.UnitFileSpec{ExecStart: "/bin/true"}}); err != nil || !strings.Contains(content, "ExecStart=/bin/true") {
+		t.Errorf("Expected rendered spec, got %q, %v", content, err)
+	}
+
+	for _, request := range []types.UnitFileRequest{
+		{},
+		{Content: "  \n"},
+		{Content: "[Service]\n", Spec: &types.UnitFileSpec{ExecStart: "/bin/true"}},
+	} {
+		if _, err := UnitFileContent(request); err == nil {
+			t.Errorf("Expected %+v to be rejected", request)
+		}
+	}
+}
+
+func TestSystemdScopeManager_WriteUnitFile(t *testing.T) {
+	manager, calls := newTestUnitFileManager(t)
+	ctx := context.Background()
+
+	info, err := manager.WriteUnitFile(ctx, "demo", "[Service]\nExecStart=/bin/true")
+	if err != nil {
+		t.Fatalf("WriteUnitFile failed: %v", err)
+	}
+	path := filepath.Join(manager.unitDir, "demo.service")
+	if info.Path != path || info.Backup != "" {
+		t.Errorf("Unexpected unit file info: %+v", info)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "[Service]\nExecStart=/bin/true\n" {
+		t.Errorf("Unexpected installed content %q", content)
+	}
+
+	// 覆盖时保留上一版本
+	info, err = manager.WriteUnitFile(ctx, "demo.service", "[Service]\nExecStart=/bin/false\n")
+	if err != nil {
+		t.Fatalf("WriteUnitFile failed: %v", err)
+	}
+	if backup, _ := os.ReadFile(info.Backup); info.Backup != path+".bak" || string(backup) != "[Service]\nExecStart=/bin/true\n" {
+		t.Errorf("Expected previous version in %s, got %q", info.Backup, backup)
+	}
+
+	// 校验失败时不修改已安装的文件
+	if _, err := manager.WriteUnitFile(ctx, "demo", "[Service]\nBogus=1\n"); err == nil || !strings.Contains(err.Error(), "Unknown key name") {
+		t.Fatalf("Expected verification failure, got %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "[Service]\nExecStart=/bin/false\n" {
+		t.Errorf("Expected installed file to be unchanged, got %q", content)
+	}
+
+	got := readCalls(t, calls)
+	if len(got) != 2 || got[0] != "daemon-reload" || got[1] != "daemon-reload" {
+		t.Errorf("Expected a daemon-reload per installed file, got %q", got)
+	}
+
+	unitFile, err := manager.GetUnitFile(ctx, "demo")
+	if err != nil || unitFile.Content != "[Service]\nExecStart=/bin/false\n" {
+		t.Errorf("Unexpected unit file %+v, %v", unitFile, err)
+	}
+}
+
+func TestSystemdScopeManager_RemoveUnitFile(t *testing.T) {
+	manager, calls := newTestUnitFileManager(t)
+	manager.backupDir = filepath.Join(t.TempDir(), "backups")
+	ctx := context.Background()
+
+	if _, err := manager.WriteUnitFile(ctx, "demo", "[Service]\nExecStart=/bin/true\n"); err != nil {
+		t.Fatalf("WriteUnitFile failed: %v", err)
+	}
+	info, err := manager.RemoveUnitFile(ctx, "demo")
+	if err != nil {
+		t.Fatalf("RemoveUnitFile failed: %v", err)
+	}
+	if _, err := os.Stat(info.Path); !os.IsNotExist(err) {
+		t.Errorf("Expected unit file to be removed, got %v", err)
+	}
+	if info.Backup != filepath.Join(manager.backupDir, "demo.service.bak") {
+		t.Errorf("Unexpected backup path %s", info.Backup)
+	}
+	if _, err := os.Stat(info.Backup); err != nil {
+		t.Errorf("Expected backup to exist: %v", err)
+	}
+
+	got := readCalls(t, calls)
+	expected := []string{"daemon-reload", "stop demo.service", "disable demo.service", "daemon-reload"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected calls %q, got %q", expected, got)
+	}
+
+	if _, err := manager.RemoveUnitFile(ctx, "demo"); err == nil {
+		t.Error("Expected removing a missing unit file to fail")
+	}
+}
+
+func TestSystemdScopeManager_UnitFileScopes(t *testing.T) {
+	manager, calls := newTestUnitFileManager(t)
+
+	info, err := manager.WriteUnitFile(WithScope(context.Background(), "user"), "demo", "[Service]\nExecStart=/bin/true\n")
+	if err != nil {
+		t.Fatalf("WriteUnitFile in user scope failed: %v", err)
+	}
+	if info.Path != filepath.Join(manager.userUnitDir, "demo.service") {
+		t.Errorf("Expected user unit directory, got %s", info.Path)
+	}
+	if got := readCalls(t, calls); len(got) != 1 || got[0] != "--user daemon-reload" {
+		t.Errorf("Expected user daemon-reload, got %q", got)
+	}
+	if got := readCalls(t, manager.analyze+".calls"); !strings.HasPrefix(got[0], "--user verify ") {
+		t.Errorf("Expected user verification, got %q", got)
+	}
+
+	if _, err := manager.WriteUnitFile(WithScope(context.Background(), "user:alice"), "demo", "[Service]\n"); err == nil {
+		t.Error("Expected another user's unit files to be rejected")
+	}
+	for _, name := range []string{"../evil.service", "demo app", "a/b.service"} {
+		if _, err := manager.WriteUnitFile(context.Background(), name, "[Service]\n"); err == nil {
+			t.Errorf("Expected invalid unit name %q to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package managers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const systemdSocketIface = "org.freedesktop.systemd1.Socket"
+
+// SystemdUnitTypes are the unit types the systemd managers list and report
+// status for. Timers have their own API, see TimerManager.
+var SystemdUnitTypes = []string{"service", "socket", "target", "mount", "automount", "path"}
+
+type unitTypesKey struct{}
+
+// WithUnitTypes returns a context that asks the systemd manager to list units
+// of the given types instead of services only.
+func WithUnitTypes(ctx context.Context, unitTypes []string) context.Context {
+	return context.WithValue(ctx, unitTypesKey{}, unitTypes)
+}
+
+// UnitTypesFromContext returns the unit types set by WithUnitTypes, or just
+// services.
+func UnitTypesFromContext(ctx context.Context) []string {
+	if unitTypes, ok := ctx.Value(unitTypesKey{}).([]string); ok && len(unitTypes) > 0 {
+		return unitTypes
+	}
+	return []string{"service"}
+}
+
+type installedUnitsKey struct{}
+
+// WithInstalledUnits returns a context that asks the systemd manager to list
+// every installed unit file, merged with the loaded units, instead of the
+// loaded units only. Other managers ignore it.
+func WithInstalledUnits(ctx context.Context, installed bool) context.Context {
+	return context.WithValue(ctx, installedUnitsKey{}, installed)
+}
+
+// InstalledUnitsFromContext reports whether WithInstalledUnits asked for
+// installed unit files.
+func InstalledUnitsFromContext(ctx context.Context) bool {
+	installed, _ := ctx.Value(installedUnitsKey{}).(bool)
+	return installed
+}
+
+// ParseUnitTypes accepts a comma-separated list of unit types, or "all" for
+// every type in SystemdUnitTypes.
+func ParseUnitTypes(value string) ([]string, error) {
+	if value == "all" {
+		return SystemdUnitTypes, nil
+	}
+
+	var unitTypes []string
+	for _, unitType := range strings.Split(value, ",") {
+		unitType = strings.TrimSpace(unitType)
+		if unitType == "" {
+			continue
+		}
+		if !containsString(SystemdUnitTypes, unitType) {
+			return nil, fmt.Errorf("invalid unit type %q, expected one of %s or all", unitType, strings.Join(SystemdUnitTypes, ", "))
+		}
+		if !containsString(unitTypes, unitType) {
+			unitTypes = append(unitTypes, unitType)
+		}
+	}
+	if len(unitTypes) == 0 {
+		return nil, fmt.Errorf("no unit type given")
+	}
+	return unitTypes, nil
+}
+
+// ApplyUnitType attaches a unit type filter for listing to ctx. Like scopes,
+// unit types only exist for systemd, so a filter without a service type
+// selects systemd and a filter with any other type is rejected.
+func ApplyUnitType(ctx context.Context, serviceType, unitType string) (context.Context, string, error) {
+	if unitType == "" {
+		return ctx, serviceType, nil
+	}
+	if serviceType == "" {
+		serviceType = string(types.ServiceTypeSystemd)
+	}
+	if serviceType != string(types.ServiceTypeSystemd) {
+		return ctx, serviceType, fmt.Errorf("unit type is only supported for systemd services, not %s", serviceType)
+	}
+	unitTypes, err := ParseUnitTypes(unitType)
+	if err != nil {
+		return ctx, serviceType, err
+	}
+	return WithUnitTypes(ctx, unitTypes), serviceType, nil
+}
+
+// ApplyInstalledUnits asks for installed unit files to be listed, see
+// WithInstalledUnits. Like ApplyUnitType it selects systemd when no service
+// type is given and rejects other types.
+func ApplyInstalledUnits(ctx context.Context, serviceType string, installed bool) (context.Context, string, error) {
+	if !installed {
+		return ctx, serviceType, nil
+	}
+	if serviceType == "" {
+		serviceType = string(types.ServiceTypeSystemd)
+	}
+	if serviceType != string(types.ServiceTypeSystemd) {
+		return ctx, serviceType, fmt.Errorf("installed unit files are only listed for systemd services, not %s", serviceType)
+	}
+	return WithInstalledUnits(ctx, true), serviceType, nil
+}
+
+// systemdUnitType returns the type of a unit from its suffix; bare names are
+// services.
+func systemdUnitType(name string) string {
+	unitName := systemdUnitName(name)
+	return unitName[strings.LastIndex(unitName, ".")+1:]
+}
+
+// systemdListName is the name a unit is listed under. Services drop their
+// suffix as they always have; other units keep it so the name can be passed
+// back to GetStatus.
+func systemdListName(unitName string) string {
+	return strings.TrimSuffix(unitName, ".service")
+}
+
+// mergeUnitFiles fills in the enablement state of the loaded units from
+// unitFiles, which maps unit names to their unit file state, and appends the
+// unit files of the requested types that are not loaded, sorted by name.
+// Template units such as getty@.service cannot run by themselves and are left
+// out.
+func mergeUnitFiles(services []types.ServiceInfo, unitFiles map[string]string, unitTypes []string, scope string) []types.ServiceInfo {
+	loaded := make(map[string]bool, len(services))
+	for i := range services {
+		unitName := systemdUnitName(services[i].Name)
+		loaded[unitName] = true
+		if state, ok := unitFiles[unitName]; ok {
+			services[i].EnabledState = state
+		}
+	}
+
+	var names []string
+	for unitName := range unitFiles {
+		unitType := systemdUnitType(unitName)
+		if loaded[unitName] || strings.Contains(unitName, "@.") ||
+			!strings.HasSuffix(unitName, "."+unitType) || !containsString(unitTypes, unitType) {
+			continue
+		}
+		names = append(names, unitName)
+	}
+	sort.Strings(names)
+
+	for _, unitName := range names {
+		services = append(services, types.ServiceInfo{
+			Name:         systemdListName(unitName),
+			Type:         types.ServiceTypeSystemd,
+			Status:       types.StatusInactive,
+			Scope:        scope,
+			UnitType:     systemdUnitType(unitName),
+			EnabledState: unitFiles[unitName],
+		})
+	}
+	return services
+}
+
+// formatSocketListen renders one entry of a socket's Listen property the way
+// systemctl show does.
+func formatSocketListen(listenType, address string) string {
+	return fmt.Sprintf("%s (%s)", address, listenType)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,347 @@
+package managers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5/prop"
+
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// fakeUnitsSystemctlScript 模拟多种单元类型的 list-units 输出以及套接字的监听地址
+const fakeUnitsSystemctlScript = `#!/bin/sh
+echo "$*" >> "$0.calls"
+case "$*" in
+*list-units*)
+echo "nginx.service        loaded active   running   Web server"
+echo "cron.service         loaded active   exited    Cron"
+echo "docker.socket        loaded active   listening Docker socket"
+echo "sshd.socket          loaded inactive dead      SSH socket"
+echo "home.mount           loaded active   mounted   /home"
+echo "multi-user.target    loaded active   active    Multi-User"
+;;
+*list-unit-files*)
+echo "nginx.service    enabled  enabled"
+echo "cron.service     static   -"
+echo "backup.service   disabled enabled"
+echo "legacy.service   masked   enabled"
+echo "getty@.service   enabled  enabled"
+echo "docker.socket    enabled  enabled"
+;;
+*"is-active ghost"*)
+echo inactive
+exit 3
+;;
+*"show ghost "*)
+echo "LoadState=not-found"
+;;
+*"is-active backup"*)
+echo inactive
+exit 3
+;;
+*show*--property=Id,Listen*)
+echo "Id=docker.socket"
+echo "Listen=/run/docker.sock (Stream)"
+echo
+echo "Id=sshd.socket"
+echo "Listen=[::]:22 (Stream)"
+echo "Listen=0.0.0.0:2222 (Stream)"
+;;
+*is-active*)
+echo active
+;;
+*"show nginx "*)
+echo "MainPID=1234"
+echo "Description=Web server"
+echo "ActiveEnterTimestamp=@1704099600"
+echo "LoadState=loaded"
+echo "SubState=running"
+echo "UnitFileState=enabled"
+echo "FragmentPath=/lib/systemd/system/nginx.service"
+echo "NRestarts=2"
+echo "ExecMainCode=2"
+echo "ExecMainStatus=9"
+echo "MemoryCurrent=10485760"
+;;
+*show*)
+echo "MainPID=0"
+echo "Description=Docker socket"
+echo "Listen=/run/docker.sock (Stream)"
+;;
+esac
+`
+
+func TestParseUnitTypes(t *testing.T) {
+	valid := map[string][]string{
+		"socket":               {"socket"},
+		"service, mount":       {"service", "mount"},
+		"socket,socket,target": {"socket", "target"},
+		"all":                  SystemdUnitTypes,
+	}
+	for input, expected := range valid {
+		unitTypes, err := ParseUnitTypes(input)
+		if err != nil {
+			t.Errorf("ParseUnitTypes(%q) failed: %v", input, err)
+			continue
+		}
+		if !reflect.DeepEqual(unitTypes, expected) {
+			t.Errorf("ParseUnitTypes(%q) = %v, expected %v", input, unitTypes, expected)
+		}
+	}
+
+	for _, input := range []string{"timer", "socket,bogus", ","} {
+		if _, err := ParseUnitTypes(input); err == nil {
+			t.Errorf("Expected ParseUnitTypes(%q) to fail", input)
+		}
+	}
+}
+
+func TestApplyUnitType(t *testing.T) {
+	ctx, serviceType, err := ApplyUnitType(context.Background(), "", "socket")
+	if err != nil {
+		t.Fatalf("ApplyUnitType failed: %v", err)
+	}
+	if serviceType != "systemd" || !reflect.DeepEqual(UnitTypesFromContext(ctx), []string{"socket"}) {
+		t.Errorf("Expected systemd sockets, got %q and %v", serviceType, UnitTypesFromContext(ctx))
+	}
+
+	if _, _, err := ApplyUnitType(context.Background(), "docker", "socket"); err == nil {
+		t.Error("Expected unit type with a non-systemd type to be rejected")
+	}
+
+	// 未指定单元类型时只列出服务
+	if got := UnitTypesFromContext(context.Background()); !reflect.DeepEqual(got, []string{"service"}) {
+		t.Errorf("Expected services by default, got %v", got)
+	}
+}
+
+func TestSystemdUnitType(t *testing.T) {
+	testCases := map[string]string{
+		"nginx":          "service",
+		"nginx.service":  "service",
+		"docker.socket":  "socket",
+		"home.mount":     "mount",
+		"proc.automount": "automount",
+		"foo.bar":        "service",
+	}
+	for input, expected := range testCases {
+		if got := systemdUnitType(input); got != expected {
+			t.Errorf("systemdUnitType(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestSystemdManager_ListUnitTypes(t *testing.T) {
+	manager, calls := newTestSystemctl(t, fakeUnitsSystemctlScript, SystemdScope{})
+
+	// 默认只列出服务，且不查询套接字
+	services, err := manager.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(services) != 2 || services[0].Name != "nginx" || services[0].UnitType != "service" || services[1].Status != types.StatusInactive {
+		t.Errorf("Unexpected services: %+v", services)
+	}
+	if got := readCalls(t, calls); len(got) != 1 || got[0] != "list-units --type=service --no-pager --plain" {
+		t.Errorf("Unexpected calls: %q", got)
+	}
+
+	units, err := manager.ListServices(WithUnitTypes(context.Background(), []string{"socket", "mount"}))
+	if err != nil {
+		t.Fatalf("ListServices with unit types failed: %v", err)
+	}
+	byName := make(map[string]types.ServiceInfo)
+	for _, unit := range units {
+		byName[unit.Name] = unit
+	}
+	if len(units) != 3 {
+		t.Fatalf("Expected sockets and mounts only, got %+v", units)
+	}
+	if docker := byName["docker.socket"]; docker.Status != types.StatusActive || !reflect.DeepEqual(docker.Listen, []string{"/run/docker.sock (Stream)"}) {
+		t.Errorf("Unexpected socket: %+v", docker)
+	}
+	if sshd := byName["sshd.socket"]; sshd.Status != types.StatusInactive || len(sshd.Listen) != 2 {
+		t.Errorf("Unexpected socket: %+v", sshd)
+	}
+	if home := byName["home.mount"]; home.UnitType != "mount" || home.Status != types.StatusActive || home.Listen != nil {
+		t.Errorf("Unexpected mount: %+v", home)
+	}
+}
+
+func TestSystemdManager_ListInstalledUnits(t *testing.T) {
+	manager, calls := newTestSystemctl(t, fakeUnitsSystemctlScript, SystemdScope{})
+
+	services, err := manager.ListServices(WithInstalledUnits(context.Background(), true))
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	// 已加载的单元在前，未加载的单元文件按名称排在后面；模板和其他类型的单元被忽略
+	var got []string
+	for _, service := range services {
+		got = append(got, service.Name+":"+string(service.Status)+":"+service.EnabledState)
+	}
+	expected := []string{"nginx:active:enabled", "cron:inactive:static", "backup:inactive:disabled", "legacy:inactive:masked"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if services[0].LoadState != "loaded" || services[2].LoadState != "" {
+		t.Errorf("Expected only loaded units to have a load state: %+v", services)
+	}
+
+	if calls := readCalls(t, calls); len(calls) != 2 || calls[1] != "list-unit-files --type=service --no-pager --plain --no-legend" {
+		t.Errorf("Unexpected calls: %q", calls)
+	}
+}
+
+func TestSystemdManager_GetStatusNotLoaded(t *testing.T) {
+	manager, _ := newTestSystemctl(t, fakeUnitsSystemctlScript, SystemdScope{})
+
+	// is-active 对未运行的单元返回非零退出码，但状态仍然有效
+	info, err := manager.GetStatus(context.Background(), "backup")
+	if err != nil || info.Status != types.StatusInactive {
+		t.Errorf("Expected inactive status, got %+v, %v", info, err)
+	}
+
+	if _, err := manager.GetStatus(context.Background(), "ghost"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected missing unit to be reported, got %v", err)
+	}
+}
+
+func TestSystemdManager_SocketStatus(t *testing.T) {
+	manager, calls := newTestSystemctl(t, fakeUnitsSystemctlScript, SystemdScope{})
+
+	info, err := manager.GetStatus(context.Background(), "docker.socket")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.UnitType != "socket" || info.Status != types.StatusActive || !reflect.DeepEqual(info.Listen, []string{"/run/docker.sock (Stream)"}) {
+		t.Errorf("Unexpected status: %+v", info)
+	}
+
+	got := readCalls(t, calls)
+	if len(got) != 2 || got[1] != "show docker.socket --property=MainPID,Description,ActiveEnterTimestamp,LoadState,SubState,UnitFileState,FragmentPath,Listen" {
+		t.Errorf("Unexpected calls: %q", got)
+	}
+}
+
+func TestSystemdManager_ServiceStatusDetails(t *testing.T) {
+	manager, _ := newTestSystemctl(t, fakeUnitsSystemctlScript, SystemdScope{})
+
+	info, err := manager.GetStatus(context.Background(), "nginx")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.EnabledState != "enabled" || info.SubState != "running" || info.LoadState != "loaded" || info.UnitPath != "/lib/systemd/system/nginx.service" {
+		t.Errorf("Unexpected unit states: %+v", info)
+	}
+	// 被 SIGKILL 终止的主进程按 shell 惯例报告 128+9
+	if info.Restarts != 2 || info.ExitCode == nil || *info.ExitCode != 137 || info.MemoryBytes != 10<<20 {
+		t.Errorf("Unexpected process details: %+v", info)
+	}
+	if info.ActiveSince == nil || !info.ActiveSince.Equal(time.Unix(1704099600, 0)) || !info.LastChanged.Equal(*info.ActiveSince) {
+		t.Errorf("Unexpected active since: %s", info.ActiveSince)
+	}
+}
+
+func TestSystemdExitCode(t *testing.T) {
+	if code := systemdExitCode(0, 0); code != nil {
+		t.Errorf("Expected no exit code before the process exits, got %d", *code)
+	}
+	if code := systemdExitCode(1, 3); code == nil || *code != 3 {
+		t.Errorf("Expected exit status 3, got %v", code)
+	}
+	if code := systemdExitCode(3, 11); code == nil || *code != 139 {
+		t.Errorf("Expected 128+SIGSEGV for a core dump, got %v", code)
+	}
+}
+
+// addSocket 导出一个套接字单元及其 Socket 接口属性
+func (f *fakeSystemd) addSocket(t *testing.T, name string, listen [][]string) {
+	t.Helper()
+
+	var entries []struct{ Type, Address string }
+	for _, entry := range listen {
+		entries = append(entries, struct{ Type, Address string }{entry[0], entry[1]})
+	}
+	props, err := prop.Export(f.conn, unitObjectPath(name), prop.Map{
+		systemdUnitIface: {
+			"Description": {Value: "Fake " + name, Emit: prop.EmitFalse},
+			"LoadState":   {Value: "loaded", Emit: prop.EmitFalse},
+			"ActiveState": {Value: "active", Emit: prop.EmitFalse},
+			"SubState":    {Value: "listening", Emit: prop.EmitFalse},
+		},
+		systemdSocketIface: {
+			"Listen": {Value: entries, Emit: prop.EmitFalse},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to export socket %s: %v", name, err)
+	}
+
+	f.mu.Lock()
+	f.units[name] = props
+	f.mu.Unlock()
+}
+
+func TestSystemdDBusManager_UnitTypes(t *testing.T) {
+	manager, fake := newTestSystemdDBusManager(t)
+	fake.addSocket(t, "docker.socket", [][]string{{"Stream", "/run/docker.sock"}, {"Stream", "[::]:2375"}})
+	ctx := WithUnitTypes(context.Background(), []string{"socket", "target"})
+
+	units, err := manager.ListServices(ctx)
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(units) != 2 {
+		t.Fatalf("Expected the socket and the target, got %+v", units)
+	}
+	for _, unit := range units {
+		switch unit.Name {
+		case "docker.socket":
+			if !reflect.DeepEqual(unit.Listen, []string{"/run/docker.sock (Stream)", "[::]:2375 (Stream)"}) {
+				t.Errorf("Unexpected listen addresses: %v", unit.Listen)
+			}
+		case "multi-user.target":
+			if unit.UnitType != "target" || unit.Status != types.StatusActive {
+				t.Errorf("Unexpected target: %+v", unit)
+			}
+		default:
+			t.Errorf("Unexpected unit %s", unit.Name)
+		}
+	}
+
+	info, err := manager.GetStatus(context.Background(), "docker.socket")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if info.UnitType != "socket" || len(info.Listen) != 2 || info.PID != 0 {
+		t.Errorf("Unexpected status: %+v", info)
+	}
+}
+
+func TestSystemdDBusManager_ListInstalledUnits(t *testing.T) {
+	manager, fake := newTestSystemdDBusManager(t)
+	fake.addSocket(t, "docker.socket", [][]string{{"Stream", "/run/docker.sock"}})
+
+	services, err := manager.ListServices(WithInstalledUnits(context.Background(), true))
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	byName := make(map[string]types.ServiceInfo)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+	if len(services) != 3 {
+		t.Fatalf("Expected nginx, cron and the unloaded backup service, got %+v", services)
+	}
+	if backup := byName["backup"]; backup.Status != types.StatusInactive || backup.EnabledState != "disabled" || backup.UnitType != "service" {
+		t.Errorf("Unexpected unit file entry: %+v", backup)
+	}
+	if nginx := byName["nginx"]; nginx.EnabledState != "disabled" || nginx.LoadState != "loaded" {
+		t.Errorf("Unexpected loaded unit: %+v", nginx)
+	}
+}
@@ -2,6 +2,7 @@ package managers
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,82 +16,109 @@ import (
 
 type SysVManager struct {
 	initDPath string
+	// logFiles maps a service to the files it logs to; services without
+	// an entry are looked up in logDir.
+	logFiles map[string][]string
+	logDir   string
+	// runDirs are searched for a service's pidfile, see ProcessProvider.
+	runDirs  []string
+	procRoot string
 }
 
 func NewSysVManager() *SysVManager {
 	return &SysVManager{
 		initDPath: "/etc/init.d",
+		logDir:    "/var/log",
+		runDirs:   []string{"/run", "/var/run"},
+		procRoot:  defaultProcRoot,
 	}
 }
 
-func (sv *SysVManager) Start(serviceName string) error {
+func (sv *SysVManager) Start(ctx context.Context, serviceName string) error {
 	scriptPath := filepath.Join(sv.initDPath, serviceName)
 	if !sv.serviceExists(serviceName) {
 		return fmt.Errorf("service %s not found", serviceName)
 	}
-	cmd := exec.Command(scriptPath, "start")
+	cmd := commandContext(ctx, scriptPath, "start")
 	return cmd.Run()
 }
 
-func (sv *SysVManager) Stop(serviceName string) error {
+func (sv *SysVManager) Stop(ctx context.Context, serviceName string) error {
 	scriptPath := filepath.Join(sv.initDPath, serviceName)
 	if !sv.serviceExists(serviceName) {
 		return fmt.Errorf("service %s not found", serviceName)
 	}
-	cmd := exec.Command(scriptPath, "stop")
+	cmd := commandContext(ctx, scriptPath, "stop")
 	return cmd.Run()
 }
 
-func (sv *SysVManager) Restart(serviceName string) error {
+func (sv *SysVManager) Restart(ctx context.Context, serviceName string) error {
 	scriptPath := filepath.Join(sv.initDPath, serviceName)
 	if !sv.serviceExists(serviceName) {
 		return fmt.Errorf("service %s not found", serviceName)
 	}
-	cmd := exec.Command(scriptPath, "restart")
+	cmd := commandContext(ctx, scriptPath, "restart")
 	return cmd.Run()
 }
 
-func (sv *SysVManager) Enable(serviceName string) error {
+// Reload runs the script's reload action; scripts that do not implement it
+// usually exit with status 3.
+func (sv *SysVManager) Reload(ctx context.Context, serviceName string) error {
+	scriptPath := filepath.Join(sv.initDPath, serviceName)
+	if !sv.serviceExists(serviceName) {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	cmd := commandContext(ctx, scriptPath, "reload")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%v: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
+
+func (sv *SysVManager) Enable(ctx context.Context, serviceName string) error {
 	if !sv.serviceExists(serviceName) {
 		return fmt.Errorf("service %s not found", serviceName)
 	}
 
 	// Use chkconfig if available
 	if sv.hasChkconfig() {
-		cmd := exec.Command("chkconfig", serviceName, "on")
+		cmd := commandContext(ctx, "chkconfig", serviceName, "on")
 		return cmd.Run()
 	}
 
 	// Use update-rc.d if available (Debian/Ubuntu)
 	if sv.hasUpdateRcd() {
-		cmd := exec.Command("update-rc.d", serviceName, "enable")
+		cmd := commandContext(ctx, "update-rc.d", serviceName, "enable")
 		return cmd.Run()
 	}
 
 	return fmt.Errorf("no suitable enable method found")
 }
 
-func (sv *SysVManager) Disable(serviceName string) error {
+func (sv *SysVManager) Disable(ctx context.Context, serviceName string) error {
 	if !sv.serviceExists(serviceName) {
 		return fmt.Errorf("service %s not found", serviceName)
 	}
 
 	// Use chkconfig if available
 	if sv.hasChkconfig() {
-		cmd := exec.Command("chkconfig", serviceName, "off")
+		cmd := commandContext(ctx, "chkconfig", serviceName, "off")
 		return cmd.Run()
 	}
 
 	// Use update-rc.d if available (Debian/Ubuntu)
 	if sv.hasUpdateRcd() {
-		cmd := exec.Command("update-rc.d", serviceName, "disable")
+		cmd := commandContext(ctx, "update-rc.d", serviceName, "disable")
 		return cmd.Run()
 	}
 
 	return fmt.Errorf("no suitable disable method found")
 }
 
-func (sv *SysVManager) GetStatus(serviceName string) (types.ServiceInfo, error) {
+func (sv *SysVManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
 	info := types.ServiceInfo{
 		Name: serviceName,
 		Type: types.ServiceTypeSysV,
@@ -103,8 +131,11 @@ func (sv *SysVManager) GetStatus(serviceName string) (types.ServiceInfo, error)
 	scriptPath := filepath.Join(sv.initDPath, serviceName)
 
 	// Try to get status from the service script
-	cmd := exec.Command(scriptPath, "status")
+	cmd := commandContext(ctx, scriptPath, "status")
 	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return info, ctx.Err()
+	}
 	if err != nil {
 		// If status command fails, assume service is inactive
 		info.Status = types.StatusInactive
@@ -116,7 +147,7 @@ func (sv *SysVManager) GetStatus(serviceName string) (types.ServiceInfo, error)
 			// Try to extract PID from status output
 			if pid := sv.extractPIDFromStatus(string(output)); pid > 0 {
 				info.PID = pid
-				info.Uptime = sv.getProcessUptime(pid)
+				info.Uptime = sv.getProcessUptime(ctx, pid)
 			}
 		} else if strings.Contains(statusOutput, "stopped") || strings.Contains(statusOutput, "inactive") {
 			info.Status = types.StatusInactive
@@ -133,7 +164,52 @@ func (sv *SysVManager) GetStatus(serviceName string) (types.ServiceInfo, error)
 	return info, nil
 }
 
-func (sv *SysVManager) ListServices() ([]types.ServiceInfo, error) {
+// ServicePIDs returns the process recorded in the service's pidfile,
+// <name>.pid or <name>/<name>.pid in a run directory, or else the PID named
+// by the script's status output, followed by all of its descendants.
+func (sv *SysVManager) ServicePIDs(ctx context.Context, serviceName string) ([]int, error) {
+	if !sv.serviceExists(serviceName) {
+		return nil, fmt.Errorf("service %s not found", serviceName)
+	}
+
+	pid := sv.pidFileProcess(serviceName)
+	if pid == 0 {
+		output, _ := commandContext(ctx, filepath.Join(sv.initDPath, serviceName), "status").Output()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if candidate := sv.extractPIDFromStatus(string(output)); candidate > 0 && sv.processExists(candidate) {
+			pid = candidate
+		}
+	}
+	if pid == 0 {
+		return nil, fmt.Errorf("no running process found for service %s", serviceName)
+	}
+	return procDescendants(sv.procRoot, pid), nil
+}
+
+// pidFileProcess returns the running process recorded in the service's
+// pidfile, or 0.
+func (sv *SysVManager) pidFileProcess(serviceName string) int {
+	for _, dir := range sv.runDirs {
+		for _, path := range []string{
+			filepath.Join(dir, serviceName+".pid"),
+			filepath.Join(dir, serviceName, serviceName+".pid"),
+		} {
+			if pid, _ := readPIDFile(path); pid > 0 && sv.processExists(pid) {
+				return pid
+			}
+		}
+	}
+	return 0
+}
+
+func (sv *SysVManager) processExists(pid int) bool {
+	_, err := os.Stat(filepath.Join(sv.procRoot, strconv.Itoa(pid)))
+	return err == nil
+}
+
+func (sv *SysVManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
 	var services []types.ServiceInfo
 
 	files, err := os.ReadDir(sv.initDPath)
@@ -151,8 +227,12 @@ func (sv *SysVManager) ListServices() ([]types.ServiceInfo, error) {
 			continue
 		}
 
+		if ctx.Err() != nil {
+			return services, ctx.Err()
+		}
+
 		serviceName := file.Name()
-		info, err := sv.GetStatus(serviceName)
+		info, err := sv.GetStatus(ctx, serviceName)
 		if err == nil {
 			services = append(services, info)
 		}
@@ -216,8 +296,8 @@ func (sv *SysVManager) extractPIDFromStatus(output string) int {
 	return 0
 }
 
-func (sv *SysVManager) getProcessUptime(pid int) time.Duration {
-	cmd := exec.Command("ps", "-o", "etime=", "-p", strconv.Itoa(pid))
+func (sv *SysVManager) getProcessUptime(ctx context.Context, pid int) time.Duration {
+	cmd := commandContext(ctx, "ps", "-o", "etime=", "-p", strconv.Itoa(pid))
 	output, err := cmd.Output()
 	if err != nil {
 		return 0
@@ -304,4 +384,21 @@ func (sv *SysVManager) isNonServiceFile(filename string) bool {
 func IsSysVAvailable() bool {
 	_, err := os.Stat("/etc/init.d")
 	return err == nil
-}
\ No newline at end of file
+}
+
+// ServiceLogs reads the log files configured for the service, or
+// /var/log/<service>.log and /var/log/<service>/*.log when there are none.
+func (sv *SysVManager) ServiceLogs(ctx context.Context, serviceName string, query LogQuery) ([]types.LogEntry, error) {
+	paths := sv.logFiles[serviceName]
+	if len(paths) == 0 {
+		if _, err := os.Stat(filepath.Join(sv.logDir, serviceName+".log")); err == nil {
+			paths = append(paths, filepath.Join(sv.logDir, serviceName+".log"))
+		}
+		matches, _ := filepath.Glob(filepath.Join(sv.logDir, serviceName, "*.log"))
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no log files found for service %s, configure them under sysv.logs", serviceName)
+	}
+	return readLogFiles(ctx, paths, query)
+}
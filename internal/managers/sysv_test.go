@@ -1,6 +1,7 @@
 package managers
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -148,7 +149,7 @@ func TestSysVManager_ListServices(t *testing.T) {
 	}
 	
 	manager := NewSysVManager()
-	services, err := manager.ListServices()
+	services, err := manager.ListServices(context.Background())
 	
 	if err != nil {
 		t.Fatalf("Failed to list SysV services: %v", err)
@@ -181,7 +182,7 @@ func TestSysVManager_GetStatus(t *testing.T) {
 	manager := NewSysVManager()
 	
 	// 首先获取服务列表
-	services, err := manager.ListServices()
+	services, err := manager.ListServices(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to list SysV services: %v", err)
 	}
@@ -192,7 +193,7 @@ func TestSysVManager_GetStatus(t *testing.T) {
 	
 	// 测试第一个服务的状态
 	serviceName := services[0].Name
-	info, err := manager.GetStatus(serviceName)
+	info, err := manager.GetStatus(context.Background(), serviceName)
 	
 	// 即使出错也要检查基本字段
 	if info.Name != serviceName {
@@ -217,27 +218,27 @@ func TestSysVManager_BasicOperations(t *testing.T) {
 	testService := "nonexistent-service-12345"
 	
 	// 测试基本操作（这些操作应该会失败，因为服务不存在或需要权限）
-	err := manager.Start(testService)
+	err := manager.Start(context.Background(), testService)
 	if err == nil {
 		t.Error("Expected error for nonexistent service start, but got none")
 	}
 	
-	err = manager.Stop(testService)
+	err = manager.Stop(context.Background(), testService)
 	if err == nil {
 		t.Error("Expected error for nonexistent service stop, but got none")
 	}
 	
-	err = manager.Restart(testService)
+	err = manager.Restart(context.Background(), testService)
 	if err == nil {
 		t.Error("Expected error for nonexistent service restart, but got none")
 	}
 	
-	err = manager.Enable(testService)
+	err = manager.Enable(context.Background(), testService)
 	if err == nil {
 		t.Error("Expected error for nonexistent service enable, but got none")
 	}
 	
-	err = manager.Disable(testService)
+	err = manager.Disable(context.Background(), testService)
 	if err == nil {
 		t.Error("Expected error for nonexistent service disable, but got none")
 	}
@@ -252,7 +253,7 @@ func TestSysVManager_NonexistentService(t *testing.T) {
 	nonexistentService := "definitely-does-not-exist-service-12345"
 	
 	// 获取不存在服务的状态应该返回错误
-	_, err := manager.GetStatus(nonexistentService)
+	_, err := manager.GetStatus(context.Background(), nonexistentService)
 	if err == nil {
 		t.Error("Expected error for nonexistent service, but got none")
 	}
@@ -268,7 +269,7 @@ func BenchmarkSysVManager_ListServices(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = manager.ListServices()
+		_, _ = manager.ListServices(context.Background())
 	}
 }
 
@@ -280,7 +281,7 @@ func BenchmarkSysVManager_GetStatus(b *testing.B) {
 	manager := NewSysVManager()
 	
 	// 获取一个服务名用于测试
-	services, err := manager.ListServices()
+	services, err := manager.ListServices(context.Background())
 	if err != nil || len(services) == 0 {
 		b.Skip("No services available for benchmark")
 	}
@@ -289,6 +290,6 @@ func BenchmarkSysVManager_GetStatus(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = manager.GetStatus(serviceName)
+		_, _ = manager.GetStatus(context.Background(), serviceName)
 	}
 }
\ No newline at end of file
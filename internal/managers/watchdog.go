@@ -0,0 +1,416 @@
+package managers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+const (
+	defaultWatchdogInterval       = 30 * time.Second
+	defaultWatchdogBackoffInitial = 30 * time.Second
+	defaultWatchdogBackoffMax     = 5 * time.Minute
+	defaultWatchdogMaxAttempts    = 5
+	defaultWatchdogWindow         = time.Hour
+
+	// maxWatchdogActions bounds the action history kept in memory.
+	maxWatchdogActions = 500
+)
+
+// States of a watched service.
+const (
+	watchdogStateWatching  = "watching"
+	watchdogStateBackoff   = "backoff"
+	watchdogStateExhausted = "exhausted"
+)
+
+// Watchdog restarts the services declared under watchdog: in config.yaml
+// when they fail or turn unhealthy, and records each action it takes.
+type Watchdog struct {
+	timeouts config.TimeoutConfig
+	managers map[types.ServiceType]types.ServiceManager
+	health   *HealthMonitor
+	logger   *logrus.Logger
+	watches  []*watch
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	actions []types.WatchdogAction
+}
+
+// watch follows one policy. Apart from state, its fields are only used by
+// the policy's loop.
+type watch struct {
+	policy config.WatchdogPolicy
+	// serviceType is the policy's type, or the type the service was found
+	// under.
+	serviceType string
+	attempts    []time.Time
+	nextAttempt time.Time
+	lastRestart time.Time
+	escalated   bool
+
+	mu    sync.Mutex
+	state types.WatchdogState
+}
+
+// NewWatchdog starts a loop for each watchdog policy of cfg. Services are
+// looked up in detected, and their health is taken from health.
+func NewWatchdog(cfg *config.Config, detected map[types.ServiceType]types.ServiceManager, health *HealthMonitor, logger *logrus.Logger) *Watchdog {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watchdog{
+		timeouts: cfg.Timeouts,
+		managers: detected,
+		health:   health,
+		logger:   logger,
+		cancel:   cancel,
+	}
+	for _, policy := range cfg.Watchdog {
+		w.watches = append(w.watches, newWatch(policy))
+	}
+
+	for _, wt := range w.watches {
+		w.wg.Add(1)
+		go func(wt *watch) {
+			defer w.wg.Done()
+			w.loop(ctx, wt)
+		}(wt)
+	}
+	if len(w.watches) > 0 {
+		logger.Infof("Watchdog started for %d services", len(w.watches))
+	}
+	return w
+}
+
+func newWatch(policy config.WatchdogPolicy) *watch {
+	policy = withWatchdogDefaults(policy)
+	return &watch{
+		policy:      policy,
+		serviceType: policy.Type,
+		state: types.WatchdogState{
+			Service:     policy.Service,
+			ServiceType: types.ServiceType(policy.Type),
+			Scope:       policy.Scope,
+			State:       watchdogStateWatching,
+			MaxAttempts: policy.MaxAttempts,
+		},
+	}
+}
+
+func withWatchdogDefaults(policy config.WatchdogPolicy) config.WatchdogPolicy {
+	if len(policy.On) == 0 {
+		policy.On = []string{config.WatchOnFailed, config.WatchOnUnhealthy}
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = defaultWatchdogInterval
+	}
+	if policy.Backoff.Initial <= 0 {
+		policy.Backoff.Initial = defaultWatchdogBackoffInitial
+	}
+	if policy.Backoff.Max < policy.Backoff.Initial {
+		policy.Backoff.Max = defaultWatchdogBackoffMax
+		if policy.Backoff.Max < policy.Backoff.Initial {
+			policy.Backoff.Max = policy.Backoff.Initial
+		}
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultWatchdogMaxAttempts
+	}
+	if policy.Window <= 0 {
+		policy.Window = defaultWatchdogWindow
+	}
+	return policy
+}
+
+// States returns the state of every watched service in configuration order.
+func (w *Watchdog) States() []types.WatchdogState {
+	if w == nil {
+		return nil
+	}
+	states := make([]types.WatchdogState, 0, len(w.watches))
+	for _, wt := range w.watches {
+		wt.mu.Lock()
+		states = append(states, wt.state)
+		wt.mu.Unlock()
+	}
+	return states
+}
+
+// Actions returns up to limit of the recorded actions, newest first,
+// optionally only those on serviceName. A limit of zero returns all.
+func (w *Watchdog) Actions(serviceName string, limit int) []types.WatchdogAction {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var actions []types.WatchdogAction
+	for i := len(w.actions) - 1; i >= 0; i-- {
+		if serviceName != "" && w.actions[i].Service != serviceName {
+			continue
+		}
+		actions = append(actions, w.actions[i])
+		if limit > 0 && len(actions) == limit {
+			break
+		}
+	}
+	return actions
+}
+
+// Close stops the watch loops and waits for running actions to return.
+func (w *Watchdog) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.cancel()
+	w.wg.Wait()
+	return nil
+}
+
+func (w *Watchdog) loop(ctx context.Context, wt *watch) {
+	ticker := time.NewTicker(wt.policy.Interval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx, wt, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check reads the service's status and restarts it when one of the
+// policy's conditions holds and the restart budget allows.
+func (w *Watchdog) check(ctx context.Context, wt *watch, now time.Time) {
+	info, err := w.status(ctx, wt)
+	if ctx.Err() != nil {
+		return
+	}
+	wt.prune(now)
+	if err != nil {
+		w.logger.Debugf("Watchdog could not check %s: %v", wt.policy.Service, err)
+		wt.update(now, watchdogStateWatching, err.Error())
+		return
+	}
+
+	reason := wt.trouble(info)
+	if reason == "" {
+		wt.update(now, watchdogStateWatching, "")
+		return
+	}
+	if len(wt.attempts) >= wt.policy.MaxAttempts {
+		if !wt.escalated {
+			wt.escalated = true
+			w.escalate(ctx, wt, reason, now)
+		}
+		wt.update(now, watchdogStateExhausted, "")
+		return
+	}
+	if now.Before(wt.nextAttempt) {
+		wt.update(now, watchdogStateBackoff, "")
+		return
+	}
+	w.restart(ctx, wt, reason, now)
+}
+
+// status returns the service's status annotated with its health.
+func (w *Watchdog) status(ctx context.Context, wt *watch) (types.ServiceInfo, error) {
+	ctx, cancel := w.timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	manager, ctx, err := w.manager(ctx, wt)
+	if err != nil {
+		return types.ServiceInfo{}, err
+	}
+	info, err := manager.GetStatus(ctx, wt.policy.Service)
+	if err != nil {
+		return info, err
+	}
+	w.health.Annotate(&info)
+	return info, nil
+}
+
+// manager returns the manager of the watched service and ctx with the
+// policy's scope applied. A service without a configured type is looked up
+// in every manager once and then kept under the type it was found in.
+func (w *Watchdog) manager(ctx context.Context, wt *watch) (types.ServiceManager, context.Context, error) {
+	ctx, serviceType, err := ApplyScope(ctx, wt.serviceType, wt.policy.Scope)
+	if err != nil {
+		return nil, ctx, err
+	}
+	if serviceType != "" {
+		if manager, exists := w.managers[types.ServiceType(serviceType)]; exists {
+			return manager, ctx, nil
+		}
+		return nil, ctx, fmt.Errorf("unsupported service type: %s", serviceType)
+	}
+	for managerType, manager := range w.managers {
+		if _, err := manager.GetStatus(ctx, wt.policy.Service); err == nil {
+			wt.serviceType = string(managerType)
+			wt.mu.Lock()
+			wt.state.ServiceType = managerType
+			wt.mu.Unlock()
+			return manager, ctx, nil
+		}
+	}
+	return nil, ctx, fmt.Errorf("service %s not found in any manager", wt.policy.Service)
+}
+
+func (w *Watchdog) restart(ctx context.Context, wt *watch, reason string, now time.Time) {
+	wt.attempts = append(wt.attempts, now)
+	wt.lastRestart = now
+	wt.nextAttempt = now.Add(wt.delay(len(wt.attempts)))
+	action := types.WatchdogAction{
+		Time:        now,
+		Service:     wt.policy.Service,
+		ServiceType: types.ServiceType(wt.serviceType),
+		Action:      "restart",
+		Reason:      reason,
+		Attempt:     len(wt.attempts),
+	}
+
+	restartCtx, cancel := w.timeouts.WithTimeout(ctx, "restart")
+	defer cancel()
+	manager, restartCtx, err := w.manager(restartCtx, wt)
+	if err == nil {
+		err = manager.Restart(restartCtx, wt.policy.Service)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		action.Error = err.Error()
+		w.logger.Errorf("Watchdog failed to restart %s (%s), attempt %d of %d: %v", action.Service, reason, action.Attempt, wt.policy.MaxAttempts, err)
+	} else {
+		w.logger.Warnf("Watchdog restarted %s (%s), attempt %d of %d", action.Service, reason, action.Attempt, wt.policy.MaxAttempts)
+	}
+	w.record(action)
+	wt.update(now, watchdogStateBackoff, "")
+}
+
+// escalate runs the policy's escalation hook once the restart budget is
+// spent.
+func (w *Watchdog) escalate(ctx context.Context, wt *watch, reason string, now time.Time) {
+	action := types.WatchdogAction{
+		Time:        now,
+		Service:     wt.policy.Service,
+		ServiceType: types.ServiceType(wt.serviceType),
+		Action:      "escalate",
+		Reason:      reason,
+		Attempt:     len(wt.attempts),
+	}
+	w.logger.Errorf("Watchdog gave up on %s after %d restarts within %s: %s", action.Service, action.Attempt, wt.policy.Window, reason)
+
+	if wt.policy.Escalate != "" {
+		hookCtx, cancel := w.timeouts.WithTimeout(ctx, "escalate")
+		defer cancel()
+		cmd := shellCommand(hookCtx, wt.policy.Escalate)
+		cmd.Env = append(os.Environ(),
+			"WATCHDOG_SERVICE="+action.Service,
+			"WATCHDOG_TYPE="+string(action.ServiceType),
+			"WATCHDOG_REASON="+reason,
+			"WATCHDOG_ATTEMPTS="+strconv.Itoa(action.Attempt),
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			action.Error = err.Error()
+			if message := probeOutput(string(output)); message != "" {
+				action.Error += ": " + message
+			}
+			w.logger.Errorf("Watchdog escalation hook for %s failed: %s", action.Service, action.Error)
+		}
+	}
+	w.record(action)
+}
+
+func (w *Watchdog) record(action types.WatchdogAction) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.actions = append(w.actions, action)
+	if len(w.actions) > maxWatchdogActions {
+		w.actions = append([]types.WatchdogAction(nil), w.actions[len(w.actions)-maxWatchdogActions:]...)
+	}
+}
+
+// trouble returns why the service needs a restart, or "" if it does not.
+// Unhealthy counts only for an active service, so one stopped on purpose is
+// left alone, and only with a probe that failed after the last restart, so
+// a restart is not repeated on results from before it.
+func (wt *watch) trouble(info types.ServiceInfo) string {
+	if wt.on(config.WatchOnFailed) && info.Status == types.StatusFailed {
+		return config.WatchOnFailed
+	}
+	if !wt.on(config.WatchOnUnhealthy) || info.Status != types.StatusActive || info.Health == nil || info.Health.Status != types.HealthUnhealthy {
+		return ""
+	}
+	var failing []string
+	for _, probe := range info.Health.Probes {
+		if probe.Status == types.HealthUnhealthy && probe.LastCheck.After(wt.lastRestart) {
+			failing = append(failing, probe.String())
+		}
+	}
+	if len(failing) == 0 {
+		return ""
+	}
+	return config.WatchOnUnhealthy + ": " + strings.Join(failing, "; ")
+}
+
+func (wt *watch) on(condition string) bool {
+	for _, on := range wt.policy.On {
+		if on == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// prune forgets the restarts that left the window. The escalation hook runs
+// again only after the budget was available once more.
+func (wt *watch) prune(now time.Time) {
+	kept := wt.attempts[:0]
+	for _, at := range wt.attempts {
+		if now.Sub(at) < wt.policy.Window {
+			kept = append(kept, at)
+		}
+	}
+	wt.attempts = kept
+	if len(wt.attempts) < wt.policy.MaxAttempts {
+		wt.escalated = false
+	}
+}
+
+// delay is the wait after the attempt-th restart in the window.
+func (wt *watch) delay(attempt int) time.Duration {
+	delay := wt.policy.Backoff.Initial
+	for i := 1; i < attempt && delay < wt.policy.Backoff.Max; i++ {
+		delay *= 2
+	}
+	if delay > wt.policy.Backoff.Max {
+		delay = wt.policy.Backoff.Max
+	}
+	return delay
+}
+
+func (wt *watch) update(now time.Time, state, lastError string) {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.state.State = state
+	wt.state.Attempts = len(wt.attempts)
+	wt.state.LastCheck = now
+	wt.state.LastError = lastError
+	wt.state.NextAttempt = time.Time{}
+	if state == watchdogStateBackoff {
+		wt.state.NextAttempt = wt.nextAttempt
+	}
+}
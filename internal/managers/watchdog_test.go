@@ -0,0 +1,241 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// watchedManager 是一个状态可控的服务管理器，记录重启次数
+type watchedManager struct {
+	mu         sync.Mutex
+	status     types.ServiceStatus
+	restarts   int
+	restartErr error
+}
+
+func (m *watchedManager) setStatus(status types.ServiceStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = status
+}
+
+func (m *watchedManager) restartCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restarts
+}
+
+func (m *watchedManager) Start(ctx context.Context, name string) error   { return nil }
+func (m *watchedManager) Stop(ctx context.Context, name string) error    { return nil }
+func (m *watchedManager) Enable(ctx context.Context, name string) error  { return nil }
+func (m *watchedManager) Disable(ctx context.Context, name string) error { return nil }
+
+func (m *watchedManager) Restart(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restarts++
+	return m.restartErr
+}
+
+func (m *watchedManager) GetStatus(ctx context.Context, name string) (types.ServiceInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if name != "app" {
+		return types.ServiceInfo{}, fmt.Errorf("service %s not found", name)
+	}
+	return types.ServiceInfo{Name: name, Type: types.ServiceTypeSystemd, Status: m.status}, nil
+}
+
+func (m *watchedManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	return nil, nil
+}
+
+// newTestWatchdog 创建不启动循环的看门狗，由测试直接调用 check
+func newTestWatchdog(manager types.ServiceManager, health *HealthMonitor) *Watchdog {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &Watchdog{
+		managers: map[types.ServiceType]types.ServiceManager{types.ServiceTypeSystemd: manager},
+		health:   health,
+		logger:   logger,
+	}
+}
+
+func TestWatchdog_RestartBackoff(t *testing.T) {
+	manager := &watchedManager{status: types.StatusFailed}
+	w := newTestWatchdog(manager, nil)
+	wt := newWatch(config.WatchdogPolicy{
+		Service:     "app",
+		Backoff:     config.BackoffConfig{Initial: 10 * time.Second, Max: 25 * time.Second},
+		MaxAttempts: 10,
+		Window:      time.Hour,
+	})
+	ctx := context.Background()
+	start := time.Now()
+
+	// 延迟依次为 10s、20s，之后封顶为 25s
+	checks := []struct {
+		after    time.Duration
+		restarts int
+		state    string
+	}{
+		{0, 1, watchdogStateBackoff},
+		{5 * time.Second, 1, watchdogStateBackoff},
+		{10 * time.Second, 2, watchdogStateBackoff},
+		{29 * time.Second, 2, watchdogStateBackoff},
+		{30 * time.Second, 3, watchdogStateBackoff},
+		{54 * time.Second, 3, watchdogStateBackoff},
+		{55 * time.Second, 4, watchdogStateBackoff},
+	}
+	for _, c := range checks {
+		w.check(ctx, wt, start.Add(c.after))
+		if manager.restartCount() != c.restarts || wt.state.State != c.state {
+			t.Fatalf("After %s: expected %d restarts in %s, got %d, %+v", c.after, c.restarts, c.state, manager.restartCount(), wt.state)
+		}
+	}
+
+	// 服务恢复后不再重启
+	manager.setStatus(types.StatusActive)
+	w.check(ctx, wt, start.Add(time.Hour/2))
+	if manager.restartCount() != 4 || wt.state.State != watchdogStateWatching || wt.state.ServiceType != types.ServiceTypeSystemd {
+		t.Errorf("Expected a recovered service to be left alone: %+v", wt.state)
+	}
+
+	actions := w.Actions("", 0)
+	if len(actions) != 4 || actions[0].Attempt != 4 || actions[3].Attempt != 1 || actions[0].Action != "restart" || actions[0].Reason != "failed" {
+		t.Errorf("Unexpected actions: %+v", actions)
+	}
+	if len(w.Actions("app", 2)) != 2 || len(w.Actions("other", 0)) != 0 {
+		t.Error("Expected actions to be filtered by service and limited")
+	}
+}
+
+func TestWatchdog_Escalate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("escalation hooks use /bin/sh")
+	}
+
+	output := filepath.Join(t.TempDir(), "escalated")
+	manager := &watchedManager{status: types.StatusFailed, restartErr: errors.New("unit failed to start")}
+	w := newTestWatchdog(manager, nil)
+	wt := newWatch(config.WatchdogPolicy{
+		Service:     "app",
+		Type:        "systemd",
+		Backoff:     config.BackoffConfig{Initial: time.Second, Max: time.Second},
+		MaxAttempts: 2,
+		Window:      time.Minute,
+		Escalate:    `echo "$WATCHDOG_SERVICE $WATCHDOG_TYPE $WATCHDOG_ATTEMPTS $WATCHDOG_REASON" >> ` + output,
+	})
+	ctx := context.Background()
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		w.check(ctx, wt, start.Add(time.Duration(i)*time.Second))
+	}
+	if manager.restartCount() != 2 || wt.state.State != watchdogStateExhausted {
+		t.Fatalf("Expected the budget to be exhausted after 2 restarts, got %d, %+v", manager.restartCount(), wt.state)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil || string(data) != "app systemd 2 failed\n" {
+		t.Fatalf("Expected the hook to run once, got %q, %v", data, err)
+	}
+	actions := w.Actions("app", 0)
+	if len(actions) != 3 || actions[0].Action != "escalate" || actions[1].Error != "unit failed to start" {
+		t.Errorf("Unexpected actions: %+v", actions)
+	}
+
+	// 旧的重启移出窗口后恢复重启，预算再次耗尽时再次升级
+	for i := 0; i < 4; i++ {
+		w.check(ctx, wt, start.Add(time.Minute+time.Duration(i)*time.Second))
+	}
+	data, _ = os.ReadFile(output)
+	if manager.restartCount() != 4 || strings.Count(string(data), "\n") != 2 {
+		t.Errorf("Expected a second round of restarts and escalation, got %d restarts, hook output %q", manager.restartCount(), data)
+	}
+}
+
+func TestWatchdog_Unhealthy(t *testing.T) {
+	manager := &watchedManager{status: types.StatusActive}
+	probe := &healthProbe{
+		check:  config.HealthCheck{Service: "app"},
+		result: types.ProbeResult{Service: "app", Name: "http", Status: types.HealthUnhealthy, FailingStreak: 3},
+	}
+	w := newTestWatchdog(manager, &HealthMonitor{probes: []*healthProbe{probe}})
+	wt := newWatch(config.WatchdogPolicy{Service: "app", Backoff: config.BackoffConfig{Initial: time.Second}})
+	ctx := context.Background()
+	start := time.Now()
+	probe.result.LastCheck = start
+
+	w.check(ctx, wt, start)
+	if manager.restartCount() != 1 || !strings.HasPrefix(w.Actions("", 0)[0].Reason, "unhealthy: http") {
+		t.Fatalf("Expected an unhealthy service to be restarted: %+v", w.Actions("", 0))
+	}
+
+	// 重启后尚未重新探测，不应再次重启
+	w.check(ctx, wt, start.Add(2*time.Second))
+	if manager.restartCount() != 1 {
+		t.Errorf("Expected no restart on a probe result from before the restart")
+	}
+
+	// 人为停止的服务即使不健康也不重启
+	probe.result.LastCheck = start.Add(3 * time.Second)
+	manager.setStatus(types.StatusInactive)
+	w.check(ctx, wt, start.Add(4*time.Second))
+	if manager.restartCount() != 1 {
+		t.Errorf("Expected a stopped service to be left alone")
+	}
+
+	manager.setStatus(types.StatusActive)
+	w.check(ctx, wt, start.Add(5*time.Second))
+	if manager.restartCount() != 2 {
+		t.Errorf("Expected a restart after a failed probe, got %d", manager.restartCount())
+	}
+
+	// 仅监控 failed 时忽略健康状态
+	only := newWatch(config.WatchdogPolicy{Service: "app", On: []string{config.WatchOnFailed}})
+	w.check(ctx, only, start.Add(time.Hour))
+	if manager.restartCount() != 2 {
+		t.Errorf("Expected unhealthy to be ignored without the unhealthy condition")
+	}
+}
+
+func TestNewWatchdog(t *testing.T) {
+	manager := &watchedManager{status: types.StatusFailed}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := &config.Config{Watchdog: []config.WatchdogPolicy{{Service: "app", Interval: 10 * time.Millisecond}}}
+	w := NewWatchdog(cfg, map[types.ServiceType]types.ServiceManager{types.ServiceTypeSystemd: manager}, nil, logger)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for manager.restartCount() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the watchdog to restart the service")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	w.Close()
+
+	states := w.States()
+	if len(states) != 1 || states[0].MaxAttempts != defaultWatchdogMaxAttempts || states[0].Attempts != 1 {
+		t.Errorf("Unexpected states: %+v", states)
+	}
+
+	var none *Watchdog
+	if none.States() != nil || none.Actions("", 0) != nil || none.Close() != nil {
+		t.Error("Expected a nil watchdog to be usable")
+	}
+}
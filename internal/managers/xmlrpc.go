@@ -0,0 +1,231 @@
+package managers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// XMLRPCFault is a <fault> returned by an XML-RPC server.
+type XMLRPCFault struct {
+	Code    int
+	Message string
+}
+
+func (f *XMLRPCFault) Error() string {
+	return fmt.Sprintf("fault %d: %s", f.Code, f.Message)
+}
+
+// xmlrpcClient is a minimal XML-RPC client covering the value types
+// supervisord uses.
+type xmlrpcClient struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+}
+
+type xmlrpcValue struct {
+	Int      *string       `xml:"int"`
+	I4       *string       `xml:"i4"`
+	Boolean  *string       `xml:"boolean"`
+	String   *string       `xml:"string"`
+	Double   *string       `xml:"double"`
+	DateTime *string       `xml:"dateTime.iso8601"`
+	Base64   *string       `xml:"base64"`
+	Struct   *xmlrpcStruct `xml:"struct"`
+	Array    *xmlrpcArray  `xml:"array"`
+	Nil      *struct{}     `xml:"nil"`
+	Text     string        `xml:",chardata"`
+}
+
+type xmlrpcStruct struct {
+	Members []xmlrpcMember `xml:"member"`
+}
+
+type xmlrpcMember struct {
+	Name  string      `xml:"name"`
+	Value xmlrpcValue `xml:"value"`
+}
+
+type xmlrpcArray struct {
+	Values []xmlrpcValue `xml:"data>value"`
+}
+
+type xmlrpcMethodResponse struct {
+	XMLName xml.Name      `xml:"methodResponse"`
+	Params  []xmlrpcValue `xml:"params>param>value"`
+	Fault   *xmlrpcValue  `xml:"fault>value"`
+}
+
+type xmlrpcMethodCall struct {
+	XMLName    xml.Name      `xml:"methodCall"`
+	MethodName string        `xml:"methodName"`
+	Params     []xmlrpcValue `xml:"params>param>value"`
+}
+
+// call invokes method and decodes its single return value into out, which
+// may be nil. Structs are decoded through their json tags.
+func (c *xmlrpcClient) call(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0"?><methodCall><methodName>`)
+	xml.EscapeText(&body, []byte(method))
+	body.WriteString("</methodName><params>")
+	for _, param := range params {
+		body.WriteString("<param>")
+		if err := writeXMLRPCValue(&body, param); err != nil {
+			return err
+		}
+		body.WriteString("</param>")
+	}
+	body.WriteString("</params></methodCall>")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/xml")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: HTTP %d: %s", method, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var response xmlrpcMethodResponse
+	if err := xml.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("%s: invalid XML-RPC response: %v", method, err)
+	}
+
+	if response.Fault != nil {
+		fault, _ := response.Fault.decode().(map[string]interface{})
+		code, _ := fault["faultCode"].(int)
+		message, _ := fault["faultString"].(string)
+		return &XMLRPCFault{Code: code, Message: message}
+	}
+
+	if out == nil || len(response.Params) == 0 {
+		return nil
+	}
+	// Round-trip through JSON so callers can use ordinary tagged structs.
+	data, err := json.Marshal(response.Params[0].decode())
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s: unexpected result: %v", method, err)
+	}
+	return nil
+}
+
+// decode converts a value into string, int, bool, float64, []byte,
+// []interface{}, map[string]interface{} or nil.
+func (v xmlrpcValue) decode() interface{} {
+	switch {
+	case v.Int != nil:
+		n, _ := strconv.Atoi(strings.TrimSpace(*v.Int))
+		return n
+	case v.I4 != nil:
+		n, _ := strconv.Atoi(strings.TrimSpace(*v.I4))
+		return n
+	case v.Boolean != nil:
+		return strings.TrimSpace(*v.Boolean) == "1"
+	case v.String != nil:
+		return *v.String
+	case v.Double != nil:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(*v.Double), 64)
+		return f
+	case v.DateTime != nil:
+		return strings.TrimSpace(*v.DateTime)
+	case v.Base64 != nil:
+		data, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(*v.Base64))
+		return data
+	case v.Struct != nil:
+		m := make(map[string]interface{}, len(v.Struct.Members))
+		for _, member := range v.Struct.Members {
+			m[member.Name] = member.Value.decode()
+		}
+		return m
+	case v.Array != nil:
+		list := make([]interface{}, 0, len(v.Array.Values))
+		for _, value := range v.Array.Values {
+			list = append(list, value.decode())
+		}
+		return list
+	case v.Nil != nil:
+		return nil
+	default:
+		// A value without a type element is a string.
+		return v.Text
+	}
+}
+
+// writeXMLRPCValue encodes v as a <value> element.
+func writeXMLRPCValue(w *bytes.Buffer, v interface{}) error {
+	w.WriteString("<value>")
+	switch value := v.(type) {
+	case nil:
+		w.WriteString("<nil/>")
+	case string:
+		w.WriteString("<string>")
+		xml.EscapeText(w, []byte(value))
+		w.WriteString("</string>")
+	case int:
+		fmt.Fprintf(w, "<int>%d</int>", value)
+	case int64:
+		fmt.Fprintf(w, "<int>%d</int>", value)
+	case bool:
+		if value {
+			w.WriteString("<boolean>1</boolean>")
+		} else {
+			w.WriteString("<boolean>0</boolean>")
+		}
+	case float64:
+		fmt.Fprintf(w, "<double>%s</double>", strconv.FormatFloat(value, 'f', -1, 64))
+	case []interface{}:
+		w.WriteString("<array><data>")
+		for _, item := range value {
+			if err := writeXMLRPCValue(w, item); err != nil {
+				return err
+			}
+		}
+		w.WriteString("</data></array>")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		w.WriteString("<struct>")
+		for _, key := range keys {
+			w.WriteString("<member><name>")
+			xml.EscapeText(w, []byte(key))
+			w.WriteString("</name>")
+			if err := writeXMLRPCValue(w, value[key]); err != nil {
+				return err
+			}
+			w.WriteString("</member>")
+		}
+		w.WriteString("</struct>")
+	default:
+		return fmt.Errorf("unsupported XML-RPC parameter type %T", v)
+	}
+	w.WriteString("</value>")
+	return nil
+}
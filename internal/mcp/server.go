@@ -2,51 +2,43 @@ package mcp
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/internal/inflight"
 	"nucc.com/mcp_srv_mgr/internal/managers"
 	"nucc.com/mcp_srv_mgr/pkg/types"
 )
 
 type Server struct {
 	managers    map[types.ServiceType]types.ServiceManager
+	health      *managers.HealthMonitor
+	watchdog    *managers.Watchdog
+	config      *config.Config
 	logger      *logrus.Logger
 	initialized bool
 	logLevel    types.LoggingLevel
+
+	writeMu  sync.Mutex
+	inflight *inflight.Requests
 }
 
-func NewServer(logger *logrus.Logger) *Server {
+func NewServer(cfg *config.Config, logger *logrus.Logger) *Server {
 	server := &Server{
-		managers: make(map[types.ServiceType]types.ServiceManager),
+		managers: managers.DetectManagers(cfg, logger),
+		health:   managers.NewHealthMonitor(cfg.Health, logger),
+		config:   cfg,
 		logger:   logger,
 		logLevel: types.LoggingLevelInfo,
-	}
-
-	// Initialize available service managers
-	if managers.IsSystemdAvailable() {
-		server.managers[types.ServiceTypeSystemd] = managers.NewSystemdManager()
-		logger.Info("Systemd manager initialized")
-	} else {
-		logger.Debug("Systemd not available on this system")
-	}
-
-	if managers.IsSysVAvailable() {
-		server.managers[types.ServiceTypeSysV] = managers.NewSysVManager()
-		logger.Info("SysV manager initialized")
-	} else {
-		logger.Debug("SysV not available on this system")
-	}
-
-	if managers.IsDockerAvailable() {
-		server.managers[types.ServiceTypeDocker] = managers.NewDockerManager()
-		logger.Info("Docker manager initialized")
-	} else {
-		logger.Debug("Docker not available on this system")
+		inflight: inflight.New(),
 	}
 
 	if len(server.managers) == 0 {
@@ -58,12 +50,35 @@ func NewServer(logger *logrus.Logger) *Server {
 		logger.Info("Mock managers initialized for testing")
 	}
 
+	server.watchdog = managers.NewWatchdog(cfg, server.managers, server.health, logger)
+
 	return server
 }
 
+// Close releases the service managers, stopping any processes supervised
+// by this server.
+func (s *Server) Close() error {
+	s.watchdog.Close()
+	s.health.Close()
+	return managers.CloseManagers(s.managers)
+}
+
 func (s *Server) Start() {
-	scanner := bufio.NewScanner(os.Stdin)
-	writer := json.NewEncoder(os.Stdout)
+	s.Serve(context.Background(), os.Stdin, os.Stdout)
+}
+
+// Serve reads newline-delimited requests from r and writes responses to w
+// until r is exhausted. Tool calls run concurrently so that a later
+// notifications/cancelled can abort them; in-flight calls are cancelled once
+// ctx is done or the input is closed.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(r)
+	writer := json.NewEncoder(w)
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -77,14 +92,41 @@ func (s *Server) Start() {
 			continue
 		}
 
-		response := s.handleRequest(&request)
+		switch request.Method {
+		case "notifications/cancelled":
+			if id, ok := inflight.CancelledRequestID(request.Params); ok && s.inflight.Cancel(id) {
+				s.logger.Infof("Request %s cancelled by client", inflight.Key(id))
+			}
+			continue
+		case "tools/call":
+			reqCtx, done := s.inflight.Begin(ctx, nil, request.ID)
+			wg.Add(1)
+			go func(request types.MCPRequest) {
+				defer wg.Done()
+				defer done()
+
+				response := s.handleRequest(reqCtx, &request)
+				if !inflight.CancelledByClient(reqCtx) {
+					s.writeResponse(writer, response)
+				}
+			}(request)
+			continue
+		}
+
+		response := s.handleRequest(ctx, &request)
 		if response != nil {
-			writer.Encode(response)
+			s.writeResponse(writer, response)
 		}
 	}
 }
 
-func (s *Server) handleRequest(request *types.MCPRequest) *types.MCPResponse {
+func (s *Server) writeResponse(writer *json.Encoder, response *types.MCPResponse) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	writer.Encode(response)
+}
+
+func (s *Server) handleRequest(ctx context.Context, request *types.MCPRequest) *types.MCPResponse {
 	switch request.Method {
 	case "initialize":
 		return s.handleInitialize(request)
@@ -93,7 +135,7 @@ func (s *Server) handleRequest(request *types.MCPRequest) *types.MCPResponse {
 	case "tools/list":
 		return s.handleListTools(request)
 	case "tools/call":
-		return s.handleCallTool(request)
+		return s.handleCallTool(ctx, request)
 	case "prompts/list":
 		return s.handleListPrompts(request)
 	case "prompts/get":
@@ -150,8 +192,20 @@ func (s *Server) handleListTools(request *types.MCPRequest) *types.MCPResponse {
 				Properties: map[string]types.JSONSchema{
 					"service_type": {
 						Type:        "string",
-						Description: "Filter services by type (systemd, sysv, docker)",
-						Enum:        []interface{}{"systemd", "sysv", "docker"},
+						Description: "Filter services by type (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+					"unit_type": {
+						Type:        "string",
+						Description: "Systemd unit types to list, comma-separated: service, socket, target, mount, automount, path, or all (default: service)",
+					},
+					"installed": {
+						Type:        "boolean",
+						Description: "Also list installed unit files that are not loaded, e.g. disabled services that never ran, with their enablement state (systemd only, default: false)",
 					},
 				},
 			},
@@ -168,8 +222,12 @@ func (s *Server) handleListTools(request *types.MCPRequest) *types.MCPResponse {
 					},
 					"service_type": {
 						Type:        "string",
-						Description: "Type of service (systemd, sysv, docker)",
-						Enum:        []interface{}{"systemd", "sysv", "docker"},
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				Required: []string{"service_name"},
@@ -187,8 +245,12 @@ func (s *Server) handleListTools(request *types.MCPRequest) *types.MCPResponse {
 					},
 					"service_type": {
 						Type:        "string",
-						Description: "Type of service (systemd, sysv, docker)",
-						Enum:        []interface{}{"systemd", "sysv", "docker"},
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				Required: []string{"service_name"},
@@ -206,8 +268,12 @@ func (s *Server) handleListTools(request *types.MCPRequest) *types.MCPResponse {
 					},
 					"service_type": {
 						Type:        "string",
-						Description: "Type of service (systemd, sysv, docker)",
-						Enum:        []interface{}{"systemd", "sysv", "docker"},
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				Required: []string{"service_name"},
@@ -225,8 +291,12 @@ func (s *Server) handleListTools(request *types.MCPRequest) *types.MCPResponse {
 					},
 					"service_type": {
 						Type:        "string",
-						Description: "Type of service (systemd, sysv, docker)",
-						Enum:        []interface{}{"systemd", "sysv", "docker"},
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				Required: []string{"service_name"},
@@ -244,8 +314,12 @@ func (s *Server) handleListTools(request *types.MCPRequest) *types.MCPResponse {
 					},
 					"service_type": {
 						Type:        "string",
-						Description: "Type of service (systemd, sysv, docker)",
-						Enum:        []interface{}{"systemd", "sysv", "docker"},
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				Required: []string{"service_name"},
@@ -263,8 +337,12 @@ func (s *Server) handleListTools(request *types.MCPRequest) *types.MCPResponse {
 					},
 					"service_type": {
 						Type:        "string",
-						Description: "Type of service (systemd, sysv, docker)",
-						Enum:        []interface{}{"systemd", "sysv", "docker"},
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				Required: []string{"service_name"},
@@ -288,200 +366,1251 @@ func (s *Server) handleListTools(request *types.MCPRequest) *types.MCPResponse {
 				Required: []string{"container_name"},
 			},
 		},
+		{
+			Name:        "get_service_logs",
+			Description: "Get log entries of any service: the journal for systemd units, log files for SysV services, container output for Docker",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"service_name": {
+						Type:        "string",
+						Description: "Name of the service",
+					},
+					"service_type": {
+						Type:        "string",
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+					"since": {
+						Type:        "string",
+						Description: "Only entries at or after this time: RFC 3339, \"2006-01-02 15:04:05\", or a duration ago such as 30m",
+					},
+					"until": {
+						Type:        "string",
+						Description: "Only entries at or before this time, in the same formats as since",
+					},
+					"priority": {
+						Type:        "string",
+						Description: "Only entries of this syslog level or more important: emerg, alert, crit, err, warning, notice, info, debug or 0-7",
+					},
+					"grep": {
+						Type:        "string",
+						Description: "Only entries whose message matches this regular expression",
+					},
+					"lines": {
+						Type:        "integer",
+						Description: "Number of most recent entries to return (default: 100)",
+					},
+				},
+				Required: []string{"service_name"},
+			},
+		},
+		{
+			Name:        "get_service_stats",
+			Description: "Get a service's resource usage: CPU time, memory current and peak, IO bytes, task count and memory events, read from the systemd unit's cgroup or from the container engine",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"service_name": {
+						Type:        "string",
+						Description: "Name of the service",
+					},
+					"service_type": {
+						Type:        "string",
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				Required: []string{"service_name"},
+			},
+		},
+		{
+			Name:        "get_service_processes",
+			Description: "List a service's processes as a tree with command line, user, resident memory, open file count and the TCP/UDP ports each one listens on, found through the systemd unit's cgroup, the container's processes or the SysV pidfile",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"service_name": {
+						Type:        "string",
+						Description: "Name of the service",
+					},
+					"service_type": {
+						Type:        "string",
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				Required: []string{"service_name"},
+			},
+		},
+		{
+			Name:        "find_service_owner",
+			Description: "Find the service that owns a process, a listening port or a file, e.g. which service holds port 8080 or keeps a log file open. Give exactly one of pid, port and path; the result is the owning systemd unit, Docker or Podman container or SysV script with its status",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"pid": {
+						Type:        "integer",
+						Description: "Process ID",
+					},
+					"port": {
+						Type:        "integer",
+						Description: "Listening TCP or UDP port",
+					},
+					"protocol": {
+						Type:        "string",
+						Description: "Restrict a port lookup to tcp or udp (default: both)",
+						Enum:        []interface{}{"tcp", "udp"},
+					},
+					"path": {
+						Type:        "string",
+						Description: "Absolute path of a file, directory or executable the process has open",
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_service_health",
+			Description: "Show the health of a service from the HTTP, TCP and exec probes declared under health: in config.yaml and, for containers, the engine's HEALTHCHECK, with each probe's status, failure streak and last error. Without service_name, lists every configured probe",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"service_name": {
+						Type:        "string",
+						Description: "Name of the service (default: all configured probes)",
+					},
+					"service_type": {
+						Type:        "string",
+						Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						Enum:        managers.ServiceTypeNames(s.config),
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_watchdog_status",
+			Description: "Show the services the watchdog watches with their restart budget, and the restarts and escalations it made on its own, newest first. These automatic actions are listed separately from actions requested through the API",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"service_name": {
+						Type:        "string",
+						Description: "Only show actions on this service",
+					},
+					"limit": {
+						Type:        "integer",
+						Description: "Maximum number of actions to show (default: 20)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "list_timers",
+			Description: "List systemd timers with their next and last elapse times and the unit each one activates",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "manage_timer",
+			Description: "Start, stop, enable or disable a systemd timer, or trigger the unit it activates now",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"timer_name": {
+						Type:        "string",
+						Description: "Name of the timer, with or without the .timer suffix",
+					},
+					"action": {
+						Type:        "string",
+						Description: "Action to perform",
+						Enum:        []interface{}{"start", "stop", "enable", "disable", "trigger"},
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				Required: []string{"timer_name", "action"},
+			},
+		},
+		{
+			Name:        "get_unit_file",
+			Description: "Read a systemd unit file installed through this server",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"unit_name": {
+						Type:        "string",
+						Description: "Unit name; a bare name means a .service unit",
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				Required: []string{"unit_name"},
+			},
+		},
+		{
+			Name:        "write_unit_file",
+			Description: "Create or replace a systemd unit file from raw content or a service spec; the file is verified, the previous version backed up, and systemd reloaded",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"unit_name": {
+						Type:        "string",
+						Description: "Unit name; a bare name means a .service unit",
+					},
+					"content": {
+						Type:        "string",
+						Description: "Raw unit file text (mutually exclusive with spec)",
+					},
+					"spec": {
+						Type:        "object",
+						Description: "Service unit spec (mutually exclusive with content)",
+						Properties: map[string]types.JSONSchema{
+							"description":       {Type: "string"},
+							"type":              {Type: "string", Description: "Service type, e.g. simple, exec, forking, oneshot"},
+							"exec_start":        {Type: "string"},
+							"user":              {Type: "string"},
+							"group":             {Type: "string"},
+							"working_directory": {Type: "string"},
+							"environment":       {Type: "object", Description: "Environment variables", AdditionalProperties: map[string]interface{}{"type": "string"}},
+							"restart":           {Type: "string", Enum: []interface{}{"no", "always", "on-success", "on-failure", "on-abnormal", "on-abort", "on-watchdog"}},
+							"wanted_by":         {Type: "string", Description: "Install target, e.g. multi-user.target"},
+						},
+						Required: []string{"exec_start"},
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				Required: []string{"unit_name"},
+			},
+		},
+		{
+			Name:        "delete_unit_file",
+			Description: "Stop and disable a systemd unit, then remove its unit file keeping a backup, and reload systemd",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"unit_name": {
+						Type:        "string",
+						Description: "Unit name; a bare name means a .service unit",
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				Required: []string{"unit_name"},
+			},
+		},
+		{
+			Name:        "list_dropins",
+			Description: "List the drop-in overrides of a systemd unit with their content",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"unit_name": {
+						Type:        "string",
+						Description: "Unit name; a bare name means a .service unit",
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				Required: []string{"unit_name"},
+			},
+		},
+		{
+			Name:        "write_dropin",
+			Description: "Create or replace a drop-in override (<unit>.d/<name>.conf) and reload systemd",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"unit_name": {
+						Type:        "string",
+						Description: "Unit name; a bare name means a .service unit",
+					},
+					"dropin_name": {
+						Type:        "string",
+						Description: "Drop-in file name; .conf is added if missing",
+					},
+					"content": {
+						Type:        "string",
+						Description: "Drop-in text, e.g. \"[Service]\\nEnvironment=DEBUG=1\"",
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				Required: []string{"unit_name", "dropin_name", "content"},
+			},
+		},
+		{
+			Name:        "delete_dropin",
+			Description: "Delete a drop-in override and reload systemd",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"unit_name": {
+						Type:        "string",
+						Description: "Unit name; a bare name means a .service unit",
+					},
+					"dropin_name": {
+						Type:        "string",
+						Description: "Drop-in file name; .conf is added if missing",
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				Required: []string{"unit_name", "dropin_name"},
+			},
+		},
+		{
+			Name:        "cat_unit",
+			Description: "Show the effective configuration of a systemd unit: its unit file followed by all drop-ins, like systemctl cat",
+			InputSchema: types.JSONSchema{
+				Type: "object",
+				Properties: map[string]types.JSONSchema{
+					"unit_name": {
+						Type:        "string",
+						Description: "Unit name; a bare name means a .service unit",
+					},
+					"scope": {
+						Type:        "string",
+						Description: "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				Required: []string{"unit_name"},
+			},
+		},
+	}
+
+	for _, action := range managers.ServiceActions {
+		properties := map[string]types.JSONSchema{
+			"service_name": {
+				Type:        "string",
+				Description: "Name of the service",
+			},
+			"service_type": {
+				Type:        "string",
+				Description: "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+				Enum:        managers.ServiceTypeNames(s.config),
+			},
+			"scope": {
+				Type:        "string",
+				Description: "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+			},
+		}
+		if action.Name == "kill" {
+			properties["signal"] = types.JSONSchema{
+				Type:        "string",
+				Description: "Signal to send, e.g. SIGHUP, HUP or 1 (default: SIGTERM)",
+			}
+		}
+		tools = append(tools, types.Tool{
+			Name:        managers.ServiceActionTool(action.Name),
+			Description: action.Description,
+			InputSchema: types.JSONSchema{
+				Type:       "object",
+				Properties: properties,
+				Required:   []string{"service_name"},
+			},
+		})
+	}
+
+	result := types.ListToolsResult{Tools: tools}
+	return s.createSuccessResponse(request.ID, result)
+}
+
+func (s *Server) handleCallTool(ctx context.Context, request *types.MCPRequest) *types.MCPResponse {
+	var params types.CallToolParams
+	if request.Params != nil {
+		paramsBytes, _ := json.Marshal(request.Params)
+		if err := json.Unmarshal(paramsBytes, &params); err != nil {
+			return s.createErrorResponse(request.ID, types.InvalidParams, "Invalid params", err)
+		}
+	}
+
+	switch params.Name {
+	case "list_services":
+		return s.callListServices(ctx, request.ID, params.Arguments)
+	case "get_service_status":
+		return s.callGetServiceStatus(ctx, request.ID, params.Arguments)
+	case "start_service":
+		return s.callStartService(ctx, request.ID, params.Arguments)
+	case "stop_service":
+		return s.callStopService(ctx, request.ID, params.Arguments)
+	case "restart_service":
+		return s.callRestartService(ctx, request.ID, params.Arguments)
+	case "enable_service":
+		return s.callEnableService(ctx, request.ID, params.Arguments)
+	case "disable_service":
+		return s.callDisableService(ctx, request.ID, params.Arguments)
+	case "get_docker_logs":
+		return s.callGetDockerLogs(ctx, request.ID, params.Arguments)
+	case "get_service_logs":
+		return s.callGetServiceLogs(ctx, request.ID, params.Arguments)
+	case "get_service_stats":
+		return s.callGetServiceStats(ctx, request.ID, params.Arguments)
+	case "get_service_processes":
+		return s.callGetServiceProcesses(ctx, request.ID, params.Arguments)
+	case "find_service_owner":
+		return s.callFindServiceOwner(ctx, request.ID, params.Arguments)
+	case "get_service_health":
+		return s.callGetServiceHealth(ctx, request.ID, params.Arguments)
+	case "get_watchdog_status":
+		return s.callGetWatchdogStatus(ctx, request.ID, params.Arguments)
+	case "list_timers":
+		return s.callListTimers(ctx, request.ID, params.Arguments)
+	case "manage_timer":
+		return s.callManageTimer(ctx, request.ID, params.Arguments)
+	case "get_unit_file":
+		return s.callGetUnitFile(ctx, request.ID, params.Arguments)
+	case "write_unit_file":
+		return s.callWriteUnitFile(ctx, request.ID, params.Arguments)
+	case "delete_unit_file":
+		return s.callDeleteUnitFile(ctx, request.ID, params.Arguments)
+	case "list_dropins":
+		return s.callListDropIns(ctx, request.ID, params.Arguments)
+	case "write_dropin":
+		return s.callWriteDropIn(ctx, request.ID, params.Arguments)
+	case "delete_dropin":
+		return s.callDeleteDropIn(ctx, request.ID, params.Arguments)
+	case "cat_unit":
+		return s.callCatUnit(ctx, request.ID, params.Arguments)
+	default:
+		if action, ok := managers.ServiceActionForTool(params.Name); ok {
+			return s.callServiceOperation(ctx, request.ID, params.Arguments, action)
+		}
+		return s.createErrorResponse(request.ID, types.MethodNotFound, "Tool not found", nil)
+	}
+}
+
+func (s *Server) callListServices(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	var allServices []types.ServiceInfo
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+	unitType, _ := args["unit_type"].(string)
+	ctx, serviceType, err = managers.ApplyUnitType(ctx, serviceType, unitType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+	installed, _ := args["installed"].(bool)
+	ctx, serviceType, err = managers.ApplyInstalledUnits(ctx, serviceType, installed)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	if serviceType != "" {
+		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
+			services, err := manager.ListServices(ctx)
+			if err != nil {
+				return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list %s services: %v", serviceType, err))
+			}
+			allServices = services
+		} else {
+			return s.createToolErrorResponse(id, fmt.Sprintf("Unsupported service type: %s", serviceType))
+		}
+	} else {
+		for _, manager := range s.managers {
+			services, err := manager.ListServices(ctx)
+			if err != nil {
+				s.logger.Warnf("Failed to list services from manager: %v", err)
+				continue
+			}
+			allServices = append(allServices, services...)
+		}
+	}
+
+	s.health.AnnotateAll(allServices)
+
+	resultText := s.formatServicesOutput(allServices)
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callGetServiceStatus(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	info, err := manager.GetStatus(ctx, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+	}
+	s.health.Annotate(&info)
+
+	resultText := s.formatServiceInfo(info)
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callStartService(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "start")
+}
+
+func (s *Server) callStopService(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "stop")
+}
+
+func (s *Server) callRestartService(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "restart")
+}
+
+func (s *Server) callEnableService(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "enable")
+}
+
+func (s *Server) callDisableService(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "disable")
+}
+
+func (s *Server) callServiceOperation(ctx context.Context, id interface{}, args map[string]interface{}, operation string) *types.MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, operation)
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	signalName, _ := args["signal"].(string)
+	signal, err := managers.ParseSignal(signalName)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	if operationErr := managers.PerformAction(ctx, manager, serviceName, operation, signal); operationErr != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s service: %v", operation, operationErr))
+	}
+
+	info, _ := manager.GetStatus(ctx, serviceName)
+	resultText := fmt.Sprintf("%s.\n\n%s", managers.ActionMessage(serviceName, operation), s.formatServiceInfo(info))
+
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callGetServiceLogs(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	since, _ := args["since"].(string)
+	until, _ := args["until"].(string)
+	priority, _ := args["priority"].(string)
+	grep, _ := args["grep"].(string)
+	lines := 0
+	if l, ok := args["lines"].(float64); ok {
+		lines = int(l)
+	}
+	query, err := managers.ParseLogQuery(since, until, priority, grep, lines)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "logs")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err = managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	entries, err := managers.ReadServiceLogs(ctx, manager, serviceName, query)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
+	}
+
+	var resultText string
+	if len(entries) == 0 {
+		resultText = fmt.Sprintf("No log entries of service %s match the query.", serviceName)
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Logs of service %s (%d entries):\n\n", serviceName, len(entries))
+		for _, entry := range entries {
+			b.WriteString(entry.String())
+			b.WriteByte('\n')
+		}
+		resultText = b.String()
+	}
+
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callGetServiceStats(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "stats")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	stats, err := managers.ReadServiceStats(ctx, manager, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get stats: %v", err))
+	}
+
+	resultText := fmt.Sprintf("**Service**: %s\n%s", serviceName, stats.String())
+
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callGetServiceProcesses(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "processes")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	processes, err := managers.ReadServiceProcesses(ctx, manager, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get processes: %v", err))
+	}
+
+	resultText := fmt.Sprintf("**Service**: %s\n**Processes**: %d\n\n%s", serviceName, len(processes), types.ProcessTree(processes))
+
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+func (s *Server) callFindServiceOwner(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	var pid, port int
+	if p, ok := args["pid"].(float64); ok {
+		pid = int(p)
+	}
+	if p, ok := args["port"].(float64); ok {
+		port = int(p)
+	}
+	protocol, _ := args["protocol"].(string)
+	path, _ := args["path"].(string)
+
+	query, err := managers.ParseOwnerQuery(pid, port, protocol, path)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	owner, err := managers.LocateOwner(ctx, query)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, string(owner.Type), owner.Scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, owner.Name, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Process %d belongs to %s service %s: %v", owner.PID, owner.Type, owner.Name, err))
+	}
+
+	info, err := manager.GetStatus(ctx, owner.Name)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Process %d belongs to %s service %s, failed to get its status: %v", owner.PID, owner.Type, owner.Name, err))
+	}
+	s.health.Annotate(&info)
+
+	resultText := fmt.Sprintf("**Process**: %d\n**Service**: %s\n**Type**: %s\n", owner.PID, info.Name, info.Type)
+	if owner.Scope != "" {
+		resultText += fmt.Sprintf("**Scope**: %s\n", owner.Scope)
+	}
+	if owner.CGroup != "" {
+		resultText += fmt.Sprintf("**CGroup**: %s\n", owner.CGroup)
+	}
+	resultText += fmt.Sprintf("**Status**: %s\n", info.Status)
+	if info.Health != nil {
+		resultText += fmt.Sprintf("**Health**: %s\n", info.Health.Status)
+	}
+	if info.Description != "" {
+		resultText += fmt.Sprintf("**Description**: %s\n", info.Description)
+	}
+
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callGetServiceHealth(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	serviceName, _ := args["service_name"].(string)
+	if serviceName == "" {
+		results := s.health.Results()
+		resultText := "No health checks configured."
+		if len(results) > 0 {
+			var b strings.Builder
+			b.WriteString(fmt.Sprintf("Found %d health probes:\n\n", len(results)))
+			for _, probe := range results {
+				b.WriteString(fmt.Sprintf("- **%s** %s\n", probe.Service, probe))
+			}
+			resultText = b.String()
+		}
+		result := types.CallToolResult{
+			Content: []types.Content{{Type: "text", Text: resultText}},
+		}
+		return s.createSuccessResponse(id, result)
+	}
+
+	serviceType, _ := args["service_type"].(string)
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	info, err := manager.GetStatus(ctx, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+	}
+	s.health.Annotate(&info)
+	if info.Health == nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("No health checks for service %s", serviceName))
 	}
 
-	result := types.ListToolsResult{Tools: tools}
-	return s.createSuccessResponse(request.ID, result)
+	resultText := fmt.Sprintf("**Service**: %s\n**Status**: %s\n", info.Name, info.Status) + info.Health.String()
+	result := types.CallToolResult{
+			Content: []types.Content{{Type: "text", Text: resultText}},
+		}
+		return s.createSuccessResponse(id, result)
 }
 
-func (s *Server) handleCallTool(request *types.MCPRequest) *types.MCPResponse {
-	var params types.CallToolParams
-	if request.Params != nil {
-		paramsBytes, _ := json.Marshal(request.Params)
-		if err := json.Unmarshal(paramsBytes, &params); err != nil {
-			return s.createErrorResponse(request.ID, types.InvalidParams, "Invalid params", err)
-		}
+func (s *Server) callGetWatchdogStatus(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	serviceName, _ := args["service_name"].(string)
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
 	}
 
-	switch params.Name {
-	case "list_services":
-		return s.callListServices(request.ID, params.Arguments)
-	case "get_service_status":
-		return s.callGetServiceStatus(request.ID, params.Arguments)
-	case "start_service":
-		return s.callStartService(request.ID, params.Arguments)
-	case "stop_service":
-		return s.callStopService(request.ID, params.Arguments)
-	case "restart_service":
-		return s.callRestartService(request.ID, params.Arguments)
-	case "enable_service":
-		return s.callEnableService(request.ID, params.Arguments)
-	case "disable_service":
-		return s.callDisableService(request.ID, params.Arguments)
-	case "get_docker_logs":
-		return s.callGetDockerLogs(request.ID, params.Arguments)
-	default:
-		return s.createErrorResponse(request.ID, types.MethodNotFound, "Tool not found", nil)
+	var b strings.Builder
+	b.WriteString("## Watched services\n")
+	states := s.watchdog.States()
+	if len(states) == 0 {
+		b.WriteString("No services are watched; declare policies under watchdog: in config.yaml.\n")
+	}
+	for _, state := range states {
+		b.WriteString(fmt.Sprintf("- %s\n", state))
 	}
+	b.WriteString("\n## Automatic actions\n")
+	actions := s.watchdog.Actions(serviceName, limit)
+	if len(actions) == 0 {
+		b.WriteString("No automatic actions recorded.\n")
+	}
+	for _, action := range actions {
+		b.WriteString(fmt.Sprintf("- %s\n", action))
+	}
+
+	resultText := b.String()
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *Server) callListServices(id interface{}, args map[string]interface{}) *types.MCPResponse {
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
+func (s *Server) callGetDockerLogs(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	containerName, ok := args["container_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "container_name is required")
 	}
 
-	var allServices []types.ServiceInfo
+	dockerManager, exists := s.managers[types.ServiceTypeDocker].(managers.DockerContainerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Docker manager not available")
+	}
 
-	if serviceType != "" {
-		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
-			services, err := manager.ListServices()
-			if err != nil {
-				return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list %s services: %v", serviceType, err))
-			}
-			allServices = services
-		} else {
-			return s.createToolErrorResponse(id, fmt.Sprintf("Unsupported service type: %s", serviceType))
-		}
-	} else {
-		for _, manager := range s.managers {
-			services, err := manager.ListServices()
-			if err != nil {
-				s.logger.Warnf("Failed to list services from manager: %v", err)
-				continue
-			}
-			allServices = append(allServices, services...)
+	lines := 100
+	if l, ok := args["lines"]; ok {
+		if linesFloat, ok := l.(float64); ok {
+			lines = int(linesFloat)
 		}
 	}
 
-	resultText := s.formatServicesOutput(allServices)
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "logs")
+	defer cancel()
+
+	logs, err := dockerManager.GetLogs(ctx, containerName, lines)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Docker container '%s' logs (last %d lines):\n\n%s", containerName, lines, logs)
 	result := types.CallToolResult{
 		Content: []types.Content{{Type: "text", Text: resultText}},
 	}
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *Server) callGetServiceStatus(id interface{}, args map[string]interface{}) *types.MCPResponse {
-	serviceName, ok := args["service_name"].(string)
+func (s *Server) callListTimers(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	timerManager, exists := s.managers[types.ServiceTypeSystemd].(managers.TimerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Systemd timers not available")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	timers, err := timerManager.ListTimers(ctx)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list timers: %v", err))
+	}
+
+	resultText := s.formatTimersOutput(timers)
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callManageTimer(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	timerName, ok := args["timer_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "service_name is required")
+		return s.createToolErrorResponse(id, "timer_name is required")
+	}
+	action, ok := args["action"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "action is required")
 	}
 
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
+	timerManager, exists := s.managers[types.ServiceTypeSystemd].(managers.TimerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Systemd timers not available")
+	}
+
+	operation := action
+	if action == "trigger" {
+		operation = "start"
 	}
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, operation)
+	defer cancel()
 
-	manager, err := s.getServiceManager(serviceName, serviceType)
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
 	if err != nil {
 		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	info, err := manager.GetStatus(serviceName)
+	if err := managers.ManageTimer(ctx, timerManager, timerName, action); err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s timer: %v", action, err))
+	}
+
+	timer, err := timerManager.GetTimer(ctx, timerName)
 	if err != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+		return s.createToolErrorResponse(id, fmt.Sprintf("Timer %s completed but failed to get status: %v", action, err))
 	}
 
-	resultText := s.formatServiceInfo(info)
+	resultText := fmt.Sprintf("Timer %s: %s completed successfully.\n\n%s", timerName, action, s.formatTimersOutput([]types.TimerInfo{timer}))
 	result := types.CallToolResult{
 		Content: []types.Content{{Type: "text", Text: resultText}},
 	}
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *Server) callStartService(id interface{}, args map[string]interface{}) *types.MCPResponse {
-	return s.callServiceOperation(id, args, "start")
-}
+func (s *Server) callGetUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	unitFile, err := unitFiles.GetUnitFile(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to read unit file: %v", err))
+	}
 
-func (s *Server) callStopService(id interface{}, args map[string]interface{}) *types.MCPResponse {
-	return s.callServiceOperation(id, args, "stop")
+	resultText := fmt.Sprintf("**%s**\n\n```ini\n%s```", unitFile.Path, unitFile.Content)
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *Server) callRestartService(id interface{}, args map[string]interface{}) *types.MCPResponse {
-	return s.callServiceOperation(id, args, "restart")
+func (s *Server) callWriteUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+
+	var request types.UnitFileRequest
+	request.Content, _ = args["content"].(string)
+	if spec, ok := args["spec"]; ok {
+		data, _ := json.Marshal(spec)
+		if err := json.Unmarshal(data, &request.Spec); err != nil {
+			return s.createToolErrorResponse(id, fmt.Sprintf("Invalid spec: %v", err))
+		}
+	}
+	content, err := managers.UnitFileContent(request)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	unitFile, err := unitFiles.WriteUnitFile(ctx, unitName, content)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to write unit file: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Unit file %s installed and systemd reloaded.", unitFile.Path)
+	if unitFile.Backup != "" {
+		resultText += fmt.Sprintf("\nPrevious version saved to %s.", unitFile.Backup)
+	}
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *Server) callEnableService(id interface{}, args map[string]interface{}) *types.MCPResponse {
-	return s.callServiceOperation(id, args, "enable")
+func (s *Server) callDeleteUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	unitFile, err := unitFiles.RemoveUnitFile(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to remove unit file: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Unit file %s removed and systemd reloaded.\nBackup saved to %s.", unitFile.Path, unitFile.Backup)
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *Server) callDisableService(id interface{}, args map[string]interface{}) *types.MCPResponse {
-	return s.callServiceOperation(id, args, "disable")
+// unitFileManager returns the systemd unit file manager with the scope
+// argument applied to ctx.
+func (s *Server) unitFileManager(ctx context.Context, args map[string]interface{}) (context.Context, managers.UnitFileManager, error) {
+	unitFiles, exists := s.managers[types.ServiceTypeSystemd].(managers.UnitFileManager)
+	if !exists {
+		return ctx, nil, fmt.Errorf("systemd unit files not available")
+	}
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, unitFiles, nil
 }
 
-func (s *Server) callServiceOperation(id interface{}, args map[string]interface{}, operation string) *types.MCPResponse {
-	serviceName, ok := args["service_name"].(string)
+func (s *Server) callListDropIns(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	unitName, ok := args["unit_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "service_name is required")
+		return s.createToolErrorResponse(id, "unit_name is required")
 	}
 
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
-	}
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
 
-	manager, err := s.getServiceManager(serviceName, serviceType)
+	ctx, dropIns, err := s.dropInManager(ctx, args)
 	if err != nil {
 		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	var operationErr error
-	switch operation {
-	case "start":
-		operationErr = manager.Start(serviceName)
-	case "stop":
-		operationErr = manager.Stop(serviceName)
-	case "restart":
-		operationErr = manager.Restart(serviceName)
-	case "enable":
-		operationErr = manager.Enable(serviceName)
-	case "disable":
-		operationErr = manager.Disable(serviceName)
+	list, err := dropIns.ListDropIns(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list drop-ins: %v", err))
 	}
 
-	if operationErr != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s service: %v", operation, operationErr))
+	var resultText strings.Builder
+	if len(list) == 0 {
+		resultText.WriteString(fmt.Sprintf("No drop-ins found for %s.", unitName))
+	} else {
+		resultText.WriteString(fmt.Sprintf("Found %d drop-ins:\n", len(list)))
+		for _, dropIn := range list {
+			resultText.WriteString(fmt.Sprintf("\n**%s**\n```ini\n%s```\n", dropIn.Path, dropIn.Content))
+		}
+	}
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText.String()}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callWriteDropIn(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+	dropInName, ok := args["dropin_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "dropin_name is required")
+	}
+	content, ok := args["content"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "content is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	info, _ := manager.GetStatus(serviceName)
-	resultText := fmt.Sprintf("Service %s %sed successfully.\n\n%s", serviceName, operation, s.formatServiceInfo(info))
+	dropIn, err := dropIns.WriteDropIn(ctx, unitName, dropInName, content)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to write drop-in: %v", err))
+	}
 
+	resultText := fmt.Sprintf("Drop-in %s installed and systemd reloaded. Restart %s to apply it.", dropIn.Path, dropIn.Unit)
 	result := types.CallToolResult{
 		Content: []types.Content{{Type: "text", Text: resultText}},
 	}
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *Server) callGetDockerLogs(id interface{}, args map[string]interface{}) *types.MCPResponse {
-	containerName, ok := args["container_name"].(string)
+func (s *Server) callDeleteDropIn(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	unitName, ok := args["unit_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "container_name is required")
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+	dropInName, ok := args["dropin_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "dropin_name is required")
 	}
 
-	dockerManager, exists := s.managers[types.ServiceTypeDocker].(*managers.DockerManager)
-	if !exists {
-		return s.createToolErrorResponse(id, "Docker manager not available")
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	lines := 100
-	if l, ok := args["lines"]; ok {
-		if linesFloat, ok := l.(float64); ok {
-			lines = int(linesFloat)
-		}
+	dropIn, err := dropIns.RemoveDropIn(ctx, unitName, dropInName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to remove drop-in: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Drop-in %s removed and systemd reloaded.", dropIn.Path)
+	result := types.CallToolResult{
+		Content: []types.Content{{Type: "text", Text: resultText}},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *Server) callCatUnit(ctx context.Context, id interface{}, args map[string]interface{}) *types.MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
 	}
 
-	logs, err := dockerManager.GetLogs(containerName, lines)
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
 	if err != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	resultText := fmt.Sprintf("Docker container '%s' logs (last %d lines):\n\n%s", containerName, lines, logs)
+	content, err := dropIns.CatUnit(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to show unit configuration: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Effective configuration of %s:\n\n```ini\n%s```", unitName, content)
 	result := types.CallToolResult{
 		Content: []types.Content{{Type: "text", Text: resultText}},
 	}
 	return s.createSuccessResponse(id, result)
 }
 
+// dropInManager returns the systemd drop-in manager with the scope argument
+// applied to ctx.
+func (s *Server) dropInManager(ctx context.Context, args map[string]interface{}) (context.Context, managers.DropInManager, error) {
+	dropIns, exists := s.managers[types.ServiceTypeSystemd].(managers.DropInManager)
+	if !exists {
+		return ctx, nil, fmt.Errorf("systemd drop-ins not available")
+	}
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, dropIns, nil
+}
+
 func (s *Server) handleListPrompts(request *types.MCPRequest) *types.MCPResponse {
 	prompts := []types.Prompt{
 		{
@@ -551,7 +1680,7 @@ func (s *Server) getServiceManagementHelp(id interface{}, args map[string]interf
 	case "docker":
 		content = "# Docker Container Management\n\nManage Docker containers as services. Key commands:\n- `docker start <container>` - Start a container\n- `docker stop <container>` - Stop a container\n- `docker restart <container>` - Restart a container\n- `docker update --restart=always <container>` - Auto-restart container\n- `docker ps -a` - List all containers\n- `docker logs <container>` - View container logs"
 	default:
-		content = "# Linux Service Management Guide\n\nThis MCP server supports managing services through multiple methods:\n\n## Supported Service Types\n1. **systemd** - Modern Linux distributions\n2. **System V init** - Traditional Linux distributions\n3. **Docker** - Container management\n\n## Available Operations\n- Start/Stop/Restart services\n- Enable/Disable services for boot\n- Get service status and information\n- List all available services\n- View Docker container logs\n\n## Usage\nUse the available tools to manage services. The server will automatically detect which service manager to use based on your system and the service name."
+		content = "# Linux Service Management Guide\n\nThis MCP server supports managing services through multiple methods:\n\n## Supported Service Types\n1. **systemd** - Modern Linux distributions\n2. **System V init** - Traditional Linux distributions\n3. **Docker** - Container management\n4. **Podman** - Rootless containers and pods (use `pod:<name>` for a pod)\n5. **OpenRC** - Alpine and Gentoo; enable/disable manage `default` runlevel membership\n6. **runit / s6** - Supervision trees; enable/disable link the service into the scan directory\n7. **supervisord** - Process groups over XML-RPC (use `group:process` for a single process)\n8. **script** - Services declared under `scripts:` in config.yaml with their own start/stop/status commands\n9. **native** - Processes declared under `native:` in config.yaml, spawned and restarted by this server\n10. **plugins** - External managers declared under `plugins:` in config.yaml; each plugin name is its own service type\n\n## Available Operations\n- Start/Stop/Restart services\n- Enable/Disable services for boot\n- Get service status and information\n- List all available services\n- View Docker container logs\n- Read any service's logs filtered by time, priority and pattern\n- Show CPU, memory, IO and task usage from a service's cgroup\n- Show a service's process tree with the ports each process listens on\n- Find the service that owns a PID, a listening port or an open file\n- Check service health from HTTP, TCP and exec probes and container health checks\n- Review the restarts and escalations made by the watchdog\n- List systemd timers and start, stop, enable, disable or trigger them\n- Create, replace and delete systemd unit files\n- Manage drop-in overrides and show the effective unit configuration\n- Reload, kill, mask, reset-failed, pause and other extended service actions\n\n## Usage\nUse the available tools to manage services. The server will automatically detect which service manager to use based on your system and the service name."
 	}
 
 	result := types.GetPromptResult{
@@ -652,7 +1781,7 @@ func (s *Server) handleSetLogLevel(request *types.MCPRequest) *types.MCPResponse
 
 // Helper methods
 
-func (s *Server) getServiceManager(serviceName, serviceType string) (types.ServiceManager, error) {
+func (s *Server) getServiceManager(ctx context.Context, serviceName, serviceType string) (types.ServiceManager, error) {
 	if serviceType != "" {
 		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
 			return manager, nil
@@ -662,7 +1791,7 @@ func (s *Server) getServiceManager(serviceName, serviceType string) (types.Servi
 
 	// Auto-detect service type
 	for _, manager := range s.managers {
-		if _, err := manager.GetStatus(serviceName); err == nil {
+		if _, err := manager.GetStatus(ctx, serviceName); err == nil {
 			return manager, nil
 		}
 	}
@@ -687,10 +1816,23 @@ func (s *Server) formatServicesOutput(services []types.ServiceInfo) string {
 	for serviceType, typeServices := range servicesByType {
 		result.WriteString(fmt.Sprintf("## %s Services\n", strings.Title(string(serviceType))))
 		for _, service := range typeServices {
-			result.WriteString(fmt.Sprintf("- **%s**: %s", service.Name, service.Status))
+			result.WriteString(fmt.Sprintf("- **%s**", service.Name))
+			if service.Scope != "" && service.Scope != "system" {
+				result.WriteString(fmt.Sprintf(" (%s)", service.Scope))
+			}
+			result.WriteString(fmt.Sprintf(": %s", service.Status))
 			if service.Description != "" {
 				result.WriteString(fmt.Sprintf(" - %s", service.Description))
 			}
+			if service.EnabledState != "" {
+				result.WriteString(fmt.Sprintf(" [unit file: %s]", service.EnabledState))
+			}
+			if len(service.Listen) > 0 {
+				result.WriteString(fmt.Sprintf(" [listen: %s]", strings.Join(service.Listen, ", ")))
+			}
+			if service.Health != nil {
+				result.WriteString(fmt.Sprintf(" [health: %s]", service.Health.Status))
+			}
 			result.WriteString("\n")
 		}
 		result.WriteString("\n")
@@ -703,6 +1845,12 @@ func (s *Server) formatServiceInfo(info types.ServiceInfo) string {
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("**Service**: %s\n", info.Name))
 	result.WriteString(fmt.Sprintf("**Type**: %s\n", info.Type))
+	if info.Scope != "" {
+		result.WriteString(fmt.Sprintf("**Scope**: %s\n", info.Scope))
+	}
+	if info.UnitType != "" && info.UnitType != "service" {
+		result.WriteString(fmt.Sprintf("**Unit Type**: %s\n", info.UnitType))
+	}
 	result.WriteString(fmt.Sprintf("**Status**: %s\n", info.Status))
 
 	if info.Description != "" {
@@ -713,6 +1861,38 @@ func (s *Server) formatServiceInfo(info types.ServiceInfo) string {
 		result.WriteString(fmt.Sprintf("**PID**: %d\n", info.PID))
 	}
 
+	for _, listen := range info.Listen {
+		result.WriteString(fmt.Sprintf("**Listen**: %s\n", listen))
+	}
+
+	if info.SubState != "" {
+		result.WriteString(fmt.Sprintf("**Sub State**: %s\n", info.SubState))
+	}
+	if info.LoadState != "" {
+		result.WriteString(fmt.Sprintf("**Load State**: %s\n", info.LoadState))
+	}
+	if info.EnabledState != "" {
+		result.WriteString(fmt.Sprintf("**Enabled**: %s\n", info.EnabledState))
+	}
+	if info.UnitPath != "" {
+		result.WriteString(fmt.Sprintf("**Unit Path**: %s\n", info.UnitPath))
+	}
+	if info.Restarts > 0 {
+		result.WriteString(fmt.Sprintf("**Restarts**: %d\n", info.Restarts))
+	}
+	if info.ExitCode != nil {
+		result.WriteString(fmt.Sprintf("**Exit Code**: %d\n", *info.ExitCode))
+	}
+	if info.OOMKilled {
+		result.WriteString("**OOM Killed**: yes\n")
+	}
+	if info.MemoryBytes > 0 {
+		result.WriteString(fmt.Sprintf("**Memory**: %.1f MiB\n", float64(info.MemoryBytes)/(1<<20)))
+	}
+	if info.ActiveSince != nil {
+		result.WriteString(fmt.Sprintf("**Active Since**: %s\n", info.ActiveSince.Format("2006-01-02 15:04:05")))
+	}
+
 	if info.Uptime > 0 {
 		result.WriteString(fmt.Sprintf("**Uptime**: %s\n", info.Uptime.String()))
 	}
@@ -721,6 +1901,38 @@ func (s *Server) formatServiceInfo(info types.ServiceInfo) string {
 		result.WriteString(fmt.Sprintf("**Last Changed**: %s\n", info.LastChanged.Format("2006-01-02 15:04:05")))
 	}
 
+	if info.Health != nil {
+		result.WriteString(info.Health.String())
+	}
+
+	return result.String()
+}
+
+func (s *Server) formatTimersOutput(timers []types.TimerInfo) string {
+	if len(timers) == 0 {
+		return "No timers found."
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d timers:\n\n", len(timers)))
+	for _, timer := range timers {
+		result.WriteString(fmt.Sprintf("- **%s**", timer.Name))
+		if timer.Scope != "" && timer.Scope != "system" {
+			result.WriteString(fmt.Sprintf(" (%s)", timer.Scope))
+		}
+		result.WriteString(fmt.Sprintf(" → %s: %s", timer.Unit, timer.Status))
+		if !timer.NextElapse.IsZero() {
+			result.WriteString(fmt.Sprintf(", next: %s", timer.NextElapse.Format("2006-01-02 15:04:05")))
+		}
+		if !timer.LastTrigger.IsZero() {
+			result.WriteString(fmt.Sprintf(", last: %s", timer.LastTrigger.Format("2006-01-02 15:04:05")))
+		}
+		if timer.Description != "" {
+			result.WriteString(fmt.Sprintf(" - %s", timer.Description))
+		}
+		result.WriteString("\n")
+	}
+
 	return result.String()
 }
 
@@ -753,6 +1965,5 @@ func (s *Server) createToolErrorResponse(id interface{}, message string) *types.
 }
 
 func (s *Server) sendError(writer *json.Encoder, id interface{}, code int, message string, data interface{}) {
-	response := s.createErrorResponse(id, code, message, data)
-	writer.Encode(response)
+	s.writeResponse(writer, s.createErrorResponse(id, code, message, data))
 }
\ No newline at end of file
@@ -1,17 +1,24 @@
 package mcp
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"nucc.com/mcp_srv_mgr/internal/config"
 	"nucc.com/mcp_srv_mgr/pkg/types"
 )
 
 func TestNewServer(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	if server == nil {
 		t.Fatal("Expected Server instance, got nil")
@@ -36,7 +43,7 @@ func TestNewServer(t *testing.T) {
 
 func TestServer_HandleInitialize(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -80,7 +87,7 @@ func TestServer_HandleInitialize(t *testing.T) {
 
 func TestServer_HandleInitialized(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -105,7 +112,7 @@ func TestServer_HandleInitialized(t *testing.T) {
 
 func TestServer_HandleListTools(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -132,7 +139,14 @@ func TestServer_HandleListTools(t *testing.T) {
 	expectedTools := []string{
 		"list_services", "get_service_status", "start_service",
 		"stop_service", "restart_service", "enable_service",
-		"disable_service", "get_docker_logs",
+		"disable_service", "get_docker_logs", "get_service_logs", "get_service_stats",
+		"get_service_processes", "find_service_owner", "get_service_health", "get_watchdog_status",
+		"list_timers", "manage_timer", "get_unit_file",
+		"write_unit_file", "delete_unit_file", "list_dropins",
+		"write_dropin", "delete_dropin", "cat_unit", "reload_service",
+		"reload_or_restart_service", "try_restart_service", "kill_service",
+		"mask_service", "unmask_service", "reset_failed_service",
+		"pause_service", "unpause_service",
 	}
 	
 	if len(result.Tools) != len(expectedTools) {
@@ -155,7 +169,7 @@ func TestServer_HandleListTools(t *testing.T) {
 
 func TestServer_HandleListPrompts(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -197,7 +211,7 @@ func TestServer_HandleListPrompts(t *testing.T) {
 
 func TestServer_HandleSetLogLevel(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -225,7 +239,7 @@ func TestServer_HandleSetLogLevel(t *testing.T) {
 
 func TestServer_HandleCallTool_ListServices(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -237,7 +251,7 @@ func TestServer_HandleCallTool_ListServices(t *testing.T) {
 		},
 	}
 	
-	response := server.handleCallTool(request)
+	response := server.handleCallTool(context.Background(), request)
 	
 	if response == nil {
 		t.Fatal("Expected response, got nil")
@@ -263,7 +277,7 @@ func TestServer_HandleCallTool_ListServices(t *testing.T) {
 
 func TestServer_HandleCallTool_GetServiceStatus(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -277,7 +291,7 @@ func TestServer_HandleCallTool_GetServiceStatus(t *testing.T) {
 		},
 	}
 	
-	response := server.handleCallTool(request)
+	response := server.handleCallTool(context.Background(), request)
 	
 	if response == nil {
 		t.Fatal("Expected response, got nil")
@@ -301,7 +315,7 @@ func TestServer_HandleCallTool_GetServiceStatus(t *testing.T) {
 
 func TestServer_HandleGetPrompt_ServiceManagementHelp(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -343,7 +357,7 @@ func TestServer_HandleGetPrompt_ServiceManagementHelp(t *testing.T) {
 
 func TestServer_HandleUnknownMethod(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -351,7 +365,7 @@ func TestServer_HandleUnknownMethod(t *testing.T) {
 		Method:  "unknown/method",
 	}
 	
-	response := server.handleRequest(request)
+	response := server.handleRequest(context.Background(), request)
 	
 	if response == nil {
 		t.Fatal("Expected response, got nil")
@@ -368,7 +382,7 @@ func TestServer_HandleUnknownMethod(t *testing.T) {
 
 func TestServer_FormatServicesOutput(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	services := []types.ServiceInfo{
 		{
@@ -412,7 +426,7 @@ func TestServer_FormatServicesOutput(t *testing.T) {
 
 func TestServer_FormatServiceInfo(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	service := types.ServiceInfo{
 		Name:        "nginx",
@@ -435,9 +449,42 @@ func TestServer_FormatServiceInfo(t *testing.T) {
 	}
 }
 
+func TestServer_FormatServiceInfoDetails(t *testing.T) {
+	server := NewServer(&config.Config{}, logrus.New())
+	exitCode := 137
+
+	output := server.formatServiceInfo(types.ServiceInfo{
+		Name:         "nginx",
+		Type:         types.ServiceTypeSystemd,
+		Status:       types.StatusFailed,
+		SubState:     "failed",
+		LoadState:    "loaded",
+		EnabledState: "enabled",
+		UnitPath:     "/lib/systemd/system/nginx.service",
+		Restarts:     3,
+		ExitCode:     &exitCode,
+		OOMKilled:    true,
+		MemoryBytes:  3 << 20,
+	})
+
+	for _, content := range []string{
+		"**Sub State**: failed", "**Load State**: loaded", "**Enabled**: enabled",
+		"**Unit Path**: /lib/systemd/system/nginx.service", "**Restarts**: 3",
+		"**Exit Code**: 137", "**OOM Killed**: yes", "**Memory**: 3.0 MiB",
+	} {
+		if !strings.Contains(output, content) {
+			t.Errorf("Expected %q in service info output:\n%s", content, output)
+		}
+	}
+	// 未运行的服务不显示启动时间
+	if strings.Contains(output, "Active Since") {
+		t.Errorf("Expected no active since for a failed service:\n%s", output)
+	}
+}
+
 func TestServer_CreateResponses(t *testing.T) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	// Test success response
 	successResponse := server.createSuccessResponse(1, "test result")
@@ -483,10 +530,97 @@ func TestServer_CreateResponses(t *testing.T) {
 	}
 }
 
+// blockingManager 在 Start 中阻塞直到 ctx 被取消，用于测试请求取消
+type blockingManager struct {
+	types.ServiceManager
+	started   chan struct{}
+	cancelled chan error
+}
+
+func (m *blockingManager) Start(ctx context.Context, serviceName string) error {
+	close(m.started)
+	<-ctx.Done()
+	m.cancelled <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestServer_Serve_CancelledNotification(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	server := NewServer(&config.Config{}, logger)
+
+	manager := &blockingManager{started: make(chan struct{}), cancelled: make(chan error, 1)}
+	server.managers = map[types.ServiceType]types.ServiceManager{types.ServiceTypeSystemd: manager}
+
+	in, inWriter := io.Pipe()
+	var out bytes.Buffer
+	served := make(chan struct{})
+	go func() {
+		server.Serve(context.Background(), in, &out)
+		close(served)
+	}()
+
+	fmt.Fprintln(inWriter, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"start_service","arguments":{"service_name":"nginx","service_type":"systemd"}}}`)
+	<-manager.started
+	fmt.Fprintln(inWriter, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`)
+
+	select {
+	case err := <-manager.cancelled:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Tool call was not cancelled")
+	}
+
+	inWriter.Close()
+	<-served
+
+	// 被取消的请求不应返回响应
+	if out.Len() != 0 {
+		t.Errorf("Expected no response for cancelled request, got %q", out.String())
+	}
+}
+
+func TestServer_Serve_ParseErrorDuringToolCall(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	server := NewServer(&config.Config{}, logger)
+
+	manager := &blockingManager{started: make(chan struct{}), cancelled: make(chan error, 1)}
+	server.managers = map[types.ServiceType]types.ServiceManager{types.ServiceTypeSystemd: manager}
+
+	in, inWriter := io.Pipe()
+	var out bytes.Buffer
+	served := make(chan struct{})
+	go func() {
+		server.Serve(context.Background(), in, &out)
+		close(served)
+	}()
+
+	fmt.Fprintln(inWriter, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"start_service","arguments":{"service_name":"nginx","service_type":"systemd"}}}`)
+	<-manager.started
+	// 解析错误与进行中的工具调用共用同一个输出，写入必须串行
+	fmt.Fprintln(inWriter, `{not json`)
+	inWriter.Close()
+	<-served
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a parse error and a tool response, got %q", out.String())
+	}
+	for _, line := range lines {
+		var response types.MCPResponse
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Errorf("Expected one JSON response per line, got %q: %v", line, err)
+		}
+	}
+}
+
 // Benchmark tests
 func BenchmarkServer_HandleListTools(b *testing.B) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -502,7 +636,7 @@ func BenchmarkServer_HandleListTools(b *testing.B) {
 
 func BenchmarkServer_HandleCallTool_ListServices(b *testing.B) {
 	logger := logrus.New()
-	server := NewServer(logger)
+	server := NewServer(&config.Config{}, logger)
 	
 	request := &types.MCPRequest{
 		JSONRPC: "2.0",
@@ -516,6 +650,6 @@ func BenchmarkServer_HandleCallTool_ListServices(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		server.handleCallTool(request)
+		server.handleCallTool(context.Background(), request)
 	}
 }
\ No newline at end of file
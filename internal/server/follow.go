@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"nucc.com/mcp_srv_mgr/internal/inflight"
+	"nucc.com/mcp_srv_mgr/internal/managers"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// logNotificationMethod is the JSON-RPC notification carrying the entries of
+// a follow_service_logs subscription.
+const logNotificationMethod = "notifications/service_log"
+
+// logSubscriptions tracks the log follows started by one MCP client or
+// session. Each runs until it is stopped through unfollow_service_logs or
+// notifications/cancelled, its log source ends, or the session closes.
+type logSubscriptions struct {
+	mu   sync.Mutex
+	next int
+	subs map[string]logSubscription
+}
+
+type logSubscription struct {
+	requestKey string
+	cancel     context.CancelFunc
+}
+
+func newLogSubscriptions() *logSubscriptions {
+	return &logSubscriptions{subs: make(map[string]logSubscription)}
+}
+
+// start runs follow in the background and returns the subscription's ID.
+// Its context keeps the values of ctx, such as the systemd scope, but is
+// only cancelled with session or by stop. requestID is the ID of the
+// follow_service_logs call, which notifications/cancelled may name.
+func (l *logSubscriptions) start(ctx, session context.Context, requestID interface{}, follow func(ctx context.Context, id string)) string {
+	subCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stopAfter := context.AfterFunc(session, cancel)
+
+	l.mu.Lock()
+	l.next++
+	id := fmt.Sprintf("logs_%d", l.next)
+	l.subs[id] = logSubscription{requestKey: inflight.Key(requestID), cancel: cancel}
+	l.mu.Unlock()
+
+	go func() {
+		defer stopAfter()
+		defer l.stop(id)
+		follow(subCtx, id)
+	}()
+	return id
+}
+
+// stop ends the subscription with the given ID. It reports whether such a
+// subscription was running.
+func (l *logSubscriptions) stop(id string) bool {
+	l.mu.Lock()
+	sub, ok := l.subs[id]
+	delete(l.subs, id)
+	l.mu.Unlock()
+
+	if ok {
+		sub.cancel()
+	}
+	return ok
+}
+
+// cancelRequest ends the subscriptions started by the request with the
+// given ID.
+func (l *logSubscriptions) cancelRequest(requestID interface{}) bool {
+	key := inflight.Key(requestID)
+	l.mu.Lock()
+	var ids []string
+	for id, sub := range l.subs {
+		if sub.requestKey == key {
+			ids = append(ids, id)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, id := range ids {
+		l.stop(id)
+	}
+	return len(ids) > 0
+}
+
+// active returns the number of running subscriptions.
+func (l *logSubscriptions) active() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.subs)
+}
+
+// logSubscriber is the MCP client or session a request came from: the
+// context its subscriptions live in and how notifications reach it.
+type logSubscriber struct {
+	session       context.Context
+	subscriptions *logSubscriptions
+	notify        func(ctx context.Context, method string, params interface{})
+}
+
+type logSubscriberKey struct{}
+
+// withLogSubscriber makes sub available to the tools handling a request.
+func withLogSubscriber(ctx context.Context, sub *logSubscriber) context.Context {
+	return context.WithValue(ctx, logSubscriberKey{}, sub)
+}
+
+// logSubscriberFrom returns the subscriber of a request. Requests that
+// cannot receive notifications, such as single HTTP requests, have none.
+func logSubscriberFrom(ctx context.Context) (*logSubscriber, bool) {
+	sub, ok := ctx.Value(logSubscriberKey{}).(*logSubscriber)
+	return sub, ok
+}
+
+// followLogs starts a subscription that sends each entry of the service's
+// log as a notifications/service_log message. When the log source ends on
+// its own, a last message with "ended" set, and the error if any, is sent.
+func (sub *logSubscriber) followLogs(ctx context.Context, requestID interface{}, manager types.ServiceManager, serviceName string, query managers.LogQuery) string {
+	return sub.subscriptions.start(ctx, sub.session, requestID, func(ctx context.Context, id string) {
+		err := managers.FollowServiceLogs(ctx, manager, serviceName, query, func(entry types.LogEntry) {
+			sub.notify(ctx, logNotificationMethod, map[string]interface{}{
+				"subscriptionId": id,
+				"service":        serviceName,
+				"entry":          entry,
+			})
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		params := map[string]interface{}{
+			"subscriptionId": id,
+			"service":        serviceName,
+			"ended":          true,
+		}
+		if err != nil {
+			params["error"] = err.Error()
+		}
+		sub.notify(ctx, logNotificationMethod, params)
+	})
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/internal/inflight"
+	"nucc.com/mcp_srv_mgr/internal/managers"
+	"nucc.com/mcp_srv_mgr/pkg/types"
+)
+
+// followingManager 先输出一条日志，然后一直跟踪直到 ctx 结束
+type followingManager struct {
+	*MockServiceManager
+	started chan struct{}
+	stopped chan struct{}
+	// ends 为 true 时日志源在第一条之后自行结束
+	ends bool
+}
+
+func newFollowingManager() *followingManager {
+	m := &followingManager{
+		MockServiceManager: NewMockServiceManager(),
+		started:            make(chan struct{}, 10),
+		stopped:            make(chan struct{}, 10),
+	}
+	m.AddService("nginx", types.ServiceInfo{Name: "nginx", Type: types.ServiceTypeSystemd, Status: types.StatusActive})
+	return m
+}
+
+func (m *followingManager) FollowLogs(ctx context.Context, serviceName string, query managers.LogQuery, emit func(types.LogEntry)) error {
+	m.started <- struct{}{}
+	defer func() { m.stopped <- struct{}{} }()
+	emit(types.LogEntry{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Priority: 6, Message: "ready", Source: serviceName})
+	if m.ends {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %s", what)
+	}
+}
+
+func TestLogSubscriptions(t *testing.T) {
+	subs := newLogSubscriptions()
+	session, closeSession := context.WithCancel(context.Background())
+
+	stopped := make(chan struct{}, 3)
+	follow := func(ctx context.Context, id string) {
+		<-ctx.Done()
+		stopped <- struct{}{}
+	}
+
+	first := subs.start(context.Background(), session, float64(1), follow)
+	subs.start(context.Background(), session, float64(2), follow)
+	subs.start(context.Background(), session, float64(3), follow)
+	if subs.active() != 3 {
+		t.Fatalf("Expected 3 subscriptions, got %d", subs.active())
+	}
+
+	if !subs.stop(first) || subs.stop(first) {
+		t.Error("Expected a subscription to be stopped exactly once")
+	}
+	waitFor(t, stopped, "unsubscribe")
+
+	// notifications/cancelled 使用 follow_service_logs 请求的 ID
+	if !subs.cancelRequest(float64(2)) {
+		t.Error("Expected the subscription of request 2 to be found")
+	}
+	waitFor(t, stopped, "cancellation")
+
+	// 会话关闭时剩余的订阅也会结束
+	closeSession()
+	waitFor(t, stopped, "session close")
+	for i := 0; i < 100 && subs.active() > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if subs.active() != 0 {
+		t.Errorf("Expected no subscriptions left, got %d", subs.active())
+	}
+}
+
+func TestLogSubscriber_Ended(t *testing.T) {
+	manager := newFollowingManager()
+	manager.ends = true
+
+	notifications := make(chan map[string]interface{}, 10)
+	sub := &logSubscriber{
+		session:       context.Background(),
+		subscriptions: newLogSubscriptions(),
+		notify: func(ctx context.Context, method string, params interface{}) {
+			if method != logNotificationMethod {
+				t.Errorf("Unexpected notification method: %s", method)
+			}
+			notifications <- params.(map[string]interface{})
+		},
+	}
+
+	query, _ := managers.ParseLogQuery("", "", "", "", 0)
+	id := sub.followLogs(context.Background(), "req-1", manager, "nginx", query)
+
+	entry := <-notifications
+	if entry["subscriptionId"] != id || entry["entry"].(types.LogEntry).Message != "ready" {
+		t.Errorf("Unexpected entry notification: %v", entry)
+	}
+	// 日志源自行结束时发送结束通知
+	if end := <-notifications; end["ended"] != true || end["error"] != nil {
+		t.Errorf("Unexpected end notification: %v", end)
+	}
+}
+
+func newTestMCPHTTPServer(manager types.ServiceManager) *MCPHTTPServer {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	return &MCPHTTPServer{
+		managers: map[types.ServiceType]types.ServiceManager{types.ServiceTypeSystemd: manager},
+		config:   &config.Config{},
+		logger:   logger,
+		clients:  make(map[string]*SSEClient),
+	}
+}
+
+func TestMCPHTTPServer_FollowServiceLogs(t *testing.T) {
+	manager := newFollowingManager()
+	server := newTestMCPHTTPServer(manager)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := httptest.NewRecorder()
+	client := &SSEClient{
+		ID:       "client_1",
+		Writer:   recorder,
+		Flusher:  recorder,
+		Context:  ctx,
+		Cancel:   cancel,
+		inflight: inflight.New(),
+		logs:     newLogSubscriptions(),
+	}
+
+	call := func(id interface{}, name string, args map[string]interface{}) *MCPResponse {
+		req := &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      id,
+			Method:  "tools/call",
+			Params:  map[string]interface{}{"name": name, "arguments": args},
+		}
+		return server.dispatchRequest(httptest.NewRequest("POST", "/message", nil), client, req)
+	}
+
+	resp := call(float64(1), "follow_service_logs", map[string]interface{}{"service_name": "nginx"})
+	if resp == nil || resp.Error != nil || !strings.Contains(toolText(resp.Result), "logs_1") {
+		t.Fatalf("Unexpected follow response: %+v", resp)
+	}
+	waitFor(t, manager.started, "follow to start")
+
+	// 通过取消原请求结束订阅
+	server.dispatchRequest(httptest.NewRequest("POST", "/message", nil), client, &MCPRequest{
+		JSONRPC: "2.0",
+		Method:  "notifications/cancelled",
+		Params:  map[string]interface{}{"requestId": float64(1)},
+	})
+	waitFor(t, manager.stopped, "cancellation")
+
+	body := recorder.Body.String()
+	if !strings.Contains(body, "event: message") || !strings.Contains(body, `"method":"notifications/service_log"`) || !strings.Contains(body, `"message":"ready"`) {
+		t.Errorf("Expected a log notification on the SSE stream, got %q", body)
+	}
+
+	resp = call(float64(2), "follow_service_logs", map[string]interface{}{"service_name": "nginx"})
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("Unexpected follow response: %+v", resp)
+	}
+	waitFor(t, manager.started, "second follow to start")
+	if resp := call(float64(3), "unfollow_service_logs", map[string]interface{}{"subscription_id": "logs_2"}); strings.Contains(toolText(resp.Result), "No log subscription") {
+		t.Errorf("Expected logs_2 to be stopped, got %v", resp.Result)
+	}
+	waitFor(t, manager.stopped, "unfollow")
+
+	// 客户端断开时订阅结束
+	call(float64(4), "follow_service_logs", map[string]interface{}{"service_name": "nginx"})
+	waitFor(t, manager.started, "third follow to start")
+	cancel()
+	waitFor(t, manager.stopped, "disconnect")
+}
+
+func TestMCPStreamableServer_FollowRequiresSession(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	server := &MCPStreamableServer{
+		managers: map[types.ServiceType]types.ServiceManager{types.ServiceTypeSystemd: newFollowingManager()},
+		config:   &config.Config{},
+		logger:   logger,
+		sessions: make(map[string]*StreamableSession),
+	}
+
+	// 单次请求无法接收通知
+	resp := server.processMCPRequest(context.Background(), &StreamableRequest{
+		JSONRPC: "2.0",
+		ID:      float64(1),
+		Method:  "tools/call",
+		Params:  map[string]interface{}{"name": "follow_service_logs", "arguments": map[string]interface{}{"service_name": "nginx"}},
+	})
+	if !strings.Contains(toolText(resp.Result), "requires a session") {
+		t.Errorf("Expected follow to be refused without a session, got %+v", resp.Result)
+	}
+}
+
+// toolText 返回工具结果中的文本内容
+func toolText(result interface{}) string {
+	data, _ := json.Marshal(result)
+	return string(data)
+}
+
+func TestMCPHTTPServer_SSEDisconnectStopsWrites(t *testing.T) {
+	server := newTestMCPHTTPServer(newFollowingManager())
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	recorder := httptest.NewRecorder()
+	returned := make(chan struct{})
+	go func() {
+		server.handleSSE(recorder, httptest.NewRequest("GET", "/sse", nil).WithContext(ctx))
+		close(returned)
+	}()
+
+	var client *SSEClient
+	deadline := time.Now().Add(5 * time.Second)
+	for client == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the SSE client")
+		}
+		server.clientMu.Lock()
+		for _, c := range server.clients {
+			client = c
+		}
+		server.clientMu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 正在写入的通知完成之前处理函数不能返回
+	client.writeMu.Lock()
+	disconnect()
+	select {
+	case <-returned:
+		t.Fatal("Expected handleSSE to wait for the write in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	client.writeMu.Unlock()
+	waitFor(t, returned, "handleSSE to return")
+
+	// 连接断开后的通知不再写入
+	written := recorder.Body.Len()
+	server.sendSSEMessage(client, "message", map[string]interface{}{"method": "notifications/service_log"})
+	if recorder.Body.Len() != written {
+		t.Errorf("Expected no write after disconnect, got %q", recorder.Body.String()[written:])
+	}
+}
@@ -1,11 +1,14 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -16,8 +19,14 @@ import (
 	"nucc.com/mcp_srv_mgr/pkg/utils"
 )
 
+// logKeepaliveInterval is how often an idle log stream sends a comment so
+// proxies do not close it.
+var logKeepaliveInterval = 15 * time.Second
+
 type HTTPServer struct {
 	managers map[types.ServiceType]types.ServiceManager
+	health   *managers.HealthMonitor
+	watchdog *managers.Watchdog
 	config   *config.Config
 	logger   *logrus.Logger
 }
@@ -28,53 +37,53 @@ type enhancedDockerManager struct {
 	mockManager types.ServiceManager
 }
 
-func (e *enhancedDockerManager) Start(serviceName string) error {
+func (e *enhancedDockerManager) Start(ctx context.Context, serviceName string) error {
 	// 先尝试真实管理器，失败则尝试mock
-	if err := e.original.Start(serviceName); err != nil {
-		return e.mockManager.Start(serviceName)
+	if err := e.original.Start(ctx, serviceName); err != nil {
+		return e.mockManager.Start(ctx, serviceName)
 	}
 	return nil
 }
 
-func (e *enhancedDockerManager) Stop(serviceName string) error {
-	if err := e.original.Stop(serviceName); err != nil {
-		return e.mockManager.Stop(serviceName)
+func (e *enhancedDockerManager) Stop(ctx context.Context, serviceName string) error {
+	if err := e.original.Stop(ctx, serviceName); err != nil {
+		return e.mockManager.Stop(ctx, serviceName)
 	}
 	return nil
 }
 
-func (e *enhancedDockerManager) Restart(serviceName string) error {
-	if err := e.original.Restart(serviceName); err != nil {
-		return e.mockManager.Restart(serviceName)
+func (e *enhancedDockerManager) Restart(ctx context.Context, serviceName string) error {
+	if err := e.original.Restart(ctx, serviceName); err != nil {
+		return e.mockManager.Restart(ctx, serviceName)
 	}
 	return nil
 }
 
-func (e *enhancedDockerManager) Enable(serviceName string) error {
-	if err := e.original.Enable(serviceName); err != nil {
-		return e.mockManager.Enable(serviceName)
+func (e *enhancedDockerManager) Enable(ctx context.Context, serviceName string) error {
+	if err := e.original.Enable(ctx, serviceName); err != nil {
+		return e.mockManager.Enable(ctx, serviceName)
 	}
 	return nil
 }
 
-func (e *enhancedDockerManager) Disable(serviceName string) error {
-	if err := e.original.Disable(serviceName); err != nil {
-		return e.mockManager.Disable(serviceName)
+func (e *enhancedDockerManager) Disable(ctx context.Context, serviceName string) error {
+	if err := e.original.Disable(ctx, serviceName); err != nil {
+		return e.mockManager.Disable(ctx, serviceName)
 	}
 	return nil
 }
 
-func (e *enhancedDockerManager) GetStatus(serviceName string) (types.ServiceInfo, error) {
+func (e *enhancedDockerManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
 	// 先尝试真实管理器，失败则尝试mock
-	if info, err := e.original.GetStatus(serviceName); err == nil {
+	if info, err := e.original.GetStatus(ctx, serviceName); err == nil {
 		return info, nil
 	}
-	return e.mockManager.GetStatus(serviceName)
+	return e.mockManager.GetStatus(ctx, serviceName)
 }
 
-func (e *enhancedDockerManager) ListServices() ([]types.ServiceInfo, error) {
+func (e *enhancedDockerManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
 	// 获取真实服务
-	realServices, _ := e.original.ListServices()
+	realServices, _ := e.original.ListServices(ctx)
 	
 	// 如果有真实服务，返回真实服务
 	if len(realServices) > 0 {
@@ -82,51 +91,22 @@ func (e *enhancedDockerManager) ListServices() ([]types.ServiceInfo, error) {
 	}
 	
 	// 否则返回mock数据
-	return e.mockManager.ListServices()
+	return e.mockManager.ListServices(ctx)
 }
 
 func NewHTTPServer(cfg *config.Config, logger *logrus.Logger) *HTTPServer {
 	server := &HTTPServer{
-		managers: make(map[types.ServiceType]types.ServiceManager),
+		managers: managers.DetectManagers(cfg, logger),
+		health:   managers.NewHealthMonitor(cfg.Health, logger),
 		config:   cfg,
 		logger:   logger,
 	}
 
-	// Initialize available service managers
-	if managers.IsSystemdAvailable() {
-		server.managers[types.ServiceTypeSystemd] = managers.NewSystemdManager()
-		logger.Info("Systemd manager initialized")
-	} else {
-		logger.Debug("Systemd not available on this system")
-	}
-
-	if managers.IsSysVAvailable() {
-		server.managers[types.ServiceTypeSysV] = managers.NewSysVManager()
-		logger.Info("SysV manager initialized")
-	} else {
-		logger.Debug("SysV not available on this system")
-	}
-
-	if managers.IsDockerAvailable() {
-		server.managers[types.ServiceTypeDocker] = managers.NewDockerManager()
-		logger.Info("Docker manager initialized")
-	} else {
-		logger.Debug("Docker not available on this system")
-	}
-
-	// 为测试目的，始终添加Mock管理器（除非对应的真实管理器存在）
-	if _, hasSystemd := server.managers[types.ServiceTypeSystemd]; !hasSystemd {
-		server.managers[types.ServiceTypeSystemd] = managers.NewMockManager(types.ServiceTypeSystemd)
-		logger.Info("Mock Systemd manager initialized for testing")
-	}
-	if _, hasSysV := server.managers[types.ServiceTypeSysV]; !hasSysV {
-		server.managers[types.ServiceTypeSysV] = managers.NewMockManager(types.ServiceTypeSysV)
-		logger.Info("Mock SysV manager initialized for testing")
-	}
+	addMockManagers(server.managers, logger)
 	// 对于Docker，我们保持真实的管理器但增强它以返回测试数据
 	if dockerManager, hasDocker := server.managers[types.ServiceTypeDocker]; hasDocker {
 		// 如果Docker可用但没有容器，添加一些测试数据到现有管理器
-		services, _ := dockerManager.ListServices()
+		services, _ := dockerManager.ListServices(context.Background())
 		if len(services) == 0 {
 			// 包装Docker管理器以添加测试数据
 			server.managers[types.ServiceTypeDocker] = &enhancedDockerManager{
@@ -137,9 +117,42 @@ func NewHTTPServer(cfg *config.Config, logger *logrus.Logger) *HTTPServer {
 		}
 	}
 
+	server.watchdog = managers.NewWatchdog(cfg, server.managers, server.health, logger)
+
 	return server
 }
 
+// realInitManagers are the init systems whose presence means this is a real
+// host rather than a test environment.
+var realInitManagers = []types.ServiceType{
+	types.ServiceTypeSystemd,
+	types.ServiceTypeOpenRC,
+	types.ServiceTypeSysV,
+	types.ServiceTypeRunit,
+	types.ServiceTypeS6,
+}
+
+// addMockManagers adds the mock managers used for testing: systemd unless
+// systemd was detected, and SysV only when no real init system was, so an
+// OpenRC or runit host does not list fake SysV services.
+func addMockManagers(detected map[types.ServiceType]types.ServiceManager, logger *logrus.Logger) {
+	// 为测试目的，始终添加Mock管理器（除非对应的真实管理器存在）
+	hasInit := false
+	for _, serviceType := range realInitManagers {
+		if _, exists := detected[serviceType]; exists {
+			hasInit = true
+		}
+	}
+	if _, hasSystemd := detected[types.ServiceTypeSystemd]; !hasSystemd {
+		detected[types.ServiceTypeSystemd] = managers.NewMockManager(types.ServiceTypeSystemd)
+		logger.Info("Mock Systemd manager initialized for testing")
+	}
+	if !hasInit {
+		detected[types.ServiceTypeSysV] = managers.NewMockManager(types.ServiceTypeSysV)
+		logger.Info("Mock SysV manager initialized for testing")
+	}
+}
+
 func (s *HTTPServer) SetupRoutes() *mux.Router {
 	router := mux.NewRouter()
 
@@ -149,21 +162,63 @@ func (s *HTTPServer) SetupRoutes() *mux.Router {
 	// Service management endpoints
 	router.HandleFunc("/services", s.handleListServices).Methods("GET", "OPTIONS")
 	router.HandleFunc("/services/{name}/status", s.handleGetStatus).Methods("GET", "OPTIONS")
+	router.HandleFunc("/services/{name}/logs", s.handleServiceLogs).Methods("GET", "OPTIONS")
+	router.HandleFunc("/services/{name}/stats", s.handleServiceStats).Methods("GET", "OPTIONS")
+	router.HandleFunc("/services/{name}/processes", s.handleServiceProcesses).Methods("GET", "OPTIONS")
+	router.HandleFunc("/services/{name}/health", s.handleServiceHealth).Methods("GET", "OPTIONS")
 	router.HandleFunc("/services/{name}/start", s.handleStartService).Methods("POST", "OPTIONS")
 	router.HandleFunc("/services/{name}/stop", s.handleStopService).Methods("POST", "OPTIONS")
 	router.HandleFunc("/services/{name}/restart", s.handleRestartService).Methods("POST", "OPTIONS")
 	router.HandleFunc("/services/{name}/enable", s.handleEnableService).Methods("POST", "OPTIONS")
 	router.HandleFunc("/services/{name}/disable", s.handleDisableService).Methods("POST", "OPTIONS")
+	router.HandleFunc("/services/{name}/{action:"+serviceActionPattern()+"}", s.handleExtendedAction).Methods("POST", "OPTIONS")
 
 	// Generic service action endpoint
 	router.HandleFunc("/services/action", s.handleServiceAction).Methods("POST", "OPTIONS")
 
+	// Find the service that owns a process, port or file
+	router.HandleFunc("/lookup", s.handleLookupOwner).Methods("GET", "OPTIONS")
+
+	// Health probe results of all configured checks
+	router.HandleFunc("/healthchecks", s.handleListHealthChecks).Methods("GET", "OPTIONS")
+
+	// Watched services and the restarts and escalations the watchdog made
+	router.HandleFunc("/watchdog", s.handleWatchdog).Methods("GET", "OPTIONS")
+
 	// Docker-specific endpoints
 	router.HandleFunc("/docker/{name}/logs", s.handleDockerLogs).Methods("GET", "OPTIONS")
 	router.HandleFunc("/docker/{name}/stats", s.handleDockerStats).Methods("GET", "OPTIONS")
 	router.HandleFunc("/docker/{name}/remove", s.handleDockerRemove).Methods("DELETE", "OPTIONS")
 	router.HandleFunc("/docker/create", s.handleDockerCreate).Methods("POST", "OPTIONS")
 
+	// Podman-specific endpoints; {name} is a container or pod:<pod name>
+	router.HandleFunc("/podman/{name}/logs", s.handlePodmanLogs).Methods("GET", "OPTIONS")
+	router.HandleFunc("/podman/{name}/stats", s.handlePodmanStats).Methods("GET", "OPTIONS")
+
+	// Supervisord-specific endpoints; {name} is a group or group:process
+	router.HandleFunc("/supervisor/{name}/logs", s.handleSupervisorLogs).Methods("GET", "OPTIONS")
+
+	// Native service endpoints
+	router.HandleFunc("/native/{name}/logs", s.handleNativeLogs).Methods("GET", "OPTIONS")
+
+	// Plugin endpoints; {plugin} is the plugin's service type
+	router.HandleFunc("/plugins/{plugin}/{name}/logs", s.handlePluginLogs).Methods("GET", "OPTIONS")
+
+	// Systemd timer endpoints
+	router.HandleFunc("/timers", s.handleListTimers).Methods("GET", "OPTIONS")
+	router.HandleFunc("/timers/{name}", s.handleGetTimer).Methods("GET", "OPTIONS")
+	router.HandleFunc("/timers/{name}/{action:start|stop|enable|disable|trigger}", s.handleTimerAction).Methods("POST", "OPTIONS")
+
+	// Systemd unit file endpoints
+	router.HandleFunc("/units/{name}", s.handleGetUnitFile).Methods("GET", "OPTIONS")
+	router.HandleFunc("/units/{name}", s.handleWriteUnitFile).Methods("PUT")
+	router.HandleFunc("/units/{name}", s.handleRemoveUnitFile).Methods("DELETE")
+	router.HandleFunc("/units/{name}/cat", s.handleCatUnit).Methods("GET", "OPTIONS")
+	router.HandleFunc("/units/{name}/dropins", s.handleListDropIns).Methods("GET", "OPTIONS")
+	router.HandleFunc("/units/{name}/dropins/{dropin}", s.handleGetDropIn).Methods("GET", "OPTIONS")
+	router.HandleFunc("/units/{name}/dropins/{dropin}", s.handleWriteDropIn).Methods("PUT")
+	router.HandleFunc("/units/{name}/dropins/{dropin}", s.handleRemoveDropIn).Methods("DELETE")
+
 	// Health check endpoint
 	router.HandleFunc("/health", s.handleHealth).Methods("GET", "OPTIONS")
 
@@ -177,10 +232,35 @@ func (s *HTTPServer) handleListServices(w http.ResponseWriter, r *http.Request)
 	serviceType := r.URL.Query().Get("type")
 	var allServices []types.ServiceInfo
 
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "list")
+	defer cancel()
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, r.URL.Query().Get("scope"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx, serviceType, err = managers.ApplyUnitType(ctx, serviceType, r.URL.Query().Get("unit_type"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if value := r.URL.Query().Get("installed"); value != "" {
+		installed, err := strconv.ParseBool(value)
+		if err != nil {
+			s.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid installed value: %s", value))
+			return
+		}
+		if ctx, serviceType, err = managers.ApplyInstalledUnits(ctx, serviceType, installed); err != nil {
+			s.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	if serviceType != "" {
 		// List services for specific type
 		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
-			services, err := manager.ListServices()
+			services, err := manager.ListServices(ctx)
 			if err != nil {
 				s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list %s services: %v", serviceType, err))
 				return
@@ -193,7 +273,7 @@ func (s *HTTPServer) handleListServices(w http.ResponseWriter, r *http.Request)
 	} else {
 		// List all services from all managers
 		for _, manager := range s.managers {
-			services, err := manager.ListServices()
+			services, err := manager.ListServices(ctx)
 			if err != nil {
 				s.logger.Warnf("Failed to list services from manager: %v", err)
 				continue
@@ -202,6 +282,8 @@ func (s *HTTPServer) handleListServices(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	s.health.AnnotateAll(allServices)
+
 	response := types.ServiceListResponse{
 		Success:  true,
 		Message:  "Services listed successfully",
@@ -216,17 +298,27 @@ func (s *HTTPServer) handleGetStatus(w http.ResponseWriter, r *http.Request) {
 	serviceName := vars["name"]
 	serviceType := r.URL.Query().Get("type")
 
-	manager, err := s.getServiceManager(serviceName, serviceType)
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "status")
+	defer cancel()
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, r.URL.Query().Get("scope"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
 	if err != nil {
 		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	info, err := manager.GetStatus(serviceName)
+	info, err := manager.GetStatus(ctx, serviceName)
 	if err != nil {
 		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get service status: %v", err))
 		return
 	}
+	s.health.Annotate(&info)
 
 	response := types.ServiceResponse{
 		Success: true,
@@ -237,162 +329,178 @@ func (s *HTTPServer) handleGetStatus(w http.ResponseWriter, r *http.Request) {
 	s.sendJSON(w, http.StatusOK, response)
 }
 
-func (s *HTTPServer) handleStartService(w http.ResponseWriter, r *http.Request) {
-	s.handleServiceOperation(w, r, "start")
-}
-
-func (s *HTTPServer) handleStopService(w http.ResponseWriter, r *http.Request) {
-	s.handleServiceOperation(w, r, "stop")
-}
-
-func (s *HTTPServer) handleRestartService(w http.ResponseWriter, r *http.Request) {
-	s.handleServiceOperation(w, r, "restart")
-}
-
-func (s *HTTPServer) handleEnableService(w http.ResponseWriter, r *http.Request) {
-	s.handleServiceOperation(w, r, "enable")
-}
-
-func (s *HTTPServer) handleDisableService(w http.ResponseWriter, r *http.Request) {
-	s.handleServiceOperation(w, r, "disable")
-}
-
-func (s *HTTPServer) handleServiceOperation(w http.ResponseWriter, r *http.Request, operation string) {
+// handleServiceLogs serves any service's log, filtered by the since, until,
+// priority, grep and lines query parameters.
+func (s *HTTPServer) handleServiceLogs(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	serviceName := vars["name"]
 	serviceType := r.URL.Query().Get("type")
 
-	manager, err := s.getServiceManager(serviceName, serviceType)
+	query, err := logQueryFromRequest(r)
 	if err != nil {
 		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	var operationErr error
-	switch operation {
-	case "start":
-		operationErr = manager.Start(serviceName)
-	case "stop":
-		operationErr = manager.Stop(serviceName)
-	case "restart":
-		operationErr = manager.Restart(serviceName)
-	case "enable":
-		operationErr = manager.Enable(serviceName)
-	case "disable":
-		operationErr = manager.Disable(serviceName)
-	default:
-		s.sendError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported operation: %s", operation))
+	if follow, _ := strconv.ParseBool(r.URL.Query().Get("follow")); follow {
+		s.followServiceLogs(w, r, serviceName, serviceType, query)
 		return
 	}
 
-	if operationErr != nil {
-		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to %s service: %v", operation, operationErr))
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "logs")
+	defer cancel()
+
+	ctx, serviceType, err = managers.ApplyScope(ctx, serviceType, r.URL.Query().Get("scope"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Get updated status
-	info, _ := manager.GetStatus(serviceName)
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	response := types.ServiceResponse{
-		Success: true,
-		Message: fmt.Sprintf("Service %s %sed successfully", serviceName, operation),
-		Service: info,
+	entries, err := managers.ReadServiceLogs(ctx, manager, serviceName, query)
+	if err != nil {
+		s.sendError(w, actionErrorStatus(err), fmt.Sprintf("Failed to get logs: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Retrieved %d log entries", len(entries)),
+		"service": serviceName,
+		"entries": entries,
 	}
 
 	s.sendJSON(w, http.StatusOK, response)
 }
 
-func (s *HTTPServer) handleServiceAction(w http.ResponseWriter, r *http.Request) {
-	var req types.ServiceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.sendError(w, http.StatusBadRequest, "Invalid request body")
+// followServiceLogs streams a service's log as server-sent events: a "log"
+// event per entry until the client disconnects, and an "end" event if the
+// log source stops first. Comment lines keep idle connections open.
+func (s *HTTPServer) followServiceLogs(w http.ResponseWriter, r *http.Request, serviceName, serviceType string, query managers.LogQuery) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.sendError(w, http.StatusInternalServerError, "Streaming unsupported")
+		return
+	}
+	if !query.Until.IsZero() {
+		s.sendError(w, http.StatusBadRequest, "until cannot be used when following logs")
 		return
 	}
 
-	manager, err := s.getServiceManager(req.Name, string(req.Type))
+	ctx, serviceType, err := managers.ApplyScope(r.Context(), serviceType, r.URL.Query().Get("scope"))
 	if err != nil {
 		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	var operationErr error
-	switch strings.ToLower(req.Action) {
-	case "start":
-		operationErr = manager.Start(req.Name)
-	case "stop":
-		operationErr = manager.Stop(req.Name)
-	case "restart":
-		operationErr = manager.Restart(req.Name)
-	case "enable":
-		operationErr = manager.Enable(req.Name)
-	case "disable":
-		operationErr = manager.Disable(req.Name)
-	default:
-		s.sendError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported action: %s", req.Action))
+	lookupCtx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	manager, err := s.getServiceManager(lookupCtx, serviceName, serviceType)
+	cancel()
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-
-	if operationErr != nil {
-		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to %s service: %v", req.Action, operationErr))
+	if err := managers.CheckFollowLogs(manager, query); err != nil {
+		s.sendError(w, actionErrorStatus(err), fmt.Sprintf("Failed to follow logs: %v", err))
 		return
 	}
 
-	info, _ := manager.GetStatus(req.Name)
-
-	response := types.ServiceResponse{
-		Success: true,
-		Message: fmt.Sprintf("Service %s %sed successfully", req.Name, req.Action),
-		Service: info,
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var mu sync.Mutex
+	write := func(event string, data interface{}) {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			s.logger.Errorf("Failed to marshal %s event: %v", event, err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, jsonData)
+		flusher.Flush()
 	}
 
-	s.sendJSON(w, http.StatusOK, response)
-}
-
-func (s *HTTPServer) handleDockerLogs(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	containerName := vars["name"]
+	ctx, stop := context.WithCancel(ctx)
+	keepalive := make(chan struct{})
+	go func() {
+		defer close(keepalive)
+		ticker := time.NewTicker(logKeepaliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				mu.Lock()
+				fmt.Fprint(w, ": keepalive\n\n")
+				flusher.Flush()
+				mu.Unlock()
+			}
+		}
+	}()
 
-	dockerManager, exists := s.managers[types.ServiceTypeDocker].(*managers.DockerManager)
-	if !exists {
-		s.sendError(w, http.StatusServiceUnavailable, "Docker manager not available")
-		return
+	s.logger.Infof("Following logs of service %s", serviceName)
+	err = managers.FollowServiceLogs(ctx, manager, serviceName, query, func(entry types.LogEntry) {
+		write("log", entry)
+	})
+	if ctx.Err() == nil {
+		end := map[string]interface{}{"service": serviceName}
+		if err != nil {
+			end["error"] = err.Error()
+		}
+		write("end", end)
 	}
+	stop()
+	<-keepalive
+	s.logger.Infof("Stopped following logs of service %s", serviceName)
+}
 
-	lines := 100 // default
-	if linesParam := r.URL.Query().Get("lines"); linesParam != "" {
-		if parsedLines, err := strconv.Atoi(linesParam); err == nil {
-			lines = parsedLines
+// logQueryFromRequest reads the log filters from the query string.
+func logQueryFromRequest(r *http.Request) (managers.LogQuery, error) {
+	params := r.URL.Query()
+	lines := 0
+	if linesParam := params.Get("lines"); linesParam != "" {
+		parsedLines, err := strconv.Atoi(linesParam)
+		if err != nil {
+			return managers.LogQuery{}, fmt.Errorf("invalid lines: %v", err)
 		}
+		lines = parsedLines
 	}
+	return managers.ParseLogQuery(params.Get("since"), params.Get("until"), params.Get("priority"), params.Get("grep"), lines)
+}
+
+func (s *HTTPServer) handleServiceStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceName := vars["name"]
+	serviceType := r.URL.Query().Get("type")
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "stats")
+	defer cancel()
 
-	logs, err := dockerManager.GetLogs(containerName, lines)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, r.URL.Query().Get("scope"))
 	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get logs: %v", err))
+		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	response := map[string]interface{}{
-		"success": true,
-		"message": "Logs retrieved successfully",
-		"logs":    logs,
-	}
-
-	s.sendJSON(w, http.StatusOK, response)
-}
-
-func (s *HTTPServer) handleDockerStats(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	containerName := vars["name"]
-
-	dockerManager, exists := s.managers[types.ServiceTypeDocker].(*managers.DockerManager)
-	if !exists {
-		s.sendError(w, http.StatusServiceUnavailable, "Docker manager not available")
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	stats, err := dockerManager.GetStats(containerName)
+	stats, err := managers.ReadServiceStats(ctx, manager, serviceName)
 	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get stats: %v", err))
+		s.sendError(w, actionErrorStatus(err), fmt.Sprintf("Failed to get stats: %v", err))
 		return
 	}
 
@@ -405,126 +513,1035 @@ func (s *HTTPServer) handleDockerStats(w http.ResponseWriter, r *http.Request) {
 	s.sendJSON(w, http.StatusOK, response)
 }
 
-func (s *HTTPServer) handleDockerRemove(w http.ResponseWriter, r *http.Request) {
+func (s *HTTPServer) handleServiceProcesses(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	containerName := vars["name"]
-	force := r.URL.Query().Get("force") == "true"
+	serviceName := vars["name"]
+	serviceType := r.URL.Query().Get("type")
 
-	dockerManager, exists := s.managers[types.ServiceTypeDocker].(*managers.DockerManager)
-	if !exists {
-		s.sendError(w, http.StatusServiceUnavailable, "Docker manager not available")
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "processes")
+	defer cancel()
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, r.URL.Query().Get("scope"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err := dockerManager.RemoveContainer(containerName, force)
+	processes, err := managers.ReadServiceProcesses(ctx, manager, serviceName)
 	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to remove container: %v", err))
+		s.sendError(w, actionErrorStatus(err), fmt.Sprintf("Failed to get processes: %v", err))
 		return
 	}
 
 	response := map[string]interface{}{
-		"success": true,
-		"message": "Container removed successfully",
+		"success":   true,
+		"message":   "Processes retrieved successfully",
+		"processes": processes,
 	}
 
 	s.sendJSON(w, http.StatusOK, response)
 }
 
-func (s *HTTPServer) handleDockerCreate(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		ImageName     string   `json:"image_name"`
-		ContainerName string   `json:"container_name"`
-		Options       []string `json:"options"`
-	}
+// handleServiceHealth returns the health of a service from its configured
+// probes and, for containers, the engine's own health check.
+func (s *HTTPServer) handleServiceHealth(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceName := vars["name"]
+	serviceType := r.URL.Query().Get("type")
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.sendError(w, http.StatusBadRequest, "Invalid request body")
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "status")
+	defer cancel()
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, r.URL.Query().Get("scope"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	dockerManager, exists := s.managers[types.ServiceTypeDocker].(*managers.DockerManager)
-	if !exists {
-		s.sendError(w, http.StatusServiceUnavailable, "Docker manager not available")
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err := dockerManager.CreateContainer(req.ImageName, req.ContainerName, req.Options)
+	info, err := manager.GetStatus(ctx, serviceName)
 	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create container: %v", err))
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get service status: %v", err))
+		return
+	}
+	s.health.Annotate(&info)
+	if info.Health == nil {
+		s.sendError(w, http.StatusNotFound, fmt.Sprintf("No health checks for service %s", serviceName))
 		return
 	}
 
 	response := map[string]interface{}{
 		"success": true,
-		"message": "Container created successfully",
+		"message": "Health retrieved successfully",
+		"health":  info.Health,
 	}
 
 	s.sendJSON(w, http.StatusOK, response)
 }
 
-func (s *HTTPServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+// handleListHealthChecks returns the state of every configured probe.
+func (s *HTTPServer) handleListHealthChecks(w http.ResponseWriter, r *http.Request) {
+	results := s.health.Results()
+	if results == nil {
+		results = []types.ProbeResult{}
+	}
+
 	response := map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": utils.GetCurrentTimestamp(),
-		"managers":  s.getAvailableManagers(),
+		"success": true,
+		"message": "Health checks listed successfully",
+		"probes":  results,
 	}
 
 	s.sendJSON(w, http.StatusOK, response)
 }
 
-func (s *HTTPServer) handleInfo(w http.ResponseWriter, r *http.Request) {
+// handleWatchdog returns the state of the watched services and the actions
+// the watchdog took, newest first, optionally for one service and limited
+// to the last limit actions.
+func (s *HTTPServer) handleWatchdog(w http.ResponseWriter, r *http.Request) {
+	serviceName := r.URL.Query().Get("service")
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			s.sendError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit %q", value))
+			return
+		}
+	}
+
+	services := s.watchdog.States()
+	if services == nil {
+		services = []types.WatchdogState{}
+	}
+	actions := s.watchdog.Actions(serviceName, limit)
+	if actions == nil {
+		actions = []types.WatchdogAction{}
+	}
+
 	response := map[string]interface{}{
-		"name":     "MCP Service Manager",
-		"version":  "1.0.0",
-		"managers": s.getAvailableManagers(),
-		"config":   s.config,
+		"success":  true,
+		"message":  "Watchdog state retrieved successfully",
+		"services": services,
+		"actions":  actions,
 	}
 
 	s.sendJSON(w, http.StatusOK, response)
 }
 
-func (s *HTTPServer) getServiceManager(serviceName, serviceType string) (types.ServiceManager, error) {
-	if serviceType != "" {
-		// Use specified service type
-		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
-			return manager, nil
+// handleLookupOwner finds the service owning the process, listening port or
+// file given by the pid, port (with an optional protocol) or path query
+// parameter.
+func (s *HTTPServer) handleLookupOwner(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	var pid, port int
+	var err error
+	if value := params.Get("pid"); value != "" {
+		if pid, err = strconv.Atoi(value); err != nil {
+			s.sendError(w, http.StatusBadRequest, fmt.Sprintf("invalid pid %q", value))
+			return
 		}
-		return nil, fmt.Errorf("unsupported service type: %s", serviceType)
 	}
-
-	// Auto-detect service type
-	for _, manager := range s.managers {
-		if _, err := manager.GetStatus(serviceName); err == nil {
-			return manager, nil
+	if value := params.Get("port"); value != "" {
+		if port, err = strconv.Atoi(value); err != nil {
+			s.sendError(w, http.StatusBadRequest, fmt.Sprintf("invalid port %q", value))
+			return
 		}
 	}
+	query, err := managers.ParseOwnerQuery(pid, port, params.Get("protocol"), params.Get("path"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	return nil, fmt.Errorf("service %s not found in any manager", serviceName)
-}
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "status")
+	defer cancel()
 
-func (s *HTTPServer) getAvailableManagers() []string {
-	var managerList []string
-	for serviceType := range s.managers {
-		managerList = append(managerList, string(serviceType))
+	owner, err := managers.LocateOwner(ctx, query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if managers.IsOwnerNotFound(err) {
+			status = http.StatusNotFound
+		}
+		s.sendError(w, status, err.Error())
+		return
 	}
-	return managerList
-}
 
-func (s *HTTPServer) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(data)
-}
+	ctx, serviceType, err := managers.ApplyScope(ctx, string(owner.Type), owner.Scope)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 
-func (s *HTTPServer) sendError(w http.ResponseWriter, statusCode int, message string) {
-	s.logger.Error(message)
-	response := map[string]interface{}{
-		"success": false,
+	manager, err := s.getServiceManager(ctx, owner.Name, serviceType)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Service %s owns process %d: %v", owner.Name, owner.PID, err))
+		return
+	}
+
+	info, err := manager.GetStatus(ctx, owner.Name)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get status of %s: %v", owner.Name, err))
+		return
+	}
+	s.health.Annotate(&info)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Process %d belongs to %s service %s", owner.PID, owner.Type, info.Name),
+		"owner":   owner,
+		"service": info,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleStartService(w http.ResponseWriter, r *http.Request) {
+	s.handleServiceOperation(w, r, "start")
+}
+
+func (s *HTTPServer) handleStopService(w http.ResponseWriter, r *http.Request) {
+	s.handleServiceOperation(w, r, "stop")
+}
+
+func (s *HTTPServer) handleRestartService(w http.ResponseWriter, r *http.Request) {
+	s.handleServiceOperation(w, r, "restart")
+}
+
+func (s *HTTPServer) handleEnableService(w http.ResponseWriter, r *http.Request) {
+	s.handleServiceOperation(w, r, "enable")
+}
+
+func (s *HTTPServer) handleDisableService(w http.ResponseWriter, r *http.Request) {
+	s.handleServiceOperation(w, r, "disable")
+}
+
+// handleExtendedAction serves the actions of managers.ServiceActions.
+func (s *HTTPServer) handleExtendedAction(w http.ResponseWriter, r *http.Request) {
+	s.handleServiceOperation(w, r, mux.Vars(r)["action"])
+}
+
+// serviceActionPattern matches the names of managers.ServiceActions in a
+// route.
+func serviceActionPattern() string {
+	names := make([]string, 0, len(managers.ServiceActions))
+	for _, action := range managers.ServiceActions {
+		names = append(names, action.Name)
+	}
+	return strings.Join(names, "|")
+}
+
+func (s *HTTPServer) handleServiceOperation(w http.ResponseWriter, r *http.Request, operation string) {
+	vars := mux.Vars(r)
+	serviceName := vars["name"]
+	serviceType := r.URL.Query().Get("type")
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), operation)
+	defer cancel()
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, r.URL.Query().Get("scope"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !managers.IsServiceAction(operation) {
+		s.sendError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported operation: %s", operation))
+		return
+	}
+	signal, err := managers.ParseSignal(r.URL.Query().Get("signal"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := managers.PerformAction(ctx, manager, serviceName, operation, signal); err != nil {
+		s.sendError(w, actionErrorStatus(err), fmt.Sprintf("Failed to %s service: %v", operation, err))
+		return
+	}
+
+	// Get updated status
+	info, _ := manager.GetStatus(ctx, serviceName)
+
+	response := types.ServiceResponse{
+		Success: true,
+		Message: managers.ActionMessage(serviceName, operation),
+		Service: info,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleServiceAction(w http.ResponseWriter, r *http.Request) {
+	var req types.ServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), strings.ToLower(req.Action))
+	defer cancel()
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, string(req.Type), req.Scope)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	manager, err := s.getServiceManager(ctx, req.Name, serviceType)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	action := strings.ToLower(req.Action)
+	if !managers.IsServiceAction(action) {
+		s.sendError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported action: %s", req.Action))
+		return
+	}
+	signal, err := managers.ParseSignal(req.Signal)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := managers.PerformAction(ctx, manager, req.Name, action, signal); err != nil {
+		s.sendError(w, actionErrorStatus(err), fmt.Sprintf("Failed to %s service: %v", req.Action, err))
+		return
+	}
+
+	info, _ := manager.GetStatus(ctx, req.Name)
+
+	response := types.ServiceResponse{
+		Success: true,
+		Message: managers.ActionMessage(req.Name, action),
+		Service: info,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleDockerLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerName := vars["name"]
+
+	dockerManager, exists := s.managers[types.ServiceTypeDocker].(managers.DockerContainerManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Docker manager not available")
+		return
+	}
+
+	lines := 100 // default
+	if linesParam := r.URL.Query().Get("lines"); linesParam != "" {
+		if parsedLines, err := strconv.Atoi(linesParam); err == nil {
+			lines = parsedLines
+		}
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "logs")
+	defer cancel()
+
+	logs, err := dockerManager.GetLogs(ctx, containerName, lines)
+	if err != nil {
+		s.sendError(w, dockerErrorStatus(err), fmt.Sprintf("Failed to get logs: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Logs retrieved successfully",
+		"logs":    logs,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleDockerStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerName := vars["name"]
+
+	dockerManager, exists := s.managers[types.ServiceTypeDocker].(managers.DockerContainerManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Docker manager not available")
+		return
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "stats")
+	defer cancel()
+
+	stats, err := dockerManager.GetStats(ctx, containerName)
+	if err != nil {
+		s.sendError(w, dockerErrorStatus(err), fmt.Sprintf("Failed to get stats: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Stats retrieved successfully",
+		"stats":   stats,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleDockerRemove(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerName := vars["name"]
+	force := r.URL.Query().Get("force") == "true"
+
+	dockerManager, exists := s.managers[types.ServiceTypeDocker].(managers.DockerContainerManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Docker manager not available")
+		return
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "remove")
+	defer cancel()
+
+	err := dockerManager.RemoveContainer(ctx, containerName, force)
+	if err != nil {
+		s.sendError(w, dockerErrorStatus(err), fmt.Sprintf("Failed to remove container: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Container removed successfully",
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleDockerCreate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ImageName     string   `json:"image_name"`
+		ContainerName string   `json:"container_name"`
+		Options       []string `json:"options"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	dockerManager, exists := s.managers[types.ServiceTypeDocker].(managers.DockerContainerManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Docker manager not available")
+		return
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "create")
+	defer cancel()
+
+	err := dockerManager.CreateContainer(ctx, req.ImageName, req.ContainerName, req.Options)
+	if err != nil {
+		s.sendError(w, dockerErrorStatus(err), fmt.Sprintf("Failed to create container: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Container created successfully",
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handlePodmanLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceName := vars["name"]
+
+	podmanManager, exists := s.managers[types.ServiceTypePodman].(*managers.PodmanManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Podman manager not available")
+		return
+	}
+
+	lines := 100 // default
+	if linesParam := r.URL.Query().Get("lines"); linesParam != "" {
+		if parsedLines, err := strconv.Atoi(linesParam); err == nil {
+			lines = parsedLines
+		}
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "logs")
+	defer cancel()
+
+	logs, err := podmanManager.GetLogs(ctx, serviceName, lines)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get logs: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Logs retrieved successfully",
+		"logs":    logs,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handlePodmanStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceName := vars["name"]
+
+	podmanManager, exists := s.managers[types.ServiceTypePodman].(*managers.PodmanManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Podman manager not available")
+		return
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "stats")
+	defer cancel()
+
+	stats, err := podmanManager.GetStats(ctx, serviceName)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get stats: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Stats retrieved successfully",
+		"stats":   stats,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+// logTailer is implemented by managers that keep per-stream log files.
+type logTailer interface {
+	TailLog(ctx context.Context, serviceName, stream string, lines int) (string, error)
+}
+
+func (s *HTTPServer) handleSupervisorLogs(w http.ResponseWriter, r *http.Request) {
+	supervisorManager, exists := s.managers[types.ServiceTypeSupervisor].(*managers.SupervisorManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Supervisor manager not available")
+		return
+	}
+	s.serveLogTail(w, r, supervisorManager)
+}
+
+func (s *HTTPServer) handleNativeLogs(w http.ResponseWriter, r *http.Request) {
+	nativeManager, exists := s.managers[types.ServiceTypeNative].(*managers.NativeManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Native manager not available")
+		return
+	}
+	s.serveLogTail(w, r, nativeManager)
+}
+
+func (s *HTTPServer) handlePluginLogs(w http.ResponseWriter, r *http.Request) {
+	plugin := mux.Vars(r)["plugin"]
+	pluginManager, exists := s.managers[types.ServiceType(plugin)].(*managers.PluginManager)
+	if !exists {
+		s.sendError(w, http.StatusNotFound, fmt.Sprintf("Plugin %s not available", plugin))
+		return
+	}
+	s.serveLogTail(w, r, pluginManager)
+}
+
+// serveLogTail answers ?lines=N&stream=stdout|stderr from a logTailer.
+func (s *HTTPServer) serveLogTail(w http.ResponseWriter, r *http.Request, tailer logTailer) {
+	vars := mux.Vars(r)
+	serviceName := vars["name"]
+
+	lines := 100 // default
+	if linesParam := r.URL.Query().Get("lines"); linesParam != "" {
+		if parsedLines, err := strconv.Atoi(linesParam); err == nil {
+			lines = parsedLines
+		}
+	}
+	stream := r.URL.Query().Get("stream")
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "logs")
+	defer cancel()
+
+	logs, err := tailer.TailLog(ctx, serviceName, stream, lines)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get logs: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Logs retrieved successfully",
+		"logs":    logs,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleListTimers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "list")
+	defer cancel()
+
+	ctx, timerManager, ok := s.timerManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	timers, err := timerManager.ListTimers(ctx)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list timers: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Timers listed successfully",
+		"timers":  timers,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleGetTimer(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "status")
+	defer cancel()
+
+	ctx, timerManager, ok := s.timerManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	timer, err := timerManager.GetTimer(ctx, mux.Vars(r)["name"])
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get timer: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Timer retrieved successfully",
+		"timer":   timer,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleTimerAction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	timerName := vars["name"]
+	action := vars["action"]
+
+	operation := action
+	if action == "trigger" {
+		operation = "start"
+	}
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), operation)
+	defer cancel()
+
+	ctx, timerManager, ok := s.timerManager(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	if err := managers.ManageTimer(ctx, timerManager, timerName, action); err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to %s timer: %v", action, err))
+		return
+	}
+
+	timer, err := timerManager.GetTimer(ctx, timerName)
+	if err != nil {
+		s.logger.Warnf("Failed to get timer status after %s: %v", action, err)
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Timer %s completed successfully", action),
+		"timer":   timer,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+// timerManager returns the systemd timer manager with the request's ?scope=
+// applied to ctx. It answers the request itself and returns false on error.
+func (s *HTTPServer) timerManager(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, managers.TimerManager, bool) {
+	timerManager, exists := s.managers[types.ServiceTypeSystemd].(managers.TimerManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Systemd timers not available")
+		return ctx, nil, false
+	}
+
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), r.URL.Query().Get("scope"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return ctx, nil, false
+	}
+	return ctx, timerManager, true
+}
+
+func (s *HTTPServer) handleGetUnitFile(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "status")
+	defer cancel()
+
+	ctx, unitFiles, ok := s.unitFileManager(ctx, w, r.URL.Query().Get("scope"))
+	if !ok {
+		return
+	}
+
+	unitFile, err := unitFiles.GetUnitFile(ctx, mux.Vars(r)["name"])
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, fmt.Sprintf("Failed to read unit file: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"message":   "Unit file retrieved successfully",
+		"unit_file": unitFile,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleWriteUnitFile(w http.ResponseWriter, r *http.Request) {
+	var req types.UnitFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid JSON request body")
+		return
+	}
+
+	content, err := managers.UnitFileContent(req)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "unit_file")
+	defer cancel()
+
+	scope := req.Scope
+	if scope == "" {
+		scope = r.URL.Query().Get("scope")
+	}
+	ctx, unitFiles, ok := s.unitFileManager(ctx, w, scope)
+	if !ok {
+		return
+	}
+
+	unitFile, err := unitFiles.WriteUnitFile(ctx, mux.Vars(r)["name"], content)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to write unit file: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"message":   "Unit file installed successfully",
+		"unit_file": unitFile,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleRemoveUnitFile(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "unit_file")
+	defer cancel()
+
+	ctx, unitFiles, ok := s.unitFileManager(ctx, w, r.URL.Query().Get("scope"))
+	if !ok {
+		return
+	}
+
+	unitFile, err := unitFiles.RemoveUnitFile(ctx, mux.Vars(r)["name"])
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to remove unit file: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"message":   "Unit file removed successfully",
+		"unit_file": unitFile,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+// unitFileManager returns the systemd unit file manager with scope applied
+// to ctx. It answers the request itself and returns false on error.
+func (s *HTTPServer) unitFileManager(ctx context.Context, w http.ResponseWriter, scope string) (context.Context, managers.UnitFileManager, bool) {
+	unitFiles, exists := s.managers[types.ServiceTypeSystemd].(managers.UnitFileManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Systemd unit files not available")
+		return ctx, nil, false
+	}
+
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return ctx, nil, false
+	}
+	return ctx, unitFiles, true
+}
+
+func (s *HTTPServer) handleCatUnit(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "status")
+	defer cancel()
+
+	ctx, dropIns, ok := s.dropInManager(ctx, w, r.URL.Query().Get("scope"))
+	if !ok {
+		return
+	}
+
+	content, err := dropIns.CatUnit(ctx, mux.Vars(r)["name"])
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to show unit configuration: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Unit configuration retrieved successfully",
+		"content": content,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleListDropIns(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "list")
+	defer cancel()
+
+	ctx, dropIns, ok := s.dropInManager(ctx, w, r.URL.Query().Get("scope"))
+	if !ok {
+		return
+	}
+
+	list, err := dropIns.ListDropIns(ctx, mux.Vars(r)["name"])
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list drop-ins: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Drop-ins listed successfully",
+		"dropins": list,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleGetDropIn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "status")
+	defer cancel()
+
+	ctx, dropIns, ok := s.dropInManager(ctx, w, r.URL.Query().Get("scope"))
+	if !ok {
+		return
+	}
+
+	dropIn, err := dropIns.GetDropIn(ctx, vars["name"], vars["dropin"])
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, fmt.Sprintf("Failed to read drop-in: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Drop-in retrieved successfully",
+		"dropin":  dropIn,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleWriteDropIn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req types.UnitFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid JSON request body")
+		return
+	}
+	if req.Spec != nil {
+		s.sendError(w, http.StatusBadRequest, "spec is only supported for unit files, drop-ins take content")
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		s.sendError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "unit_file")
+	defer cancel()
+
+	scope := req.Scope
+	if scope == "" {
+		scope = r.URL.Query().Get("scope")
+	}
+	ctx, dropIns, ok := s.dropInManager(ctx, w, scope)
+	if !ok {
+		return
+	}
+
+	dropIn, err := dropIns.WriteDropIn(ctx, vars["name"], vars["dropin"], req.Content)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to write drop-in: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Drop-in installed successfully",
+		"dropin":  dropIn,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleRemoveDropIn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(r.Context(), "unit_file")
+	defer cancel()
+
+	ctx, dropIns, ok := s.dropInManager(ctx, w, r.URL.Query().Get("scope"))
+	if !ok {
+		return
+	}
+
+	dropIn, err := dropIns.RemoveDropIn(ctx, vars["name"], vars["dropin"])
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to remove drop-in: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Drop-in removed successfully",
+		"dropin":  dropIn,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+// dropInManager returns the systemd drop-in manager with scope applied to
+// ctx. It answers the request itself and returns false on error.
+func (s *HTTPServer) dropInManager(ctx context.Context, w http.ResponseWriter, scope string) (context.Context, managers.DropInManager, bool) {
+	dropIns, exists := s.managers[types.ServiceTypeSystemd].(managers.DropInManager)
+	if !exists {
+		s.sendError(w, http.StatusServiceUnavailable, "Systemd drop-ins not available")
+		return ctx, nil, false
+	}
+
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, err.Error())
+		return ctx, nil, false
+	}
+	return ctx, dropIns, true
+}
+
+func (s *HTTPServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"status":    "healthy",
+		"timestamp": utils.GetCurrentTimestamp(),
+		"managers":  s.getAvailableManagers(),
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) handleInfo(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"name":     "MCP Service Manager",
+		"version":  "1.0.0",
+		"managers": s.getAvailableManagers(),
+		"config":   s.config,
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *HTTPServer) getServiceManager(ctx context.Context, serviceName, serviceType string) (types.ServiceManager, error) {
+	if serviceType != "" {
+		// Use specified service type
+		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
+			return manager, nil
+		}
+		return nil, fmt.Errorf("unsupported service type: %s", serviceType)
+	}
+
+	// Auto-detect service type
+	for _, manager := range s.managers {
+		if _, err := manager.GetStatus(ctx, serviceName); err == nil {
+			return manager, nil
+		}
+	}
+
+	return nil, fmt.Errorf("service %s not found in any manager", serviceName)
+}
+
+func (s *HTTPServer) getAvailableManagers() []string {
+	var managerList []string
+	for serviceType := range s.managers {
+		managerList = append(managerList, string(serviceType))
+	}
+	return managerList
+}
+
+func (s *HTTPServer) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+func (s *HTTPServer) sendError(w http.ResponseWriter, statusCode int, message string) {
+	s.logger.Error(message)
+	response := map[string]interface{}{
+		"success": false,
 		"message": message,
 	}
 	s.sendJSON(w, statusCode, response)
 }
 
+// actionErrorStatus maps actions the service's manager does not implement
+// to 501 and other failures to 500.
+func actionErrorStatus(err error) int {
+	if managers.IsUnsupportedAction(err) {
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// dockerErrorStatus passes Engine API 404/409 errors through to the client and
+// maps everything else to 500.
+func dockerErrorStatus(err error) int {
+	switch {
+	case managers.IsDockerNotFound(err):
+		return http.StatusNotFound
+	case managers.IsDockerConflict(err):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // corsMiddleware 添加CORS头
 func (s *HTTPServer) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -544,6 +1561,14 @@ func (s *HTTPServer) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// Close releases the service managers, stopping any processes supervised
+// by this server.
+func (s *HTTPServer) Close() error {
+	s.watchdog.Close()
+	s.health.Close()
+	return managers.CloseManagers(s.managers)
+}
+
 func (s *HTTPServer) Start() error {
 	router := s.SetupRoutes()
 	address := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
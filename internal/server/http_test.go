@@ -1,11 +1,15 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +17,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/internal/managers"
 	"nucc.com/mcp_srv_mgr/pkg/types"
 )
 
@@ -37,7 +42,7 @@ func (m *MockServiceManager) SetError(operation string, err error) {
 	m.errors[operation] = err
 }
 
-func (m *MockServiceManager) Start(serviceName string) error {
+func (m *MockServiceManager) Start(ctx context.Context, serviceName string) error {
 	if err, exists := m.errors["start"]; exists {
 		return err
 	}
@@ -48,7 +53,7 @@ func (m *MockServiceManager) Start(serviceName string) error {
 	return nil
 }
 
-func (m *MockServiceManager) Stop(serviceName string) error {
+func (m *MockServiceManager) Stop(ctx context.Context, serviceName string) error {
 	if err, exists := m.errors["stop"]; exists {
 		return err
 	}
@@ -59,7 +64,7 @@ func (m *MockServiceManager) Stop(serviceName string) error {
 	return nil
 }
 
-func (m *MockServiceManager) Restart(serviceName string) error {
+func (m *MockServiceManager) Restart(ctx context.Context, serviceName string) error {
 	if err, exists := m.errors["restart"]; exists {
 		return err
 	}
@@ -70,21 +75,28 @@ func (m *MockServiceManager) Restart(serviceName string) error {
 	return nil
 }
 
-func (m *MockServiceManager) Enable(serviceName string) error {
+func (m *MockServiceManager) Enable(ctx context.Context, serviceName string) error {
 	if err, exists := m.errors["enable"]; exists {
 		return err
 	}
 	return nil
 }
 
-func (m *MockServiceManager) Disable(serviceName string) error {
+func (m *MockServiceManager) Disable(ctx context.Context, serviceName string) error {
 	if err, exists := m.errors["disable"]; exists {
 		return err
 	}
 	return nil
 }
 
-func (m *MockServiceManager) GetStatus(serviceName string) (types.ServiceInfo, error) {
+func (m *MockServiceManager) Reload(ctx context.Context, serviceName string) error {
+	if err, exists := m.errors["reload"]; exists {
+		return err
+	}
+	return nil
+}
+
+func (m *MockServiceManager) GetStatus(ctx context.Context, serviceName string) (types.ServiceInfo, error) {
 	if err, exists := m.errors["get_status"]; exists {
 		return types.ServiceInfo{}, err
 	}
@@ -94,7 +106,7 @@ func (m *MockServiceManager) GetStatus(serviceName string) (types.ServiceInfo, e
 	return types.ServiceInfo{}, fmt.Errorf("service %s not found", serviceName)
 }
 
-func (m *MockServiceManager) ListServices() ([]types.ServiceInfo, error) {
+func (m *MockServiceManager) ListServices(ctx context.Context) ([]types.ServiceInfo, error) {
 	if err, exists := m.errors["list_services"]; exists {
 		return nil, err
 	}
@@ -177,6 +189,31 @@ func TestNewHTTPServer(t *testing.T) {
 	}
 }
 
+func TestAddMockManagers(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// OpenRC 主机不应出现模拟的 SysV 服务
+	detected := map[types.ServiceType]types.ServiceManager{types.ServiceTypeOpenRC: managers.NewOpenRCManager()}
+	addMockManagers(detected, logger)
+	if _, exists := detected[types.ServiceTypeSysV]; exists {
+		t.Error("Expected no mock SysV manager next to OpenRC")
+	}
+	if _, ok := detected[types.ServiceTypeOpenRC].(*managers.OpenRCManager); !ok {
+		t.Error("Expected the OpenRC manager to be kept")
+	}
+
+	// 没有任何初始化系统时添加模拟管理器
+	detected = map[types.ServiceType]types.ServiceManager{}
+	addMockManagers(detected, logger)
+	if _, ok := detected[types.ServiceTypeSysV].(*managers.MockManager); !ok {
+		t.Error("Expected a mock SysV manager without an init system")
+	}
+	if _, ok := detected[types.ServiceTypeSystemd].(*managers.MockManager); !ok {
+		t.Error("Expected a mock systemd manager without systemd")
+	}
+}
+
 func TestHTTPServer_SetupRoutes(t *testing.T) {
 	server := createTestServer()
 	router := server.SetupRoutes()
@@ -431,6 +468,530 @@ func TestHTTPServer_HandleUnsupportedServiceType(t *testing.T) {
 	}
 }
 
+func TestHTTPServer_HandleScope(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 作用域只适用于systemd服务
+	for _, url := range []string{
+		"/services?type=docker&scope=user",
+		"/services/nginx/status?scope=session",
+		"/services/nginx/start?type=sysv&scope=user:alice",
+	} {
+		method := "GET"
+		if strings.HasSuffix(strings.Split(url, "?")[0], "/start") {
+			method = "POST"
+		}
+		req := httptest.NewRequest(method, url, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, w.Code)
+		}
+	}
+
+	// 指定作用域但未指定类型时使用systemd
+	req := httptest.NewRequest("GET", "/services/nginx/status?scope=system", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestHTTPServer_HandleListServices_UnitType(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 单元类型只适用于systemd服务，且必须是已知类型
+	for _, url := range []string{
+		"/services?type=docker&unit_type=socket",
+		"/services?unit_type=timer",
+	} {
+		req := httptest.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/services?unit_type=socket,mount", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestHTTPServer_HandleListServices_Installed(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 已安装的单元文件只适用于systemd服务
+	for _, url := range []string{
+		"/services?type=docker&installed=true",
+		"/services?installed=maybe",
+	} {
+		req := httptest.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, w.Code)
+		}
+	}
+
+	for _, url := range []string{"/services?installed=true", "/services?type=systemd&installed=false"} {
+		req := httptest.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", url, w.Code)
+		}
+	}
+}
+
+func TestHTTPServer_HandleExtendedActions(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	req := httptest.NewRequest("POST", "/services/nginx/reload", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response types.ServiceResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Message != "Service nginx reloaded successfully" {
+		t.Errorf("Unexpected message: %s", response.Message)
+	}
+
+	// 模拟的管理器只支持 reload
+	for _, path := range []string{"/services/nginx/kill?signal=HUP", "/services/nginx/mask", "/services/nginx/pause"} {
+		req := httptest.NewRequest("POST", path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotImplemented {
+			t.Errorf("%s: expected status 501, got %d", path, w.Code)
+		}
+	}
+
+	req = httptest.NewRequest("POST", "/services/nginx/kill?signal=BOGUS", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid signal, got %d", w.Code)
+	}
+
+	bodyBytes, _ := json.Marshal(types.ServiceRequest{Name: "nginx", Type: types.ServiceTypeSystemd, Action: "reset-failed"})
+	req = httptest.NewRequest("POST", "/services/action", bytes.NewReader(bodyBytes))
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 for reset-failed, got %d", w.Code)
+	}
+
+	bodyBytes, _ = json.Marshal(types.ServiceRequest{Name: "nginx", Type: types.ServiceTypeSystemd, Action: "explode"})
+	req = httptest.NewRequest("POST", "/services/action", bytes.NewReader(bodyBytes))
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown action, got %d", w.Code)
+	}
+}
+
+func TestHTTPServer_HandleServiceLogs(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 模拟的管理器不提供日志
+	req := httptest.NewRequest("GET", "/services/nginx/logs?priority=err&since=1h", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501, got %d", w.Code)
+	}
+
+	for _, params := range []string{"priority=loud", "since=yesterday", "grep=(", "lines=many"} {
+		req := httptest.NewRequest("GET", "/services/nginx/logs?"+params, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", params, w.Code)
+		}
+	}
+}
+
+func TestHTTPServer_FollowServiceLogs(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 模拟的管理器不支持跟踪日志
+	for params, expected := range map[string]int{
+		"follow=true":                  http.StatusNotImplemented,
+		"follow=true&until=2099-01-01": http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest("GET", "/services/nginx/logs?"+params, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != expected {
+			t.Errorf("%s: expected status %d, got %d", params, expected, w.Code)
+		}
+	}
+
+	manager := newFollowingManager()
+	server.managers[types.ServiceTypeSystemd] = manager
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/services/nginx/logs?follow=1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Expected an event stream, got %s", resp.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(resp.Body)
+	event, _ := reader.ReadString('\n')
+	data, _ := reader.ReadString('\n')
+	if event != "event: log\n" || !strings.Contains(data, `"message":"ready"`) {
+		t.Errorf("Unexpected event: %q %q", event, data)
+	}
+
+	// 客户端断开后停止跟踪
+	cancel()
+	waitFor(t, manager.stopped, "disconnect")
+}
+
+// statsManager 在模拟管理器的基础上提供资源统计
+type statsManager struct {
+	*MockServiceManager
+}
+
+func (m statsManager) ServiceStats(ctx context.Context, serviceName string) (types.ServiceStats, error) {
+	return types.ServiceStats{Name: serviceName, CGroup: "/system.slice/" + serviceName + ".service", MemoryCurrent: 1 << 20, PidsCurrent: 2}, nil
+}
+
+func TestHTTPServer_HandleServiceStats(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 模拟的管理器不提供统计
+	req := httptest.NewRequest("GET", "/services/nginx/stats", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501, got %d", w.Code)
+	}
+
+	mock := server.managers[types.ServiceTypeSystemd].(*MockServiceManager)
+	server.managers[types.ServiceTypeSystemd] = statsManager{mock}
+
+	req = httptest.NewRequest("GET", "/services/nginx/stats", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Success bool               `json:"success"`
+		Stats   types.ServiceStats `json:"stats"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.Success || response.Stats.CGroup != "/system.slice/nginx.service" || response.Stats.PidsCurrent != 2 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+// processManager 在模拟管理器的基础上提供进程列表
+type processManager struct {
+	*MockServiceManager
+}
+
+func (m processManager) ServicePIDs(ctx context.Context, serviceName string) ([]int, error) {
+	return []int{os.Getpid()}, nil
+}
+
+func TestHTTPServer_HandleServiceProcesses(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 模拟的管理器无法列出进程
+	req := httptest.NewRequest("GET", "/services/nginx/processes", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501, got %d", w.Code)
+	}
+
+	mock := server.managers[types.ServiceTypeSystemd].(*MockServiceManager)
+	server.managers[types.ServiceTypeSystemd] = processManager{mock}
+
+	req = httptest.NewRequest("GET", "/services/nginx/processes", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Success   bool                `json:"success"`
+		Processes []types.ProcessInfo `json:"processes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.Success || len(response.Processes) != 1 || response.Processes[0].PID != os.Getpid() || response.Processes[0].Command == "" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestHTTPServer_HandleLookupOwner(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"", http.StatusBadRequest},
+		{"?pid=abc", http.StatusBadRequest},
+		{"?pid=1&port=80", http.StatusBadRequest},
+		{"?path=relative.log", http.StatusBadRequest},
+		{"?port=80&protocol=sctp", http.StatusBadRequest},
+		// 不存在的进程
+		{"?pid=999999999", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/lookup"+tt.query, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("GET /lookup%s: expected status %d, got %d: %s", tt.query, tt.status, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHTTPServer_HandleServiceHealth(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	server := createTestServer()
+	server.health = managers.NewHealthMonitor([]config.HealthCheck{
+		{Service: "nginx", HTTP: &config.HTTPProbe{URL: backend.URL}, Interval: 10 * time.Millisecond},
+	}, server.logger)
+	defer server.Close()
+	router := server.SetupRoutes()
+
+	// 等待首次探测完成
+	deadline := time.Now().Add(5 * time.Second)
+	for server.health.Results()[0].Status != types.HealthHealthy {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the probe, last %+v", server.health.Results())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	req := httptest.NewRequest("GET", "/services/nginx/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Health types.ServiceHealth `json:"health"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Health.Status != types.HealthHealthy || len(response.Health.Probes) != 1 || response.Health.Probes[0].Target != backend.URL {
+		t.Errorf("Unexpected health: %+v", response.Health)
+	}
+
+	// 状态接口同样携带健康信息
+	req = httptest.NewRequest("GET", "/services/nginx/status", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `"health":{"status":"healthy"`) {
+		t.Errorf("Expected health in the status response: %s", w.Body.String())
+	}
+
+	// 没有配置探测的服务
+	req = httptest.NewRequest("GET", "/services/mysql/health", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/healthchecks", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"service":"nginx"`) {
+		t.Errorf("Unexpected health checks response %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHTTPServer_HandleWatchdog(t *testing.T) {
+	server := createTestServer()
+	// mysql 处于停止状态而非失败，看门狗只记录状态不重启
+	server.watchdog = managers.NewWatchdog(&config.Config{Watchdog: []config.WatchdogPolicy{
+		{Service: "mysql", Type: "systemd", Interval: 10 * time.Millisecond},
+	}}, server.managers, nil, server.logger)
+	defer server.Close()
+	router := server.SetupRoutes()
+
+	req := httptest.NewRequest("GET", "/watchdog?service=mysql&limit=10", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Services []types.WatchdogState  `json:"services"`
+		Actions  []types.WatchdogAction `json:"actions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Services) != 1 || response.Services[0].Service != "mysql" || response.Services[0].MaxAttempts != 5 {
+		t.Errorf("Unexpected services: %+v", response.Services)
+	}
+	if response.Actions == nil || len(response.Actions) != 0 {
+		t.Errorf("Expected no actions, got %+v", response.Actions)
+	}
+
+	req = httptest.NewRequest("GET", "/watchdog?limit=-1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHTTPServer_HandleTimersUnavailable(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 模拟的systemd管理器不支持定时器
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/timers"},
+		{"GET", "/timers/backup"},
+		{"POST", "/timers/backup/trigger"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: expected status 503, got %d", route.method, route.path, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/timers/backup/explode", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code == http.StatusOK || w.Code == http.StatusServiceUnavailable {
+		t.Errorf("Expected unknown timer action to be rejected, got %d", w.Code)
+	}
+}
+
+func TestHTTPServer_HandleUnitFiles(t *testing.T) {
+	server := createTestServer()
+	router := server.SetupRoutes()
+
+	// 请求体必须包含 content 或 spec
+	req := httptest.NewRequest("PUT", "/units/demo", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+
+	// 模拟的systemd管理器不支持单元文件
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		req := httptest.NewRequest(method, "/units/demo", strings.NewReader(`{"spec": {"exec_start": "/bin/true"}}`))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected status 503, got %d", method, w.Code)
+		}
+	}
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/units/demo/cat"},
+		{"GET", "/units/demo/dropins"},
+		{"GET", "/units/demo/dropins/override"},
+		{"PUT", "/units/demo/dropins/override"},
+		{"DELETE", "/units/demo/dropins/override"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, strings.NewReader(`{"content": "[Service]\nNice=5"}`))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: expected status 503, got %d", route.method, route.path, w.Code)
+		}
+	}
+}
+
 func TestHTTPServer_HandleInvalidJSON(t *testing.T) {
 	server := createTestServer()
 	router := server.SetupRoutes()
@@ -450,7 +1011,7 @@ func TestHTTPServer_GetServiceManager(t *testing.T) {
 	server := createTestServer()
 	
 	// Test with specific service type
-	manager, err := server.getServiceManager("nginx", "systemd")
+	manager, err := server.getServiceManager(context.Background(), "nginx", "systemd")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -459,7 +1020,7 @@ func TestHTTPServer_GetServiceManager(t *testing.T) {
 	}
 	
 	// Test with auto-detection
-	manager, err = server.getServiceManager("nginx", "")
+	manager, err = server.getServiceManager(context.Background(), "nginx", "")
 	if err != nil {
 		t.Errorf("Expected no error for auto-detection, got %v", err)
 	}
@@ -468,18 +1029,36 @@ func TestHTTPServer_GetServiceManager(t *testing.T) {
 	}
 	
 	// Test with unsupported type
-	_, err = server.getServiceManager("nginx", "unsupported")
+	_, err = server.getServiceManager(context.Background(), "nginx", "unsupported")
 	if err == nil {
 		t.Error("Expected error for unsupported type, got none")
 	}
 	
 	// Test with nonexistent service
-	_, err = server.getServiceManager("nonexistent", "")
+	_, err = server.getServiceManager(context.Background(), "nonexistent", "")
 	if err == nil {
 		t.Error("Expected error for nonexistent service, got none")
 	}
 }
 
+func TestDockerErrorStatus(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected int
+	}{
+		{&managers.DockerAPIError{StatusCode: 404, Message: "No such container: x"}, http.StatusNotFound},
+		{fmt.Errorf("remove: %w", &managers.DockerAPIError{StatusCode: 409, Message: "conflict"}), http.StatusConflict},
+		{&managers.DockerAPIError{StatusCode: 500, Message: "boom"}, http.StatusInternalServerError},
+		{fmt.Errorf("exit status 1"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		if status := dockerErrorStatus(tc.err); status != tc.expected {
+			t.Errorf("For %v expected %d, got %d", tc.err, tc.expected, status)
+		}
+	}
+}
+
 func TestHTTPServer_GetAvailableManagers(t *testing.T) {
 	server := createTestServer()
 	managers := server.getAvailableManagers()
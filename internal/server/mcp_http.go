@@ -13,12 +13,15 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/internal/inflight"
 	"nucc.com/mcp_srv_mgr/internal/managers"
 	"nucc.com/mcp_srv_mgr/pkg/types"
 )
 
 type MCPHTTPServer struct {
 	managers map[types.ServiceType]types.ServiceManager
+	health   *managers.HealthMonitor
+	watchdog *managers.Watchdog
 	config   *config.Config
 	logger   *logrus.Logger
 	clients  map[string]*SSEClient
@@ -32,6 +35,10 @@ type SSEClient struct {
 	Context  context.Context
 	Cancel   context.CancelFunc
 	LastSeen time.Time
+
+	writeMu  sync.Mutex
+	inflight *inflight.Requests
+	logs     *logSubscriptions
 }
 
 type MCPRequest struct {
@@ -56,34 +63,13 @@ type MCPError struct {
 
 func NewMCPHTTPServer(cfg *config.Config, logger *logrus.Logger) *MCPHTTPServer {
 	server := &MCPHTTPServer{
-		managers: make(map[types.ServiceType]types.ServiceManager),
+		managers: managers.DetectManagers(cfg, logger),
+		health:   managers.NewHealthMonitor(cfg.Health, logger),
 		config:   cfg,
 		logger:   logger,
 		clients:  make(map[string]*SSEClient),
 	}
 
-	// Initialize service managers
-	if managers.IsSystemdAvailable() {
-		server.managers[types.ServiceTypeSystemd] = managers.NewSystemdManager()
-		logger.Info("Systemd manager initialized")
-	} else {
-		logger.Debug("Systemd not available on this system")
-	}
-
-	if managers.IsSysVAvailable() {
-		server.managers[types.ServiceTypeSysV] = managers.NewSysVManager()
-		logger.Info("SysV manager initialized")
-	} else {
-		logger.Debug("SysV not available on this system")
-	}
-
-	if managers.IsDockerAvailable() {
-		server.managers[types.ServiceTypeDocker] = managers.NewDockerManager()
-		logger.Info("Docker manager initialized")
-	} else {
-		logger.Debug("Docker not available on this system")
-	}
-
 	if len(server.managers) == 0 {
 		logger.Warn("No service managers available")
 		// 添加一个mock管理器用于测试
@@ -96,6 +82,8 @@ func NewMCPHTTPServer(cfg *config.Config, logger *logrus.Logger) *MCPHTTPServer
 	// Start cleanup routine for stale clients
 	go server.cleanupClients()
 
+	server.watchdog = managers.NewWatchdog(cfg, server.managers, server.health, logger)
+
 	return server
 }
 
@@ -156,6 +144,8 @@ func (s *MCPHTTPServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		Context:  ctx,
 		Cancel:   cancel,
 		LastSeen: time.Now(),
+		inflight: inflight.New(),
+		logs:     newLogSubscriptions(),
 	}
 
 	// Register client
@@ -194,6 +184,11 @@ func (s *MCPHTTPServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 			s.clientMu.Lock()
 			delete(s.clients, clientID)
 			s.clientMu.Unlock()
+			// Wait for a write in progress. Later writers see the cancelled
+			// context once they hold writeMu, so none touches w after this
+			// handler has returned.
+			client.writeMu.Lock()
+			client.writeMu.Unlock()
 			return
 		case <-ticker.C:
 			// Send heartbeat
@@ -233,10 +228,10 @@ func (s *MCPHTTPServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Process MCP request
-	response := s.processMCPRequest(&mcpReq)
-
-	// Send response via SSE
-	s.sendSSEMessage(client, "message", response)
+	if response := s.dispatchRequest(r, client, &mcpReq); response != nil {
+		// Send response via SSE
+		s.sendSSEMessage(client, "message", response)
+	}
 
 	// Return 202 Accepted (mark3labs/mcp-go expects this)
 	w.WriteHeader(http.StatusAccepted)
@@ -270,10 +265,10 @@ func (s *MCPHTTPServer) handleMessages(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Process MCP request
-	response := s.processMCPRequest(&mcpReq)
-
-	// Send response via SSE
-	s.sendSSEMessage(client, "response", response)
+	if response := s.dispatchRequest(r, client, &mcpReq); response != nil {
+		// Send response via SSE
+		s.sendSSEMessage(client, "response", response)
+	}
 
 	// Return acknowledgment
 	w.Header().Set("Content-Type", "application/json")
@@ -283,14 +278,42 @@ func (s *MCPHTTPServer) handleMessages(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *MCPHTTPServer) processMCPRequest(req *MCPRequest) *MCPResponse {
+// dispatchRequest runs req on behalf of client. The request is aborted when the
+// POST is dropped, the SSE stream closes, or the client sends
+// notifications/cancelled for it. A nil response means nothing must be sent back.
+func (s *MCPHTTPServer) dispatchRequest(r *http.Request, client *SSEClient, req *MCPRequest) *MCPResponse {
+	if req.Method == "notifications/cancelled" {
+		if id, ok := inflight.CancelledRequestID(req.Params); ok && (client.inflight.Cancel(id) || client.logs.cancelRequest(id)) {
+			s.logger.Infof("Request %v cancelled by client %s", id, client.ID)
+		}
+		return nil
+	}
+
+	ctx, done := client.inflight.Begin(r.Context(), client.Context, req.ID)
+	defer done()
+	ctx = withLogSubscriber(ctx, &logSubscriber{
+		session:       client.Context,
+		subscriptions: client.logs,
+		notify: func(ctx context.Context, method string, params interface{}) {
+			s.sendSSEMessage(client, "message", &MCPRequest{JSONRPC: "2.0", Method: method, Params: params})
+		},
+	})
+
+	response := s.processMCPRequest(ctx, req)
+	if inflight.CancelledByClient(ctx) {
+		return nil
+	}
+	return response
+}
+
+func (s *MCPHTTPServer) processMCPRequest(ctx context.Context, req *MCPRequest) *MCPResponse {
 	switch req.Method {
 	case "initialize":
 		return s.handleInitialize(req)
 	case "tools/list":
 		return s.handleListTools(req)
 	case "tools/call":
-		return s.handleCallTool(req)
+		return s.handleCallTool(ctx, req)
 	case "prompts/list":
 		return s.handleListPrompts(req)
 	case "prompts/get":
@@ -330,8 +353,20 @@ func (s *MCPHTTPServer) handleListTools(req *MCPRequest) *MCPResponse {
 				"properties": map[string]interface{}{
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Filter services by type (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Filter services by type (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+					"unit_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd unit types to list, comma-separated: service, socket, target, mount, automount, path, or all (default: service)",
+					},
+					"installed": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also list installed unit files that are not loaded, e.g. disabled services that never ran, with their enablement state (systemd only, default: false)",
 					},
 				},
 			},
@@ -348,8 +383,12 @@ func (s *MCPHTTPServer) handleListTools(req *MCPRequest) *MCPResponse {
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -367,8 +406,12 @@ func (s *MCPHTTPServer) handleListTools(req *MCPRequest) *MCPResponse {
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -386,8 +429,12 @@ func (s *MCPHTTPServer) handleListTools(req *MCPRequest) *MCPResponse {
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -405,8 +452,12 @@ func (s *MCPHTTPServer) handleListTools(req *MCPRequest) *MCPResponse {
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -424,8 +475,12 @@ func (s *MCPHTTPServer) handleListTools(req *MCPRequest) *MCPResponse {
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -443,8 +498,12 @@ func (s *MCPHTTPServer) handleListTools(req *MCPRequest) *MCPResponse {
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -468,80 +527,1193 @@ func (s *MCPHTTPServer) handleListTools(req *MCPRequest) *MCPResponse {
 				"required": []string{"container_name"},
 			},
 		},
+		{
+			"name":        "get_service_logs",
+			"description": "Get log entries of any service: the journal for systemd units, log files for SysV services, container output for Docker",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+					"since": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries at or after this time: RFC 3339, \"2006-01-02 15:04:05\", or a duration ago such as 30m",
+					},
+					"until": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries at or before this time, in the same formats as since",
+					},
+					"priority": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries of this syslog level or more important: emerg, alert, crit, err, warning, notice, info, debug or 0-7",
+					},
+					"grep": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries whose message matches this regular expression",
+					},
+					"lines": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of most recent entries to return (default: 100)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			"name":        "follow_service_logs",
+			"description": "Follow a service's log like tail -f: the most recent entries, then new ones as they are written, sent as notifications/service_log messages until unfollow_service_logs is called, the call is cancelled or the session closes",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+					"since": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries at or after this time: RFC 3339, \"2006-01-02 15:04:05\", or a duration ago such as 30m",
+					},
+					"priority": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries of this syslog level or more important: emerg, alert, crit, err, warning, notice, info, debug or 0-7",
+					},
+					"grep": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries whose message matches this regular expression",
+					},
+					"lines": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of recent entries to send before following (default: 100)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			"name":        "unfollow_service_logs",
+			"description": "Stop a log subscription started with follow_service_logs",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"subscription_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Subscription ID returned by follow_service_logs",
+					},
+				},
+				"required": []string{"subscription_id"},
+			},
+		},
+		{
+			"name":        "get_service_stats",
+			"description": "Get a service's resource usage: CPU time, memory current and peak, IO bytes, task count and memory events, read from the systemd unit's cgroup or from the container engine",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			"name":        "get_service_processes",
+			"description": "List a service's processes as a tree with command line, user, resident memory, open file count and the TCP/UDP ports each one listens on, found through the systemd unit's cgroup, the container's processes or the SysV pidfile",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			"name":        "find_service_owner",
+			"description": "Find the service that owns a process, a listening port or a file, e.g. which service holds port 8080 or keeps a log file open. Give exactly one of pid, port and path; the result is the owning systemd unit, Docker or Podman container or SysV script with its status",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"pid": map[string]interface{}{
+						"type":        "integer",
+						"description": "Process ID",
+					},
+					"port": map[string]interface{}{
+						"type":        "integer",
+						"description": "Listening TCP or UDP port",
+					},
+					"protocol": map[string]interface{}{
+						"type":        "string",
+						"description": "Restrict a port lookup to tcp or udp (default: both)",
+						"enum":        []string{"tcp", "udp"},
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Absolute path of a file, directory or executable the process has open",
+					},
+				},
+			},
+		},
+		{
+			"name":        "get_service_health",
+			"description": "Show the health of a service from the HTTP, TCP and exec probes declared under health: in config.yaml and, for containers, the engine's HEALTHCHECK, with each probe's status, failure streak and last error. Without service_name, lists every configured probe",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service (default: all configured probes)",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+			},
+		},
+		{
+			"name":        "get_watchdog_status",
+			"description": "Show the services the watchdog watches with their restart budget, and the restarts and escalations it made on its own, newest first. These automatic actions are listed separately from actions requested through the API",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only show actions on this service",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of actions to show (default: 20)",
+					},
+				},
+			},
+		},
+		{
+			"name":        "list_timers",
+			"description": "List systemd timers with their next and last elapse times and the unit each one activates",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+			},
+		},
+		{
+			"name":        "manage_timer",
+			"description": "Start, stop, enable or disable a systemd timer, or trigger the unit it activates now",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"timer_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the timer, with or without the .timer suffix",
+					},
+					"action": map[string]interface{}{
+						"type":        "string",
+						"description": "Action to perform",
+						"enum":        []string{"start", "stop", "enable", "disable", "trigger"},
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				"required": []string{"timer_name", "action"},
+			},
+		},
+		{
+			"name":        "get_unit_file",
+			"description": "Read a systemd unit file installed through this server",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+		{
+			"name":        "write_unit_file",
+			"description": "Create or replace a systemd unit file from raw content or a service spec; the file is verified, the previous version backed up, and systemd reloaded",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Raw unit file text (mutually exclusive with spec)",
+					},
+					"spec": map[string]interface{}{
+						"type":        "object",
+						"description": "Service unit spec (mutually exclusive with content)",
+						"properties": map[string]interface{}{
+							"description":       map[string]interface{}{"type": "string"},
+							"type":              map[string]interface{}{"type": "string", "description": "Service type, e.g. simple, exec, forking, oneshot"},
+							"exec_start":        map[string]interface{}{"type": "string"},
+							"user":              map[string]interface{}{"type": "string"},
+							"group":             map[string]interface{}{"type": "string"},
+							"working_directory": map[string]interface{}{"type": "string"},
+							"environment":       map[string]interface{}{"type": "object", "description": "Environment variables", "additionalProperties": map[string]interface{}{"type": "string"}},
+							"restart":           map[string]interface{}{"type": "string", "enum": []string{"no", "always", "on-success", "on-failure", "on-abnormal", "on-abort", "on-watchdog"}},
+							"wanted_by":         map[string]interface{}{"type": "string", "description": "Install target, e.g. multi-user.target"},
+						},
+						"required": []string{"exec_start"},
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+		{
+			"name":        "delete_unit_file",
+			"description": "Stop and disable a systemd unit, then remove its unit file keeping a backup, and reload systemd",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+		{
+			"name":        "list_dropins",
+			"description": "List the drop-in overrides of a systemd unit with their content",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+		{
+			"name":        "write_dropin",
+			"description": "Create or replace a drop-in override (<unit>.d/<name>.conf) and reload systemd",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"dropin_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Drop-in file name; .conf is added if missing",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Drop-in text, e.g. \"[Service]\\nEnvironment=DEBUG=1\"",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name", "dropin_name", "content"},
+			},
+		},
+		{
+			"name":        "delete_dropin",
+			"description": "Delete a drop-in override and reload systemd",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"dropin_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Drop-in file name; .conf is added if missing",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name", "dropin_name"},
+			},
+		},
+		{
+			"name":        "cat_unit",
+			"description": "Show the effective configuration of a systemd unit: its unit file followed by all drop-ins, like systemctl cat",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+	}
+
+	for _, action := range managers.ServiceActions {
+		properties := map[string]interface{}{
+			"service_name": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the service",
+			},
+			"service_type": map[string]interface{}{
+				"type":        "string",
+				"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+				"enum":        managers.ServiceTypeNames(s.config),
+			},
+			"scope": map[string]interface{}{
+				"type":        "string",
+				"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+			},
+		}
+		if action.Name == "kill" {
+			properties["signal"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Signal to send, e.g. SIGHUP, HUP or 1 (default: SIGTERM)",
+			}
+		}
+		tools = append(tools, map[string]interface{}{
+			"name":        managers.ServiceActionTool(action.Name),
+			"description": action.Description,
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": properties,
+				"required":   []string{"service_name"},
+			},
+		})
+	}
+
+	result := map[string]interface{}{
+		"tools": tools,
+	}
+
+	return s.createSuccessResponse(req.ID, result)
+}
+
+func (s *MCPHTTPServer) handleCallTool(ctx context.Context, req *MCPRequest) *MCPResponse {
+	params, ok := req.Params.(map[string]interface{})
+	if !ok {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", nil)
+	}
+
+	toolName, ok := params["name"].(string)
+	if !ok {
+		return s.createErrorResponse(req.ID, -32602, "Missing tool name", nil)
+	}
+
+	arguments, _ := params["arguments"].(map[string]interface{})
+
+	switch toolName {
+	case "list_services":
+		return s.callListServices(ctx, req.ID, arguments)
+	case "get_service_status":
+		return s.callGetServiceStatus(ctx, req.ID, arguments)
+	case "start_service":
+		return s.callStartService(ctx, req.ID, arguments)
+	case "stop_service":
+		return s.callStopService(ctx, req.ID, arguments)
+	case "restart_service":
+		return s.callRestartService(ctx, req.ID, arguments)
+	case "enable_service":
+		return s.callEnableService(ctx, req.ID, arguments)
+	case "disable_service":
+		return s.callDisableService(ctx, req.ID, arguments)
+	case "get_docker_logs":
+		return s.callGetDockerLogs(ctx, req.ID, arguments)
+	case "get_service_logs":
+		return s.callGetServiceLogs(ctx, req.ID, arguments)
+	case "follow_service_logs":
+		return s.callFollowServiceLogs(ctx, req.ID, arguments)
+	case "unfollow_service_logs":
+		return s.callUnfollowServiceLogs(ctx, req.ID, arguments)
+	case "get_service_stats":
+		return s.callGetServiceStats(ctx, req.ID, arguments)
+	case "get_service_processes":
+		return s.callGetServiceProcesses(ctx, req.ID, arguments)
+	case "find_service_owner":
+		return s.callFindServiceOwner(ctx, req.ID, arguments)
+	case "get_service_health":
+		return s.callGetServiceHealth(ctx, req.ID, arguments)
+	case "get_watchdog_status":
+		return s.callGetWatchdogStatus(ctx, req.ID, arguments)
+	case "list_timers":
+		return s.callListTimers(ctx, req.ID, arguments)
+	case "manage_timer":
+		return s.callManageTimer(ctx, req.ID, arguments)
+	case "get_unit_file":
+		return s.callGetUnitFile(ctx, req.ID, arguments)
+	case "write_unit_file":
+		return s.callWriteUnitFile(ctx, req.ID, arguments)
+	case "delete_unit_file":
+		return s.callDeleteUnitFile(ctx, req.ID, arguments)
+	case "list_dropins":
+		return s.callListDropIns(ctx, req.ID, arguments)
+	case "write_dropin":
+		return s.callWriteDropIn(ctx, req.ID, arguments)
+	case "delete_dropin":
+		return s.callDeleteDropIn(ctx, req.ID, arguments)
+	case "cat_unit":
+		return s.callCatUnit(ctx, req.ID, arguments)
+	default:
+		if action, ok := managers.ServiceActionForTool(toolName); ok {
+			return s.callServiceOperation(ctx, req.ID, arguments, action)
+		}
+		return s.createErrorResponse(req.ID, -32601, "Tool not found", nil)
+	}
+}
+
+func (s *MCPHTTPServer) callListServices(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	var allServices []types.ServiceInfo
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+	unitType, _ := args["unit_type"].(string)
+	ctx, serviceType, err = managers.ApplyUnitType(ctx, serviceType, unitType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+	installed, _ := args["installed"].(bool)
+	ctx, serviceType, err = managers.ApplyInstalledUnits(ctx, serviceType, installed)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	if serviceType != "" {
+		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
+			services, err := manager.ListServices(ctx)
+			if err != nil {
+				return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list %s services: %v", serviceType, err))
+			}
+			allServices = services
+		} else {
+			return s.createToolErrorResponse(id, fmt.Sprintf("Unsupported service type: %s", serviceType))
+		}
+	} else {
+		for _, manager := range s.managers {
+			services, err := manager.ListServices(ctx)
+			if err != nil {
+				s.logger.Warnf("Failed to list services from manager: %v", err)
+				continue
+			}
+			allServices = append(allServices, services...)
+		}
+	}
+
+	s.health.AnnotateAll(allServices)
+
+	resultText := s.formatServicesOutput(allServices)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callGetServiceStatus(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	info, err := manager.GetStatus(ctx, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+	}
+	s.health.Annotate(&info)
+
+	resultText := s.formatServiceInfo(info)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callStartService(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "start")
+}
+
+func (s *MCPHTTPServer) callStopService(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "stop")
+}
+
+func (s *MCPHTTPServer) callRestartService(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "restart")
+}
+
+func (s *MCPHTTPServer) callEnableService(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "enable")
+}
+
+func (s *MCPHTTPServer) callDisableService(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	return s.callServiceOperation(ctx, id, args, "disable")
+}
+
+func (s *MCPHTTPServer) callServiceOperation(ctx context.Context, id interface{}, args map[string]interface{}, operation string) *MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, operation)
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	signalName, _ := args["signal"].(string)
+	signal, err := managers.ParseSignal(signalName)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	if operationErr := managers.PerformAction(ctx, manager, serviceName, operation, signal); operationErr != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s service: %v", operation, operationErr))
+	}
+
+	info, _ := manager.GetStatus(ctx, serviceName)
+	resultText := fmt.Sprintf("%s.\n\n%s", managers.ActionMessage(serviceName, operation), s.formatServiceInfo(info))
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callGetServiceLogs(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	since, _ := args["since"].(string)
+	until, _ := args["until"].(string)
+	priority, _ := args["priority"].(string)
+	grep, _ := args["grep"].(string)
+	lines := 0
+	if l, ok := args["lines"].(float64); ok {
+		lines = int(l)
+	}
+	query, err := managers.ParseLogQuery(since, until, priority, grep, lines)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "logs")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err = managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	entries, err := managers.ReadServiceLogs(ctx, manager, serviceName, query)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
+	}
+
+	var resultText string
+	if len(entries) == 0 {
+		resultText = fmt.Sprintf("No log entries of service %s match the query.", serviceName)
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Logs of service %s (%d entries):\n\n", serviceName, len(entries))
+		for _, entry := range entries {
+			b.WriteString(entry.String())
+			b.WriteByte('\n')
+		}
+		resultText = b.String()
+	}
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+// callFollowServiceLogs starts a log subscription for the client or session
+// the request came from and returns its ID at once; the entries follow as
+// notifications.
+func (s *MCPHTTPServer) callFollowServiceLogs(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	sub, ok := logSubscriberFrom(ctx)
+	if !ok {
+		return s.createToolErrorResponse(id, "Following logs requires a session that can receive notifications")
+	}
+
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	since, _ := args["since"].(string)
+	priority, _ := args["priority"].(string)
+	grep, _ := args["grep"].(string)
+	lines := 0
+	if l, ok := args["lines"].(float64); ok {
+		lines = int(l)
+	}
+	query, err := managers.ParseLogQuery(since, "", priority, grep, lines)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	lookupCtx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	lookupCtx, serviceType, err = managers.ApplyScope(lookupCtx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(lookupCtx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+	if err := managers.CheckFollowLogs(manager, query); err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to follow logs: %v", err))
+	}
+
+	subscriptionID := sub.followLogs(lookupCtx, id, manager, serviceName, query)
+	s.logger.Infof("Following logs of service %s as %s", serviceName, subscriptionID)
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": fmt.Sprintf("Following logs of service %s as subscription %s. Entries are sent as %s notifications until unfollow_service_logs is called.", serviceName, subscriptionID, logNotificationMethod),
+			},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callUnfollowServiceLogs(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	subscriptionID, ok := args["subscription_id"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "subscription_id is required")
+	}
+
+	sub, ok := logSubscriberFrom(ctx)
+	if !ok || !sub.subscriptions.stop(subscriptionID) {
+		return s.createToolErrorResponse(id, fmt.Sprintf("No log subscription %s", subscriptionID))
+	}
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": fmt.Sprintf("Stopped log subscription %s", subscriptionID)},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callGetServiceStats(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "stats")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	stats, err := managers.ReadServiceStats(ctx, manager, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get stats: %v", err))
+	}
+
+	resultText := fmt.Sprintf("**Service**: %s\n%s", serviceName, stats.String())
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callGetServiceProcesses(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "processes")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	processes, err := managers.ReadServiceProcesses(ctx, manager, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get processes: %v", err))
+	}
+
+	resultText := fmt.Sprintf("**Service**: %s\n**Processes**: %d\n\n%s", serviceName, len(processes), types.ProcessTree(processes))
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callFindServiceOwner(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	var pid, port int
+	if p, ok := args["pid"].(float64); ok {
+		pid = int(p)
+	}
+	if p, ok := args["port"].(float64); ok {
+		port = int(p)
+	}
+	protocol, _ := args["protocol"].(string)
+	path, _ := args["path"].(string)
+
+	query, err := managers.ParseOwnerQuery(pid, port, protocol, path)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	owner, err := managers.LocateOwner(ctx, query)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, string(owner.Type), owner.Scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, owner.Name, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Process %d belongs to %s service %s: %v", owner.PID, owner.Type, owner.Name, err))
+	}
+
+	info, err := manager.GetStatus(ctx, owner.Name)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Process %d belongs to %s service %s, failed to get its status: %v", owner.PID, owner.Type, owner.Name, err))
+	}
+	s.health.Annotate(&info)
+
+	resultText := fmt.Sprintf("**Process**: %d\n**Service**: %s\n**Type**: %s\n", owner.PID, info.Name, info.Type)
+	if owner.Scope != "" {
+		resultText += fmt.Sprintf("**Scope**: %s\n", owner.Scope)
+	}
+	if owner.CGroup != "" {
+		resultText += fmt.Sprintf("**CGroup**: %s\n", owner.CGroup)
+	}
+	resultText += fmt.Sprintf("**Status**: %s\n", info.Status)
+	if info.Health != nil {
+		resultText += fmt.Sprintf("**Health**: %s\n", info.Health.Status)
+	}
+	if info.Description != "" {
+		resultText += fmt.Sprintf("**Description**: %s\n", info.Description)
+	}
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callGetServiceHealth(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	serviceName, _ := args["service_name"].(string)
+	if serviceName == "" {
+		results := s.health.Results()
+		resultText := "No health checks configured."
+		if len(results) > 0 {
+			var b strings.Builder
+			b.WriteString(fmt.Sprintf("Found %d health probes:\n\n", len(results)))
+			for _, probe := range results {
+				b.WriteString(fmt.Sprintf("- **%s** %s\n", probe.Service, probe))
+			}
+			resultText = b.String()
+		}
+		result := map[string]interface{}{
+			"content": []map[string]interface{}{
+				{"type": "text", "text": resultText},
+			},
+		}
+		return s.createSuccessResponse(id, result)
+	}
+
+	serviceType, _ := args["service_type"].(string)
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	info, err := manager.GetStatus(ctx, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+	}
+	s.health.Annotate(&info)
+	if info.Health == nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("No health checks for service %s", serviceName))
+	}
+
+	resultText := fmt.Sprintf("**Service**: %s\n**Status**: %s\n", info.Name, info.Status) + info.Health.String()
+	result := map[string]interface{}{
+			"content": []map[string]interface{}{
+				{"type": "text", "text": resultText},
+			},
+		}
+		return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callGetWatchdogStatus(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	serviceName, _ := args["service_name"].(string)
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	var b strings.Builder
+	b.WriteString("## Watched services\n")
+	states := s.watchdog.States()
+	if len(states) == 0 {
+		b.WriteString("No services are watched; declare policies under watchdog: in config.yaml.\n")
+	}
+	for _, state := range states {
+		b.WriteString(fmt.Sprintf("- %s\n", state))
+	}
+	b.WriteString("\n## Automatic actions\n")
+	actions := s.watchdog.Actions(serviceName, limit)
+	if len(actions) == 0 {
+		b.WriteString("No automatic actions recorded.\n")
+	}
+	for _, action := range actions {
+		b.WriteString(fmt.Sprintf("- %s\n", action))
+	}
+
+	resultText := b.String()
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callGetDockerLogs(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	containerName, ok := args["container_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "container_name is required")
+	}
+
+	dockerManager, exists := s.managers[types.ServiceTypeDocker].(managers.DockerContainerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Docker manager not available")
+	}
+
+	lines := 100
+	if l, ok := args["lines"]; ok {
+		if linesFloat, ok := l.(float64); ok {
+			lines = int(linesFloat)
+		}
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "logs")
+	defer cancel()
+
+	logs, err := dockerManager.GetLogs(ctx, containerName, lines)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
 	}
 
+	resultText := fmt.Sprintf("Docker container '%s' logs (last %d lines):\n\n%s", containerName, lines, logs)
 	result := map[string]interface{}{
-		"tools": tools,
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
 	}
+	return s.createSuccessResponse(id, result)
+}
 
-	return s.createSuccessResponse(req.ID, result)
+func (s *MCPHTTPServer) callListTimers(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	timerManager, exists := s.managers[types.ServiceTypeSystemd].(managers.TimerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Systemd timers not available")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	timers, err := timerManager.ListTimers(ctx)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list timers: %v", err))
+	}
+
+	resultText := s.formatTimersOutput(timers)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPHTTPServer) handleCallTool(req *MCPRequest) *MCPResponse {
-	params, ok := req.Params.(map[string]interface{})
+func (s *MCPHTTPServer) callManageTimer(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	timerName, ok := args["timer_name"].(string)
 	if !ok {
-		return s.createErrorResponse(req.ID, -32602, "Invalid params", nil)
+		return s.createToolErrorResponse(id, "timer_name is required")
 	}
-
-	toolName, ok := params["name"].(string)
+	action, ok := args["action"].(string)
 	if !ok {
-		return s.createErrorResponse(req.ID, -32602, "Missing tool name", nil)
+		return s.createToolErrorResponse(id, "action is required")
 	}
 
-	arguments, _ := params["arguments"].(map[string]interface{})
+	timerManager, exists := s.managers[types.ServiceTypeSystemd].(managers.TimerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Systemd timers not available")
+	}
 
-	switch toolName {
-	case "list_services":
-		return s.callListServices(req.ID, arguments)
-	case "get_service_status":
-		return s.callGetServiceStatus(req.ID, arguments)
-	case "start_service":
-		return s.callStartService(req.ID, arguments)
-	case "stop_service":
-		return s.callStopService(req.ID, arguments)
-	case "restart_service":
-		return s.callRestartService(req.ID, arguments)
-	case "enable_service":
-		return s.callEnableService(req.ID, arguments)
-	case "disable_service":
-		return s.callDisableService(req.ID, arguments)
-	case "get_docker_logs":
-		return s.callGetDockerLogs(req.ID, arguments)
-	default:
-		return s.createErrorResponse(req.ID, -32601, "Tool not found", nil)
+	operation := action
+	if action == "trigger" {
+		operation = "start"
 	}
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, operation)
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	if err := managers.ManageTimer(ctx, timerManager, timerName, action); err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s timer: %v", action, err))
+	}
+
+	timer, err := timerManager.GetTimer(ctx, timerName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Timer %s completed but failed to get status: %v", action, err))
+	}
+
+	resultText := fmt.Sprintf("Timer %s: %s completed successfully.\n\n%s", timerName, action, s.formatTimersOutput([]types.TimerInfo{timer}))
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPHTTPServer) callListServices(id interface{}, args map[string]interface{}) *MCPResponse {
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
+func (s *MCPHTTPServer) callGetUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
 	}
 
-	var allServices []types.ServiceInfo
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
 
-	if serviceType != "" {
-		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
-			services, err := manager.ListServices()
-			if err != nil {
-				return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list %s services: %v", serviceType, err))
-			}
-			allServices = services
-		} else {
-			return s.createToolErrorResponse(id, fmt.Sprintf("Unsupported service type: %s", serviceType))
-		}
-	} else {
-		for _, manager := range s.managers {
-			services, err := manager.ListServices()
-			if err != nil {
-				s.logger.Warnf("Failed to list services from manager: %v", err)
-				continue
-			}
-			allServices = append(allServices, services...)
-		}
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	resultText := s.formatServicesOutput(allServices)
+	unitFile, err := unitFiles.GetUnitFile(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to read unit file: %v", err))
+	}
+
+	resultText := fmt.Sprintf("**%s**\n\n```ini\n%s```", unitFile.Path, unitFile.Content)
 	result := map[string]interface{}{
 		"content": []map[string]interface{}{
 			{"type": "text", "text": resultText},
@@ -550,28 +1722,42 @@ func (s *MCPHTTPServer) callListServices(id interface{}, args map[string]interfa
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPHTTPServer) callGetServiceStatus(id interface{}, args map[string]interface{}) *MCPResponse {
-	serviceName, ok := args["service_name"].(string)
+func (s *MCPHTTPServer) callWriteUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	unitName, ok := args["unit_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "service_name is required")
+		return s.createToolErrorResponse(id, "unit_name is required")
 	}
 
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
+	var request types.UnitFileRequest
+	request.Content, _ = args["content"].(string)
+	if spec, ok := args["spec"]; ok {
+		data, _ := json.Marshal(spec)
+		if err := json.Unmarshal(data, &request.Spec); err != nil {
+			return s.createToolErrorResponse(id, fmt.Sprintf("Invalid spec: %v", err))
+		}
+	}
+	content, err := managers.UnitFileContent(request)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	manager, err := s.getServiceManager(serviceName, serviceType)
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
 	if err != nil {
 		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	info, err := manager.GetStatus(serviceName)
+	unitFile, err := unitFiles.WriteUnitFile(ctx, unitName, content)
 	if err != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to write unit file: %v", err))
 	}
 
-	resultText := s.formatServiceInfo(info)
+	resultText := fmt.Sprintf("Unit file %s installed and systemd reloaded.", unitFile.Path)
+	if unitFile.Backup != "" {
+		resultText += fmt.Sprintf("\nPrevious version saved to %s.", unitFile.Backup)
+	}
 	result := map[string]interface{}{
 		"content": []map[string]interface{}{
 			{"type": "text", "text": resultText},
@@ -580,63 +1766,114 @@ func (s *MCPHTTPServer) callGetServiceStatus(id interface{}, args map[string]int
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPHTTPServer) callStartService(id interface{}, args map[string]interface{}) *MCPResponse {
-	return s.callServiceOperation(id, args, "start")
-}
+func (s *MCPHTTPServer) callDeleteUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
 
-func (s *MCPHTTPServer) callStopService(id interface{}, args map[string]interface{}) *MCPResponse {
-	return s.callServiceOperation(id, args, "stop")
-}
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
 
-func (s *MCPHTTPServer) callRestartService(id interface{}, args map[string]interface{}) *MCPResponse {
-	return s.callServiceOperation(id, args, "restart")
-}
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	unitFile, err := unitFiles.RemoveUnitFile(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to remove unit file: %v", err))
+	}
 
-func (s *MCPHTTPServer) callEnableService(id interface{}, args map[string]interface{}) *MCPResponse {
-	return s.callServiceOperation(id, args, "enable")
+	resultText := fmt.Sprintf("Unit file %s removed and systemd reloaded.\nBackup saved to %s.", unitFile.Path, unitFile.Backup)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPHTTPServer) callDisableService(id interface{}, args map[string]interface{}) *MCPResponse {
-	return s.callServiceOperation(id, args, "disable")
+// unitFileManager returns the systemd unit file manager with the scope
+// argument applied to ctx.
+func (s *MCPHTTPServer) unitFileManager(ctx context.Context, args map[string]interface{}) (context.Context, managers.UnitFileManager, error) {
+	unitFiles, exists := s.managers[types.ServiceTypeSystemd].(managers.UnitFileManager)
+	if !exists {
+		return ctx, nil, fmt.Errorf("systemd unit files not available")
+	}
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, unitFiles, nil
 }
 
-func (s *MCPHTTPServer) callServiceOperation(id interface{}, args map[string]interface{}, operation string) *MCPResponse {
-	serviceName, ok := args["service_name"].(string)
+func (s *MCPHTTPServer) callListDropIns(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	unitName, ok := args["unit_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "service_name is required")
+		return s.createToolErrorResponse(id, "unit_name is required")
 	}
 
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
-	}
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
 
-	manager, err := s.getServiceManager(serviceName, serviceType)
+	ctx, dropIns, err := s.dropInManager(ctx, args)
 	if err != nil {
 		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	var operationErr error
-	switch operation {
-	case "start":
-		operationErr = manager.Start(serviceName)
-	case "stop":
-		operationErr = manager.Stop(serviceName)
-	case "restart":
-		operationErr = manager.Restart(serviceName)
-	case "enable":
-		operationErr = manager.Enable(serviceName)
-	case "disable":
-		operationErr = manager.Disable(serviceName)
+	list, err := dropIns.ListDropIns(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list drop-ins: %v", err))
 	}
 
-	if operationErr != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s service: %v", operation, operationErr))
+	var resultText strings.Builder
+	if len(list) == 0 {
+		resultText.WriteString(fmt.Sprintf("No drop-ins found for %s.", unitName))
+	} else {
+		resultText.WriteString(fmt.Sprintf("Found %d drop-ins:\n", len(list)))
+		for _, dropIn := range list {
+			resultText.WriteString(fmt.Sprintf("\n**%s**\n```ini\n%s```\n", dropIn.Path, dropIn.Content))
+		}
+	}
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText.String()},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callWriteDropIn(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+	dropInName, ok := args["dropin_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "dropin_name is required")
+	}
+	content, ok := args["content"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "content is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	info, _ := manager.GetStatus(serviceName)
-	resultText := fmt.Sprintf("Service %s %sed successfully.\n\n%s", serviceName, operation, s.formatServiceInfo(info))
+	dropIn, err := dropIns.WriteDropIn(ctx, unitName, dropInName, content)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to write drop-in: %v", err))
+	}
 
+	resultText := fmt.Sprintf("Drop-in %s installed and systemd reloaded. Restart %s to apply it.", dropIn.Path, dropIn.Unit)
 	result := map[string]interface{}{
 		"content": []map[string]interface{}{
 			{"type": "text", "text": resultText},
@@ -645,30 +1882,58 @@ func (s *MCPHTTPServer) callServiceOperation(id interface{}, args map[string]int
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPHTTPServer) callGetDockerLogs(id interface{}, args map[string]interface{}) *MCPResponse {
-	containerName, ok := args["container_name"].(string)
+func (s *MCPHTTPServer) callDeleteDropIn(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	unitName, ok := args["unit_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "container_name is required")
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+	dropInName, ok := args["dropin_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "dropin_name is required")
 	}
 
-	dockerManager, exists := s.managers[types.ServiceTypeDocker].(*managers.DockerManager)
-	if !exists {
-		return s.createToolErrorResponse(id, "Docker manager not available")
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	lines := 100
-	if l, ok := args["lines"]; ok {
-		if linesFloat, ok := l.(float64); ok {
-			lines = int(linesFloat)
-		}
+	dropIn, err := dropIns.RemoveDropIn(ctx, unitName, dropInName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to remove drop-in: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Drop-in %s removed and systemd reloaded.", dropIn.Path)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPHTTPServer) callCatUnit(ctx context.Context, id interface{}, args map[string]interface{}) *MCPResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	logs, err := dockerManager.GetLogs(containerName, lines)
+	content, err := dropIns.CatUnit(ctx, unitName)
 	if err != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to show unit configuration: %v", err))
 	}
 
-	resultText := fmt.Sprintf("Docker container '%s' logs (last %d lines):\n\n%s", containerName, lines, logs)
+	resultText := fmt.Sprintf("Effective configuration of %s:\n\n```ini\n%s```", unitName, content)
 	result := map[string]interface{}{
 		"content": []map[string]interface{}{
 			{"type": "text", "text": resultText},
@@ -677,6 +1942,22 @@ func (s *MCPHTTPServer) callGetDockerLogs(id interface{}, args map[string]interf
 	return s.createSuccessResponse(id, result)
 }
 
+// dropInManager returns the systemd drop-in manager with the scope argument
+// applied to ctx.
+func (s *MCPHTTPServer) dropInManager(ctx context.Context, args map[string]interface{}) (context.Context, managers.DropInManager, error) {
+	dropIns, exists := s.managers[types.ServiceTypeSystemd].(managers.DropInManager)
+	if !exists {
+		return ctx, nil, fmt.Errorf("systemd drop-ins not available")
+	}
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, dropIns, nil
+}
+
 func (s *MCPHTTPServer) handleListPrompts(req *MCPRequest) *MCPResponse {
 	prompts := []map[string]interface{}{
 		{
@@ -752,7 +2033,7 @@ func (s *MCPHTTPServer) getServiceManagementHelp(id interface{}, args map[string
 	case "docker":
 		content = "# Docker Container Management\n\nManage Docker containers as services. Key commands:\n- `docker start <container>` - Start a container\n- `docker stop <container>` - Stop a container\n- `docker restart <container>` - Restart a container\n- `docker update --restart=always <container>` - Auto-restart container\n- `docker ps -a` - List all containers\n- `docker logs <container>` - View container logs"
 	default:
-		content = "# Linux Service Management Guide\n\nThis MCP server supports managing services through multiple methods:\n\n## Supported Service Types\n1. **systemd** - Modern Linux distributions\n2. **System V init** - Traditional Linux distributions\n3. **Docker** - Container management\n\n## Available Operations\n- Start/Stop/Restart services\n- Enable/Disable services for boot\n- Get service status and information\n- List all available services\n- View Docker container logs\n\n## Usage\nUse the available tools to manage services. The server will automatically detect which service manager to use based on your system and the service name."
+		content = "# Linux Service Management Guide\n\nThis MCP server supports managing services through multiple methods:\n\n## Supported Service Types\n1. **systemd** - Modern Linux distributions\n2. **System V init** - Traditional Linux distributions\n3. **Docker** - Container management\n4. **Podman** - Rootless containers and pods (use `pod:<name>` for a pod)\n5. **OpenRC** - Alpine and Gentoo; enable/disable manage `default` runlevel membership\n6. **runit / s6** - Supervision trees; enable/disable link the service into the scan directory\n7. **supervisord** - Process groups over XML-RPC (use `group:process` for a single process)\n8. **script** - Services declared under `scripts:` in config.yaml with their own start/stop/status commands\n9. **native** - Processes declared under `native:` in config.yaml, spawned and restarted by this server\n10. **plugins** - External managers declared under `plugins:` in config.yaml; each plugin name is its own service type\n\n## Available Operations\n- Start/Stop/Restart services\n- Enable/Disable services for boot\n- Get service status and information\n- List all available services\n- View Docker container logs\n- Read any service's logs filtered by time, priority and pattern\n- Follow a service's log live, with entries sent as notifications\n- Show CPU, memory, IO and task usage from a service's cgroup\n- Show a service's process tree with the ports each process listens on\n- Find the service that owns a PID, a listening port or an open file\n- Check service health from HTTP, TCP and exec probes and container health checks\n- Review the restarts and escalations made by the watchdog\n- List systemd timers and start, stop, enable, disable or trigger them\n- Create, replace and delete systemd unit files\n- Manage drop-in overrides and show the effective unit configuration\n- Reload, kill, mask, reset-failed, pause and other extended service actions\n\n## Usage\nUse the available tools to manage services. The server will automatically detect which service manager to use based on your system and the service name."
 	}
 
 	result := map[string]interface{}{
@@ -840,7 +2121,7 @@ func (s *MCPHTTPServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // Helper methods
 
-func (s *MCPHTTPServer) getServiceManager(serviceName, serviceType string) (types.ServiceManager, error) {
+func (s *MCPHTTPServer) getServiceManager(ctx context.Context, serviceName, serviceType string) (types.ServiceManager, error) {
 	if serviceType != "" {
 		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
 			return manager, nil
@@ -850,7 +2131,7 @@ func (s *MCPHTTPServer) getServiceManager(serviceName, serviceType string) (type
 
 	// Auto-detect service type
 	for _, manager := range s.managers {
-		if _, err := manager.GetStatus(serviceName); err == nil {
+		if _, err := manager.GetStatus(ctx, serviceName); err == nil {
 			return manager, nil
 		}
 	}
@@ -883,10 +2164,23 @@ func (s *MCPHTTPServer) formatServicesOutput(services []types.ServiceInfo) strin
 	for serviceType, typeServices := range servicesByType {
 		result.WriteString(fmt.Sprintf("## %s Services\n", strings.Title(string(serviceType))))
 		for _, service := range typeServices {
-			result.WriteString(fmt.Sprintf("- **%s**: %s", service.Name, service.Status))
+			result.WriteString(fmt.Sprintf("- **%s**", service.Name))
+			if service.Scope != "" && service.Scope != "system" {
+				result.WriteString(fmt.Sprintf(" (%s)", service.Scope))
+			}
+			result.WriteString(fmt.Sprintf(": %s", service.Status))
 			if service.Description != "" {
 				result.WriteString(fmt.Sprintf(" - %s", service.Description))
 			}
+			if service.EnabledState != "" {
+				result.WriteString(fmt.Sprintf(" [unit file: %s]", service.EnabledState))
+			}
+			if len(service.Listen) > 0 {
+				result.WriteString(fmt.Sprintf(" [listen: %s]", strings.Join(service.Listen, ", ")))
+			}
+			if service.Health != nil {
+				result.WriteString(fmt.Sprintf(" [health: %s]", service.Health.Status))
+			}
 			result.WriteString("\n")
 		}
 		result.WriteString("\n")
@@ -899,6 +2193,12 @@ func (s *MCPHTTPServer) formatServiceInfo(info types.ServiceInfo) string {
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("**Service**: %s\n", info.Name))
 	result.WriteString(fmt.Sprintf("**Type**: %s\n", info.Type))
+	if info.Scope != "" {
+		result.WriteString(fmt.Sprintf("**Scope**: %s\n", info.Scope))
+	}
+	if info.UnitType != "" && info.UnitType != "service" {
+		result.WriteString(fmt.Sprintf("**Unit Type**: %s\n", info.UnitType))
+	}
 	result.WriteString(fmt.Sprintf("**Status**: %s\n", info.Status))
 
 	if info.Description != "" {
@@ -909,6 +2209,38 @@ func (s *MCPHTTPServer) formatServiceInfo(info types.ServiceInfo) string {
 		result.WriteString(fmt.Sprintf("**PID**: %d\n", info.PID))
 	}
 
+	for _, listen := range info.Listen {
+		result.WriteString(fmt.Sprintf("**Listen**: %s\n", listen))
+	}
+
+	if info.SubState != "" {
+		result.WriteString(fmt.Sprintf("**Sub State**: %s\n", info.SubState))
+	}
+	if info.LoadState != "" {
+		result.WriteString(fmt.Sprintf("**Load State**: %s\n", info.LoadState))
+	}
+	if info.EnabledState != "" {
+		result.WriteString(fmt.Sprintf("**Enabled**: %s\n", info.EnabledState))
+	}
+	if info.UnitPath != "" {
+		result.WriteString(fmt.Sprintf("**Unit Path**: %s\n", info.UnitPath))
+	}
+	if info.Restarts > 0 {
+		result.WriteString(fmt.Sprintf("**Restarts**: %d\n", info.Restarts))
+	}
+	if info.ExitCode != nil {
+		result.WriteString(fmt.Sprintf("**Exit Code**: %d\n", *info.ExitCode))
+	}
+	if info.OOMKilled {
+		result.WriteString("**OOM Killed**: yes\n")
+	}
+	if info.MemoryBytes > 0 {
+		result.WriteString(fmt.Sprintf("**Memory**: %.1f MiB\n", float64(info.MemoryBytes)/(1<<20)))
+	}
+	if info.ActiveSince != nil {
+		result.WriteString(fmt.Sprintf("**Active Since**: %s\n", info.ActiveSince.Format("2006-01-02 15:04:05")))
+	}
+
 	if info.Uptime > 0 {
 		result.WriteString(fmt.Sprintf("**Uptime**: %s\n", info.Uptime.String()))
 	}
@@ -917,6 +2249,10 @@ func (s *MCPHTTPServer) formatServiceInfo(info types.ServiceInfo) string {
 		result.WriteString(fmt.Sprintf("**Last Changed**: %s\n", info.LastChanged.Format("2006-01-02 15:04:05")))
 	}
 
+	if info.Health != nil {
+		result.WriteString(info.Health.String())
+	}
+
 	return result.String()
 }
 
@@ -931,6 +2267,13 @@ func (s *MCPHTTPServer) sendSSEMessage(client *SSEClient, eventType string, data
 		return
 	}
 
+	client.writeMu.Lock()
+	defer client.writeMu.Unlock()
+	// The client may have gone while waiting for the lock.
+	if client.Context.Err() != nil {
+		return
+	}
+
 	_, err = fmt.Fprintf(client.Writer, "event: %s\ndata: %s\n\n", eventType, jsonData)
 	if err != nil {
 		s.logger.Errorf("Failed to write SSE message: %v", err)
@@ -945,6 +2288,12 @@ func (s *MCPHTTPServer) sendSSEEndpoint(client *SSEClient, endpoint string) {
 		return
 	}
 
+	client.writeMu.Lock()
+	defer client.writeMu.Unlock()
+	if client.Context.Err() != nil {
+		return
+	}
+
 	_, err := fmt.Fprintf(client.Writer, "event: endpoint\ndata: %s\n\n", endpoint)
 	if err != nil {
 		s.logger.Errorf("Failed to write SSE endpoint: %v", err)
@@ -961,7 +2310,9 @@ func (s *MCPHTTPServer) cleanupClients() {
 	for range ticker.C {
 		s.clientMu.Lock()
 		for id, client := range s.clients {
-			if time.Since(client.LastSeen) > 10*time.Minute {
+			// Clients following logs are still listening even if they
+			// send nothing.
+			if time.Since(client.LastSeen) > 10*time.Minute && client.logs.active() == 0 {
 				client.Cancel()
 				delete(s.clients, id)
 				s.logger.Infof("Cleaned up stale client: %s", id)
@@ -971,6 +2322,34 @@ func (s *MCPHTTPServer) cleanupClients() {
 	}
 }
 
+func (s *MCPHTTPServer) formatTimersOutput(timers []types.TimerInfo) string {
+	if len(timers) == 0 {
+		return "No timers found."
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d timers:\n\n", len(timers)))
+	for _, timer := range timers {
+		result.WriteString(fmt.Sprintf("- **%s**", timer.Name))
+		if timer.Scope != "" && timer.Scope != "system" {
+			result.WriteString(fmt.Sprintf(" (%s)", timer.Scope))
+		}
+		result.WriteString(fmt.Sprintf(" → %s: %s", timer.Unit, timer.Status))
+		if !timer.NextElapse.IsZero() {
+			result.WriteString(fmt.Sprintf(", next: %s", timer.NextElapse.Format("2006-01-02 15:04:05")))
+		}
+		if !timer.LastTrigger.IsZero() {
+			result.WriteString(fmt.Sprintf(", last: %s", timer.LastTrigger.Format("2006-01-02 15:04:05")))
+		}
+		if timer.Description != "" {
+			result.WriteString(fmt.Sprintf(" - %s", timer.Description))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
 func (s *MCPHTTPServer) createSuccessResponse(id interface{}, result interface{}) *MCPResponse {
 	return &MCPResponse{
 		JSONRPC: "2.0",
@@ -1001,6 +2380,14 @@ func (s *MCPHTTPServer) createToolErrorResponse(id interface{}, message string)
 	return s.createSuccessResponse(id, result)
 }
 
+// Close releases the service managers, stopping any processes supervised
+// by this server.
+func (s *MCPHTTPServer) Close() error {
+	s.watchdog.Close()
+	s.health.Close()
+	return managers.CloseManagers(s.managers)
+}
+
 func (s *MCPHTTPServer) Start() error {
 	router := s.SetupRoutes()
 	address := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
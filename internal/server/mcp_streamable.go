@@ -14,12 +14,15 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"nucc.com/mcp_srv_mgr/internal/config"
+	"nucc.com/mcp_srv_mgr/internal/inflight"
 	"nucc.com/mcp_srv_mgr/internal/managers"
 	"nucc.com/mcp_srv_mgr/pkg/types"
 )
 
 type MCPStreamableServer struct {
 	managers map[types.ServiceType]types.ServiceManager
+	health   *managers.HealthMonitor
+	watchdog *managers.Watchdog
 	config   *config.Config
 	logger   *logrus.Logger
 	sessions map[string]*StreamableSession
@@ -35,7 +38,12 @@ type StreamableSession struct {
 	LastSeen   time.Time
 	Requests   chan *StreamableRequest
 	Responses  chan *StreamableResponse
+	// Notifications carries messages without an ID, such as log entries
+	// of follow_service_logs subscriptions.
+	Notifications chan *StreamableRequest
 	initialized bool
+	inflight   *inflight.Requests
+	logs       *logSubscriptions
 }
 
 type StreamableRequest struct {
@@ -60,34 +68,13 @@ type StreamableError struct {
 
 func NewMCPStreamableServer(cfg *config.Config, logger *logrus.Logger) *MCPStreamableServer {
 	server := &MCPStreamableServer{
-		managers: make(map[types.ServiceType]types.ServiceManager),
+		managers: managers.DetectManagers(cfg, logger),
+		health:   managers.NewHealthMonitor(cfg.Health, logger),
 		config:   cfg,
 		logger:   logger,
 		sessions: make(map[string]*StreamableSession),
 	}
 
-	// Initialize service managers
-	if managers.IsSystemdAvailable() {
-		server.managers[types.ServiceTypeSystemd] = managers.NewSystemdManager()
-		logger.Info("Systemd manager initialized")
-	} else {
-		logger.Debug("Systemd not available on this system")
-	}
-
-	if managers.IsSysVAvailable() {
-		server.managers[types.ServiceTypeSysV] = managers.NewSysVManager()
-		logger.Info("SysV manager initialized")
-	} else {
-		logger.Debug("SysV not available on this system")
-	}
-
-	if managers.IsDockerAvailable() {
-		server.managers[types.ServiceTypeDocker] = managers.NewDockerManager()
-		logger.Info("Docker manager initialized")
-	} else {
-		logger.Debug("Docker not available on this system")
-	}
-
 	if len(server.managers) == 0 {
 		logger.Warn("No service managers available")
 		// 添加一个mock管理器用于测试
@@ -100,6 +87,8 @@ func NewMCPStreamableServer(cfg *config.Config, logger *logrus.Logger) *MCPStrea
 	// Start cleanup routine for stale sessions
 	go server.cleanupSessions()
 
+	server.watchdog = managers.NewWatchdog(cfg, server.managers, server.health, logger)
+
 	return server
 }
 
@@ -176,6 +165,9 @@ func (s *MCPStreamableServer) handleStreamingUpgrade(w http.ResponseWriter, r *h
 		LastSeen:  time.Now(),
 		Requests:  make(chan *StreamableRequest, 10),
 		Responses: make(chan *StreamableResponse, 10),
+		Notifications: make(chan *StreamableRequest, 100),
+		inflight:  inflight.New(),
+		logs:      newLogSubscriptions(),
 	}
 
 	// Register session
@@ -239,8 +231,15 @@ func (s *MCPStreamableServer) handleSingleRequest(w http.ResponseWriter, r *http
 		return
 	}
 
+	// A single request has nothing in flight to cancel; it is aborted by
+	// closing the connection instead.
+	if req.Method == "notifications/cancelled" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	// Process request
-	response := s.processMCPRequest(&req)
+	response := s.processMCPRequest(r.Context(), &req)
 
 	// Send response
 	w.Header().Set("Content-Type", "application/json")
@@ -272,31 +271,35 @@ func (s *MCPStreamableServer) handleSessionStream(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Stream responses
+	// Stream responses and notifications
 	for {
+		var message interface{}
 		select {
 		case response := <-session.Responses:
-			jsonData, err := json.Marshal(response)
-			if err != nil {
-				s.logger.Errorf("Failed to marshal response: %v", err)
-				continue
-			}
-			
-			if _, err := w.Write(jsonData); err != nil {
-				s.logger.Errorf("Failed to write response: %v", err)
-				return
-			}
-			if _, err := w.Write([]byte("\n")); err != nil {
-				s.logger.Errorf("Failed to write newline: %v", err)
-				return
-			}
-			flusher.Flush()
-
+			message = response
+		case notification := <-session.Notifications:
+			message = notification
 		case <-session.Context.Done():
 			return
 		case <-r.Context().Done():
 			return
 		}
+
+		jsonData, err := json.Marshal(message)
+		if err != nil {
+			s.logger.Errorf("Failed to marshal message: %v", err)
+			continue
+		}
+
+		if _, err := w.Write(jsonData); err != nil {
+			s.logger.Errorf("Failed to write message: %v", err)
+			return
+		}
+		if _, err := w.Write([]byte("\n")); err != nil {
+			s.logger.Errorf("Failed to write newline: %v", err)
+			return
+		}
+		flusher.Flush()
 	}
 }
 
@@ -304,15 +307,9 @@ func (s *MCPStreamableServer) processSession(session *StreamableSession) {
 	for {
 		select {
 		case req := <-session.Requests:
-			response := s.processMCPRequest(req)
-			
-			select {
-			case session.Responses <- response:
-			case <-session.Context.Done():
-				return
-			default:
-				s.logger.Warn("Response channel full, dropping response")
-			}
+			// Requests run concurrently so a later notifications/cancelled
+			// can reach one that is still in progress.
+			go s.serveSessionRequest(session, req)
 
 		case <-session.Context.Done():
 			return
@@ -320,14 +317,50 @@ func (s *MCPStreamableServer) processSession(session *StreamableSession) {
 	}
 }
 
-func (s *MCPStreamableServer) processMCPRequest(req *StreamableRequest) *StreamableResponse {
+func (s *MCPStreamableServer) serveSessionRequest(session *StreamableSession, req *StreamableRequest) {
+	if req.Method == "notifications/cancelled" {
+		if id, ok := inflight.CancelledRequestID(req.Params); ok && (session.inflight.Cancel(id) || session.logs.cancelRequest(id)) {
+			s.logger.Infof("Request %v cancelled in session %s", id, session.ID)
+		}
+		return
+	}
+
+	ctx, done := session.inflight.Begin(context.Background(), session.Context, req.ID)
+	defer done()
+	ctx = withLogSubscriber(ctx, &logSubscriber{
+		session:       session.Context,
+		subscriptions: session.logs,
+		notify: func(ctx context.Context, method string, params interface{}) {
+			// Log entries wait for the stream to be read rather than being
+			// dropped; the subscription's context bounds the wait.
+			select {
+			case session.Notifications <- &StreamableRequest{JSONRPC: "2.0", Method: method, Params: params}:
+			case <-ctx.Done():
+			}
+		},
+	})
+
+	response := s.processMCPRequest(ctx, req)
+	if inflight.CancelledByClient(ctx) {
+		return
+	}
+
+	select {
+	case session.Responses <- response:
+	case <-session.Context.Done():
+	default:
+		s.logger.Warn("Response channel full, dropping response")
+	}
+}
+
+func (s *MCPStreamableServer) processMCPRequest(ctx context.Context, req *StreamableRequest) *StreamableResponse {
 	switch req.Method {
 	case "initialize":
 		return s.handleInitialize(req)
 	case "tools/list":
 		return s.handleListTools(req)
 	case "tools/call":
-		return s.handleCallTool(req)
+		return s.handleCallTool(ctx, req)
 	case "prompts/list":
 		return s.handleListPrompts(req)
 	case "prompts/get":
@@ -367,8 +400,20 @@ func (s *MCPStreamableServer) handleListTools(req *StreamableRequest) *Streamabl
 				"properties": map[string]interface{}{
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Filter services by type (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Filter services by type (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+					"unit_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd unit types to list, comma-separated: service, socket, target, mount, automount, path, or all (default: service)",
+					},
+					"installed": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also list installed unit files that are not loaded, e.g. disabled services that never ran, with their enablement state (systemd only, default: false)",
 					},
 				},
 			},
@@ -385,8 +430,12 @@ func (s *MCPStreamableServer) handleListTools(req *StreamableRequest) *Streamabl
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -404,8 +453,12 @@ func (s *MCPStreamableServer) handleListTools(req *StreamableRequest) *Streamabl
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -423,8 +476,12 @@ func (s *MCPStreamableServer) handleListTools(req *StreamableRequest) *Streamabl
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -442,8 +499,12 @@ func (s *MCPStreamableServer) handleListTools(req *StreamableRequest) *Streamabl
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -461,8 +522,12 @@ func (s *MCPStreamableServer) handleListTools(req *StreamableRequest) *Streamabl
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -480,8 +545,12 @@ func (s *MCPStreamableServer) handleListTools(req *StreamableRequest) *Streamabl
 					},
 					"service_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of service (systemd, sysv, docker)",
-						"enum":        []string{"systemd", "sysv", "docker"},
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
 					},
 				},
 				"required": []string{"service_name"},
@@ -505,80 +574,1119 @@ func (s *MCPStreamableServer) handleListTools(req *StreamableRequest) *Streamabl
 				"required": []string{"container_name"},
 			},
 		},
+		{
+			"name":        "get_service_logs",
+			"description": "Get log entries of any service: the journal for systemd units, log files for SysV services, container output for Docker",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+					"since": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries at or after this time: RFC 3339, \"2006-01-02 15:04:05\", or a duration ago such as 30m",
+					},
+					"until": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries at or before this time, in the same formats as since",
+					},
+					"priority": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries of this syslog level or more important: emerg, alert, crit, err, warning, notice, info, debug or 0-7",
+					},
+					"grep": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries whose message matches this regular expression",
+					},
+					"lines": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of most recent entries to return (default: 100)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			"name":        "follow_service_logs",
+			"description": "Follow a service's log like tail -f: the most recent entries, then new ones as they are written, sent as notifications/service_log messages until unfollow_service_logs is called, the call is cancelled or the session closes",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+					"since": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries at or after this time: RFC 3339, \"2006-01-02 15:04:05\", or a duration ago such as 30m",
+					},
+					"priority": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries of this syslog level or more important: emerg, alert, crit, err, warning, notice, info, debug or 0-7",
+					},
+					"grep": map[string]interface{}{
+						"type":        "string",
+						"description": "Only entries whose message matches this regular expression",
+					},
+					"lines": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of recent entries to send before following (default: 100)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			"name":        "unfollow_service_logs",
+			"description": "Stop a log subscription started with follow_service_logs",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"subscription_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Subscription ID returned by follow_service_logs",
+					},
+				},
+				"required": []string{"subscription_id"},
+			},
+		},
+		{
+			"name":        "get_service_stats",
+			"description": "Get a service's resource usage: CPU time, memory current and peak, IO bytes, task count and memory events, read from the systemd unit's cgroup or from the container engine",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			"name":        "get_service_processes",
+			"description": "List a service's processes as a tree with command line, user, resident memory, open file count and the TCP/UDP ports each one listens on, found through the systemd unit's cgroup, the container's processes or the SysV pidfile",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			"name":        "find_service_owner",
+			"description": "Find the service that owns a process, a listening port or a file, e.g. which service holds port 8080 or keeps a log file open. Give exactly one of pid, port and path; the result is the owning systemd unit, Docker or Podman container or SysV script with its status",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"pid": map[string]interface{}{
+						"type":        "integer",
+						"description": "Process ID",
+					},
+					"port": map[string]interface{}{
+						"type":        "integer",
+						"description": "Listening TCP or UDP port",
+					},
+					"protocol": map[string]interface{}{
+						"type":        "string",
+						"description": "Restrict a port lookup to tcp or udp (default: both)",
+						"enum":        []string{"tcp", "udp"},
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Absolute path of a file, directory or executable the process has open",
+					},
+				},
+			},
+		},
+		{
+			"name":        "get_service_health",
+			"description": "Show the health of a service from the HTTP, TCP and exec probes declared under health: in config.yaml and, for containers, the engine's HEALTHCHECK, with each probe's status, failure streak and last error. Without service_name, lists every configured probe",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service (default: all configured probes)",
+					},
+					"service_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+						"enum":        managers.ServiceTypeNames(s.config),
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+			},
+		},
+		{
+			"name":        "get_watchdog_status",
+			"description": "Show the services the watchdog watches with their restart budget, and the restarts and escalations it made on its own, newest first. These automatic actions are listed separately from actions requested through the API",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Only show actions on this service",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of actions to show (default: 20)",
+					},
+				},
+			},
+		},
+		{
+			"name":        "list_timers",
+			"description": "List systemd timers with their next and last elapse times and the unit each one activates",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+			},
+		},
+		{
+			"name":        "manage_timer",
+			"description": "Start, stop, enable or disable a systemd timer, or trigger the unit it activates now",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"timer_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the timer, with or without the .timer suffix",
+					},
+					"action": map[string]interface{}{
+						"type":        "string",
+						"description": "Action to perform",
+						"enum":        []string{"start", "stop", "enable", "disable", "trigger"},
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+					},
+				},
+				"required": []string{"timer_name", "action"},
+			},
+		},
+		{
+			"name":        "get_unit_file",
+			"description": "Read a systemd unit file installed through this server",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+		{
+			"name":        "write_unit_file",
+			"description": "Create or replace a systemd unit file from raw content or a service spec; the file is verified, the previous version backed up, and systemd reloaded",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Raw unit file text (mutually exclusive with spec)",
+					},
+					"spec": map[string]interface{}{
+						"type":        "object",
+						"description": "Service unit spec (mutually exclusive with content)",
+						"properties": map[string]interface{}{
+							"description":       map[string]interface{}{"type": "string"},
+							"type":              map[string]interface{}{"type": "string", "description": "Service type, e.g. simple, exec, forking, oneshot"},
+							"exec_start":        map[string]interface{}{"type": "string"},
+							"user":              map[string]interface{}{"type": "string"},
+							"group":             map[string]interface{}{"type": "string"},
+							"working_directory": map[string]interface{}{"type": "string"},
+							"environment":       map[string]interface{}{"type": "object", "description": "Environment variables", "additionalProperties": map[string]interface{}{"type": "string"}},
+							"restart":           map[string]interface{}{"type": "string", "enum": []string{"no", "always", "on-success", "on-failure", "on-abnormal", "on-abort", "on-watchdog"}},
+							"wanted_by":         map[string]interface{}{"type": "string", "description": "Install target, e.g. multi-user.target"},
+						},
+						"required": []string{"exec_start"},
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+		{
+			"name":        "delete_unit_file",
+			"description": "Stop and disable a systemd unit, then remove its unit file keeping a backup, and reload systemd",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+		{
+			"name":        "list_dropins",
+			"description": "List the drop-in overrides of a systemd unit with their content",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+		{
+			"name":        "write_dropin",
+			"description": "Create or replace a drop-in override (<unit>.d/<name>.conf) and reload systemd",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"dropin_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Drop-in file name; .conf is added if missing",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Drop-in text, e.g. \"[Service]\\nEnvironment=DEBUG=1\"",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name", "dropin_name", "content"},
+			},
+		},
+		{
+			"name":        "delete_dropin",
+			"description": "Delete a drop-in override and reload systemd",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"dropin_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Drop-in file name; .conf is added if missing",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name", "dropin_name"},
+			},
+		},
+		{
+			"name":        "cat_unit",
+			"description": "Show the effective configuration of a systemd unit: its unit file followed by all drop-ins, like systemctl cat",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"unit_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Unit name; a bare name means a .service unit",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "Systemd instance: system or user (default: the configured scope)",
+					},
+				},
+				"required": []string{"unit_name"},
+			},
+		},
+	}
+
+	for _, action := range managers.ServiceActions {
+		properties := map[string]interface{}{
+			"service_name": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the service",
+			},
+			"service_type": map[string]interface{}{
+				"type":        "string",
+				"description": "Type of service (systemd, sysv, openrc, runit, s6, docker, podman, supervisor, script, native)",
+				"enum":        managers.ServiceTypeNames(s.config),
+			},
+			"scope": map[string]interface{}{
+				"type":        "string",
+				"description": "Systemd instance: system, user, or user:<name> (default: the configured scope)",
+			},
+		}
+		if action.Name == "kill" {
+			properties["signal"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Signal to send, e.g. SIGHUP, HUP or 1 (default: SIGTERM)",
+			}
+		}
+		tools = append(tools, map[string]interface{}{
+			"name":        managers.ServiceActionTool(action.Name),
+			"description": action.Description,
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": properties,
+				"required":   []string{"service_name"},
+			},
+		})
+	}
+
+	result := map[string]interface{}{
+		"tools": tools,
+	}
+
+	return s.createSuccessResponse(req.ID, result)
+}
+
+func (s *MCPStreamableServer) handleCallTool(ctx context.Context, req *StreamableRequest) *StreamableResponse {
+	params, ok := req.Params.(map[string]interface{})
+	if !ok {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", nil)
+	}
+
+	toolName, ok := params["name"].(string)
+	if !ok {
+		return s.createErrorResponse(req.ID, -32602, "Missing tool name", nil)
+	}
+
+	arguments, _ := params["arguments"].(map[string]interface{})
+
+	switch toolName {
+	case "list_services":
+		return s.callListServices(ctx, req.ID, arguments)
+	case "get_service_status":
+		return s.callGetServiceStatus(ctx, req.ID, arguments)
+	case "start_service":
+		return s.callStartService(ctx, req.ID, arguments)
+	case "stop_service":
+		return s.callStopService(ctx, req.ID, arguments)
+	case "restart_service":
+		return s.callRestartService(ctx, req.ID, arguments)
+	case "enable_service":
+		return s.callEnableService(ctx, req.ID, arguments)
+	case "disable_service":
+		return s.callDisableService(ctx, req.ID, arguments)
+	case "get_docker_logs":
+		return s.callGetDockerLogs(ctx, req.ID, arguments)
+	case "get_service_logs":
+		return s.callGetServiceLogs(ctx, req.ID, arguments)
+	case "follow_service_logs":
+		return s.callFollowServiceLogs(ctx, req.ID, arguments)
+	case "unfollow_service_logs":
+		return s.callUnfollowServiceLogs(ctx, req.ID, arguments)
+	case "get_service_stats":
+		return s.callGetServiceStats(ctx, req.ID, arguments)
+	case "get_service_processes":
+		return s.callGetServiceProcesses(ctx, req.ID, arguments)
+	case "find_service_owner":
+		return s.callFindServiceOwner(ctx, req.ID, arguments)
+	case "get_service_health":
+		return s.callGetServiceHealth(ctx, req.ID, arguments)
+	case "get_watchdog_status":
+		return s.callGetWatchdogStatus(ctx, req.ID, arguments)
+	case "list_timers":
+		return s.callListTimers(ctx, req.ID, arguments)
+	case "manage_timer":
+		return s.callManageTimer(ctx, req.ID, arguments)
+	case "get_unit_file":
+		return s.callGetUnitFile(ctx, req.ID, arguments)
+	case "write_unit_file":
+		return s.callWriteUnitFile(ctx, req.ID, arguments)
+	case "delete_unit_file":
+		return s.callDeleteUnitFile(ctx, req.ID, arguments)
+	case "list_dropins":
+		return s.callListDropIns(ctx, req.ID, arguments)
+	case "write_dropin":
+		return s.callWriteDropIn(ctx, req.ID, arguments)
+	case "delete_dropin":
+		return s.callDeleteDropIn(ctx, req.ID, arguments)
+	case "cat_unit":
+		return s.callCatUnit(ctx, req.ID, arguments)
+	default:
+		if action, ok := managers.ServiceActionForTool(toolName); ok {
+			return s.callServiceOperation(ctx, req.ID, arguments, action)
+		}
+		return s.createErrorResponse(req.ID, -32601, "Tool not found", nil)
+	}
+}
+
+func (s *MCPStreamableServer) callListServices(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	var allServices []types.ServiceInfo
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+	unitType, _ := args["unit_type"].(string)
+	ctx, serviceType, err = managers.ApplyUnitType(ctx, serviceType, unitType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+	installed, _ := args["installed"].(bool)
+	ctx, serviceType, err = managers.ApplyInstalledUnits(ctx, serviceType, installed)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	if serviceType != "" {
+		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
+			services, err := manager.ListServices(ctx)
+			if err != nil {
+				return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list %s services: %v", serviceType, err))
+			}
+			allServices = services
+		} else {
+			return s.createToolErrorResponse(id, fmt.Sprintf("Unsupported service type: %s", serviceType))
+		}
+	} else {
+		for _, manager := range s.managers {
+			services, err := manager.ListServices(ctx)
+			if err != nil {
+				s.logger.Warnf("Failed to list services from manager: %v", err)
+				continue
+			}
+			allServices = append(allServices, services...)
+		}
+	}
+
+	s.health.AnnotateAll(allServices)
+
+	resultText := s.formatServicesOutput(allServices)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callGetServiceStatus(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	info, err := manager.GetStatus(ctx, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+	}
+	s.health.Annotate(&info)
+
+	resultText := s.formatServiceInfo(info)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callStartService(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	return s.callServiceOperation(ctx, id, args, "start")
+}
+
+func (s *MCPStreamableServer) callStopService(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	return s.callServiceOperation(ctx, id, args, "stop")
+}
+
+func (s *MCPStreamableServer) callRestartService(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	return s.callServiceOperation(ctx, id, args, "restart")
+}
+
+func (s *MCPStreamableServer) callEnableService(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	return s.callServiceOperation(ctx, id, args, "enable")
+}
+
+func (s *MCPStreamableServer) callDisableService(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	return s.callServiceOperation(ctx, id, args, "disable")
+}
+
+func (s *MCPStreamableServer) callServiceOperation(ctx context.Context, id interface{}, args map[string]interface{}, operation string) *StreamableResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, operation)
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	signalName, _ := args["signal"].(string)
+	signal, err := managers.ParseSignal(signalName)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	if operationErr := managers.PerformAction(ctx, manager, serviceName, operation, signal); operationErr != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s service: %v", operation, operationErr))
+	}
+
+	info, _ := manager.GetStatus(ctx, serviceName)
+	resultText := fmt.Sprintf("%s.\n\n%s", managers.ActionMessage(serviceName, operation), s.formatServiceInfo(info))
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callGetServiceLogs(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	since, _ := args["since"].(string)
+	until, _ := args["until"].(string)
+	priority, _ := args["priority"].(string)
+	grep, _ := args["grep"].(string)
+	lines := 0
+	if l, ok := args["lines"].(float64); ok {
+		lines = int(l)
+	}
+	query, err := managers.ParseLogQuery(since, until, priority, grep, lines)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "logs")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err = managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	entries, err := managers.ReadServiceLogs(ctx, manager, serviceName, query)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
+	}
+
+	var resultText string
+	if len(entries) == 0 {
+		resultText = fmt.Sprintf("No log entries of service %s match the query.", serviceName)
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Logs of service %s (%d entries):\n\n", serviceName, len(entries))
+		for _, entry := range entries {
+			b.WriteString(entry.String())
+			b.WriteByte('\n')
+		}
+		resultText = b.String()
+	}
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+// callFollowServiceLogs starts a log subscription for the client or session
+// the request came from and returns its ID at once; the entries follow as
+// notifications.
+func (s *MCPStreamableServer) callFollowServiceLogs(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	sub, ok := logSubscriberFrom(ctx)
+	if !ok {
+		return s.createToolErrorResponse(id, "Following logs requires a session that can receive notifications")
+	}
+
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	since, _ := args["since"].(string)
+	priority, _ := args["priority"].(string)
+	grep, _ := args["grep"].(string)
+	lines := 0
+	if l, ok := args["lines"].(float64); ok {
+		lines = int(l)
+	}
+	query, err := managers.ParseLogQuery(since, "", priority, grep, lines)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	lookupCtx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	lookupCtx, serviceType, err = managers.ApplyScope(lookupCtx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(lookupCtx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+	if err := managers.CheckFollowLogs(manager, query); err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to follow logs: %v", err))
+	}
+
+	subscriptionID := sub.followLogs(lookupCtx, id, manager, serviceName, query)
+	s.logger.Infof("Following logs of service %s as %s", serviceName, subscriptionID)
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": fmt.Sprintf("Following logs of service %s as subscription %s. Entries are sent as %s notifications until unfollow_service_logs is called.", serviceName, subscriptionID, logNotificationMethod),
+			},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callUnfollowServiceLogs(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	subscriptionID, ok := args["subscription_id"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "subscription_id is required")
+	}
+
+	sub, ok := logSubscriberFrom(ctx)
+	if !ok || !sub.subscriptions.stop(subscriptionID) {
+		return s.createToolErrorResponse(id, fmt.Sprintf("No log subscription %s", subscriptionID))
+	}
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": fmt.Sprintf("Stopped log subscription %s", subscriptionID)},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callGetServiceStats(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "stats")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	stats, err := managers.ReadServiceStats(ctx, manager, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get stats: %v", err))
+	}
+
+	resultText := fmt.Sprintf("**Service**: %s\n%s", serviceName, stats.String())
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callGetServiceProcesses(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	serviceName, ok := args["service_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "service_name is required")
+	}
+
+	var serviceType string
+	if st, ok := args["service_type"]; ok {
+		serviceType = st.(string)
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "processes")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	processes, err := managers.ReadServiceProcesses(ctx, manager, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get processes: %v", err))
+	}
+
+	resultText := fmt.Sprintf("**Service**: %s\n**Processes**: %d\n\n%s", serviceName, len(processes), types.ProcessTree(processes))
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callFindServiceOwner(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	var pid, port int
+	if p, ok := args["pid"].(float64); ok {
+		pid = int(p)
+	}
+	if p, ok := args["port"].(float64); ok {
+		port = int(p)
+	}
+	protocol, _ := args["protocol"].(string)
+	path, _ := args["path"].(string)
+
+	query, err := managers.ParseOwnerQuery(pid, port, protocol, path)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	owner, err := managers.LocateOwner(ctx, query)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, serviceType, err := managers.ApplyScope(ctx, string(owner.Type), owner.Scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, owner.Name, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Process %d belongs to %s service %s: %v", owner.PID, owner.Type, owner.Name, err))
+	}
+
+	info, err := manager.GetStatus(ctx, owner.Name)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Process %d belongs to %s service %s, failed to get its status: %v", owner.PID, owner.Type, owner.Name, err))
+	}
+	s.health.Annotate(&info)
+
+	resultText := fmt.Sprintf("**Process**: %d\n**Service**: %s\n**Type**: %s\n", owner.PID, info.Name, info.Type)
+	if owner.Scope != "" {
+		resultText += fmt.Sprintf("**Scope**: %s\n", owner.Scope)
+	}
+	if owner.CGroup != "" {
+		resultText += fmt.Sprintf("**CGroup**: %s\n", owner.CGroup)
+	}
+	resultText += fmt.Sprintf("**Status**: %s\n", info.Status)
+	if info.Health != nil {
+		resultText += fmt.Sprintf("**Health**: %s\n", info.Health.Status)
+	}
+	if info.Description != "" {
+		resultText += fmt.Sprintf("**Description**: %s\n", info.Description)
+	}
+
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callGetServiceHealth(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	serviceName, _ := args["service_name"].(string)
+	if serviceName == "" {
+		results := s.health.Results()
+		resultText := "No health checks configured."
+		if len(results) > 0 {
+			var b strings.Builder
+			b.WriteString(fmt.Sprintf("Found %d health probes:\n\n", len(results)))
+			for _, probe := range results {
+				b.WriteString(fmt.Sprintf("- **%s** %s\n", probe.Service, probe))
+			}
+			resultText = b.String()
+		}
+		result := map[string]interface{}{
+			"content": []map[string]interface{}{
+				{"type": "text", "text": resultText},
+			},
+		}
+		return s.createSuccessResponse(id, result)
+	}
+
+	serviceType, _ := args["service_type"].(string)
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	scope, _ := args["scope"].(string)
+	ctx, serviceType, err := managers.ApplyScope(ctx, serviceType, scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	manager, err := s.getServiceManager(ctx, serviceName, serviceType)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	info, err := manager.GetStatus(ctx, serviceName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+	}
+	s.health.Annotate(&info)
+	if info.Health == nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("No health checks for service %s", serviceName))
+	}
+
+	resultText := fmt.Sprintf("**Service**: %s\n**Status**: %s\n", info.Name, info.Status) + info.Health.String()
+	result := map[string]interface{}{
+			"content": []map[string]interface{}{
+				{"type": "text", "text": resultText},
+			},
+		}
+		return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callGetWatchdogStatus(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	serviceName, _ := args["service_name"].(string)
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	var b strings.Builder
+	b.WriteString("## Watched services\n")
+	states := s.watchdog.States()
+	if len(states) == 0 {
+		b.WriteString("No services are watched; declare policies under watchdog: in config.yaml.\n")
+	}
+	for _, state := range states {
+		b.WriteString(fmt.Sprintf("- %s\n", state))
+	}
+	b.WriteString("\n## Automatic actions\n")
+	actions := s.watchdog.Actions(serviceName, limit)
+	if len(actions) == 0 {
+		b.WriteString("No automatic actions recorded.\n")
+	}
+	for _, action := range actions {
+		b.WriteString(fmt.Sprintf("- %s\n", action))
 	}
 
+	resultText := b.String()
 	result := map[string]interface{}{
-		"tools": tools,
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
 	}
-
-	return s.createSuccessResponse(req.ID, result)
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPStreamableServer) handleCallTool(req *StreamableRequest) *StreamableResponse {
-	params, ok := req.Params.(map[string]interface{})
+func (s *MCPStreamableServer) callGetDockerLogs(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	containerName, ok := args["container_name"].(string)
 	if !ok {
-		return s.createErrorResponse(req.ID, -32602, "Invalid params", nil)
+		return s.createToolErrorResponse(id, "container_name is required")
 	}
 
-	toolName, ok := params["name"].(string)
-	if !ok {
-		return s.createErrorResponse(req.ID, -32602, "Missing tool name", nil)
+	dockerManager, exists := s.managers[types.ServiceTypeDocker].(managers.DockerContainerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Docker manager not available")
 	}
 
-	arguments, _ := params["arguments"].(map[string]interface{})
+	lines := 100
+	if l, ok := args["lines"]; ok {
+		if linesFloat, ok := l.(float64); ok {
+			lines = int(linesFloat)
+		}
+	}
 
-	switch toolName {
-	case "list_services":
-		return s.callListServices(req.ID, arguments)
-	case "get_service_status":
-		return s.callGetServiceStatus(req.ID, arguments)
-	case "start_service":
-		return s.callStartService(req.ID, arguments)
-	case "stop_service":
-		return s.callStopService(req.ID, arguments)
-	case "restart_service":
-		return s.callRestartService(req.ID, arguments)
-	case "enable_service":
-		return s.callEnableService(req.ID, arguments)
-	case "disable_service":
-		return s.callDisableService(req.ID, arguments)
-	case "get_docker_logs":
-		return s.callGetDockerLogs(req.ID, arguments)
-	default:
-		return s.createErrorResponse(req.ID, -32601, "Tool not found", nil)
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "logs")
+	defer cancel()
+
+	logs, err := dockerManager.GetLogs(ctx, containerName, lines)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Docker container '%s' logs (last %d lines):\n\n%s", containerName, lines, logs)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
 	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPStreamableServer) callListServices(id interface{}, args map[string]interface{}) *StreamableResponse {
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
+func (s *MCPStreamableServer) callListTimers(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	timerManager, exists := s.managers[types.ServiceTypeSystemd].(managers.TimerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Systemd timers not available")
 	}
 
-	var allServices []types.ServiceInfo
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
 
-	if serviceType != "" {
-		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
-			services, err := manager.ListServices()
-			if err != nil {
-				return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list %s services: %v", serviceType, err))
-			}
-			allServices = services
-		} else {
-			return s.createToolErrorResponse(id, fmt.Sprintf("Unsupported service type: %s", serviceType))
-		}
-	} else {
-		for _, manager := range s.managers {
-			services, err := manager.ListServices()
-			if err != nil {
-				s.logger.Warnf("Failed to list services from manager: %v", err)
-				continue
-			}
-			allServices = append(allServices, services...)
-		}
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	resultText := s.formatServicesOutput(allServices)
+	timers, err := timerManager.ListTimers(ctx)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list timers: %v", err))
+	}
+
+	resultText := s.formatTimersOutput(timers)
 	result := map[string]interface{}{
 		"content": []map[string]interface{}{
 			{"type": "text", "text": resultText},
@@ -587,28 +1695,44 @@ func (s *MCPStreamableServer) callListServices(id interface{}, args map[string]i
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPStreamableServer) callGetServiceStatus(id interface{}, args map[string]interface{}) *StreamableResponse {
-	serviceName, ok := args["service_name"].(string)
+func (s *MCPStreamableServer) callManageTimer(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	timerName, ok := args["timer_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "service_name is required")
+		return s.createToolErrorResponse(id, "timer_name is required")
+	}
+	action, ok := args["action"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "action is required")
 	}
 
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
+	timerManager, exists := s.managers[types.ServiceTypeSystemd].(managers.TimerManager)
+	if !exists {
+		return s.createToolErrorResponse(id, "Systemd timers not available")
+	}
+
+	operation := action
+	if action == "trigger" {
+		operation = "start"
 	}
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, operation)
+	defer cancel()
 
-	manager, err := s.getServiceManager(serviceName, serviceType)
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
 	if err != nil {
 		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	info, err := manager.GetStatus(serviceName)
+	if err := managers.ManageTimer(ctx, timerManager, timerName, action); err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s timer: %v", action, err))
+	}
+
+	timer, err := timerManager.GetTimer(ctx, timerName)
 	if err != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get service status: %v", err))
+		return s.createToolErrorResponse(id, fmt.Sprintf("Timer %s completed but failed to get status: %v", action, err))
 	}
 
-	resultText := s.formatServiceInfo(info)
+	resultText := fmt.Sprintf("Timer %s: %s completed successfully.\n\n%s", timerName, action, s.formatTimersOutput([]types.TimerInfo{timer}))
 	result := map[string]interface{}{
 		"content": []map[string]interface{}{
 			{"type": "text", "text": resultText},
@@ -617,63 +1741,186 @@ func (s *MCPStreamableServer) callGetServiceStatus(id interface{}, args map[stri
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPStreamableServer) callStartService(id interface{}, args map[string]interface{}) *StreamableResponse {
-	return s.callServiceOperation(id, args, "start")
-}
+func (s *MCPStreamableServer) callGetUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
 
-func (s *MCPStreamableServer) callStopService(id interface{}, args map[string]interface{}) *StreamableResponse {
-	return s.callServiceOperation(id, args, "stop")
+	unitFile, err := unitFiles.GetUnitFile(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to read unit file: %v", err))
+	}
+
+	resultText := fmt.Sprintf("**%s**\n\n```ini\n%s```", unitFile.Path, unitFile.Content)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPStreamableServer) callRestartService(id interface{}, args map[string]interface{}) *StreamableResponse {
-	return s.callServiceOperation(id, args, "restart")
+func (s *MCPStreamableServer) callWriteUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+
+	var request types.UnitFileRequest
+	request.Content, _ = args["content"].(string)
+	if spec, ok := args["spec"]; ok {
+		data, _ := json.Marshal(spec)
+		if err := json.Unmarshal(data, &request.Spec); err != nil {
+			return s.createToolErrorResponse(id, fmt.Sprintf("Invalid spec: %v", err))
+		}
+	}
+	content, err := managers.UnitFileContent(request)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	unitFile, err := unitFiles.WriteUnitFile(ctx, unitName, content)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to write unit file: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Unit file %s installed and systemd reloaded.", unitFile.Path)
+	if unitFile.Backup != "" {
+		resultText += fmt.Sprintf("\nPrevious version saved to %s.", unitFile.Backup)
+	}
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPStreamableServer) callEnableService(id interface{}, args map[string]interface{}) *StreamableResponse {
-	return s.callServiceOperation(id, args, "enable")
+func (s *MCPStreamableServer) callDeleteUnitFile(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, unitFiles, err := s.unitFileManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
+	}
+
+	unitFile, err := unitFiles.RemoveUnitFile(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to remove unit file: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Unit file %s removed and systemd reloaded.\nBackup saved to %s.", unitFile.Path, unitFile.Backup)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPStreamableServer) callDisableService(id interface{}, args map[string]interface{}) *StreamableResponse {
-	return s.callServiceOperation(id, args, "disable")
+// unitFileManager returns the systemd unit file manager with the scope
+// argument applied to ctx.
+func (s *MCPStreamableServer) unitFileManager(ctx context.Context, args map[string]interface{}) (context.Context, managers.UnitFileManager, error) {
+	unitFiles, exists := s.managers[types.ServiceTypeSystemd].(managers.UnitFileManager)
+	if !exists {
+		return ctx, nil, fmt.Errorf("systemd unit files not available")
+	}
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, unitFiles, nil
 }
 
-func (s *MCPStreamableServer) callServiceOperation(id interface{}, args map[string]interface{}, operation string) *StreamableResponse {
-	serviceName, ok := args["service_name"].(string)
+func (s *MCPStreamableServer) callListDropIns(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	unitName, ok := args["unit_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "service_name is required")
+		return s.createToolErrorResponse(id, "unit_name is required")
 	}
 
-	var serviceType string
-	if st, ok := args["service_type"]; ok {
-		serviceType = st.(string)
-	}
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "list")
+	defer cancel()
 
-	manager, err := s.getServiceManager(serviceName, serviceType)
+	ctx, dropIns, err := s.dropInManager(ctx, args)
 	if err != nil {
 		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	var operationErr error
-	switch operation {
-	case "start":
-		operationErr = manager.Start(serviceName)
-	case "stop":
-		operationErr = manager.Stop(serviceName)
-	case "restart":
-		operationErr = manager.Restart(serviceName)
-	case "enable":
-		operationErr = manager.Enable(serviceName)
-	case "disable":
-		operationErr = manager.Disable(serviceName)
+	list, err := dropIns.ListDropIns(ctx, unitName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to list drop-ins: %v", err))
 	}
 
-	if operationErr != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to %s service: %v", operation, operationErr))
+	var resultText strings.Builder
+	if len(list) == 0 {
+		resultText.WriteString(fmt.Sprintf("No drop-ins found for %s.", unitName))
+	} else {
+		resultText.WriteString(fmt.Sprintf("Found %d drop-ins:\n", len(list)))
+		for _, dropIn := range list {
+			resultText.WriteString(fmt.Sprintf("\n**%s**\n```ini\n%s```\n", dropIn.Path, dropIn.Content))
+		}
+	}
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText.String()},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callWriteDropIn(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+	dropInName, ok := args["dropin_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "dropin_name is required")
+	}
+	content, ok := args["content"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "content is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	info, _ := manager.GetStatus(serviceName)
-	resultText := fmt.Sprintf("Service %s %sed successfully.\n\n%s", serviceName, operation, s.formatServiceInfo(info))
+	dropIn, err := dropIns.WriteDropIn(ctx, unitName, dropInName, content)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to write drop-in: %v", err))
+	}
 
+	resultText := fmt.Sprintf("Drop-in %s installed and systemd reloaded. Restart %s to apply it.", dropIn.Path, dropIn.Unit)
 	result := map[string]interface{}{
 		"content": []map[string]interface{}{
 			{"type": "text", "text": resultText},
@@ -682,30 +1929,58 @@ func (s *MCPStreamableServer) callServiceOperation(id interface{}, args map[stri
 	return s.createSuccessResponse(id, result)
 }
 
-func (s *MCPStreamableServer) callGetDockerLogs(id interface{}, args map[string]interface{}) *StreamableResponse {
-	containerName, ok := args["container_name"].(string)
+func (s *MCPStreamableServer) callDeleteDropIn(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	unitName, ok := args["unit_name"].(string)
 	if !ok {
-		return s.createToolErrorResponse(id, "container_name is required")
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+	dropInName, ok := args["dropin_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "dropin_name is required")
 	}
 
-	dockerManager, exists := s.managers[types.ServiceTypeDocker].(*managers.DockerManager)
-	if !exists {
-		return s.createToolErrorResponse(id, "Docker manager not available")
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "unit_file")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	lines := 100
-	if l, ok := args["lines"]; ok {
-		if linesFloat, ok := l.(float64); ok {
-			lines = int(linesFloat)
-		}
+	dropIn, err := dropIns.RemoveDropIn(ctx, unitName, dropInName)
+	if err != nil {
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to remove drop-in: %v", err))
+	}
+
+	resultText := fmt.Sprintf("Drop-in %s removed and systemd reloaded.", dropIn.Path)
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": resultText},
+		},
+	}
+	return s.createSuccessResponse(id, result)
+}
+
+func (s *MCPStreamableServer) callCatUnit(ctx context.Context, id interface{}, args map[string]interface{}) *StreamableResponse {
+	unitName, ok := args["unit_name"].(string)
+	if !ok {
+		return s.createToolErrorResponse(id, "unit_name is required")
+	}
+
+	ctx, cancel := s.config.Timeouts.WithTimeout(ctx, "status")
+	defer cancel()
+
+	ctx, dropIns, err := s.dropInManager(ctx, args)
+	if err != nil {
+		return s.createToolErrorResponse(id, err.Error())
 	}
 
-	logs, err := dockerManager.GetLogs(containerName, lines)
+	content, err := dropIns.CatUnit(ctx, unitName)
 	if err != nil {
-		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to get logs: %v", err))
+		return s.createToolErrorResponse(id, fmt.Sprintf("Failed to show unit configuration: %v", err))
 	}
 
-	resultText := fmt.Sprintf("Docker container '%s' logs (last %d lines):\n\n%s", containerName, lines, logs)
+	resultText := fmt.Sprintf("Effective configuration of %s:\n\n```ini\n%s```", unitName, content)
 	result := map[string]interface{}{
 		"content": []map[string]interface{}{
 			{"type": "text", "text": resultText},
@@ -714,6 +1989,22 @@ func (s *MCPStreamableServer) callGetDockerLogs(id interface{}, args map[string]
 	return s.createSuccessResponse(id, result)
 }
 
+// dropInManager returns the systemd drop-in manager with the scope argument
+// applied to ctx.
+func (s *MCPStreamableServer) dropInManager(ctx context.Context, args map[string]interface{}) (context.Context, managers.DropInManager, error) {
+	dropIns, exists := s.managers[types.ServiceTypeSystemd].(managers.DropInManager)
+	if !exists {
+		return ctx, nil, fmt.Errorf("systemd drop-ins not available")
+	}
+
+	scope, _ := args["scope"].(string)
+	ctx, _, err := managers.ApplyScope(ctx, string(types.ServiceTypeSystemd), scope)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, dropIns, nil
+}
+
 func (s *MCPStreamableServer) handleListPrompts(req *StreamableRequest) *StreamableResponse {
 	prompts := []map[string]interface{}{
 		{
@@ -789,7 +2080,7 @@ func (s *MCPStreamableServer) getServiceManagementHelp(id interface{}, args map[
 	case "docker":
 		content = "# Docker Container Management\n\nManage Docker containers as services. Key commands:\n- `docker start <container>` - Start a container\n- `docker stop <container>` - Stop a container\n- `docker restart <container>` - Restart a container\n- `docker update --restart=always <container>` - Auto-restart container\n- `docker ps -a` - List all containers\n- `docker logs <container>` - View container logs"
 	default:
-		content = "# Linux Service Management Guide\n\nThis MCP server supports managing services through multiple methods:\n\n## Supported Service Types\n1. **systemd** - Modern Linux distributions\n2. **System V init** - Traditional Linux distributions\n3. **Docker** - Container management\n\n## Available Operations\n- Start/Stop/Restart services\n- Enable/Disable services for boot\n- Get service status and information\n- List all available services\n- View Docker container logs\n\n## Usage\nUse the available tools to manage services. The server will automatically detect which service manager to use based on your system and the service name."
+		content = "# Linux Service Management Guide\n\nThis MCP server supports managing services through multiple methods:\n\n## Supported Service Types\n1. **systemd** - Modern Linux distributions\n2. **System V init** - Traditional Linux distributions\n3. **Docker** - Container management\n4. **Podman** - Rootless containers and pods (use `pod:<name>` for a pod)\n5. **OpenRC** - Alpine and Gentoo; enable/disable manage `default` runlevel membership\n6. **runit / s6** - Supervision trees; enable/disable link the service into the scan directory\n7. **supervisord** - Process groups over XML-RPC (use `group:process` for a single process)\n8. **script** - Services declared under `scripts:` in config.yaml with their own start/stop/status commands\n9. **native** - Processes declared under `native:` in config.yaml, spawned and restarted by this server\n10. **plugins** - External managers declared under `plugins:` in config.yaml; each plugin name is its own service type\n\n## Available Operations\n- Start/Stop/Restart services\n- Enable/Disable services for boot\n- Get service status and information\n- List all available services\n- View Docker container logs\n- Read any service's logs filtered by time, priority and pattern\n- Follow a service's log live, with entries sent as notifications\n- Show CPU, memory, IO and task usage from a service's cgroup\n- Show a service's process tree with the ports each process listens on\n- Find the service that owns a PID, a listening port or an open file\n- Check service health from HTTP, TCP and exec probes and container health checks\n- Review the restarts and escalations made by the watchdog\n- List systemd timers and start, stop, enable, disable or trigger them\n- Create, replace and delete systemd unit files\n- Manage drop-in overrides and show the effective unit configuration\n- Reload, kill, mask, reset-failed, pause and other extended service actions\n\n## Usage\nUse the available tools to manage services. The server will automatically detect which service manager to use based on your system and the service name."
 	}
 
 	result := map[string]interface{}{
@@ -877,7 +2168,7 @@ func (s *MCPStreamableServer) handleHealth(w http.ResponseWriter, r *http.Reques
 
 // Helper methods
 
-func (s *MCPStreamableServer) getServiceManager(serviceName, serviceType string) (types.ServiceManager, error) {
+func (s *MCPStreamableServer) getServiceManager(ctx context.Context, serviceName, serviceType string) (types.ServiceManager, error) {
 	if serviceType != "" {
 		if manager, exists := s.managers[types.ServiceType(serviceType)]; exists {
 			return manager, nil
@@ -887,7 +2178,7 @@ func (s *MCPStreamableServer) getServiceManager(serviceName, serviceType string)
 
 	// Auto-detect service type
 	for _, manager := range s.managers {
-		if _, err := manager.GetStatus(serviceName); err == nil {
+		if _, err := manager.GetStatus(ctx, serviceName); err == nil {
 			return manager, nil
 		}
 	}
@@ -920,10 +2211,23 @@ func (s *MCPStreamableServer) formatServicesOutput(services []types.ServiceInfo)
 	for serviceType, typeServices := range servicesByType {
 		result.WriteString(fmt.Sprintf("## %s Services\n", strings.Title(string(serviceType))))
 		for _, service := range typeServices {
-			result.WriteString(fmt.Sprintf("- **%s**: %s", service.Name, service.Status))
+			result.WriteString(fmt.Sprintf("- **%s**", service.Name))
+			if service.Scope != "" && service.Scope != "system" {
+				result.WriteString(fmt.Sprintf(" (%s)", service.Scope))
+			}
+			result.WriteString(fmt.Sprintf(": %s", service.Status))
 			if service.Description != "" {
 				result.WriteString(fmt.Sprintf(" - %s", service.Description))
 			}
+			if service.EnabledState != "" {
+				result.WriteString(fmt.Sprintf(" [unit file: %s]", service.EnabledState))
+			}
+			if len(service.Listen) > 0 {
+				result.WriteString(fmt.Sprintf(" [listen: %s]", strings.Join(service.Listen, ", ")))
+			}
+			if service.Health != nil {
+				result.WriteString(fmt.Sprintf(" [health: %s]", service.Health.Status))
+			}
 			result.WriteString("\n")
 		}
 		result.WriteString("\n")
@@ -936,6 +2240,12 @@ func (s *MCPStreamableServer) formatServiceInfo(info types.ServiceInfo) string {
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("**Service**: %s\n", info.Name))
 	result.WriteString(fmt.Sprintf("**Type**: %s\n", info.Type))
+	if info.Scope != "" {
+		result.WriteString(fmt.Sprintf("**Scope**: %s\n", info.Scope))
+	}
+	if info.UnitType != "" && info.UnitType != "service" {
+		result.WriteString(fmt.Sprintf("**Unit Type**: %s\n", info.UnitType))
+	}
 	result.WriteString(fmt.Sprintf("**Status**: %s\n", info.Status))
 
 	if info.Description != "" {
@@ -946,6 +2256,38 @@ func (s *MCPStreamableServer) formatServiceInfo(info types.ServiceInfo) string {
 		result.WriteString(fmt.Sprintf("**PID**: %d\n", info.PID))
 	}
 
+	for _, listen := range info.Listen {
+		result.WriteString(fmt.Sprintf("**Listen**: %s\n", listen))
+	}
+
+	if info.SubState != "" {
+		result.WriteString(fmt.Sprintf("**Sub State**: %s\n", info.SubState))
+	}
+	if info.LoadState != "" {
+		result.WriteString(fmt.Sprintf("**Load State**: %s\n", info.LoadState))
+	}
+	if info.EnabledState != "" {
+		result.WriteString(fmt.Sprintf("**Enabled**: %s\n", info.EnabledState))
+	}
+	if info.UnitPath != "" {
+		result.WriteString(fmt.Sprintf("**Unit Path**: %s\n", info.UnitPath))
+	}
+	if info.Restarts > 0 {
+		result.WriteString(fmt.Sprintf("**Restarts**: %d\n", info.Restarts))
+	}
+	if info.ExitCode != nil {
+		result.WriteString(fmt.Sprintf("**Exit Code**: %d\n", *info.ExitCode))
+	}
+	if info.OOMKilled {
+		result.WriteString("**OOM Killed**: yes\n")
+	}
+	if info.MemoryBytes > 0 {
+		result.WriteString(fmt.Sprintf("**Memory**: %.1f MiB\n", float64(info.MemoryBytes)/(1<<20)))
+	}
+	if info.ActiveSince != nil {
+		result.WriteString(fmt.Sprintf("**Active Since**: %s\n", info.ActiveSince.Format("2006-01-02 15:04:05")))
+	}
+
 	if info.Uptime > 0 {
 		result.WriteString(fmt.Sprintf("**Uptime**: %s\n", info.Uptime.String()))
 	}
@@ -954,6 +2296,10 @@ func (s *MCPStreamableServer) formatServiceInfo(info types.ServiceInfo) string {
 		result.WriteString(fmt.Sprintf("**Last Changed**: %s\n", info.LastChanged.Format("2006-01-02 15:04:05")))
 	}
 
+	if info.Health != nil {
+		result.WriteString(info.Health.String())
+	}
+
 	return result.String()
 }
 
@@ -964,7 +2310,9 @@ func (s *MCPStreamableServer) cleanupSessions() {
 	for range ticker.C {
 		s.sessMu.Lock()
 		for id, session := range s.sessions {
-			if time.Since(session.LastSeen) > 10*time.Minute {
+			// Sessions following logs are still listening even if they
+			// send nothing.
+			if time.Since(session.LastSeen) > 10*time.Minute && session.logs.active() == 0 {
 				session.Cancel()
 				delete(s.sessions, id)
 				s.logger.Infof("Cleaned up stale session: %s", id)
@@ -974,6 +2322,34 @@ func (s *MCPStreamableServer) cleanupSessions() {
 	}
 }
 
+func (s *MCPStreamableServer) formatTimersOutput(timers []types.TimerInfo) string {
+	if len(timers) == 0 {
+		return "No timers found."
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d timers:\n\n", len(timers)))
+	for _, timer := range timers {
+		result.WriteString(fmt.Sprintf("- **%s**", timer.Name))
+		if timer.Scope != "" && timer.Scope != "system" {
+			result.WriteString(fmt.Sprintf(" (%s)", timer.Scope))
+		}
+		result.WriteString(fmt.Sprintf(" → %s: %s", timer.Unit, timer.Status))
+		if !timer.NextElapse.IsZero() {
+			result.WriteString(fmt.Sprintf(", next: %s", timer.NextElapse.Format("2006-01-02 15:04:05")))
+		}
+		if !timer.LastTrigger.IsZero() {
+			result.WriteString(fmt.Sprintf(", last: %s", timer.LastTrigger.Format("2006-01-02 15:04:05")))
+		}
+		if timer.Description != "" {
+			result.WriteString(fmt.Sprintf(" - %s", timer.Description))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
 func (s *MCPStreamableServer) createSuccessResponse(id interface{}, result interface{}) *StreamableResponse {
 	return &StreamableResponse{
 		JSONRPC: "2.0",
@@ -1004,6 +2380,14 @@ func (s *MCPStreamableServer) createToolErrorResponse(id interface{}, message st
 	return s.createSuccessResponse(id, result)
 }
 
+// Close releases the service managers, stopping any processes supervised
+// by this server.
+func (s *MCPStreamableServer) Close() error {
+	s.watchdog.Close()
+	s.health.Close()
+	return managers.CloseManagers(s.managers)
+}
+
 func (s *MCPStreamableServer) Start() error {
 	router := s.SetupRoutes()
 	address := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
@@ -1,15 +1,45 @@
 package types
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type ServiceType string
 
 const (
-	ServiceTypeSystemd ServiceType = "systemd"
-	ServiceTypeSysV    ServiceType = "sysv"
-	ServiceTypeDocker  ServiceType = "docker"
+	ServiceTypeSystemd    ServiceType = "systemd"
+	ServiceTypeSysV       ServiceType = "sysv"
+	ServiceTypeOpenRC     ServiceType = "openrc"
+	ServiceTypeRunit      ServiceType = "runit"
+	ServiceTypeS6         ServiceType = "s6"
+	ServiceTypeDocker     ServiceType = "docker"
+	ServiceTypePodman     ServiceType = "podman"
+	ServiceTypeSupervisor ServiceType = "supervisor"
+	ServiceTypeScript     ServiceType = "script"
+	ServiceTypeNative     ServiceType = "native"
 )
 
+// BuiltinServiceTypes lists the service types implemented in this module.
+// Plugins declared in the configuration add their own types at runtime.
+var BuiltinServiceTypes = []ServiceType{
+	ServiceTypeSystemd,
+	ServiceTypeSysV,
+	ServiceTypeOpenRC,
+	ServiceTypeRunit,
+	ServiceTypeS6,
+	ServiceTypeDocker,
+	ServiceTypePodman,
+	ServiceTypeSupervisor,
+	ServiceTypeScript,
+	ServiceTypeNative,
+}
+
 type ServiceStatus string
 
 const (
@@ -27,12 +57,431 @@ type ServiceInfo struct {
 	PID         int           `json:"pid,omitempty"`
 	Uptime      time.Duration `json:"uptime,omitempty"`
 	LastChanged time.Time     `json:"last_changed,omitempty"`
+	// Scope is the systemd instance a unit belongs to: "system", "user"
+	// or "user:<name>". It is empty for other service types.
+	Scope string `json:"scope,omitempty"`
+	// UnitType is the systemd unit type: service, socket, target, mount,
+	// automount or path. Units other than services keep their suffix in
+	// Name.
+	UnitType string `json:"unit_type,omitempty"`
+	// Listen holds a socket unit's listen addresses, e.g. "[::]:22 (Stream)".
+	Listen []string `json:"listen,omitempty"`
+
+	// The fields below are filled in where the backend reports them and
+	// left empty otherwise.
+
+	// EnabledState is whether the service starts at boot: the unit file
+	// state for systemd, e.g. enabled, disabled, static or masked, and
+	// enabled or disabled for the backends that can tell, from runlevel
+	// membership, scan directory links or the container restart policy.
+	EnabledState string `json:"enabled_state,omitempty"`
+	// SubState and LoadState are the backend's own states behind Status,
+	// e.g. running/exited and loaded/not-found for systemd.
+	SubState  string `json:"sub_state,omitempty"`
+	LoadState string `json:"load_state,omitempty"`
+	// UnitPath is the file the service is defined in.
+	UnitPath string `json:"unit_path,omitempty"`
+	// Restarts counts automatic restarts: NRestarts for systemd,
+	// RestartCount for Docker and Podman, and the restarts since the last
+	// start for native processes.
+	Restarts int `json:"restarts,omitempty"`
+	// ExitCode is the main process's last exit code, nil while it has not
+	// exited. Processes killed by a signal report 128+signal.
+	ExitCode  *int `json:"exit_code,omitempty"`
+	OOMKilled bool `json:"oom_killed,omitempty"`
+	// MemoryBytes is the memory currently used by the service's processes.
+	MemoryBytes uint64 `json:"memory_bytes,omitempty"`
+	// ActiveSince is when the service last became active, nil while it is
+	// not.
+	ActiveSince *time.Time `json:"active_since,omitempty"`
+
+	// Health is the outcome of the service's health probes and of the
+	// container engine's own health check, nil when it has neither. It is
+	// separate from Status: an active service can be unhealthy.
+	Health *ServiceHealth `json:"health,omitempty"`
+}
+
+// HealthStatus is the state of a health probe or of a service's overall
+// health, named as Docker names container health.
+type HealthStatus string
+
+const (
+	// HealthStarting means no probe result has reached its threshold yet.
+	HealthStarting  HealthStatus = "starting"
+	HealthHealthy   HealthStatus = "healthy"
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
+// ServiceHealth combines the probes of one service: it is unhealthy when any
+// probe is, starting while any probe is, and healthy otherwise.
+type ServiceHealth struct {
+	Status HealthStatus  `json:"status"`
+	Probes []ProbeResult `json:"probes"`
+}
+
+// NewServiceHealth combines probe results into a service's health.
+func NewServiceHealth(probes []ProbeResult) *ServiceHealth {
+	health := &ServiceHealth{Status: HealthHealthy, Probes: probes}
+	for _, probe := range probes {
+		switch probe.Status {
+		case HealthUnhealthy:
+			health.Status = HealthUnhealthy
+		case HealthStarting:
+			if health.Status == HealthHealthy {
+				health.Status = HealthStarting
+			}
+		}
+	}
+	return health
+}
+
+// ProbeResult is the current state of one health probe.
+type ProbeResult struct {
+	Service     string      `json:"service"`
+	ServiceType ServiceType `json:"service_type,omitempty"`
+	// Name is the probe's configured name, or its kind: http, tcp, exec,
+	// or docker and podman for the engine's HEALTHCHECK.
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Target is the probed URL, address or command.
+	Target string       `json:"target,omitempty"`
+	Status HealthStatus `json:"status"`
+	// The streaks count consecutive results; the probe turns healthy or
+	// unhealthy when one reaches its threshold.
+	SuccessStreak int       `json:"success_streak"`
+	FailingStreak int       `json:"failing_streak"`
+	LastCheck     time.Time `json:"last_check,omitempty"`
+	// Output is the last failure's error or the exec probe's output.
+	Output string `json:"output,omitempty"`
+}
+
+// String renders the health for tool output, e.g.
+//
+//	**Health**: unhealthy
+//	- http (http://127.0.0.1:8080/healthz): unhealthy, 3 failures: connection refused
+func (h ServiceHealth) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Health**: %s\n", h.Status)
+	for _, probe := range h.Probes {
+		fmt.Fprintf(&b, "- %s\n", probe)
+	}
+	return b.String()
+}
+
+// String renders the probe as "name (target): status, N failures: output".
+func (p ProbeResult) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	if p.Target != "" {
+		fmt.Fprintf(&b, " (%s)", p.Target)
+	}
+	fmt.Fprintf(&b, ": %s", p.Status)
+	if p.FailingStreak > 0 {
+		fmt.Fprintf(&b, ", %d failures", p.FailingStreak)
+	}
+	if p.Output != "" {
+		fmt.Fprintf(&b, ": %s", p.Output)
+	}
+	return b.String()
+}
+
+// WatchdogAction records an action the watchdog took on its own, as opposed
+// to one requested through the API.
+type WatchdogAction struct {
+	Time        time.Time   `json:"time"`
+	Service     string      `json:"service"`
+	ServiceType ServiceType `json:"service_type,omitempty"`
+	// Action is "restart", or "escalate" when the restart budget ran out.
+	Action string `json:"action"`
+	// Reason is the condition that triggered the action, "failed" or
+	// "unhealthy" followed by the failing probes.
+	Reason string `json:"reason"`
+	// Attempt numbers the restart within the policy's window.
+	Attempt int `json:"attempt"`
+	// Error is set when the restart or the escalation hook failed.
+	Error string `json:"error,omitempty"`
+}
+
+// WatchdogState is the watchdog's view of one watched service.
+type WatchdogState struct {
+	Service     string      `json:"service"`
+	ServiceType ServiceType `json:"service_type,omitempty"`
+	Scope       string      `json:"scope,omitempty"`
+	// State is "watching", "backoff" while a restart waits for its delay,
+	// or "exhausted" once MaxAttempts restarts happened within the window.
+	State string `json:"state"`
+	// Attempts counts the restarts within the current window.
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"max_attempts"`
+	NextAttempt time.Time `json:"next_attempt,omitempty"`
+	LastCheck   time.Time `json:"last_check,omitempty"`
+	// LastError is why the service's status could not be read at the last
+	// check.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// String renders the state as "service: state, N/M restarts in window".
+func (s WatchdogState) String() string {
+	var b strings.Builder
+	b.WriteString(s.Service)
+	if s.ServiceType != "" {
+		fmt.Fprintf(&b, " (%s)", s.ServiceType)
+	}
+	fmt.Fprintf(&b, ": %s, %d/%d restarts in window", s.State, s.Attempts, s.MaxAttempts)
+	if !s.NextAttempt.IsZero() {
+		fmt.Fprintf(&b, ", next attempt %s", s.NextAttempt.Format("2006-01-02 15:04:05"))
+	}
+	if s.LastError != "" {
+		fmt.Fprintf(&b, ", last check failed: %s", s.LastError)
+	}
+	return b.String()
+}
+
+// String renders the action as "time action service (reason), attempt N".
+func (a WatchdogAction) String() string {
+	text := fmt.Sprintf("%s %s %s (%s), attempt %d", a.Time.Format("2006-01-02 15:04:05"), a.Action, a.Service, a.Reason, a.Attempt)
+	if a.Error != "" {
+		text += ", failed: " + a.Error
+	}
+	return text
+}
+
+// TimerInfo describes a systemd timer and the unit it activates. Zero
+// times mean the timer is not scheduled or has never elapsed.
+type TimerInfo struct {
+	Name        string        `json:"name"`
+	Unit        string        `json:"unit"`
+	Status      ServiceStatus `json:"status"`
+	Description string        `json:"description,omitempty"`
+	NextElapse  time.Time     `json:"next_elapse,omitempty"`
+	LastTrigger time.Time     `json:"last_trigger,omitempty"`
+	Scope       string        `json:"scope,omitempty"`
+}
+
+// UnitFileSpec describes a simple service unit. It is rendered into a unit
+// file with [Unit], [Service] and, when WantedBy is set, [Install] sections.
+type UnitFileSpec struct {
+	Description      string            `json:"description,omitempty"`
+	Type             string            `json:"type,omitempty"`
+	ExecStart        string            `json:"exec_start"`
+	User             string            `json:"user,omitempty"`
+	Group            string            `json:"group,omitempty"`
+	WorkingDirectory string            `json:"working_directory,omitempty"`
+	Environment      map[string]string `json:"environment,omitempty"`
+	Restart          string            `json:"restart,omitempty"`
+	WantedBy         string            `json:"wanted_by,omitempty"`
+}
+
+// UnitFileRequest creates or replaces a unit file from either raw Content
+// or a Spec.
+type UnitFileRequest struct {
+	Content string        `json:"content,omitempty"`
+	Spec    *UnitFileSpec `json:"spec,omitempty"`
+	Scope   string        `json:"scope,omitempty"`
+}
+
+// UnitFileInfo describes an installed unit file. Backup is the path the
+// previous version was saved to, if there was one.
+type UnitFileInfo struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+// DropInInfo describes a drop-in override file of a unit.
+type DropInInfo struct {
+	Unit    string `json:"unit"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+}
+
+// LogEntry is one entry of a service's log. Priority is the syslog level,
+// from 0 (emerg) to 7 (debug); sources without levels report 6 (info), or
+// 3 (err) for a container's stderr.
+type LogEntry struct {
+	Time     time.Time `json:"time,omitempty"`
+	Priority int       `json:"priority"`
+	Message  string    `json:"message"`
+	// Source is where the entry was read from: a unit, a log file, or a
+	// container's stdout or stderr.
+	Source string `json:"source,omitempty"`
+	PID    int    `json:"pid,omitempty"`
+}
+
+var logPriorityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// String renders the entry as one line, e.g.
+// "2024-01-02T15:04:05Z [err] nginx[42]: bind failed".
+func (e LogEntry) String() string {
+	var b strings.Builder
+	if !e.Time.IsZero() {
+		b.WriteString(e.Time.Format(time.RFC3339))
+		b.WriteByte(' ')
+	}
+	if e.Priority >= 0 && e.Priority < len(logPriorityNames) {
+		fmt.Fprintf(&b, "[%s] ", logPriorityNames[e.Priority])
+	}
+	if e.Source != "" {
+		b.WriteString(e.Source)
+		if e.PID > 0 {
+			fmt.Fprintf(&b, "[%d]", e.PID)
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// ServiceStats is a snapshot of a service's resource usage, read from its
+// cgroup or from the container engine. Counters are cumulative since the
+// service started; values a source does not report are zero.
+type ServiceStats struct {
+	Name string `json:"name"`
+	// CGroup is the service's cgroup below the cgroup root.
+	CGroup        string `json:"cgroup,omitempty"`
+	CPUUsageUsec  uint64 `json:"cpu_usage_usec"`
+	CPUUserUsec   uint64 `json:"cpu_user_usec,omitempty"`
+	CPUSystemUsec uint64 `json:"cpu_system_usec,omitempty"`
+	MemoryCurrent uint64 `json:"memory_current"`
+	MemoryPeak    uint64 `json:"memory_peak,omitempty"`
+	// MemoryMax is the memory limit; zero means unlimited.
+	MemoryMax    uint64 `json:"memory_max,omitempty"`
+	IOReadBytes  uint64 `json:"io_read_bytes"`
+	IOWriteBytes uint64 `json:"io_write_bytes"`
+	PidsCurrent  uint64 `json:"pids_current"`
+	// MemoryEvents are the memory.events counters, such as oom_kill.
+	MemoryEvents map[string]uint64 `json:"memory_events,omitempty"`
+}
+
+// String renders the stats as Markdown lines, one per value.
+func (s ServiceStats) String() string {
+	mib := func(bytes uint64) string { return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20)) }
+
+	var b strings.Builder
+	if s.CGroup != "" {
+		fmt.Fprintf(&b, "**CGroup**: %s\n", s.CGroup)
+	}
+	fmt.Fprintf(&b, "**CPU Time**: %s", time.Duration(s.CPUUsageUsec)*time.Microsecond)
+	if s.CPUUserUsec > 0 || s.CPUSystemUsec > 0 {
+		fmt.Fprintf(&b, " (user %s, system %s)", time.Duration(s.CPUUserUsec)*time.Microsecond, time.Duration(s.CPUSystemUsec)*time.Microsecond)
+	}
+	b.WriteByte('\n')
+	fmt.Fprintf(&b, "**Memory**: %s", mib(s.MemoryCurrent))
+	if s.MemoryPeak > 0 {
+		fmt.Fprintf(&b, ", peak %s", mib(s.MemoryPeak))
+	}
+	if s.MemoryMax > 0 {
+		fmt.Fprintf(&b, ", limit %s", mib(s.MemoryMax))
+	}
+	b.WriteByte('\n')
+	fmt.Fprintf(&b, "**IO**: %s read, %s written\n", mib(s.IOReadBytes), mib(s.IOWriteBytes))
+	fmt.Fprintf(&b, "**Tasks**: %d\n", s.PidsCurrent)
+	if len(s.MemoryEvents) > 0 {
+		names := make([]string, 0, len(s.MemoryEvents))
+		for name := range s.MemoryEvents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		events := make([]string, len(names))
+		for i, name := range names {
+			events[i] = fmt.Sprintf("%s=%d", name, s.MemoryEvents[name])
+		}
+		fmt.Fprintf(&b, "**Memory Events**: %s\n", strings.Join(events, ", "))
+	}
+	return b.String()
+}
+
+// ProcessInfo describes a process of a service as read from /proc.
+type ProcessInfo struct {
+	PID  int    `json:"pid"`
+	PPID int    `json:"ppid"`
+	User string `json:"user,omitempty"`
+	// Command is the command line, or the process name in brackets for
+	// kernel threads and zombies.
+	Command  string `json:"command"`
+	RSSBytes uint64 `json:"rss_bytes"`
+	// OpenFiles is zero when the process's descriptors cannot be read,
+	// which needs root for other users' processes.
+	OpenFiles int            `json:"open_files"`
+	Listening []ListenSocket `json:"listening,omitempty"`
+}
+
+// ListenSocket is a TCP socket in the listening state, or a bound but
+// unconnected UDP socket.
+type ListenSocket struct {
+	Protocol string `json:"protocol"`
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+}
+
+func (l ListenSocket) String() string {
+	return fmt.Sprintf("%s %s", l.Protocol, net.JoinHostPort(l.Address, strconv.Itoa(l.Port)))
+}
+
+// ProcessTree renders processes as a tree, children indented below their
+// parent, e.g.
+//
+//	1234 root 12.0 MiB, 9 files: nginx: master process [tcp 0.0.0.0:80]
+//	  1235 www-data 4.0 MiB, 7 files: nginx: worker process
+func ProcessTree(processes []ProcessInfo) string {
+	present := make(map[int]bool, len(processes))
+	children := make(map[int][]ProcessInfo)
+	for _, p := range processes {
+		present[p.PID] = true
+	}
+	var roots []ProcessInfo
+	for _, p := range processes {
+		if present[p.PPID] && p.PPID != p.PID {
+			children[p.PPID] = append(children[p.PPID], p)
+		} else {
+			roots = append(roots, p)
+		}
+	}
+
+	var b strings.Builder
+	var write func(p ProcessInfo, depth int)
+	write = func(p ProcessInfo, depth int) {
+		fmt.Fprintf(&b, "%s%d %s %.1f MiB, %d files: %s", strings.Repeat("  ", depth), p.PID, p.User, float64(p.RSSBytes)/(1<<20), p.OpenFiles, p.Command)
+		if len(p.Listening) > 0 {
+			sockets := make([]string, len(p.Listening))
+			for i, l := range p.Listening {
+				sockets[i] = l.String()
+			}
+			fmt.Fprintf(&b, " [%s]", strings.Join(sockets, ", "))
+		}
+		b.WriteByte('\n')
+		for _, child := range children[p.PID] {
+			write(child, depth+1)
+		}
+	}
+	for _, p := range roots {
+		write(p, 0)
+	}
+	return b.String()
+}
+
+// ServiceOwner identifies the service a process, listening port or open
+// file belongs to.
+type ServiceOwner struct {
+	Type ServiceType `json:"type"`
+	// Name is the unit name without .service, the container ID or the
+	// init script name.
+	Name  string `json:"name"`
+	Scope string `json:"scope,omitempty"`
+	// PID is the process through which the service was found.
+	PID    int    `json:"pid"`
+	CGroup string `json:"cgroup,omitempty"`
 }
 
 type ServiceRequest struct {
 	Name   string      `json:"name"`
 	Type   ServiceType `json:"type,omitempty"`
 	Action string      `json:"action"`
+	Scope  string      `json:"scope,omitempty"`
+	// Signal is sent by the kill action, SIGTERM by default.
+	Signal string `json:"signal,omitempty"`
 }
 
 type ServiceResponse struct {
@@ -47,12 +496,14 @@ type ServiceListResponse struct {
 	Services []ServiceInfo `json:"services"`
 }
 
+// ServiceManager is implemented by every backend. All methods honour ctx:
+// cancellation or an expired deadline aborts the underlying operation.
 type ServiceManager interface {
-	Start(serviceName string) error
-	Stop(serviceName string) error
-	Restart(serviceName string) error
-	GetStatus(serviceName string) (ServiceInfo, error)
-	ListServices() ([]ServiceInfo, error)
-	Enable(serviceName string) error
-	Disable(serviceName string) error
-}
\ No newline at end of file
+	Start(ctx context.Context, serviceName string) error
+	Stop(ctx context.Context, serviceName string) error
+	Restart(ctx context.Context, serviceName string) error
+	GetStatus(ctx context.Context, serviceName string) (ServiceInfo, error)
+	ListServices(ctx context.Context) ([]ServiceInfo, error)
+	Enable(ctx context.Context, serviceName string) error
+	Disable(ctx context.Context, serviceName string) error
+}
@@ -217,14 +217,87 @@ func TestServiceType_Constants(t *testing.T) {
 	types := []ServiceType{
 		ServiceTypeSystemd,
 		ServiceTypeSysV,
+		ServiceTypeOpenRC,
+		ServiceTypeRunit,
+		ServiceTypeS6,
 		ServiceTypeDocker,
+		ServiceTypePodman,
+		ServiceTypeSupervisor,
+		ServiceTypeScript,
+		ServiceTypeNative,
 	}
 
-	expectedValues := []string{"systemd", "sysv", "docker"}
+	expectedValues := []string{"systemd", "sysv", "openrc", "runit", "s6", "docker", "podman", "supervisor", "script", "native"}
 
 	for i, serviceType := range types {
 		if string(serviceType) != expectedValues[i] {
 			t.Errorf("Type constant %d mismatch: expected %s, got %s", i, expectedValues[i], string(serviceType))
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestServiceStats_String(t *testing.T) {
+	stats := ServiceStats{
+		CPUUsageUsec:  1500000,
+		MemoryCurrent: 10 << 20,
+		MemoryEvents:  map[string]uint64{"oom_kill": 1, "high": 2},
+	}
+	expected := "**CPU Time**: 1.5s\n**Memory**: 10.0 MiB\n**IO**: 0.0 MiB read, 0.0 MiB written\n**Tasks**: 0\n**Memory Events**: high=2, oom_kill=1\n"
+	if got := stats.String(); got != expected {
+		t.Errorf("Unexpected stats text:\n%s", got)
+	}
+}
+
+func TestProcessTree(t *testing.T) {
+	processes := []ProcessInfo{
+		{PID: 10, PPID: 1, User: "root", Command: "nginx: master process", RSSBytes: 12 << 20, OpenFiles: 9,
+			Listening: []ListenSocket{{Protocol: "tcp", Address: "0.0.0.0", Port: 80}, {Protocol: "tcp6", Address: "::", Port: 80}}},
+		{PID: 11, PPID: 10, User: "www-data", Command: "nginx: worker process", RSSBytes: 4 << 20, OpenFiles: 7},
+		{PID: 20, PPID: 1, User: "root", Command: "helper", RSSBytes: 1 << 20},
+	}
+	expected := "10 root 12.0 MiB, 9 files: nginx: master process [tcp 0.0.0.0:80, tcp6 [::]:80]\n" +
+		"  11 www-data 4.0 MiB, 7 files: nginx: worker process\n" +
+		"20 root 1.0 MiB, 0 files: helper\n"
+	if got := ProcessTree(processes); got != expected {
+		t.Errorf("Unexpected process tree:\n%s", got)
+	}
+}
+
+func TestNewServiceHealth(t *testing.T) {
+	tests := []struct {
+		statuses []HealthStatus
+		want     HealthStatus
+	}{
+		{[]HealthStatus{HealthHealthy, HealthHealthy}, HealthHealthy},
+		{[]HealthStatus{HealthHealthy, HealthStarting}, HealthStarting},
+		{[]HealthStatus{HealthStarting, HealthUnhealthy}, HealthUnhealthy},
+	}
+	for _, tt := range tests {
+		var probes []ProbeResult
+		for _, status := range tt.statuses {
+			probes = append(probes, ProbeResult{Status: status})
+		}
+		if got := NewServiceHealth(probes).Status; got != tt.want {
+			t.Errorf("NewServiceHealth(%v) = %s, want %s", tt.statuses, got, tt.want)
+		}
+	}
+
+	health := NewServiceHealth([]ProbeResult{{Name: "http", Target: "http://127.0.0.1/healthz", Status: HealthUnhealthy, FailingStreak: 3, Output: "connection refused"}})
+	expected := "**Health**: unhealthy\n- http (http://127.0.0.1/healthz): unhealthy, 3 failures: connection refused\n"
+	if health.String() != expected {
+		t.Errorf("Unexpected health output:\n%s", health.String())
+	}
+}
+
+func TestWatchdog_String(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 30, 0, time.UTC)
+	state := WatchdogState{Service: "nginx", ServiceType: ServiceTypeSystemd, State: "backoff", Attempts: 1, MaxAttempts: 5, NextAttempt: at}
+	if got := state.String(); got != "nginx (systemd): backoff, 1/5 restarts in window, next attempt 2024-05-01 12:00:30" {
+		t.Errorf("Unexpected state output: %s", got)
+	}
+
+	action := WatchdogAction{Time: at, Service: "nginx", Action: "restart", Reason: "failed", Attempt: 2, Error: "timeout"}
+	if got := action.String(); got != "2024-05-01 12:00:30 restart nginx (failed), attempt 2, failed: timeout" {
+		t.Errorf("Unexpected action output: %s", got)
+	}
+}